@@ -0,0 +1,81 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ChaosPluginSpec defines an out-of-process injector serving the havock8s
+// plugin gRPC protocol
+type ChaosPluginSpec struct {
+	// ChaosTypes are the chaos types the plugin injects. They must not clash
+	// with built-in chaos types or the chaos types of other plugins.
+	// +kubebuilder:validation:MinItems=1
+	ChaosTypes []string `json:"chaosTypes"`
+
+	// Service is the Service the plugin's gRPC server listens behind
+	Service PluginServiceReference `json:"service"`
+
+	// CABundle is a PEM encoded CA bundle used to verify the plugin's serving
+	// certificate. The connection is not encrypted when empty.
+	// +optional
+	CABundle string `json:"caBundle,omitempty"`
+
+	// TimeoutSeconds bounds each call to the plugin. Defaults to 30 seconds.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// PluginServiceReference names the Service of a plugin
+type PluginServiceReference struct {
+	// Name of the Service
+	Name string `json:"name"`
+
+	// Namespace of the Service
+	Namespace string `json:"namespace"`
+
+	// Port the plugin's gRPC server listens on
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+}
+
+// ChaosPluginStatus defines the observed state of a ChaosPlugin
+type ChaosPluginStatus struct {
+	// RegisteredChaosTypes are the chaos types experiments can use the plugin for
+	// +optional
+	RegisteredChaosTypes []string `json:"registeredChaosTypes,omitempty"`
+
+	// Conditions represent the latest available observations of the plugin's state
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Types",type="string",JSONPath=".status.registeredChaosTypes",description="Registered chaos types"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Plugin registered"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ChaosPlugin is the Schema for the chaosplugins API
+type ChaosPlugin struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ChaosPluginSpec   `json:"spec,omitempty"`
+	Status ChaosPluginStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ChaosPluginList contains a list of ChaosPlugin
+type ChaosPluginList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ChaosPlugin `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ChaosPlugin{}, &ChaosPluginList{})
+}
@@ -0,0 +1,5 @@
+package v1alpha1
+
+// Hub marks v1alpha1 as the version other versions convert through. It is
+// also the storage version the controller works with.
+func (*Havock8sExperiment) Hub() {}
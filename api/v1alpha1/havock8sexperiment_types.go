@@ -9,8 +9,9 @@ type Havock8sExperimentSpec struct {
 	// Target defines the selection criteria for what to target with chaos
 	Target TargetSpec `json:"target"`
 
-	// ChaosType defines the type of chaos to be injected
-	// +kubebuilder:validation:Enum=DiskFailure;NetworkLatency;DatabaseConnectionDisruption;PodFailure;ResourcePressure;DataCorruption;StatefulSetScaling
+	// ChaosType defines the type of chaos to be injected, either a built-in
+	// chaos type or one registered by a ChaosPlugin
+	// +kubebuilder:validation:MinLength=1
 	ChaosType string `json:"chaosType"`
 
 	// Duration defines how long the chaos experiment should run
@@ -50,21 +51,132 @@ type TargetSpec struct {
 	Namespace string `json:"namespace,omitempty"`
 
 	// TargetType defines what type of resource to target
-	// +kubebuilder:validation:Enum=StatefulSet;Deployment;Pod;PersistentVolume;PersistentVolumeClaim;Service
+	// +kubebuilder:validation:Enum=StatefulSet;Deployment;ReplicaSet;DaemonSet;Job;Pod;PersistentVolume;PersistentVolumeClaim;Service
 	// +optional
 	TargetType string `json:"targetType,omitempty"`
 
 	// Mode defines how to select targets from the filtered resources
-	// (one, all, random, percentage)
-	// +kubebuilder:validation:Enum=One;All;Random;Percentage;Fixed
+	// (one, all, fixed, fixed-percent, random-max-percent). The modes of the
+	// original API are still accepted: One, All and Fixed mean one, all and
+	// fixed, Random means one and Percentage means fixed-percent.
+	// +kubebuilder:validation:Enum=one;all;fixed;fixed-percent;random-max-percent;One;All;Random;Percentage;Fixed
 	// +optional
 	Mode string `json:"mode,omitempty"`
 
 	// Value is used in conjunction with Mode (e.g., percentage or fixed count)
 	// +optional
 	Value string `json:"value,omitempty"`
+
+	// StatefulSetPods narrows a StatefulSet target down to some of its pods,
+	// by ordinal or by role. The selected pods become the targets and Mode
+	// and Value pick among them.
+	// +optional
+	StatefulSetPods *StatefulSetPodsSpec `json:"statefulSetPods,omitempty"`
+}
+
+// StatefulSetPodsSpec selects pods of a StatefulSet. Every option that is set
+// must match: Ordinals, OrdinalRange and Role filter the pods, then Ordinal
+// keeps the highest or lowest of the remaining ones.
+type StatefulSetPodsSpec struct {
+	// Ordinals of the selected pods, e.g. [0, 2]
+	// +kubebuilder:validation:items:Minimum=0
+	// +optional
+	Ordinals []int32 `json:"ordinals,omitempty"`
+
+	// OrdinalRange selects the pods whose ordinal is within an inclusive
+	// range, e.g. "1-3"
+	// +kubebuilder:validation:Pattern=`^[0-9]+-[0-9]+$`
+	// +optional
+	OrdinalRange string `json:"ordinalRange,omitempty"`
+
+	// Ordinal selects the pod with the highest or lowest ordinal
+	// +kubebuilder:validation:Enum=highest;lowest
+	// +optional
+	Ordinal string `json:"ordinal,omitempty"`
+
+	// Role selects pods by the role they play in a replicated system
+	// +optional
+	Role *PodRoleSpec `json:"role,omitempty"`
+}
+
+// PodRoleSpec discovers the role of each pod from exactly one of a label, an
+// annotation or a probe run in the pod, and selects the pods playing Role
+type PodRoleSpec struct {
+	// Role of the selected pods. The postgres and mongodb probes report
+	// primary or replica, the other sources are compared verbatim.
+	Role string `json:"role"`
+
+	// Label holding the role of each pod, e.g. spilo-role
+	// +optional
+	Label string `json:"label,omitempty"`
+
+	// Annotation holding the role of each pod
+	// +optional
+	Annotation string `json:"annotation,omitempty"`
+
+	// Probe discovers the role by running a built-in command in each pod
+	// through the pods/exec subresource: postgres runs pg_is_in_recovery(),
+	// mongodb runs rs.isMaster()
+	// +kubebuilder:validation:Enum=postgres;mongodb
+	// +optional
+	Probe string `json:"probe,omitempty"`
+
+	// Container probes run in. Defaults to the pod's first container.
+	// +optional
+	Container string `json:"container,omitempty"`
 }
 
+// Target selection modes for TargetSpec.Mode
+const (
+	// TargetModeOne selects a single random target
+	TargetModeOne = "one"
+
+	// TargetModeAll selects every matching target
+	TargetModeAll = "all"
+
+	// TargetModeFixed selects Value random targets
+	TargetModeFixed = "fixed"
+
+	// TargetModeFixedPercent selects Value percent of the matching targets
+	TargetModeFixedPercent = "fixed-percent"
+
+	// TargetModeRandomMaxPercent selects a random number of targets up to Value percent
+	TargetModeRandomMaxPercent = "random-max-percent"
+
+	// TargetModeRandom is the original API's name for TargetModeOne
+	TargetModeRandom = "Random"
+
+	// TargetModePercentage is the original API's name for TargetModeFixedPercent
+	TargetModePercentage = "Percentage"
+)
+
+// Ordinals for StatefulSetPodsSpec.Ordinal
+const (
+	// OrdinalHighest selects the pod with the highest ordinal
+	OrdinalHighest = "highest"
+
+	// OrdinalLowest selects the pod with the lowest ordinal
+	OrdinalLowest = "lowest"
+)
+
+// Role probes for PodRoleSpec.Probe
+const (
+	// RoleProbePostgres asks PostgreSQL whether it is in recovery
+	RoleProbePostgres = "postgres"
+
+	// RoleProbeMongoDB asks MongoDB whether it is the replica set primary
+	RoleProbeMongoDB = "mongodb"
+)
+
+// Roles reported by the postgres and mongodb probes
+const (
+	// RolePrimary is the role of the pod accepting writes
+	RolePrimary = "primary"
+
+	// RoleReplica is the role of the pods replicating from the primary
+	RoleReplica = "replica"
+)
+
 // ScheduleSpec defines when to run chaos experiments
 type ScheduleSpec struct {
 	// Cron expression for scheduling experiments
@@ -78,14 +190,44 @@ type ScheduleSpec struct {
 	// Once runs the experiment only once
 	// +optional
 	Once bool `json:"once,omitempty"`
+
+	// ConcurrencyPolicy specifies how to treat a scheduled run while the
+	// previous run is still active (Forbid, Allow, Replace)
+	// +kubebuilder:validation:Enum=Forbid;Allow;Replace
+	// +kubebuilder:default=Forbid
+	// +optional
+	ConcurrencyPolicy string `json:"concurrencyPolicy,omitempty"`
+
+	// HistoryLimit is the number of finished runs to keep. Defaults to 3.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	HistoryLimit *int32 `json:"historyLimit,omitempty"`
 }
 
+// Concurrency policies for ScheduleSpec.ConcurrencyPolicy
+const (
+	// ConcurrencyPolicyForbid does not start a run while another one is active
+	ConcurrencyPolicyForbid = "Forbid"
+
+	// ConcurrencyPolicyAllow lets runs overlap
+	ConcurrencyPolicyAllow = "Allow"
+
+	// ConcurrencyPolicyReplace stops the active runs before starting a new one
+	ConcurrencyPolicyReplace = "Replace"
+)
+
 // SafetySpec defines safety mechanisms for chaos experiments
 type SafetySpec struct {
 	// AutoRollback automatically reverses chaos when conditions are met
 	// +optional
 	AutoRollback bool `json:"autoRollback,omitempty"`
 
+	// CheckInterval is how often health checks and pause conditions are
+	// evaluated while the chaos is active. Defaults to 30s.
+	// +kubebuilder:validation:Pattern=^([0-9]+h)?([0-9]+m)?([0-9]+s)?$
+	// +optional
+	CheckInterval string `json:"checkInterval,omitempty"`
+
 	// HealthChecks defines endpoints to monitor during experiments
 	// +optional
 	HealthChecks []HealthCheckSpec `json:"healthChecks,omitempty"`
@@ -112,10 +254,57 @@ type HealthCheckSpec struct {
 	// +optional
 	Port int32 `json:"port,omitempty"`
 
-	// Command for exec health checks
+	// Host to probe instead of the target pods, e.g. an external hostname
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// Service is the name of a Service in the target namespace to probe
+	// instead of the target pods
+	// +optional
+	Service string `json:"service,omitempty"`
+
+	// Scheme used by httpGet health checks (HTTP or HTTPS)
+	// +kubebuilder:validation:Enum=HTTP;HTTPS
+	// +optional
+	Scheme string `json:"scheme,omitempty"`
+
+	// CABundle is a PEM encoded CA bundle used to verify HTTPS endpoints.
+	// Defaults to the system roots.
+	// +optional
+	CABundle string `json:"caBundle,omitempty"`
+
+	// ServerName is the name HTTPS certificates are verified against.
+	// Defaults to the probed host.
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+
+	// InsecureSkipVerify disables certificate verification for HTTPS endpoints
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+
+	// ExpectedStatusCodes are the HTTP status codes considered healthy.
+	// Defaults to any code from 200 to 399.
+	// +optional
+	ExpectedStatusCodes []int32 `json:"expectedStatusCodes,omitempty"`
+
+	// ExpectedBody is a regular expression the HTTP response body must match
+	// +optional
+	ExpectedBody string `json:"expectedBody,omitempty"`
+
+	// Command for exec health checks, run in each target pod through the
+	// pods/exec subresource. A zero exit code is healthy.
 	// +optional
 	Command []string `json:"command,omitempty"`
 
+	// Container exec health checks run in. Defaults to the pod's first container.
+	// +optional
+	Container string `json:"container,omitempty"`
+
+	// TimeoutSeconds after which a probe counts as failed. Defaults to 5.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+
 	// FailureThreshold defines how many consecutive failures constitute unhealthy
 	// +optional
 	FailureThreshold int32 `json:"failureThreshold,omitempty"`
@@ -126,13 +315,22 @@ type PauseConditionSpec struct {
 	// Type of condition (metric, alert, manual)
 	Type string `json:"type"`
 
-	// MetricQuery for metric-based conditions
+	// MetricQuery is the PromQL expression evaluated for metric-based conditions
 	// +optional
 	MetricQuery string `json:"metricQuery,omitempty"`
 
-	// Threshold for metric-based conditions
+	// Threshold for metric-based conditions, an optional comparison operator
+	// (>, <, >=, <=, ==, !=) followed by a number, e.g. ">= 10". A bare number
+	// means ">". Without a threshold the condition is met when the query
+	// returns any sample.
 	// +optional
 	Threshold string `json:"threshold,omitempty"`
+
+	// Window is how long the condition must hold before it is met, evaluated
+	// as a range query over the window. Without a window the current value is used.
+	// +kubebuilder:validation:Pattern=^([0-9]+h)?([0-9]+m)?([0-9]+s)?$
+	// +optional
+	Window string `json:"window,omitempty"`
 }
 
 // ProtectionSpec defines protected resources
@@ -147,7 +345,9 @@ type ProtectionSpec struct {
 
 // Havock8sExperimentStatus defines the observed state of a chaos experiment
 type Havock8sExperimentStatus struct {
-	// Phase of the chaos experiment (Pending, Running, Completed, Failed)
+	// Phase of the chaos experiment (Pending, Injecting, Running, Completed,
+	// Failed, RolledBack), or Scheduled for an experiment that spawns runs on a
+	// cron schedule
 	Phase string `json:"phase"`
 
 	// StartTime when the experiment began
@@ -169,6 +369,52 @@ type Havock8sExperimentStatus struct {
 	// FailureReason provides more information about a failure
 	// +optional
 	FailureReason string `json:"failureReason,omitempty"`
+
+	// LastScheduleTime is when the last run of a scheduled experiment was started
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// NextScheduleTime is when the next run of a scheduled experiment is due
+	// +optional
+	NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+
+	// ActiveRuns lists the names of the runs of a scheduled experiment that
+	// have not finished yet
+	// +optional
+	ActiveRuns []string `json:"activeRuns,omitempty"`
+
+	// LastSafetyCheckTime is when the safety checks last ran while the chaos was active
+	// +optional
+	LastSafetyCheckTime *metav1.Time `json:"lastSafetyCheckTime,omitempty"`
+
+	// HealthChecks reports the result of each health check in spec.safety.healthChecks
+	// +optional
+	HealthChecks []HealthCheckStatus `json:"healthChecks,omitempty"`
+}
+
+// HealthCheckStatus describes the latest result of a health check
+type HealthCheckStatus struct {
+	// Index of the health check in spec.safety.healthChecks
+	Index int32 `json:"index"`
+
+	// Type of the health check
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Healthy is true when the last probe succeeded
+	Healthy bool `json:"healthy"`
+
+	// ConsecutiveFailures counts the failed probes since the last success
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+
+	// LastProbeTime is when the health check last ran
+	// +optional
+	LastProbeTime *metav1.Time `json:"lastProbeTime,omitempty"`
+
+	// Message explains the last failure, naming the endpoint or pod that failed
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // TargetResourceStatus describes a resource affected by chaos
@@ -190,6 +436,12 @@ type TargetResourceStatus struct {
 	// Status of chaos injection for this target
 	// +optional
 	Status string `json:"status,omitempty"`
+
+	// RestartCount is how often the targeted containers restarted since the
+	// chaos was injected, reported by chaos types that kill containers or
+	// processes
+	// +optional
+	RestartCount *int32 `json:"restartCount,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -199,6 +451,7 @@ type TargetResourceStatus struct {
 // +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Experiment phase"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:resource:shortName=h8s
+// +kubebuilder:storageversion
 
 // Havock8sExperiment is the Schema for the chaosexperiments API
 type Havock8sExperiment struct {
@@ -9,6 +9,113 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChaosPlugin) DeepCopyInto(out *ChaosPlugin) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChaosPlugin.
+func (in *ChaosPlugin) DeepCopy() *ChaosPlugin {
+	if in == nil {
+		return nil
+	}
+	out := new(ChaosPlugin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ChaosPlugin) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChaosPluginList) DeepCopyInto(out *ChaosPluginList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ChaosPlugin, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChaosPluginList.
+func (in *ChaosPluginList) DeepCopy() *ChaosPluginList {
+	if in == nil {
+		return nil
+	}
+	out := new(ChaosPluginList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ChaosPluginList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChaosPluginSpec) DeepCopyInto(out *ChaosPluginSpec) {
+	*out = *in
+	if in.ChaosTypes != nil {
+		in, out := &in.ChaosTypes, &out.ChaosTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Service = in.Service
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChaosPluginSpec.
+func (in *ChaosPluginSpec) DeepCopy() *ChaosPluginSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ChaosPluginSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChaosPluginStatus) DeepCopyInto(out *ChaosPluginStatus) {
+	*out = *in
+	if in.RegisteredChaosTypes != nil {
+		in, out := &in.RegisteredChaosTypes, &out.RegisteredChaosTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChaosPluginStatus.
+func (in *ChaosPluginStatus) DeepCopy() *ChaosPluginStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ChaosPluginStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Havock8sExperiment) DeepCopyInto(out *Havock8sExperiment) {
 	*out = *in
@@ -82,7 +189,7 @@ func (in *Havock8sExperimentSpec) DeepCopyInto(out *Havock8sExperimentSpec) {
 	if in.Schedule != nil {
 		in, out := &in.Schedule, &out.Schedule
 		*out = new(ScheduleSpec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Safety != nil {
 		in, out := &in.Safety, &out.Safety
@@ -122,8 +229,34 @@ func (in *Havock8sExperimentStatus) DeepCopyInto(out *Havock8sExperimentStatus)
 	if in.TargetResources != nil {
 		in, out := &in.TargetResources, &out.TargetResources
 		*out = make([]TargetResourceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextScheduleTime != nil {
+		in, out := &in.NextScheduleTime, &out.NextScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ActiveRuns != nil {
+		in, out := &in.ActiveRuns, &out.ActiveRuns
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.LastSafetyCheckTime != nil {
+		in, out := &in.LastSafetyCheckTime, &out.LastSafetyCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.HealthChecks != nil {
+		in, out := &in.HealthChecks, &out.HealthChecks
+		*out = make([]HealthCheckStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Havock8sExperimentStatus.
@@ -139,6 +272,11 @@ func (in *Havock8sExperimentStatus) DeepCopy() *Havock8sExperimentStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HealthCheckSpec) DeepCopyInto(out *HealthCheckSpec) {
 	*out = *in
+	if in.ExpectedStatusCodes != nil {
+		in, out := &in.ExpectedStatusCodes, &out.ExpectedStatusCodes
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
 	if in.Command != nil {
 		in, out := &in.Command, &out.Command
 		*out = make([]string, len(*in))
@@ -156,6 +294,25 @@ func (in *HealthCheckSpec) DeepCopy() *HealthCheckSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthCheckStatus) DeepCopyInto(out *HealthCheckStatus) {
+	*out = *in
+	if in.LastProbeTime != nil {
+		in, out := &in.LastProbeTime, &out.LastProbeTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheckStatus.
+func (in *HealthCheckStatus) DeepCopy() *HealthCheckStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthCheckStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PauseConditionSpec) DeepCopyInto(out *PauseConditionSpec) {
 	*out = *in
@@ -171,6 +328,36 @@ func (in *PauseConditionSpec) DeepCopy() *PauseConditionSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginServiceReference) DeepCopyInto(out *PluginServiceReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginServiceReference.
+func (in *PluginServiceReference) DeepCopy() *PluginServiceReference {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginServiceReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodRoleSpec) DeepCopyInto(out *PodRoleSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodRoleSpec.
+func (in *PodRoleSpec) DeepCopy() *PodRoleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PodRoleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProtectionSpec) DeepCopyInto(out *ProtectionSpec) {
 	*out = *in
@@ -221,6 +408,11 @@ func (in *SafetySpec) DeepCopy() *SafetySpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScheduleSpec) DeepCopyInto(out *ScheduleSpec) {
 	*out = *in
+	if in.HistoryLimit != nil {
+		in, out := &in.HistoryLimit, &out.HistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleSpec.
@@ -233,9 +425,39 @@ func (in *ScheduleSpec) DeepCopy() *ScheduleSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetPodsSpec) DeepCopyInto(out *StatefulSetPodsSpec) {
+	*out = *in
+	if in.Ordinals != nil {
+		in, out := &in.Ordinals, &out.Ordinals
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.Role != nil {
+		in, out := &in.Role, &out.Role
+		*out = new(PodRoleSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetPodsSpec.
+func (in *StatefulSetPodsSpec) DeepCopy() *StatefulSetPodsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetPodsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TargetResourceStatus) DeepCopyInto(out *TargetResourceStatus) {
 	*out = *in
+	if in.RestartCount != nil {
+		in, out := &in.RestartCount, &out.RestartCount
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetResourceStatus.
@@ -256,6 +478,11 @@ func (in *TargetSpec) DeepCopyInto(out *TargetSpec) {
 		*out = new(v1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.StatefulSetPods != nil {
+		in, out := &in.StatefulSetPods, &out.StatefulSetPods
+		*out = new(StatefulSetPodsSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetSpec.
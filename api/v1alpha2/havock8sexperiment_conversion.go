@@ -0,0 +1,269 @@
+package v1alpha2
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/havock8s/havock8s/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// ConvertTo converts this Havock8sExperiment to the hub version (v1alpha1).
+// The typed chaos fields become entries of the v1alpha1 Parameters map.
+func (src *Havock8sExperiment) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1alpha1.Havock8sExperiment)
+	if !ok {
+		return fmt.Errorf("unsupported hub type %T", dstRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	// Every field but the typed chaos fields is the same in both versions
+	if err := convertJSON(&src.Spec, &dst.Spec); err != nil {
+		return err
+	}
+	if err := convertJSON(&src.Status, &dst.Status); err != nil {
+		return err
+	}
+
+	params := parameters{}
+	for key, value := range src.Spec.Parameters {
+		params[key] = value
+	}
+	if spec := src.Spec.PodFailure; spec != nil {
+		params.setString("failureMode", spec.FailureMode)
+		params.setInt64("gracePeriodSeconds", spec.GracePeriodSeconds)
+		params.setInt32("podCount", spec.PodCount)
+		params.setInt32("podPercentage", spec.PodPercentage)
+		params.setBool("forceDelete", spec.ForceDelete)
+	}
+	if spec := src.Spec.NetworkLatency; spec != nil {
+		params.setString("latency", spec.Latency)
+		params.setString("jitter", spec.Jitter)
+		params.setInt32("correlation", spec.Correlation)
+		params.setPorts("ports", spec.Ports)
+	}
+	if spec := src.Spec.DiskFailure; spec != nil {
+		params.setString("failureMode", spec.FailureMode)
+		params.setString("mountPath", spec.MountPath)
+		params.setString("container", spec.Container)
+		params.setInt32("failureRate", spec.FailureRate)
+		params.setInt32("writeIOPS", spec.WriteIOPS)
+		params.setInt32("fillPercentage", spec.FillPercentage)
+	}
+	if spec := src.Spec.StatefulSetScaling; spec != nil {
+		params.setString("scaleMode", spec.ScaleMode)
+		params.setInt32("scaleCount", spec.ScaleCount)
+		params.setInt32("scaleMin", spec.ScaleMin)
+		params.setInt32("scaleMax", spec.ScaleMax)
+		params.setBool("allowZero", spec.AllowZero)
+	}
+
+	dst.Spec.Parameters = nil
+	if len(params) > 0 {
+		dst.Spec.Parameters = params
+	}
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1alpha1) to this version. The
+// Parameters of the experiment's chaos type move to its typed field; values
+// the typed field cannot represent stay in Parameters.
+func (dst *Havock8sExperiment) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1alpha1.Havock8sExperiment)
+	if !ok {
+		return fmt.Errorf("unsupported hub type %T", srcRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	dst.Spec = Havock8sExperimentSpec{}
+	if err := convertJSON(&src.Spec, &dst.Spec); err != nil {
+		return err
+	}
+	dst.Status = Havock8sExperimentStatus{}
+	if err := convertJSON(&src.Status, &dst.Status); err != nil {
+		return err
+	}
+
+	params := parameters{}
+	for key, value := range src.Spec.Parameters {
+		params[key] = value
+	}
+	switch src.Spec.ChaosType {
+	case "PodFailure":
+		spec := &PodFailureSpec{
+			FailureMode:        params.takeString("failureMode"),
+			GracePeriodSeconds: params.takeInt64("gracePeriodSeconds"),
+			PodCount:           params.takeInt32("podCount"),
+			PodPercentage:      params.takeInt32("podPercentage"),
+			ForceDelete:        params.takeBool("forceDelete"),
+		}
+		if *spec != (PodFailureSpec{}) {
+			dst.Spec.PodFailure = spec
+		}
+	case "NetworkLatency":
+		spec := &NetworkLatencySpec{
+			Latency:     params.takeString("latency"),
+			Jitter:      params.takeString("jitter"),
+			Correlation: params.takeInt32("correlation"),
+			Ports:       params.takePorts("ports"),
+		}
+		if spec.Latency != "" || spec.Jitter != "" || spec.Correlation != nil || spec.Ports != nil {
+			dst.Spec.NetworkLatency = spec
+		}
+	case "DiskFailure":
+		spec := &DiskFailureSpec{
+			FailureMode:    params.takeString("failureMode"),
+			MountPath:      params.takeString("mountPath"),
+			Container:      params.takeString("container"),
+			FailureRate:    params.takeInt32("failureRate"),
+			WriteIOPS:      params.takeInt32("writeIOPS"),
+			FillPercentage: params.takeInt32("fillPercentage"),
+		}
+		if *spec != (DiskFailureSpec{}) {
+			dst.Spec.DiskFailure = spec
+		}
+	case "StatefulSetScaling":
+		spec := &StatefulSetScalingSpec{
+			ScaleMode:  params.takeString("scaleMode"),
+			ScaleCount: params.takeInt32("scaleCount"),
+			ScaleMin:   params.takeInt32("scaleMin"),
+			ScaleMax:   params.takeInt32("scaleMax"),
+			AllowZero:  params.takeBool("allowZero"),
+		}
+		if *spec != (StatefulSetScalingSpec{}) {
+			dst.Spec.StatefulSetScaling = spec
+		}
+	}
+
+	dst.Spec.Parameters = nil
+	if len(params) > 0 {
+		dst.Spec.Parameters = params
+	}
+	return nil
+}
+
+// convertJSON copies the fields two versions share by their JSON names
+func convertJSON(src, dst interface{}) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %T: %w", src, err)
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return fmt.Errorf("failed to convert %T to %T: %w", src, dst, err)
+	}
+	return nil
+}
+
+// parameters is a v1alpha1 Parameters map being converted to or from typed
+// fields. A value is only taken into a typed field when formatting the field
+// gives back the same string, so a round trip never alters a parameter.
+type parameters map[string]string
+
+// takeString removes and returns a parameter
+func (p parameters) takeString(key string) string {
+	value := p[key]
+	delete(p, key)
+	return value
+}
+
+// takeInt32 removes and returns a parameter holding a 32 bit integer
+func (p parameters) takeInt32(key string) *int32 {
+	value, ok := p[key]
+	if !ok {
+		return nil
+	}
+	n, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || strconv.FormatInt(n, 10) != value {
+		return nil
+	}
+	delete(p, key)
+	result := int32(n)
+	return &result
+}
+
+// takeInt64 removes and returns a parameter holding a 64 bit integer
+func (p parameters) takeInt64(key string) *int64 {
+	value, ok := p[key]
+	if !ok {
+		return nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || strconv.FormatInt(n, 10) != value {
+		return nil
+	}
+	delete(p, key)
+	return &n
+}
+
+// takeBool removes and returns a parameter holding true or false
+func (p parameters) takeBool(key string) *bool {
+	value, ok := p[key]
+	if !ok || (value != "true" && value != "false") {
+		return nil
+	}
+	delete(p, key)
+	result := value == "true"
+	return &result
+}
+
+// takePorts removes and returns a parameter holding a comma separated list of ports
+func (p parameters) takePorts(key string) []int32 {
+	value, ok := p[key]
+	if !ok || value == "" {
+		return nil
+	}
+	var ports []int32
+	for _, field := range strings.Split(value, ",") {
+		n, err := strconv.ParseInt(field, 10, 32)
+		if err != nil || strconv.FormatInt(n, 10) != field {
+			return nil
+		}
+		ports = append(ports, int32(n))
+	}
+	delete(p, key)
+	return ports
+}
+
+// setString sets a parameter unless the value is empty
+func (p parameters) setString(key, value string) {
+	if value != "" {
+		p[key] = value
+	}
+}
+
+// setInt32 sets a parameter unless the value is unset
+func (p parameters) setInt32(key string, value *int32) {
+	if value != nil {
+		p[key] = strconv.FormatInt(int64(*value), 10)
+	}
+}
+
+// setInt64 sets a parameter unless the value is unset
+func (p parameters) setInt64(key string, value *int64) {
+	if value != nil {
+		p[key] = strconv.FormatInt(*value, 10)
+	}
+}
+
+// setBool sets a parameter unless the value is unset
+func (p parameters) setBool(key string, value *bool) {
+	if value != nil {
+		p[key] = strconv.FormatBool(*value)
+	}
+}
+
+// setPorts sets a parameter to a comma separated list of ports
+func (p parameters) setPorts(key string, ports []int32) {
+	if len(ports) == 0 {
+		return
+	}
+	fields := make([]string, 0, len(ports))
+	for _, port := range ports {
+		fields = append(fields, strconv.FormatInt(int64(port), 10))
+	}
+	p[key] = strings.Join(fields, ",")
+}
@@ -0,0 +1,209 @@
+package v1alpha2
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/havock8s/havock8s/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func int32Ptr(v int32) *int32 { return &v }
+func int64Ptr(v int64) *int64 { return &v }
+func boolPtr(v bool) *bool    { return &v }
+
+func TestHavock8sExperiment_ConvertFrom(t *testing.T) {
+	tests := []struct {
+		name      string
+		chaosType string
+		params    map[string]string
+		want      Havock8sExperimentSpec
+	}{
+		{
+			name:      "pod failure parameters become typed",
+			chaosType: "PodFailure",
+			params: map[string]string{
+				"failureMode":        "terminate",
+				"gracePeriodSeconds": "30",
+				"podCount":           "2",
+				"forceDelete":        "false",
+			},
+			want: Havock8sExperimentSpec{
+				PodFailure: &PodFailureSpec{
+					FailureMode:        "terminate",
+					GracePeriodSeconds: int64Ptr(30),
+					PodCount:           int32Ptr(2),
+					ForceDelete:        boolPtr(false),
+				},
+			},
+		},
+		{
+			name:      "pod percentage becomes typed",
+			chaosType: "PodFailure",
+			params:    map[string]string{"failureMode": "terminate", "podPercentage": "30"},
+			want: Havock8sExperimentSpec{
+				PodFailure: &PodFailureSpec{FailureMode: "terminate", PodPercentage: int32Ptr(30)},
+			},
+		},
+		{
+			name:      "unrepresentable values stay in parameters",
+			chaosType: "PodFailure",
+			params: map[string]string{
+				"failureMode": "crash",
+				"podCount":    "all",
+				"forceDelete": "yes",
+			},
+			want: Havock8sExperimentSpec{
+				PodFailure: &PodFailureSpec{FailureMode: "crash"},
+				Parameters: map[string]string{"podCount": "all", "forceDelete": "yes"},
+			},
+		},
+		{
+			name:      "non canonical integers stay in parameters",
+			chaosType: "StatefulSetScaling",
+			params:    map[string]string{"scaleCount": "02", "scaleMode": "down"},
+			want: Havock8sExperimentSpec{
+				StatefulSetScaling: &StatefulSetScalingSpec{ScaleMode: "down"},
+				Parameters:         map[string]string{"scaleCount": "02"},
+			},
+		},
+		{
+			name:      "network latency ports",
+			chaosType: "NetworkLatency",
+			params:    map[string]string{"latency": "200ms", "ports": "5432,6379", "correlation": "25"},
+			want: Havock8sExperimentSpec{
+				NetworkLatency: &NetworkLatencySpec{
+					Latency:     "200ms",
+					Correlation: int32Ptr(25),
+					Ports:       []int32{5432, 6379},
+				},
+			},
+		},
+		{
+			name:      "disk failure",
+			chaosType: "DiskFailure",
+			params:    map[string]string{"failureMode": "eio", "mountPath": "/data", "failureRate": "50", "writeIOPS": "20"},
+			want: Havock8sExperimentSpec{
+				DiskFailure: &DiskFailureSpec{FailureMode: "eio", MountPath: "/data", FailureRate: int32Ptr(50), WriteIOPS: int32Ptr(20)},
+			},
+		},
+		{
+			name:      "parameters of other chaos types are untouched",
+			chaosType: "ResourcePressure",
+			params:    map[string]string{"failureMode": "crash", "cpu": "2"},
+			want: Havock8sExperimentSpec{
+				Parameters: map[string]string{"failureMode": "crash", "cpu": "2"},
+			},
+		},
+		{
+			name:      "no typed field without matching parameters",
+			chaosType: "PodFailure",
+			params:    map[string]string{"latency": "200ms"},
+			want: Havock8sExperimentSpec{
+				Parameters: map[string]string{"latency": "200ms"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := &v1alpha1.Havock8sExperiment{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec: v1alpha1.Havock8sExperimentSpec{
+					ChaosType:  tt.chaosType,
+					Duration:   "5m",
+					Parameters: tt.params,
+				},
+			}
+
+			dst := &Havock8sExperiment{}
+			if err := dst.ConvertFrom(src); err != nil {
+				t.Fatalf("ConvertFrom() error = %v", err)
+			}
+
+			tt.want.ChaosType = tt.chaosType
+			tt.want.Duration = "5m"
+			if !reflect.DeepEqual(dst.Spec, tt.want) {
+				t.Errorf("ConvertFrom() spec = %+v, want %+v", dst.Spec, tt.want)
+			}
+
+			// Converting back must reproduce the original parameters
+			hub := &v1alpha1.Havock8sExperiment{}
+			if err := dst.ConvertTo(hub); err != nil {
+				t.Fatalf("ConvertTo() error = %v", err)
+			}
+			if !reflect.DeepEqual(hub.Spec, src.Spec) {
+				t.Errorf("round trip spec = %+v, want %+v", hub.Spec, src.Spec)
+			}
+		})
+	}
+}
+
+func TestHavock8sExperiment_ConvertTo(t *testing.T) {
+	now := metav1.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	src := &Havock8sExperiment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", Labels: map[string]string{"app": "db"}},
+		Spec: Havock8sExperimentSpec{
+			Target: TargetSpec{
+				Name:       "postgres",
+				TargetType: "StatefulSet",
+				StatefulSetPods: &StatefulSetPodsSpec{
+					Ordinal: OrdinalHighest,
+					Role:    &PodRoleSpec{Role: RoleReplica, Probe: RoleProbePostgres},
+				},
+			},
+			ChaosType: "NetworkLatency",
+			Duration:  "1m",
+			Intensity: 0.5,
+			NetworkLatency: &NetworkLatencySpec{
+				Latency: "100ms",
+				Jitter:  "10ms",
+				Ports:   []int32{5432},
+			},
+			Parameters: map[string]string{"interface": "eth0"},
+			Safety: &SafetySpec{
+				AutoRollback: true,
+			},
+		},
+		Status: Havock8sExperimentStatus{
+			Phase:     "Running",
+			StartTime: &now,
+		},
+	}
+
+	dst := &v1alpha1.Havock8sExperiment{}
+	if err := src.ConvertTo(dst); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+
+	wantParams := map[string]string{
+		"interface": "eth0",
+		"latency":   "100ms",
+		"jitter":    "10ms",
+		"ports":     "5432",
+	}
+	if !reflect.DeepEqual(dst.Spec.Parameters, wantParams) {
+		t.Errorf("Parameters = %v, want %v", dst.Spec.Parameters, wantParams)
+	}
+	if dst.Name != "test" || dst.Labels["app"] != "db" {
+		t.Errorf("ObjectMeta not copied: %+v", dst.ObjectMeta)
+	}
+	if pods := dst.Spec.Target.StatefulSetPods; pods == nil || pods.Role == nil || pods.Role.Probe != "postgres" {
+		t.Errorf("StatefulSet pod selection not copied: %+v", dst.Spec.Target)
+	}
+	if dst.Spec.Target.Name != "postgres" || dst.Spec.ChaosType != "NetworkLatency" || dst.Spec.Safety == nil || !dst.Spec.Safety.AutoRollback {
+		t.Errorf("shared spec fields not copied: %+v", dst.Spec)
+	}
+	if dst.Status.Phase != "Running" || dst.Status.StartTime == nil || !dst.Status.StartTime.Equal(&now) {
+		t.Errorf("status not copied: %+v", dst.Status)
+	}
+
+	back := &Havock8sExperiment{}
+	if err := back.ConvertFrom(dst); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	if !reflect.DeepEqual(back.Spec, src.Spec) {
+		t.Errorf("round trip spec = %+v, want %+v", back.Spec, src.Spec)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	"github.com/havock8s/havock8s/pkg/agent"
+)
+
+var (
+	scheme   = runtime.NewScheme()
+	setupLog = ctrl.Log.WithName("setup")
+)
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+}
+
+func main() {
+	var metricsAddr string
+	var probeAddr string
+	var nodeName string
+	var procRoot string
+	var sysRoot string
+	var cgroupRoot string
+	var kubeletRoot string
+	var device string
+	var proxyBinary string
+
+	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8090", "The address the metric endpoint binds to.")
+	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8091", "The address the probe endpoint binds to.")
+	flag.StringVar(&nodeName, "node-name", os.Getenv("NODE_NAME"), "The name of the node the agent runs on.")
+	flag.StringVar(&procRoot, "proc-root", "/proc", "The path where the host's /proc is mounted.")
+	flag.StringVar(&sysRoot, "sys-root", "/sys", "The path where the host's /sys is mounted.")
+	flag.StringVar(&cgroupRoot, "cgroup-root", "/sys/fs/cgroup", "The path where the host's cgroup v2 hierarchy is mounted.")
+	flag.StringVar(&kubeletRoot, "kubelet-root", "/var/lib/kubelet", "The kubelet's root directory on the host.")
+	flag.StringVar(&device, "network-device", "eth0", "The network interface inside target pods.")
+	flag.StringVar(&proxyBinary, "proxy-binary", "/havock8s-proxy", "The path of the havock8s-proxy binary.")
+
+	opts := zap.Options{
+		Development: true,
+	}
+	opts.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+
+	if nodeName == "" {
+		setupLog.Info("node name is required, set --node-name or NODE_NAME")
+		os.Exit(1)
+	}
+
+	// Set up agent manager options, only caching pods on this node
+	options := ctrl.Options{
+		Scheme: scheme,
+		Metrics: server.Options{
+			BindAddress: metricsAddr,
+		},
+		HealthProbeBindAddress: probeAddr,
+		Cache:                  agent.CacheOptions(nodeName),
+	}
+
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), options)
+	if err != nil {
+		setupLog.Error(err, "unable to start agent")
+		os.Exit(1)
+	}
+
+	if err = (&agent.PodReconciler{
+		Client:   mgr.GetClient(),
+		NodeName: nodeName,
+		ProcRoot: procRoot,
+		Exec:     agent.CommandExecutor{},
+		Faults: []agent.Fault{
+			&agent.NetworkLatencyFault{Device: device},
+			&agent.NetworkPartitionFault{},
+			&agent.DiskFailureFault{SysRoot: sysRoot, CgroupRoot: cgroupRoot, KubeletRoot: kubeletRoot},
+			&agent.VolumeFailureFault{},
+			&agent.KillFault{},
+			&agent.ResourcePressureFault{CgroupRoot: cgroupRoot},
+			&agent.DataCorruptionFault{},
+			&agent.StateDelayFault{ProxyBinary: proxyBinary},
+		},
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "havock8s-agent")
+		os.Exit(1)
+	}
+
+	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
+		setupLog.Error(err, "unable to set up health check")
+		os.Exit(1)
+	}
+	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
+		setupLog.Error(err, "unable to set up ready check")
+		os.Exit(1)
+	}
+
+	setupLog.Info("starting agent", "node", nodeName)
+	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+		setupLog.Error(err, "problem running agent")
+		os.Exit(1)
+	}
+}
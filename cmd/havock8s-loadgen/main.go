@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/havock8s/havock8s/pkg/loadgen"
+)
+
+func main() {
+	var config loadgen.Config
+	var targets string
+	var duration time.Duration
+
+	flag.StringVar(&targets, "targets", "", "Comma separated host:port addresses to connect to.")
+	flag.IntVar(&config.Connections, "connections", 100, "The number of connections to hold.")
+	flag.StringVar(&config.ConnectionType, "connection-type", loadgen.ConnectionMixed, "How connections are used: idle, slowloris, active or mixed.")
+	flag.StringVar(&config.Protocol, "protocol", "", "The database wire protocol: postgres, mongodb or redis. Required for active connections.")
+	flag.StringVar(&config.User, "user", "postgres", "The user active PostgreSQL connections log in as.")
+	flag.DurationVar(&config.Interval, "interval", 10*time.Second, "The pause between slow loris bytes and between active requests.")
+	flag.DurationVar(&duration, "duration", 0, "How long to hold the connections, until terminated when zero.")
+	flag.Parse()
+
+	for _, target := range strings.Split(targets, ",") {
+		if target = strings.TrimSpace(target); target != "" {
+			config.Targets = append(config.Targets, target)
+		}
+	}
+	generator, err := loadgen.NewGenerator(config)
+	if err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+
+	go func() {
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				log.Printf("%d of %d connections open", generator.Open(), config.Connections)
+			}
+		}
+	}()
+
+	log.Printf("holding %d %s connections to %s", config.Connections, config.ConnectionType, targets)
+	generator.Run(ctx)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/havock8s/havock8s/pkg/proxy"
+)
+
+func main() {
+	var listenAddr string
+	var config proxy.Config
+	var operations string
+	var podUID string
+
+	flag.StringVar(&listenAddr, "listen", ":15999", "The address the proxy listens on.")
+	flag.StringVar(&config.Upstream, "upstream", "", "The address of the database.")
+	flag.StringVar(&config.Protocol, "protocol", "", "The database wire protocol: postgres, mongodb or redis.")
+	flag.StringVar(&operations, "operations", proxy.CategoryAll, "Comma separated operations to act on, by category (read, write, commit, all) or command name.")
+	flag.StringVar(&config.Action, "action", proxy.ActionDelay, "The action applied to the selected operations: delay, drop or error.")
+	flag.DurationVar(&config.Delay, "delay", 100*time.Millisecond, "How long delayed operations are held back.")
+	flag.IntVar(&config.Percentage, "percentage", 100, "The percentage of the selected operations the action applies to.")
+	// The node agent finds the proxies of a pod by this flag
+	flag.StringVar(&podUID, "pod-uid", "", "The UID of the pod the proxy runs for.")
+	flag.Parse()
+
+	config.Operations = proxy.ParseOperations(operations)
+	server, err := proxy.NewServer(config)
+	if err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		log.Fatalf("unable to listen on %s: %v", listenAddr, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("proxying %s to %s, pod %s", listenAddr, config.Upstream, podUID)
+	if err := server.Serve(ctx, listener); err != nil {
+		log.Fatalf("problem running proxy: %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/chaos"
+	"github.com/havock8s/havock8s/pkg/plugin"
+	"google.golang.org/grpc"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// conditionPluginReady tracks whether all chaos types of a plugin are registered
+	conditionPluginReady = "Ready"
+
+	// pluginConflictRetryInterval is how soon to retry registering chaos
+	// types claimed by another plugin, which may have been deleted since
+	pluginConflictRetryInterval = time.Minute
+)
+
+// ChaosPluginReconciler registers the chaos types of ChaosPlugins with the
+// injector registry, so experiments of those types are injected by the plugin
+type ChaosPluginReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// mu guards plugins, as plugins are reconciled concurrently with the
+	// experiments looking up their injectors
+	mu      sync.Mutex
+	plugins map[string]*pluginRegistration
+}
+
+// pluginRegistration is the connection to a plugin and the chaos types
+// registered for it
+type pluginRegistration struct {
+	conn       *grpc.ClientConn
+	generation int64
+	chaosTypes []string
+}
+
+// +kubebuilder:rbac:groups=chaos.havock8s.io,resources=chaosplugins,verbs=get;list;watch
+// +kubebuilder:rbac:groups=chaos.havock8s.io,resources=chaosplugins/status,verbs=get;update;patch
+
+// Reconcile registers a plugin's chaos types, or removes them once the plugin is deleted
+func (r *ChaosPluginReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	chaosPlugin := &chaosv1alpha1.ChaosPlugin{}
+	if err := r.Get(ctx, req.NamespacedName, chaosPlugin); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("Plugin deleted, unregistering its chaos types")
+			r.unregister(req.Name)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !chaosPlugin.DeletionTimestamp.IsZero() {
+		r.unregister(chaosPlugin.Name)
+		return ctrl.Result{}, nil
+	}
+
+	registered, conflicts, err := r.register(chaosPlugin)
+
+	condition := metav1.Condition{
+		Type:    conditionPluginReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Registered",
+		Message: fmt.Sprintf("Experiments of chaos types %s are injected by the plugin", strings.Join(registered, ", ")),
+	}
+	result := ctrl.Result{}
+	switch {
+	case err != nil:
+		logger.Error(err, "Failed to register plugin")
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "InvalidSpec"
+		condition.Message = err.Error()
+	case len(conflicts) > 0:
+		logger.Info("Chaos types of the plugin are already registered", "chaosTypes", conflicts)
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ChaosTypeConflict"
+		condition.Message = fmt.Sprintf("Chaos types %s are already registered", strings.Join(conflicts, ", "))
+		result.RequeueAfter = pluginConflictRetryInterval
+	default:
+		logger.Info("Registered plugin", "chaosTypes", registered, "address", plugin.Address(chaosPlugin.Spec.Service))
+	}
+
+	chaosPlugin.Status.RegisteredChaosTypes = registered
+	meta.SetStatusCondition(&chaosPlugin.Status.Conditions, condition)
+	if err := r.Status().Update(ctx, chaosPlugin); err != nil {
+		return ctrl.Result{}, err
+	}
+	return result, nil
+}
+
+// register connects to a plugin and registers the chaos types nobody else
+// injects, returning the registered and the conflicting chaos types
+func (r *ChaosPluginReconciler) register(chaosPlugin *chaosv1alpha1.ChaosPlugin) (registered, conflicts []string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.plugins == nil {
+		r.plugins = make(map[string]*pluginRegistration)
+	}
+
+	// A changed spec may point somewhere else, so start over
+	registration := r.plugins[chaosPlugin.Name]
+	if registration != nil && registration.generation != chaosPlugin.Generation {
+		r.release(chaosPlugin.Name)
+		registration = nil
+	}
+	if registration == nil {
+		conn, err := plugin.Dial(chaosPlugin.Spec)
+		if err != nil {
+			return nil, nil, err
+		}
+		registration = &pluginRegistration{conn: conn, generation: chaosPlugin.Generation}
+		r.plugins[chaosPlugin.Name] = registration
+	}
+
+	factory := plugin.Factory(registration.conn, plugin.Timeout(chaosPlugin.Spec))
+	registration.chaosTypes = nil
+	for _, chaosType := range chaosPlugin.Spec.ChaosTypes {
+		if owner := r.owner(chaosType); owner != chaosPlugin.Name && (owner != "" || chaos.IsRegistered(chaosType)) {
+			conflicts = append(conflicts, chaosType)
+			continue
+		}
+		chaos.RegisterInjector(chaosType, factory)
+		registration.chaosTypes = append(registration.chaosTypes, chaosType)
+	}
+	return registration.chaosTypes, conflicts, nil
+}
+
+// owner returns the name of the plugin a chaos type is registered for.
+// Must be called with mu held.
+func (r *ChaosPluginReconciler) owner(chaosType string) string {
+	for name, registration := range r.plugins {
+		for _, registered := range registration.chaosTypes {
+			if registered == chaosType {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// unregister removes a plugin's chaos types and closes its connection
+func (r *ChaosPluginReconciler) unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.release(name)
+}
+
+// release removes a plugin's chaos types and closes its connection.
+// Must be called with mu held.
+func (r *ChaosPluginReconciler) release(name string) {
+	registration, ok := r.plugins[name]
+	if !ok {
+		return
+	}
+	for _, chaosType := range registration.chaosTypes {
+		chaos.UnregisterInjector(chaosType)
+	}
+	registration.conn.Close()
+	delete(r.plugins, name)
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *ChaosPluginReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&chaosv1alpha1.ChaosPlugin{}).
+		Complete(r)
+}
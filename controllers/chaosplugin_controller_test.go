@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/chaos"
+	"github.com/havock8s/havock8s/pkg/plugin"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestChaosPluginReconciler_Reconcile(t *testing.T) {
+	newPlugin := func(name string, chaosTypes ...string) *chaosv1alpha1.ChaosPlugin {
+		return &chaosv1alpha1.ChaosPlugin{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: chaosv1alpha1.ChaosPluginSpec{
+				ChaosTypes: chaosTypes,
+				Service:    chaosv1alpha1.PluginServiceReference{Name: name, Namespace: "chaos", Port: 9000},
+			},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		plugins        []*chaosv1alpha1.ChaosPlugin
+		wantReady      metav1.ConditionStatus
+		wantReason     string
+		wantRegistered []string
+	}{
+		{
+			name:           "registers chaos types",
+			plugins:        []*chaosv1alpha1.ChaosPlugin{newPlugin("storage", "StorageNodeRestart", "StorageCompactionStall")},
+			wantReady:      metav1.ConditionTrue,
+			wantReason:     "Registered",
+			wantRegistered: []string{"StorageNodeRestart", "StorageCompactionStall"},
+		},
+		{
+			name:           "does not replace built-in chaos types",
+			plugins:        []*chaosv1alpha1.ChaosPlugin{newPlugin("storage", "StorageNodeRestart", "PodFailure")},
+			wantReady:      metav1.ConditionFalse,
+			wantReason:     "ChaosTypeConflict",
+			wantRegistered: []string{"StorageNodeRestart"},
+		},
+		{
+			name: "does not take chaos types of other plugins",
+			plugins: []*chaosv1alpha1.ChaosPlugin{
+				newPlugin("other", "StorageNodeRestart"),
+				newPlugin("storage", "StorageNodeRestart", "StorageCompactionStall"),
+			},
+			wantReady:      metav1.ConditionFalse,
+			wantReason:     "ChaosTypeConflict",
+			wantRegistered: []string{"StorageCompactionStall"},
+		},
+		{
+			name: "rejects an invalid CA bundle",
+			plugins: []*chaosv1alpha1.ChaosPlugin{func() *chaosv1alpha1.ChaosPlugin {
+				p := newPlugin("storage", "StorageNodeRestart")
+				p.Spec.CABundle = "not a certificate"
+				return p
+			}()},
+			wantReady:  metav1.ConditionFalse,
+			wantReason: "InvalidSpec",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme()
+			builder := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&chaosv1alpha1.ChaosPlugin{})
+			for _, p := range tt.plugins {
+				builder = builder.WithObjects(p)
+			}
+			fakeClient := builder.Build()
+			ctx := context.Background()
+
+			reconciler := &ChaosPluginReconciler{Client: fakeClient, Scheme: scheme}
+			for _, p := range tt.plugins {
+				if _, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: p.Name}}); err != nil {
+					t.Fatalf("Reconcile(%s) error = %v", p.Name, err)
+				}
+			}
+
+			// The last plugin is the one under test
+			name := tt.plugins[len(tt.plugins)-1].Name
+			got := &chaosv1alpha1.ChaosPlugin{}
+			if err := fakeClient.Get(ctx, types.NamespacedName{Name: name}, got); err != nil {
+				t.Fatalf("Failed to get plugin: %v", err)
+			}
+			condition := meta.FindStatusCondition(got.Status.Conditions, conditionPluginReady)
+			if condition == nil || condition.Status != tt.wantReady || condition.Reason != tt.wantReason {
+				t.Errorf("Ready condition = %v, want %s/%s", condition, tt.wantReady, tt.wantReason)
+			}
+			if len(got.Status.RegisteredChaosTypes) != len(tt.wantRegistered) {
+				t.Fatalf("Registered chaos types = %v, want %v", got.Status.RegisteredChaosTypes, tt.wantRegistered)
+			}
+			for i, chaosType := range tt.wantRegistered {
+				if got.Status.RegisteredChaosTypes[i] != chaosType {
+					t.Errorf("Registered chaos types = %v, want %v", got.Status.RegisteredChaosTypes, tt.wantRegistered)
+				}
+				injector, err := chaos.NewInjector(chaosType, chaos.Dependencies{})
+				if err != nil {
+					t.Fatalf("NewInjector(%s) error = %v", chaosType, err)
+				}
+				if _, ok := injector.(*plugin.Injector); !ok {
+					t.Errorf("NewInjector(%s) = %T, want a plugin injector", chaosType, injector)
+				}
+			}
+			if _, err := chaos.NewInjector("PodFailure", chaos.Dependencies{}); err != nil {
+				t.Errorf("Built-in PodFailure injector was unregistered: %v", err)
+			}
+
+			// Deleting the plugins releases their chaos types
+			for _, p := range tt.plugins {
+				if err := fakeClient.Delete(ctx, p); err != nil {
+					t.Fatalf("Failed to delete plugin: %v", err)
+				}
+				if _, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: p.Name}}); err != nil {
+					t.Fatalf("Reconcile(%s) error = %v", p.Name, err)
+				}
+			}
+			for _, chaosType := range tt.wantRegistered {
+				if chaos.IsRegistered(chaosType) {
+					t.Errorf("Chaos type %s is still registered after the plugin was deleted", chaosType)
+				}
+			}
+		})
+	}
+}
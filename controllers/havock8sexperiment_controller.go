@@ -8,41 +8,101 @@ import (
 	"github.com/go-logr/logr"
 	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
 	"github.com/havock8s/havock8s/pkg/chaos"
+	"github.com/havock8s/havock8s/pkg/metrics"
+	"github.com/havock8s/havock8s/pkg/tracing"
 	"github.com/havock8s/havock8s/pkg/utils"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+const (
+	// experimentFinalizer keeps an experiment around until its chaos has been cleaned up
+	experimentFinalizer = "chaos.havock8s.io/finalizer"
+
+	// conditionCleanedUp tracks whether the chaos could be cleaned up on deletion
+	conditionCleanedUp = "CleanedUp"
+
+	// conditionAgentAcknowledged tracks whether the node agent has applied the chaos
+	conditionAgentAcknowledged = "AgentAcknowledged"
+
+	// conditionSafetyChecksPassing tracks the safety checks of a running experiment
+	conditionSafetyChecksPassing = "SafetyChecksPassing"
+
+	// defaultSafetyCheckInterval is how often safety checks run when the experiment does not say
+	defaultSafetyCheckInterval = 30 * time.Second
+
+	// agentAckTimeout is how long to wait for the node agent before failing the experiment
+	agentAckTimeout = 2 * time.Minute
+)
+
 // Havock8sExperimentReconciler reconciles a Havock8sExperiment object
 type Havock8sExperimentReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// PrometheusURL is the Prometheus server metric pause conditions are evaluated against
+	PrometheusURL string
+
+	// PodExecutor runs the commands of exec health checks and role probes in
+	// target pods
+	PodExecutor utils.PodExecutor
+
+	// Recorder records the experiment's lifecycle as Kubernetes events
+	Recorder record.EventRecorder
+
+	// MaxConcurrentReconciles is the number of experiments reconciled in
+	// parallel, one when not set
+	MaxConcurrentReconciles int
+
+	// nowFunc returns the current time, tests override it to drive schedules
+	nowFunc func() time.Time
 }
 
 // +kubebuilder:rbac:groups=chaos.havock8s.io,resources=havock8sexperiments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=chaos.havock8s.io,resources=havock8sexperiments/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=chaos.havock8s.io,resources=havock8sexperiments/finalizers,verbs=update
+// +kubebuilder:rbac:groups=chaos.havock8s.io,resources=chaosplugins,verbs=get;list;watch
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
-// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;delete
-// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods/exec,verbs=create
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;update
 // +kubebuilder:rbac:groups=core,resources=persistentvolumes,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=volumeattachments,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch
 
 // Reconcile handles the reconciliation of Havock8sExperiment resources
-func (r *Havock8sExperimentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *Havock8sExperimentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
 	logger := log.FromContext(ctx)
 
+	ctx, span := tracing.Tracer().Start(ctx, "Reconcile", trace.WithAttributes(
+		tracing.ExperimentNameKey.String(req.Name),
+		tracing.ExperimentNamespaceKey.String(req.Namespace),
+	))
+	defer func() { tracing.EndSpan(span, err) }()
+
 	// Fetch the Havock8sExperiment instance
 	experiment := &chaosv1alpha1.Havock8sExperiment{}
 	if err := r.Get(ctx, req.NamespacedName, experiment); err != nil {
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	span.SetAttributes(tracing.ExperimentAttributes(experiment)...)
+	span.SetAttributes(tracing.PhaseKey.String(experiment.Status.Phase))
 
 	// Handle experiment deletion
 	if !experiment.DeletionTimestamp.IsZero() {
@@ -54,14 +114,23 @@ func (r *Havock8sExperimentReconciler) Reconcile(ctx context.Context, req ctrl.R
 	case "":
 		// Initialize new experiment
 		return r.initializeExperiment(ctx, experiment, logger)
+	case "Scheduled":
+		// Spawn runs of a scheduled experiment
+		return r.processScheduledExperiment(ctx, experiment, logger)
 	case "Pending":
 		// Process pending experiment
 		return r.processPendingExperiment(ctx, experiment, logger)
+	case "Injecting":
+		// Wait for the node agent to apply the chaos
+		return r.processInjectingExperiment(ctx, experiment, logger)
 	case "Running":
 		// Process running experiment
 		return r.processRunningExperiment(ctx, experiment, logger)
+	case "Completed", "RolledBack":
+		// Wait for the targets to recover from the chaos
+		return r.processFinishedExperiment(ctx, experiment, logger)
 	default:
-		// No action needed for completed or failed experiments
+		// No action needed for failed experiments
 		return ctrl.Result{}, nil
 	}
 }
@@ -70,46 +139,187 @@ func (r *Havock8sExperimentReconciler) Reconcile(ctx context.Context, req ctrl.R
 func (r *Havock8sExperimentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&chaosv1alpha1.Havock8sExperiment{}).
+		Owns(&chaosv1alpha1.Havock8sExperiment{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Complete(r)
 }
 
-// handleExperimentDeletion handles cleanup when an experiment is being deleted
+// newSafetyChecker creates a SafetyChecker using the reconciler's configuration
+func (r *Havock8sExperimentReconciler) newSafetyChecker() *utils.SafetyChecker {
+	safetyChecker := utils.NewSafetyChecker(r.Client)
+	safetyChecker.SetPrometheusURL(r.PrometheusURL)
+	safetyChecker.SetPodExecutor(r.PodExecutor)
+	return safetyChecker
+}
+
+// injectorFor builds an injector of the experiment's chaos type using the
+// reconciler's client and event recorder and the reconcile's logger
+func (r *Havock8sExperimentReconciler) injectorFor(experiment *chaosv1alpha1.Havock8sExperiment, logger logr.Logger) (chaos.Injector, error) {
+	return chaos.NewInjector(experiment.Spec.ChaosType, chaos.Dependencies{
+		Client:   r.Client,
+		Log:      logger,
+		Recorder: r.Recorder,
+	})
+}
+
+// providedByPlugin reports whether a ChaosPlugin that isn't being deleted
+// claims a chaos type. Its injector may not be registered yet.
+func (r *Havock8sExperimentReconciler) providedByPlugin(ctx context.Context, chaosType string) (bool, error) {
+	plugins := &chaosv1alpha1.ChaosPluginList{}
+	if err := r.List(ctx, plugins); err != nil {
+		return false, err
+	}
+	for _, chaosPlugin := range plugins.Items {
+		if !chaosPlugin.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if containsString(chaosPlugin.Spec.ChaosTypes, chaosType) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// inject applies the experiment's chaos, recording how long it took
+func (r *Havock8sExperimentReconciler) inject(ctx context.Context, injector chaos.Injector, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	ctx, span := tracing.StartExperimentSpan(ctx, "Inject", experiment)
+	start := time.Now()
+	err := injector.Inject(ctx, experiment)
+	metrics.ObserveInjector(experiment.Spec.ChaosType, metrics.OperationInject, time.Since(start), err)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+// cleanup removes the experiment's chaos, recording how long it took
+func (r *Havock8sExperimentReconciler) cleanup(ctx context.Context, injector chaos.Injector, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	ctx, span := tracing.StartExperimentSpan(ctx, "Cleanup", experiment)
+	start := time.Now()
+	err := injector.Cleanup(ctx, experiment)
+	metrics.ObserveInjector(experiment.Spec.ChaosType, metrics.OperationCleanup, time.Since(start), err)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+// observeTargets lets injectors that report on their targets update the
+// experiment's target statuses. The caller persists the status.
+func (r *Havock8sExperimentReconciler) observeTargets(ctx context.Context, injector chaos.Injector, experiment *chaosv1alpha1.Havock8sExperiment, logger logr.Logger) {
+	observer, ok := injector.(chaos.TargetObserver)
+	if !ok {
+		return
+	}
+	if err := observer.ObserveTargets(ctx, experiment); err != nil {
+		logger.Error(err, "Failed to observe targets")
+	}
+}
+
+// event records an event on the experiment
+func (r *Havock8sExperimentReconciler) event(experiment *chaosv1alpha1.Havock8sExperiment, eventtype, reason, messageFmt string, args ...interface{}) {
+	if r.Recorder != nil {
+		r.Recorder.Eventf(experiment, eventtype, reason, messageFmt, args...)
+	}
+}
+
+// now returns the current time
+func (r *Havock8sExperimentReconciler) now() time.Time {
+	if r.nowFunc != nil {
+		return r.nowFunc()
+	}
+	return time.Now()
+}
+
+// handleExperimentDeletion handles cleanup when an experiment is being deleted.
+// The finalizer is only removed once the injector's Cleanup succeeded; failures
+// are recorded in the conditions and retried with the controller's backoff.
 func (r *Havock8sExperimentReconciler) handleExperimentDeletion(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, logger logr.Logger) (ctrl.Result, error) {
+	if !containsString(experiment.Finalizers, experimentFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
 	// Perform cleanup
-	injector, err := chaos.GetInjector(experiment.Spec.ChaosType)
+	injector, err := r.injectorFor(experiment, logger)
 	if err != nil {
-		return ctrl.Result{}, err
+		// A plugin's chaos types are only registered once its ChaosPlugin is
+		// reconciled, e.g. after a restart, so wait for them to clean up
+		if provided, listErr := r.providedByPlugin(ctx, experiment.Spec.ChaosType); listErr != nil {
+			return ctrl.Result{}, listErr
+		} else if provided {
+			logger.Info("Waiting for the plugin of the chaos type to be registered", "chaosType", experiment.Spec.ChaosType)
+			return ctrl.Result{}, fmt.Errorf("chaos type %s is not registered by its plugin yet", experiment.Spec.ChaosType)
+		}
+
+		// Nothing can be cleaned up for an unknown chaos type
+		logger.Error(err, "Skipping cleanup of deleted experiment")
+	} else {
+		if err := r.cleanup(ctx, injector, experiment); err != nil {
+			logger.Error(err, "Failed to clean up deleted experiment")
+			r.event(experiment, corev1.EventTypeWarning, "CleanupFailed", "Failed to clean up chaos: %v", err)
+			meta.SetStatusCondition(&experiment.Status.Conditions, metav1.Condition{
+				Type:    conditionCleanedUp,
+				Status:  metav1.ConditionFalse,
+				Reason:  "CleanupFailed",
+				Message: err.Error(),
+			})
+			if updateErr := r.Status().Update(ctx, experiment); updateErr != nil {
+				logger.Error(updateErr, "Failed to record cleanup failure")
+			}
+			return ctrl.Result{}, err
+		}
 	}
 
-	injector.SetClient(r.Client)
-	if err := injector.Cleanup(ctx, experiment, logger); err != nil {
+	// Let the experiment go
+	experiment.Finalizers = removeString(experiment.Finalizers, experimentFinalizer)
+	if err := r.Update(ctx, experiment); err != nil {
 		return ctrl.Result{}, err
 	}
 
+	logger.Info("Cleaned up deleted experiment")
 	return ctrl.Result{}, nil
 }
 
 // initializeExperiment initializes a new experiment
 func (r *Havock8sExperimentReconciler) initializeExperiment(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, logger logr.Logger) (ctrl.Result, error) {
-	// Set initial phase
+	// Make sure deleting the experiment cleans up its chaos first
+	if !containsString(experiment.Finalizers, experimentFinalizer) {
+		experiment.Finalizers = append(experiment.Finalizers, experimentFinalizer)
+		if err := r.Update(ctx, experiment); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Set initial phase, scheduled experiments only spawn runs
 	experiment.Status.Phase = "Pending"
-	experiment.Status.StartTime = &metav1.Time{Time: time.Now()}
+	if isScheduled(experiment) {
+		experiment.Status.Phase = "Scheduled"
+	}
+	experiment.Status.StartTime = &metav1.Time{Time: r.now()}
 
 	if err := r.Status().Update(ctx, experiment); err != nil {
 		return ctrl.Result{}, err
 	}
+	if experiment.Status.Phase == "Scheduled" {
+		r.event(experiment, corev1.EventTypeNormal, "Scheduled", "Experiment runs on schedule %q", experiment.Spec.Schedule.Cron)
+	} else {
+		r.event(experiment, corev1.EventTypeNormal, "Pending", "Experiment accepted, resolving targets")
+	}
 
 	return ctrl.Result{Requeue: true}, nil
 }
 
 // processPendingExperiment processes an experiment in the Pending phase
 func (r *Havock8sExperimentReconciler) processPendingExperiment(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, logger logr.Logger) (ctrl.Result, error) {
-	// Check if target exists
-	targetExists, err := utils.CheckTargetExists(ctx, r.Client, experiment.Spec.Target)
+	// Resolve the targets matching the experiment's selection criteria
+	targets, err := utils.ResolveTargets(ctx, r.Client, r.PodExecutor, experiment)
 	if err != nil {
+		r.event(experiment, corev1.EventTypeWarning, "Failed", "Failed to resolve targets: %v", err)
+		experiment.Status.Phase = "Failed"
+		experiment.Status.FailureReason = err.Error()
+		if err := r.Status().Update(ctx, experiment); err != nil {
+			return ctrl.Result{}, err
+		}
 		return ctrl.Result{}, err
 	}
-	if !targetExists {
+	if len(targets) == 0 {
+		r.event(experiment, corev1.EventTypeWarning, "TargetNotFound", "No target matches the experiment")
 		experiment.Status.Phase = "Failed"
 		experiment.Status.FailureReason = "Target resource not found"
 		if err := r.Status().Update(ctx, experiment); err != nil {
@@ -118,9 +328,16 @@ func (r *Havock8sExperimentReconciler) processPendingExperiment(ctx context.Cont
 		return ctrl.Result{}, fmt.Errorf("target resource not found")
 	}
 
+	// Record the resolved targets so the safety checks and every injector see
+	// the same victims
+	logger.Info("Resolved experiment targets", "count", len(targets), "mode", experiment.Spec.Target.Mode)
+	experiment.Status.TargetResources = targets
+
 	// Check safety conditions
-	safetyChecker := utils.NewSafetyChecker(r.Client)
+	safetyChecker := r.newSafetyChecker()
 	if shouldRollback, reason := safetyChecker.CheckSafety(ctx, experiment, logger); shouldRollback {
+		metrics.RecordSafetyTrip(experiment.Spec.ChaosType, safetyChecker.Tripped())
+		r.event(experiment, corev1.EventTypeWarning, "SafetyTripped", "Safety check failed before injection: %s", reason)
 		experiment.Status.Phase = "Failed"
 		experiment.Status.FailureReason = reason
 		if err := r.Status().Update(ctx, experiment); err != nil {
@@ -129,35 +346,32 @@ func (r *Havock8sExperimentReconciler) processPendingExperiment(ctx context.Cont
 		return ctrl.Result{}, fmt.Errorf(reason)
 	}
 
-	// Set up target resources in status
-	if experiment.Spec.Target.TargetType == "Pod" {
-		// Get the pod to get its UID
-		pod := &corev1.Pod{}
-		err := r.Get(ctx, types.NamespacedName{
-			Namespace: experiment.Spec.Target.Namespace,
-			Name:      experiment.Spec.Target.Name,
-		}, pod)
-		if err != nil {
-			return ctrl.Result{}, err
-		}
+	if err := r.Status().Update(ctx, experiment); err != nil {
+		return ctrl.Result{}, err
+	}
 
-		experiment.Status.TargetResources = []chaosv1alpha1.TargetResourceStatus{
-			{
-				Kind:      "Pod",
-				Name:      pod.Name,
-				Namespace: pod.Namespace,
-				UID:       string(pod.UID),
-				Status:    "Targeted",
-			},
+	// Start chaos injection
+	injector, err := r.injectorFor(experiment, logger)
+	if err != nil {
+		// Stay pending until the plugin of the chaos type is registered
+		if provided, listErr := r.providedByPlugin(ctx, experiment.Spec.ChaosType); listErr != nil {
+			return ctrl.Result{}, listErr
+		} else if provided {
+			logger.Info("Waiting for the plugin of the chaos type to be registered", "chaosType", experiment.Spec.ChaosType)
+			return ctrl.Result{}, fmt.Errorf("chaos type %s is not registered by its plugin yet", experiment.Spec.ChaosType)
 		}
+
+		experiment.Status.Phase = "Failed"
+		experiment.Status.FailureReason = err.Error()
+		r.event(experiment, corev1.EventTypeWarning, "Failed", "Experiment failed: %v", err)
 		if err := r.Status().Update(ctx, experiment); err != nil {
 			return ctrl.Result{}, err
 		}
+		return ctrl.Result{}, err
 	}
 
-	// Start chaos injection
-	injector, err := chaos.GetInjector(experiment.Spec.ChaosType)
-	if err != nil {
+	if err := r.inject(ctx, injector, experiment); err != nil {
+		r.event(experiment, corev1.EventTypeWarning, "InjectionFailed", "Failed to inject %s chaos: %v", experiment.Spec.ChaosType, err)
 		experiment.Status.Phase = "Failed"
 		experiment.Status.FailureReason = err.Error()
 		if err := r.Status().Update(ctx, experiment); err != nil {
@@ -166,18 +380,82 @@ func (r *Havock8sExperimentReconciler) processPendingExperiment(ctx context.Cont
 		return ctrl.Result{}, err
 	}
 
-	injector.SetClient(r.Client)
-	if err := injector.Inject(ctx, experiment, logger); err != nil {
+	// Chaos applied by the node agent only counts once the agent acknowledges it
+	if _, ok := injector.(chaos.Acknowledger); ok {
+		r.event(experiment, corev1.EventTypeNormal, "Injecting", "Waiting for the node agent to apply %s chaos to %d targets", experiment.Spec.ChaosType, len(targets))
+		experiment.Status.Phase = "Injecting"
+		meta.SetStatusCondition(&experiment.Status.Conditions, metav1.Condition{
+			Type:    conditionAgentAcknowledged,
+			Status:  metav1.ConditionFalse,
+			Reason:  "WaitingForAgent",
+			Message: "Waiting for the node agent to apply chaos",
+		})
+		if err := r.Status().Update(ctx, experiment); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: time.Second * 5}, nil
+	}
+
+	// Update status to Running
+	r.event(experiment, corev1.EventTypeNormal, "Running", "Injected %s chaos into %d targets", experiment.Spec.ChaosType, len(targets))
+	experiment.Status.Phase = "Running"
+	if err := r.Status().Update(ctx, experiment); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{RequeueAfter: time.Second * 30}, nil
+}
+
+// processInjectingExperiment waits for the node agent to acknowledge the chaos
+func (r *Havock8sExperimentReconciler) processInjectingExperiment(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, logger logr.Logger) (ctrl.Result, error) {
+	injector, err := r.injectorFor(experiment, logger)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	acknowledged := true
+	if acknowledger, ok := injector.(chaos.Acknowledger); ok {
+		acknowledged, err = acknowledger.Acknowledged(ctx, experiment)
+	}
+
+	if err == nil && !acknowledged {
+		condition := meta.FindStatusCondition(experiment.Status.Conditions, conditionAgentAcknowledged)
+		if condition == nil || time.Since(condition.LastTransitionTime.Time) < agentAckTimeout {
+			return ctrl.Result{RequeueAfter: time.Second * 5}, nil
+		}
+		err = fmt.Errorf("node agent did not acknowledge chaos within %s", agentAckTimeout)
+	}
+
+	if err != nil {
+		logger.Error(err, "Chaos was not applied by the node agent")
+		r.event(experiment, corev1.EventTypeWarning, "AgentFailed", "Chaos was not applied by the node agent: %v", err)
+		if cleanupErr := r.cleanup(ctx, injector, experiment); cleanupErr != nil {
+			logger.Error(cleanupErr, "Failed to clean up chaos")
+		}
 		experiment.Status.Phase = "Failed"
 		experiment.Status.FailureReason = err.Error()
+		experiment.Status.EndTime = &metav1.Time{Time: time.Now()}
+		meta.SetStatusCondition(&experiment.Status.Conditions, metav1.Condition{
+			Type:    conditionAgentAcknowledged,
+			Status:  metav1.ConditionFalse,
+			Reason:  "AgentFailed",
+			Message: err.Error(),
+		})
 		if err := r.Status().Update(ctx, experiment); err != nil {
 			return ctrl.Result{}, err
 		}
-		return ctrl.Result{}, err
+		return ctrl.Result{}, nil
 	}
 
 	// Update status to Running
+	r.event(experiment, corev1.EventTypeNormal, "Running", "The node agent applied %s chaos to all targets", experiment.Spec.ChaosType)
 	experiment.Status.Phase = "Running"
+	meta.SetStatusCondition(&experiment.Status.Conditions, metav1.Condition{
+		Type:    conditionAgentAcknowledged,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Acknowledged",
+		Message: "The node agent applied chaos to all targets",
+	})
 	if err := r.Status().Update(ctx, experiment); err != nil {
 		return ctrl.Result{}, err
 	}
@@ -202,19 +480,23 @@ func (r *Havock8sExperimentReconciler) processRunningExperiment(ctx context.Cont
 		return ctrl.Result{RequeueAfter: time.Second * 30}, nil
 	}
 
-	if time.Since(experiment.Status.StartTime.Time) > duration {
+	if r.now().Sub(experiment.Status.StartTime.Time) > duration {
 		// Clean up chaos
-		injector, err := chaos.GetInjector(experiment.Spec.ChaosType)
+		injector, err := r.injectorFor(experiment, logger)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
 
-		injector.SetClient(r.Client)
-		if err := injector.Cleanup(ctx, experiment, logger); err != nil {
+		// Take a last look at the targets while the chaos is still in place
+		r.observeTargets(ctx, injector, experiment, logger)
+
+		if err := r.cleanup(ctx, injector, experiment); err != nil {
+			r.event(experiment, corev1.EventTypeWarning, "CleanupFailed", "Failed to clean up chaos: %v", err)
 			return ctrl.Result{}, err
 		}
 
 		// Update status to Completed
+		r.event(experiment, corev1.EventTypeNormal, "Completed", "Experiment ran for %s and its chaos was cleaned up", duration)
 		experiment.Status.Phase = "Completed"
 		experiment.Status.EndTime = &metav1.Time{Time: time.Now()}
 		if err := r.Status().Update(ctx, experiment); err != nil {
@@ -224,15 +506,17 @@ func (r *Havock8sExperimentReconciler) processRunningExperiment(ctx context.Cont
 		return ctrl.Result{}, nil
 	}
 
-	// Check if target still exists
-	targetExists, err := utils.CheckTargetExists(ctx, r.Client, experiment.Spec.Target)
+	// Check if the resolved targets still exist, before the safety checks
+	// probe the targets a pod failure deleted on purpose
+	targetExists, err := utils.TargetsExist(ctx, r.Client, experiment.Status.TargetResources)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	// If target doesn't exist and it's a pod failure experiment, this is expected
-	if !targetExists && experiment.Spec.ChaosType == "pod-failure" {
-		// Update status to Completed since the pod has been successfully deleted
+	// If the targets are gone and it's a pod failure experiment, this is expected
+	if !targetExists && experiment.Spec.ChaosType == "PodFailure" {
+		// Update status to Completed since the pods have been successfully deleted
+		r.event(experiment, corev1.EventTypeNormal, "Completed", "Target pods were deleted")
 		experiment.Status.Phase = "Completed"
 		experiment.Status.EndTime = &metav1.Time{Time: time.Now()}
 		if err := r.Status().Update(ctx, experiment); err != nil {
@@ -241,8 +525,97 @@ func (r *Havock8sExperimentReconciler) processRunningExperiment(ctx context.Cont
 		return ctrl.Result{}, nil
 	}
 
+	// Keep evaluating the safety checks while the chaos is active
+	interval := safetyCheckInterval(experiment)
+	now := r.now()
+	if last := experiment.Status.LastSafetyCheckTime; last == nil || now.Sub(last.Time) >= interval {
+		safetyChecker := r.newSafetyChecker()
+		shouldRollback, reason := safetyChecker.CheckRunningSafety(ctx, experiment, now, logger)
+		experiment.Status.LastSafetyCheckTime = &metav1.Time{Time: now}
+		if shouldRollback {
+			metrics.RecordSafetyTrip(experiment.Spec.ChaosType, safetyChecker.Tripped())
+		}
+
+		if shouldRollback && experiment.Spec.Safety != nil && experiment.Spec.Safety.AutoRollback {
+			return r.rollbackExperiment(ctx, experiment, reason, logger)
+		}
+
+		condition := metav1.Condition{
+			Type:    conditionSafetyChecksPassing,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Healthy",
+			Message: "All safety checks are passing",
+		}
+		if shouldRollback {
+			// Without auto-rollback a tripped check is only reported
+			logger.Info("Safety check failed, auto-rollback is disabled", "reason", reason)
+			r.event(experiment, corev1.EventTypeWarning, "SafetyTripped", "Safety check failed, auto-rollback is disabled: %s", reason)
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = "SafetyCheckFailed"
+			condition.Message = reason
+		}
+		meta.SetStatusCondition(&experiment.Status.Conditions, condition)
+		if injector, err := r.injectorFor(experiment, logger); err == nil {
+			r.observeTargets(ctx, injector, experiment, logger)
+		}
+		if err := r.Status().Update(ctx, experiment); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	// Continue monitoring
-	return ctrl.Result{RequeueAfter: time.Second * 30}, nil
+	return ctrl.Result{RequeueAfter: interval}, nil
+}
+
+// rollbackExperiment cleans up the chaos of an experiment whose safety checks tripped
+func (r *Havock8sExperimentReconciler) rollbackExperiment(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, reason string, logger logr.Logger) (ctrl.Result, error) {
+	logger.Info("Safety check failed, rolling back experiment", "reason", reason)
+
+	// Tell the owners of the targets why their chaos is going away
+	r.event(experiment, corev1.EventTypeWarning, "SafetyTripped", "Safety check failed: %s", reason)
+	if r.Recorder != nil {
+		for _, target := range experiment.Status.TargetResources {
+			r.Recorder.Eventf(chaos.TargetReference(target), corev1.EventTypeWarning, "SafetyTripped",
+				"Safety check failed, rolling back havock8s experiment %s/%s: %s", experiment.Namespace, experiment.Name, reason)
+		}
+	}
+
+	injector, err := r.injectorFor(experiment, logger)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.cleanup(ctx, injector, experiment); err != nil {
+		r.event(experiment, corev1.EventTypeWarning, "CleanupFailed", "Failed to roll back chaos: %v", err)
+		// Stay in Running so the rollback is retried
+		return ctrl.Result{}, fmt.Errorf("failed to roll back experiment: %w", err)
+	}
+
+	r.event(experiment, corev1.EventTypeNormal, "RolledBack", "Rolled back chaos after a safety check failed")
+	experiment.Status.Phase = "RolledBack"
+	experiment.Status.FailureReason = reason
+	experiment.Status.EndTime = &metav1.Time{Time: r.now()}
+	meta.SetStatusCondition(&experiment.Status.Conditions, metav1.Condition{
+		Type:    conditionSafetyChecksPassing,
+		Status:  metav1.ConditionFalse,
+		Reason:  "RolledBack",
+		Message: reason,
+	})
+	if err := r.Status().Update(ctx, experiment); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// safetyCheckInterval returns how often to evaluate the safety checks of a running experiment
+func safetyCheckInterval(experiment *chaosv1alpha1.Havock8sExperiment) time.Duration {
+	if experiment.Spec.Safety != nil && experiment.Spec.Safety.CheckInterval != "" {
+		if interval, err := time.ParseDuration(experiment.Spec.Safety.CheckInterval); err == nil && interval > 0 {
+			return interval
+		}
+	}
+	return defaultSafetyCheckInterval
 }
 
 // Helper functions
@@ -267,13 +640,12 @@ func removeString(slice []string, s string) []string {
 
 // cleanupExperiment performs cleanup for an experiment
 func (r *Havock8sExperimentReconciler) cleanupExperiment(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, logger logr.Logger) error {
-	injector, err := chaos.GetInjector(experiment.Spec.ChaosType)
+	injector, err := r.injectorFor(experiment, logger)
 	if err != nil {
 		return err
 	}
 
-	injector.SetClient(r.Client)
-	if err := injector.Cleanup(ctx, experiment, logger); err != nil {
+	if err := r.cleanup(ctx, injector, experiment); err != nil {
 		return err
 	}
 
@@ -7,16 +7,27 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
 	"github.com/havock8s/havock8s/pkg/chaos"
+	"github.com/havock8s/havock8s/pkg/tracing"
+	"github.com/havock8s/havock8s/pkg/utils/prometheustest"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -85,6 +96,8 @@ func setupTestPod(client client.Client, name, namespace string) error {
 	// Update the pod status
 	createdPod.Status = corev1.PodStatus{
 		Phase: corev1.PodRunning,
+		// Health checks probe the pod IP
+		PodIP: "127.0.0.1",
 		Conditions: []corev1.PodCondition{
 			{
 				Type:               corev1.PodReady,
@@ -198,7 +211,7 @@ func TestHavock8sExperimentReconciler_Reconcile(t *testing.T) {
 						Namespace:  "default",
 						TargetType: "Pod",
 					},
-					ChaosType: "pod-failure",
+					ChaosType: "PodFailure",
 					Duration:  "1s",
 					Intensity: 100,
 					Parameters: map[string]string{
@@ -242,7 +255,7 @@ func TestHavock8sExperimentReconciler_Reconcile(t *testing.T) {
 						Namespace:  "default",
 						TargetType: "Pod",
 					},
-					ChaosType: "pod-failure",
+					ChaosType: "PodFailure",
 					Duration:  "10s",
 					Intensity: 100,
 					Parameters: map[string]string{
@@ -314,9 +327,6 @@ func TestHavock8sExperimentReconciler_Reconcile(t *testing.T) {
 				t.Fatalf("Failed to create experiment: %v", err)
 			}
 
-			// Register the pod failure injector with lowercase name
-			chaos.RegisterInjector("pod-failure", &chaos.PodFailureInjector{})
-
 			reconciler := &Havock8sExperimentReconciler{
 				Client: fakeClient,
 				Scheme: scheme,
@@ -369,4 +379,917 @@ func TestHavock8sExperimentReconciler_Reconcile(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestHavock8sExperimentReconciler_WaitsForAgentAcknowledgement(t *testing.T) {
+	scheme := setupScheme()
+	fakeClient := setupFakeClient(scheme)
+
+	if err := setupTestPod(fakeClient, "test-pod", "default"); err != nil {
+		t.Fatalf("Failed to create test pod: %v", err)
+	}
+
+	experiment := &chaosv1alpha1.Havock8sExperiment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "latency-experiment",
+			Namespace: "default",
+		},
+		Spec: chaosv1alpha1.Havock8sExperimentSpec{
+			Target: chaosv1alpha1.TargetSpec{
+				Name:       "test-pod",
+				Namespace:  "default",
+				TargetType: "Pod",
+			},
+			ChaosType: "NetworkLatency",
+			Duration:  "1m",
+			Parameters: map[string]string{
+				"latency": "100ms",
+			},
+		},
+	}
+	if err := fakeClient.Create(context.Background(), experiment); err != nil {
+		t.Fatalf("Failed to create experiment: %v", err)
+	}
+
+	reconciler := &Havock8sExperimentReconciler{
+		Client: fakeClient,
+		Scheme: scheme,
+	}
+	req := reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: experiment.Name, Namespace: experiment.Namespace},
+	}
+
+	getPhase := func() string {
+		exp := &chaosv1alpha1.Havock8sExperiment{}
+		if err := fakeClient.Get(context.Background(), req.NamespacedName, exp); err != nil {
+			t.Fatalf("Failed to get experiment: %v", err)
+		}
+		return exp.Status.Phase
+	}
+
+	// Initialize, inject and check without an acknowledgement
+	for i := 0; i < 3; i++ {
+		if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+	}
+	if phase := getPhase(); phase != "Injecting" {
+		t.Fatalf("Expected phase Injecting before acknowledgement, got %s", phase)
+	}
+
+	// Simulate the node agent acknowledging the latency
+	pod := &corev1.Pod{}
+	if err := fakeClient.Get(context.Background(), types.NamespacedName{Name: "test-pod", Namespace: "default"}, pod); err != nil {
+		t.Fatalf("Failed to get pod: %v", err)
+	}
+	pod.Annotations["havock8s.io/network-latency-ack"] = "applied"
+	if err := fakeClient.Update(context.Background(), pod); err != nil {
+		t.Fatalf("Failed to update pod: %v", err)
+	}
+
+	if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if phase := getPhase(); phase != "Running" {
+		t.Errorf("Expected phase Running after acknowledgement, got %s", phase)
+	}
+}
+
+func TestHavock8sExperimentReconciler_RunsSelectorTargetedExperiment(t *testing.T) {
+	scheme := setupScheme()
+	fakeClient := setupFakeClient(scheme)
+	ctx := context.Background()
+
+	for _, name := range []string{"web-0", "web-1", "db-0"} {
+		if err := setupTestPod(fakeClient, name, "default"); err != nil {
+			t.Fatalf("Failed to create test pod: %v", err)
+		}
+	}
+	for _, name := range []string{"web-0", "web-1"} {
+		pod := &corev1.Pod{}
+		if err := fakeClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, pod); err != nil {
+			t.Fatalf("Failed to get pod: %v", err)
+		}
+		pod.Labels = map[string]string{"app": "web"}
+		if err := fakeClient.Update(ctx, pod); err != nil {
+			t.Fatalf("Failed to label pod: %v", err)
+		}
+	}
+
+	// No name, only a selector
+	experiment := &chaosv1alpha1.Havock8sExperiment{
+		ObjectMeta: metav1.ObjectMeta{Name: "selector-experiment", Namespace: "default"},
+		Spec: chaosv1alpha1.Havock8sExperimentSpec{
+			Target: chaosv1alpha1.TargetSpec{
+				Selector:   &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				TargetType: "Pod",
+				Mode:       chaosv1alpha1.TargetModeAll,
+			},
+			ChaosType:  "PodFailure",
+			Duration:   "1m",
+			Parameters: map[string]string{"failureMode": "terminate"},
+		},
+	}
+	if err := fakeClient.Create(ctx, experiment); err != nil {
+		t.Fatalf("Failed to create experiment: %v", err)
+	}
+
+	reconciler := &Havock8sExperimentReconciler{Client: fakeClient, Scheme: scheme}
+	req := reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: experiment.Name, Namespace: experiment.Namespace},
+	}
+	for i := 0; i < 3 && experiment.Status.Phase != "Running"; i++ {
+		if _, err := reconciler.Reconcile(ctx, req); err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+		if err := fakeClient.Get(ctx, req.NamespacedName, experiment); err != nil {
+			t.Fatalf("Failed to get experiment: %v", err)
+		}
+	}
+
+	if experiment.Status.Phase != "Running" {
+		t.Fatalf("Expected phase Running, got %s (%s)", experiment.Status.Phase, experiment.Status.FailureReason)
+	}
+	var targets []string
+	for _, target := range experiment.Status.TargetResources {
+		targets = append(targets, target.Name)
+	}
+	if fmt.Sprint(targets) != "[web-0 web-1]" {
+		t.Errorf("TargetResources = %v, want [web-0 web-1]", targets)
+	}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Name: "db-0", Namespace: "default"}, &corev1.Pod{}); err != nil {
+		t.Errorf("Pod outside the selector was touched: %v", err)
+	}
+}
+
+func TestHavock8sExperimentReconciler_RecordsRestartCounts(t *testing.T) {
+	scheme := setupScheme()
+	fakeClient := setupFakeClient(scheme)
+	ctx := context.Background()
+
+	// A pod killed by the node agent whose container restarted once since
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "db-0",
+			Namespace: "default",
+			Annotations: map[string]string{
+				"havock8s.io/kill":               "true",
+				"havock8s.io/kill-container":     "db",
+				"havock8s.io/kill-signal":        "SIGKILL",
+				"havock8s.io/kill-restart-count": "4",
+				"havock8s.io/kill-ack":           "applied",
+			},
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "db", Image: "postgres"}}},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "db", RestartCount: 5}},
+		},
+	}
+	if err := fakeClient.Create(ctx, pod); err != nil {
+		t.Fatalf("Failed to create pod: %v", err)
+	}
+
+	start := time.Now().Add(-2 * time.Minute)
+	experiment := &chaosv1alpha1.Havock8sExperiment{
+		ObjectMeta: metav1.ObjectMeta{Name: "kill-experiment", Namespace: "default"},
+		Spec: chaosv1alpha1.Havock8sExperimentSpec{
+			Target:    chaosv1alpha1.TargetSpec{Name: "db-0", Namespace: "default", TargetType: "Pod"},
+			ChaosType: "ContainerKill",
+			Duration:  "1m",
+		},
+	}
+	if err := fakeClient.Create(ctx, experiment); err != nil {
+		t.Fatalf("Failed to create experiment: %v", err)
+	}
+	experiment.Status.Phase = "Running"
+	experiment.Status.StartTime = &metav1.Time{Time: start}
+	experiment.Status.TargetResources = []chaosv1alpha1.TargetResourceStatus{
+		{Kind: "Pod", Name: "db-0", Namespace: "default", Status: "Targeted"},
+	}
+	if err := fakeClient.Status().Update(ctx, experiment); err != nil {
+		t.Fatalf("Failed to update experiment status: %v", err)
+	}
+
+	reconciler := &Havock8sExperimentReconciler{Client: fakeClient, Scheme: scheme}
+	req := reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: experiment.Name, Namespace: experiment.Namespace},
+	}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if err := fakeClient.Get(ctx, req.NamespacedName, experiment); err != nil {
+		t.Fatalf("Failed to get experiment: %v", err)
+	}
+	if experiment.Status.Phase != "Completed" {
+		t.Fatalf("Expected phase Completed, got %s", experiment.Status.Phase)
+	}
+	restarts := experiment.Status.TargetResources[0].RestartCount
+	if restarts == nil || *restarts != 1 {
+		t.Errorf("Expected 1 restart recorded for db-0, got %v", restarts)
+	}
+
+	// The kill request is gone once the experiment completed
+	if err := fakeClient.Get(ctx, types.NamespacedName{Name: "db-0", Namespace: "default"}, pod); err != nil {
+		t.Fatalf("Failed to get pod: %v", err)
+	}
+	if _, ok := pod.Annotations["havock8s.io/kill"]; ok {
+		t.Errorf("Kill request still present on pod after completion")
+	}
+}
+
+// failingCleanupInjector injects nothing and always fails to clean up
+type failingCleanupInjector struct{}
+
+func (i *failingCleanupInjector) Inject(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	return nil
+}
+
+func (i *failingCleanupInjector) Cleanup(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	return fmt.Errorf("target unreachable")
+}
+
+func TestHavock8sExperimentReconciler_CleansUpOnDeletion(t *testing.T) {
+	chaos.RegisterInjector("FailingCleanup", func(chaos.Dependencies) chaos.Injector { return &failingCleanupInjector{} })
+
+	tests := []struct {
+		name          string
+		chaosType     string
+		pluginTypes   []string
+		wantErr       bool
+		wantDeleted   bool
+		wantReplicas  int32
+		wantCondition bool
+	}{
+		{
+			name:         "restores scaled down StatefulSet",
+			chaosType:    "StatefulSetScaling",
+			wantDeleted:  true,
+			wantReplicas: 3,
+		},
+		{
+			name:          "keeps finalizer when cleanup fails",
+			chaosType:     "FailingCleanup",
+			wantErr:       true,
+			wantReplicas:  1,
+			wantCondition: true,
+		},
+		{
+			name:         "keeps finalizer until the plugin of the chaos type is registered",
+			chaosType:    "PluginChaos",
+			pluginTypes:  []string{"PluginChaos"},
+			wantErr:      true,
+			wantReplicas: 1,
+		},
+		{
+			name:         "skips cleanup of unknown chaos type",
+			chaosType:    "UnknownChaos",
+			wantDeleted:  true,
+			wantReplicas: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme()
+			fakeClient := setupFakeClient(scheme)
+			ctx := context.Background()
+
+			replicas := int32(1)
+			sts := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-sts",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"havock8s.io/original-replicas": "3",
+					},
+				},
+				Spec: appsv1.StatefulSetSpec{
+					Replicas: &replicas,
+				},
+			}
+			if err := fakeClient.Create(ctx, sts); err != nil {
+				t.Fatalf("Failed to create StatefulSet: %v", err)
+			}
+			if tt.pluginTypes != nil {
+				chaosPlugin := &chaosv1alpha1.ChaosPlugin{
+					ObjectMeta: metav1.ObjectMeta{Name: "example-plugin"},
+					Spec:       chaosv1alpha1.ChaosPluginSpec{ChaosTypes: tt.pluginTypes},
+				}
+				if err := fakeClient.Create(ctx, chaosPlugin); err != nil {
+					t.Fatalf("Failed to create ChaosPlugin: %v", err)
+				}
+			}
+
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "scaling-experiment",
+					Namespace: "default",
+				},
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					Target: chaosv1alpha1.TargetSpec{
+						Name:       "test-sts",
+						Namespace:  "default",
+						TargetType: "StatefulSet",
+					},
+					ChaosType: tt.chaosType,
+					Duration:  "1h",
+				},
+			}
+			if err := fakeClient.Create(ctx, experiment); err != nil {
+				t.Fatalf("Failed to create experiment: %v", err)
+			}
+
+			reconciler := &Havock8sExperimentReconciler{
+				Client: fakeClient,
+				Scheme: scheme,
+			}
+			req := reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: experiment.Name, Namespace: experiment.Namespace},
+			}
+
+			// Initializing the experiment attaches the finalizer
+			if _, err := reconciler.Reconcile(ctx, req); err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			if err := fakeClient.Get(ctx, req.NamespacedName, experiment); err != nil {
+				t.Fatalf("Failed to get experiment: %v", err)
+			}
+			if !containsString(experiment.Finalizers, experimentFinalizer) {
+				t.Fatalf("Expected finalizer %s, got %v", experimentFinalizer, experiment.Finalizers)
+			}
+
+			// Pretend the chaos is running against the StatefulSet
+			experiment.Status.Phase = "Running"
+			experiment.Status.TargetResources = []chaosv1alpha1.TargetResourceStatus{
+				{Kind: "StatefulSet", Name: "test-sts", Namespace: "default", Status: "Targeted"},
+			}
+			if err := fakeClient.Status().Update(ctx, experiment); err != nil {
+				t.Fatalf("Failed to update experiment status: %v", err)
+			}
+
+			if err := fakeClient.Delete(ctx, experiment); err != nil {
+				t.Fatalf("Failed to delete experiment: %v", err)
+			}
+			_, err := reconciler.Reconcile(ctx, req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Reconcile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			updatedExp := &chaosv1alpha1.Havock8sExperiment{}
+			err = fakeClient.Get(ctx, req.NamespacedName, updatedExp)
+			if tt.wantDeleted {
+				if !apierrors.IsNotFound(err) {
+					t.Errorf("Expected experiment to be deleted, got error %v", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Failed to get experiment: %v", err)
+				}
+				if !containsString(updatedExp.Finalizers, experimentFinalizer) {
+					t.Error("Expected finalizer to be kept after failed cleanup")
+				}
+				condition := meta.FindStatusCondition(updatedExp.Status.Conditions, conditionCleanedUp)
+				if tt.wantCondition && (condition == nil || condition.Status != metav1.ConditionFalse) {
+					t.Errorf("Expected %s condition to be False, got %v", conditionCleanedUp, condition)
+				}
+			}
+
+			updatedSts := &appsv1.StatefulSet{}
+			if err := fakeClient.Get(ctx, types.NamespacedName{Name: "test-sts", Namespace: "default"}, updatedSts); err != nil {
+				t.Fatalf("Failed to get StatefulSet: %v", err)
+			}
+			if *updatedSts.Spec.Replicas != tt.wantReplicas {
+				t.Errorf("Expected %d replicas, got %d", tt.wantReplicas, *updatedSts.Spec.Replicas)
+			}
+		})
+	}
+}
+
+func TestHavock8sExperimentReconciler_WaitsForPluginRegistration(t *testing.T) {
+	scheme := setupScheme()
+	fakeClient := setupFakeClient(scheme)
+	ctx := context.Background()
+
+	if err := setupTestPod(fakeClient, "test-pod", "default"); err != nil {
+		t.Fatalf("Failed to create test pod: %v", err)
+	}
+	chaosPlugin := &chaosv1alpha1.ChaosPlugin{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-plugin"},
+		Spec:       chaosv1alpha1.ChaosPluginSpec{ChaosTypes: []string{"PluginChaos"}},
+	}
+	if err := fakeClient.Create(ctx, chaosPlugin); err != nil {
+		t.Fatalf("Failed to create ChaosPlugin: %v", err)
+	}
+	experiment := &chaosv1alpha1.Havock8sExperiment{
+		ObjectMeta: metav1.ObjectMeta{Name: "plugin-experiment", Namespace: "default"},
+		Spec: chaosv1alpha1.Havock8sExperimentSpec{
+			Target: chaosv1alpha1.TargetSpec{
+				Name:       "test-pod",
+				Namespace:  "default",
+				TargetType: "Pod",
+			},
+			ChaosType: "PluginChaos",
+			Duration:  "1m",
+		},
+	}
+	if err := fakeClient.Create(ctx, experiment); err != nil {
+		t.Fatalf("Failed to create experiment: %v", err)
+	}
+
+	reconciler := &Havock8sExperimentReconciler{Client: fakeClient, Scheme: scheme}
+	req := reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: experiment.Name, Namespace: experiment.Namespace},
+	}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	// The plugin's chaos type isn't registered until its ChaosPlugin is reconciled
+	if _, err := reconciler.Reconcile(ctx, req); err == nil {
+		t.Fatal("Expected Reconcile() to retry while the plugin isn't registered")
+	}
+	if err := fakeClient.Get(ctx, req.NamespacedName, experiment); err != nil {
+		t.Fatalf("Failed to get experiment: %v", err)
+	}
+	if experiment.Status.Phase != "Pending" {
+		t.Errorf("Expected phase Pending, got %s (%s)", experiment.Status.Phase, experiment.Status.FailureReason)
+	}
+}
+
+func TestHavock8sExperimentReconciler_RollsBackOnFailedSafetyChecks(t *testing.T) {
+	// A closed server leaves a port nothing listens on
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	u, err := url.Parse(down.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse server URL: %v", err)
+	}
+	port, err := strconv.ParseInt(u.Port(), 10, 32)
+	if err != nil {
+		t.Fatalf("Failed to parse port: %v", err)
+	}
+	down.Close()
+
+	tests := []struct {
+		name         string
+		autoRollback bool
+		wantPhase    string
+		wantReplicas int32
+		wantReason   string
+	}{
+		{
+			name:         "auto-rollback restores the target",
+			autoRollback: true,
+			wantPhase:    "RolledBack",
+			wantReplicas: 3,
+			wantReason:   "RolledBack",
+		},
+		{
+			name:         "without auto-rollback the failure is only reported",
+			autoRollback: false,
+			wantPhase:    "Running",
+			wantReplicas: 1,
+			wantReason:   "SafetyCheckFailed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme()
+			fakeClient := setupFakeClient(scheme)
+			ctx := context.Background()
+
+			replicas := int32(1)
+			sts := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-sts",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"havock8s.io/original-replicas": "3",
+					},
+				},
+				Spec: appsv1.StatefulSetSpec{
+					Replicas: &replicas,
+				},
+			}
+			if err := fakeClient.Create(ctx, sts); err != nil {
+				t.Fatalf("Failed to create StatefulSet: %v", err)
+			}
+
+			start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "scaling-experiment",
+					Namespace: "default",
+				},
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					Target: chaosv1alpha1.TargetSpec{
+						Name:       "test-sts",
+						Namespace:  "default",
+						TargetType: "StatefulSet",
+					},
+					ChaosType: "StatefulSetScaling",
+					Duration:  "1h",
+					Safety: &chaosv1alpha1.SafetySpec{
+						AutoRollback:  tt.autoRollback,
+						CheckInterval: "10s",
+						HealthChecks: []chaosv1alpha1.HealthCheckSpec{
+							{
+								Type:             "httpGet",
+								Host:             "127.0.0.1",
+								Path:             "/health",
+								Port:             int32(port),
+								FailureThreshold: 2,
+							},
+						},
+					},
+				},
+			}
+			if err := fakeClient.Create(ctx, experiment); err != nil {
+				t.Fatalf("Failed to create experiment: %v", err)
+			}
+			experiment.Status.Phase = "Running"
+			experiment.Status.StartTime = &metav1.Time{Time: start}
+			experiment.Status.TargetResources = []chaosv1alpha1.TargetResourceStatus{
+				{Kind: "StatefulSet", Name: "test-sts", Namespace: "default", Status: "Targeted"},
+			}
+			if err := fakeClient.Status().Update(ctx, experiment); err != nil {
+				t.Fatalf("Failed to update experiment status: %v", err)
+			}
+
+			now := start.Add(time.Minute)
+			reconciler := &Havock8sExperimentReconciler{
+				Client:  fakeClient,
+				Scheme:  scheme,
+				nowFunc: func() time.Time { return now },
+			}
+			req := reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: experiment.Name, Namespace: experiment.Namespace},
+			}
+
+			// First failure, then a reconcile within the interval that must not probe again
+			for _, offset := range []time.Duration{0, 5 * time.Second} {
+				now = start.Add(time.Minute + offset)
+				if _, err := reconciler.Reconcile(ctx, req); err != nil {
+					t.Fatalf("Reconcile() error = %v", err)
+				}
+			}
+			if err := fakeClient.Get(ctx, req.NamespacedName, experiment); err != nil {
+				t.Fatalf("Failed to get experiment: %v", err)
+			}
+			if experiment.Status.Phase != "Running" {
+				t.Fatalf("Expected phase Running below the failure threshold, got %s", experiment.Status.Phase)
+			}
+			if len(experiment.Status.HealthChecks) != 1 || experiment.Status.HealthChecks[0].ConsecutiveFailures != 1 {
+				t.Fatalf("Expected one recorded failure, got %+v", experiment.Status.HealthChecks)
+			}
+
+			// Second failure reaches the threshold
+			now = start.Add(time.Minute + 10*time.Second)
+			if _, err := reconciler.Reconcile(ctx, req); err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			if err := fakeClient.Get(ctx, req.NamespacedName, experiment); err != nil {
+				t.Fatalf("Failed to get experiment: %v", err)
+			}
+			if experiment.Status.Phase != tt.wantPhase {
+				t.Errorf("Expected phase %s, got %s", tt.wantPhase, experiment.Status.Phase)
+			}
+			condition := meta.FindStatusCondition(experiment.Status.Conditions, conditionSafetyChecksPassing)
+			if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != tt.wantReason {
+				t.Errorf("Expected %s condition False/%s, got %+v", conditionSafetyChecksPassing, tt.wantReason, condition)
+			}
+
+			updatedSts := &appsv1.StatefulSet{}
+			if err := fakeClient.Get(ctx, types.NamespacedName{Name: "test-sts", Namespace: "default"}, updatedSts); err != nil {
+				t.Fatalf("Failed to get StatefulSet: %v", err)
+			}
+			if *updatedSts.Spec.Replicas != tt.wantReplicas {
+				t.Errorf("Expected %d replicas, got %d", tt.wantReplicas, *updatedSts.Spec.Replicas)
+			}
+		})
+	}
+}
+
+func TestHavock8sExperimentReconciler_CompletesPodFailureWithAutoRollback(t *testing.T) {
+	scheme := setupScheme()
+	fakeClient := setupFakeClient(scheme)
+	ctx := context.Background()
+
+	// The target pod was deleted by the pod failure, so a health check of its
+	// pods would fail if it ran
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	experiment := &chaosv1alpha1.Havock8sExperiment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod-failure-experiment",
+			Namespace: "default",
+		},
+		Spec: chaosv1alpha1.Havock8sExperimentSpec{
+			Target: chaosv1alpha1.TargetSpec{
+				Name:       "test-pod",
+				Namespace:  "default",
+				TargetType: "Pod",
+			},
+			ChaosType: "PodFailure",
+			Duration:  "1h",
+			Safety: &chaosv1alpha1.SafetySpec{
+				AutoRollback: true,
+				HealthChecks: []chaosv1alpha1.HealthCheckSpec{
+					{
+						Type:             "httpGet",
+						Path:             "/health",
+						Port:             8080,
+						FailureThreshold: 1,
+					},
+				},
+			},
+		},
+	}
+	if err := fakeClient.Create(ctx, experiment); err != nil {
+		t.Fatalf("Failed to create experiment: %v", err)
+	}
+	experiment.Status.Phase = "Running"
+	experiment.Status.StartTime = &metav1.Time{Time: start}
+	experiment.Status.TargetResources = []chaosv1alpha1.TargetResourceStatus{
+		{Kind: "Pod", Name: "test-pod", Namespace: "default", Status: "Targeted"},
+	}
+	if err := fakeClient.Status().Update(ctx, experiment); err != nil {
+		t.Fatalf("Failed to update experiment status: %v", err)
+	}
+
+	reconciler := &Havock8sExperimentReconciler{
+		Client:  fakeClient,
+		Scheme:  scheme,
+		nowFunc: func() time.Time { return start.Add(time.Minute) },
+	}
+	req := reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: experiment.Name, Namespace: experiment.Namespace},
+	}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if err := fakeClient.Get(ctx, req.NamespacedName, experiment); err != nil {
+		t.Fatalf("Failed to get experiment: %v", err)
+	}
+	if experiment.Status.Phase != "Completed" {
+		t.Errorf("Expected phase Completed, got %s (%s)", experiment.Status.Phase, experiment.Status.FailureReason)
+	}
+}
+
+func TestHavock8sExperimentReconciler_RollsBackOnMetricPauseCondition(t *testing.T) {
+	prometheus := prometheustest.NewServer()
+	defer prometheus.Close()
+	prometheus.SetVector(`sum(rate(http_requests_total{code="500"}[1m]))`, 25)
+
+	scheme := setupScheme()
+	fakeClient := setupFakeClient(scheme)
+	ctx := context.Background()
+
+	experiment := &chaosv1alpha1.Havock8sExperiment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "scaling-experiment",
+			Namespace: "default",
+		},
+		Spec: chaosv1alpha1.Havock8sExperimentSpec{
+			Target: chaosv1alpha1.TargetSpec{
+				Name:       "test-sts",
+				Namespace:  "default",
+				TargetType: "StatefulSet",
+			},
+			ChaosType: "StatefulSetScaling",
+			Duration:  "1h",
+			Safety: &chaosv1alpha1.SafetySpec{
+				AutoRollback: true,
+				PauseConditions: []chaosv1alpha1.PauseConditionSpec{
+					{
+						Type:        "metric",
+						MetricQuery: `sum(rate(http_requests_total{code="500"}[1m]))`,
+						Threshold:   "> 10",
+					},
+				},
+			},
+		},
+	}
+	if err := fakeClient.Create(ctx, experiment); err != nil {
+		t.Fatalf("Failed to create experiment: %v", err)
+	}
+	experiment.Status.Phase = "Running"
+	experiment.Status.StartTime = &metav1.Time{Time: time.Now()}
+	if err := fakeClient.Status().Update(ctx, experiment); err != nil {
+		t.Fatalf("Failed to update experiment status: %v", err)
+	}
+
+	reconciler := &Havock8sExperimentReconciler{
+		Client:        fakeClient,
+		Scheme:        scheme,
+		PrometheusURL: prometheus.URL,
+	}
+	req := reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: experiment.Name, Namespace: experiment.Namespace},
+	}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if err := fakeClient.Get(ctx, req.NamespacedName, experiment); err != nil {
+		t.Fatalf("Failed to get experiment: %v", err)
+	}
+	if experiment.Status.Phase != "RolledBack" {
+		t.Errorf("Expected phase RolledBack, got %s", experiment.Status.Phase)
+	}
+	if len(prometheus.Queries()) != 1 {
+		t.Errorf("Expected one Prometheus query, got %v", prometheus.Queries())
+	}
+}
+
+func TestHavock8sExperimentReconciler_RecordsRollbackEvents(t *testing.T) {
+	prometheus := prometheustest.NewServer()
+	defer prometheus.Close()
+	prometheus.SetVector(`up{job="db"}`, 0)
+
+	scheme := setupScheme()
+	fakeClient := setupFakeClient(scheme)
+	ctx := context.Background()
+
+	replicas := int32(1)
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-sts",
+			Namespace: "default",
+			Annotations: map[string]string{
+				"havock8s.io/original-replicas": "3",
+			},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+		},
+	}
+	if err := fakeClient.Create(ctx, sts); err != nil {
+		t.Fatalf("Failed to create StatefulSet: %v", err)
+	}
+
+	experiment := &chaosv1alpha1.Havock8sExperiment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "scaling-experiment",
+			Namespace: "default",
+		},
+		Spec: chaosv1alpha1.Havock8sExperimentSpec{
+			Target: chaosv1alpha1.TargetSpec{
+				Name:       "test-sts",
+				Namespace:  "default",
+				TargetType: "StatefulSet",
+			},
+			ChaosType: "StatefulSetScaling",
+			Duration:  "1h",
+			Safety: &chaosv1alpha1.SafetySpec{
+				AutoRollback: true,
+				PauseConditions: []chaosv1alpha1.PauseConditionSpec{
+					{
+						Type:        "metric",
+						MetricQuery: `up{job="db"}`,
+						Threshold:   "< 1",
+					},
+				},
+			},
+		},
+	}
+	if err := fakeClient.Create(ctx, experiment); err != nil {
+		t.Fatalf("Failed to create experiment: %v", err)
+	}
+	experiment.Status.Phase = "Running"
+	experiment.Status.StartTime = &metav1.Time{Time: time.Now()}
+	experiment.Status.TargetResources = []chaosv1alpha1.TargetResourceStatus{
+		{Kind: "StatefulSet", Name: "test-sts", Namespace: "default", Status: "Targeted"},
+	}
+	if err := fakeClient.Status().Update(ctx, experiment); err != nil {
+		t.Fatalf("Failed to update experiment status: %v", err)
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	recorder.IncludeObject = true
+	reconciler := &Havock8sExperimentReconciler{
+		Client:        fakeClient,
+		Scheme:        scheme,
+		PrometheusURL: prometheus.URL,
+		Recorder:      recorder,
+	}
+	req := reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: experiment.Name, Namespace: experiment.Namespace},
+	}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+
+	// The experiment, the target and the injector's restore are all reported
+	wantReasons := []struct {
+		reason string
+		object string
+	}{
+		{"Warning SafetyTripped", "involvedObject{kind=,apiVersion=}"},
+		{"Warning SafetyTripped", "involvedObject{kind=StatefulSet,apiVersion=apps/v1}"},
+		{"Normal ReplicasRestored", "involvedObject{kind=,apiVersion=}"},
+		{"Normal ReplicasRestored", "involvedObject{kind=,apiVersion=}"},
+		{"Normal RolledBack", "involvedObject{kind=,apiVersion=}"},
+	}
+	if len(events) != len(wantReasons) {
+		t.Fatalf("Expected %d events, got %q", len(wantReasons), events)
+	}
+	for i, want := range wantReasons {
+		if !strings.HasPrefix(events[i], want.reason+" ") || !strings.HasSuffix(events[i], want.object) {
+			t.Errorf("Event %d = %q, want %s on %s", i, events[i], want.reason, want.object)
+		}
+	}
+}
+
+func TestHavock8sExperimentReconciler_TracesReconcile(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(tracing.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(previous)
+
+	scheme := setupScheme()
+	fakeClient := setupFakeClient(scheme)
+	ctx := context.Background()
+
+	replicas := int32(3)
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-sts",
+			Namespace: "default",
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+		},
+	}
+	if err := fakeClient.Create(ctx, sts); err != nil {
+		t.Fatalf("Failed to create StatefulSet: %v", err)
+	}
+
+	experiment := &chaosv1alpha1.Havock8sExperiment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "scaling-experiment",
+			Namespace: "default",
+			UID:       "experiment-uid",
+		},
+		Spec: chaosv1alpha1.Havock8sExperimentSpec{
+			Target: chaosv1alpha1.TargetSpec{
+				Name:       "test-sts",
+				Namespace:  "default",
+				TargetType: "StatefulSet",
+			},
+			ChaosType: "StatefulSetScaling",
+			Duration:  "1h",
+		},
+	}
+	if err := fakeClient.Create(ctx, experiment); err != nil {
+		t.Fatalf("Failed to create experiment: %v", err)
+	}
+	experiment.Status.Phase = "Pending"
+	if err := fakeClient.Status().Update(ctx, experiment); err != nil {
+		t.Fatalf("Failed to update experiment status: %v", err)
+	}
+
+	reconciler := &Havock8sExperimentReconciler{
+		Client: fakeClient,
+		Scheme: scheme,
+	}
+	req := reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: experiment.Name, Namespace: experiment.Namespace},
+	}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	// Children end before the reconcile that started them
+	spans := exporter.GetSpans()
+	wantNames := []string{"CheckSafety", "Inject", "Reconcile"}
+	if len(spans) != len(wantNames) {
+		t.Fatalf("Expected %d spans, got %d", len(wantNames), len(spans))
+	}
+	reconcileSpan := spans[len(spans)-1]
+	for i, span := range spans {
+		if span.Name != wantNames[i] {
+			t.Errorf("Span %d = %q, want %q", i, span.Name, wantNames[i])
+		}
+		if uid := spanAttribute(span, tracing.ExperimentUIDKey); uid != "experiment-uid" {
+			t.Errorf("Span %q experiment UID = %q, want experiment-uid", span.Name, uid)
+		}
+		if span.Name != "Reconcile" && span.Parent.SpanID() != reconcileSpan.SpanContext.SpanID() {
+			t.Errorf("Span %q is not a child of the reconcile span", span.Name)
+		}
+	}
+}
+
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) string {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value.AsString()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/metrics"
+	"github.com/havock8s/havock8s/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// conditionTargetsRecovered tracks whether the target pods became ready after the chaos ended
+	conditionTargetsRecovered = "TargetsRecovered"
+
+	// recoveryPollInterval is how often the target pods are checked while they recover
+	recoveryPollInterval = 5 * time.Second
+
+	// recoveryTimeout is how long to wait for the target pods to recover
+	recoveryTimeout = 10 * time.Minute
+)
+
+// processFinishedExperiment waits for the target pods of an experiment whose
+// chaos was cleaned up to become ready again and records the time to recovery
+func (r *Havock8sExperimentReconciler) processFinishedExperiment(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, logger logr.Logger) (ctrl.Result, error) {
+	// Schedules only spawn runs, their runs track their own recovery
+	if isScheduled(experiment) || experiment.Status.EndTime == nil {
+		return ctrl.Result{}, nil
+	}
+	if condition := meta.FindStatusCondition(experiment.Status.Conditions, conditionTargetsRecovered); condition != nil && condition.Reason != "Recovering" {
+		return ctrl.Result{}, nil
+	}
+
+	pods, err := utils.WorkloadPods(ctx, r.Client, experiment)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	elapsed := r.now().Sub(experiment.Status.EndTime.Time)
+	condition := metav1.Condition{
+		Type: conditionTargetsRecovered,
+	}
+	result := ctrl.Result{}
+	switch {
+	case len(pods) == 0 && (experiment.Spec.Target.TargetType == "" || experiment.Spec.Target.TargetType == "Pod"):
+		// A deleted pod is replaced under a new name, there is nothing to wait for
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = "NotTracked"
+		condition.Message = "The target pods no longer exist"
+	case len(pods) > 0 && allPodsReady(pods):
+		logger.Info("Experiment targets recovered", "after", elapsed)
+		metrics.ObserveRecovery(experiment.Spec.ChaosType, elapsed)
+		r.event(experiment, corev1.EventTypeNormal, "Recovered", "All %d target pods were ready %s after the chaos ended", len(pods), elapsed.Round(time.Second))
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "Recovered"
+		condition.Message = "All target pods are ready"
+	case elapsed > recoveryTimeout:
+		logger.Info("Experiment targets did not recover", "timeout", recoveryTimeout)
+		metrics.RecordRecoveryTimeout(experiment.Spec.ChaosType)
+		r.event(experiment, corev1.EventTypeWarning, "RecoveryTimedOut", "Target pods were not ready %s after the chaos ended", recoveryTimeout)
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "RecoveryTimedOut"
+		condition.Message = "Target pods were not ready within " + recoveryTimeout.String()
+	default:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Recovering"
+		condition.Message = "Waiting for the target pods to become ready"
+		result.RequeueAfter = recoveryPollInterval
+	}
+
+	if meta.SetStatusCondition(&experiment.Status.Conditions, condition) {
+		if err := r.Status().Update(ctx, experiment); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return result, nil
+}
+
+// allPodsReady reports whether every pod is ready and not being deleted
+func allPodsReady(pods []corev1.Pod) bool {
+	for _, pod := range pods {
+		if !pod.DeletionTimestamp.IsZero() {
+			return false
+		}
+		ready := false
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+				ready = true
+				break
+			}
+		}
+		if !ready {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestHavock8sExperimentReconciler_TracksRecovery(t *testing.T) {
+	end := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		targetType  string
+		podReady    corev1.ConditionStatus
+		elapsed     time.Duration
+		wantStatus  metav1.ConditionStatus
+		wantReason  string
+		wantRequeue time.Duration
+	}{
+		{
+			name:       "ready pods recovered",
+			targetType: "StatefulSet",
+			podReady:   corev1.ConditionTrue,
+			elapsed:    30 * time.Second,
+			wantStatus: metav1.ConditionTrue,
+			wantReason: "Recovered",
+		},
+		{
+			name:        "unready pods are waited for",
+			targetType:  "StatefulSet",
+			podReady:    corev1.ConditionFalse,
+			elapsed:     30 * time.Second,
+			wantStatus:  metav1.ConditionFalse,
+			wantReason:  "Recovering",
+			wantRequeue: recoveryPollInterval,
+		},
+		{
+			name:       "unready pods time out",
+			targetType: "StatefulSet",
+			podReady:   corev1.ConditionFalse,
+			elapsed:    recoveryTimeout + time.Second,
+			wantStatus: metav1.ConditionFalse,
+			wantReason: "RecoveryTimedOut",
+		},
+		{
+			name:       "deleted pod is not tracked",
+			targetType: "Pod",
+			elapsed:    30 * time.Second,
+			wantStatus: metav1.ConditionUnknown,
+			wantReason: "NotTracked",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme()
+			fakeClient := setupFakeClient(scheme)
+			ctx := context.Background()
+
+			sts := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-sts", Namespace: "default"},
+			}
+			if err := fakeClient.Create(ctx, sts); err != nil {
+				t.Fatalf("Failed to create StatefulSet: %v", err)
+			}
+			if tt.podReady != "" {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-sts-0",
+						Namespace: "default",
+						OwnerReferences: []metav1.OwnerReference{
+							{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "test-sts", UID: "sts-uid"},
+						},
+					},
+					Status: corev1.PodStatus{
+						Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: tt.podReady}},
+					},
+				}
+				if err := fakeClient.Create(ctx, pod); err != nil {
+					t.Fatalf("Failed to create pod: %v", err)
+				}
+			}
+
+			targetName := "test-sts"
+			if tt.targetType == "Pod" {
+				targetName = "deleted-pod"
+			}
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				ObjectMeta: metav1.ObjectMeta{Name: "recovery-experiment", Namespace: "default"},
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					Target: chaosv1alpha1.TargetSpec{
+						Name:       targetName,
+						Namespace:  "default",
+						TargetType: tt.targetType,
+					},
+					ChaosType: "PodFailure",
+					Duration:  "1m",
+				},
+			}
+			if err := fakeClient.Create(ctx, experiment); err != nil {
+				t.Fatalf("Failed to create experiment: %v", err)
+			}
+			experiment.Status.Phase = "Completed"
+			experiment.Status.EndTime = &metav1.Time{Time: end}
+			if err := fakeClient.Status().Update(ctx, experiment); err != nil {
+				t.Fatalf("Failed to update experiment status: %v", err)
+			}
+
+			reconciler := &Havock8sExperimentReconciler{
+				Client:  fakeClient,
+				Scheme:  scheme,
+				nowFunc: func() time.Time { return end.Add(tt.elapsed) },
+			}
+			req := reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: experiment.Name, Namespace: experiment.Namespace},
+			}
+			result, err := reconciler.Reconcile(ctx, req)
+			if err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			if result.RequeueAfter != tt.wantRequeue {
+				t.Errorf("RequeueAfter = %v, want %v", result.RequeueAfter, tt.wantRequeue)
+			}
+
+			if err := fakeClient.Get(ctx, req.NamespacedName, experiment); err != nil {
+				t.Fatalf("Failed to get experiment: %v", err)
+			}
+			condition := meta.FindStatusCondition(experiment.Status.Conditions, conditionTargetsRecovered)
+			if condition == nil {
+				t.Fatalf("Expected a %s condition", conditionTargetsRecovered)
+			}
+			if condition.Status != tt.wantStatus || condition.Reason != tt.wantReason {
+				t.Errorf("Condition = %s/%s, want %s/%s", condition.Status, condition.Reason, tt.wantStatus, tt.wantReason)
+			}
+
+			// A settled recovery is not checked again
+			if tt.wantRequeue == 0 {
+				result, err := reconciler.Reconcile(ctx, req)
+				if err != nil || result.RequeueAfter != 0 {
+					t.Errorf("Reconcile() after recovery = %v, %v", result, err)
+				}
+			}
+		})
+	}
+}
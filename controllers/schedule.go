@@ -0,0 +1,240 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// scheduleLabel is set on every run spawned by a scheduled experiment and
+	// holds the name of the scheduled experiment
+	scheduleLabel = "chaos.havock8s.io/schedule"
+
+	// defaultHistoryLimit is how many finished runs are kept by default
+	defaultHistoryLimit = 3
+
+	// scheduleRetryInterval is how soon to look again at a schedule whose
+	// due run is held back by the concurrency policy
+	scheduleRetryInterval = 10 * time.Second
+)
+
+// isScheduled reports whether an experiment spawns runs on a cron schedule
+// instead of running itself
+func isScheduled(experiment *chaosv1alpha1.Havock8sExperiment) bool {
+	return experiment.Spec.Schedule != nil && experiment.Spec.Schedule.Cron != ""
+}
+
+// isFinished reports whether an experiment run has finished
+func isFinished(experiment *chaosv1alpha1.Havock8sExperiment) bool {
+	switch experiment.Status.Phase {
+	case "Completed", "Failed", "RolledBack":
+		return true
+	}
+	return false
+}
+
+// processScheduledExperiment spawns runs of a scheduled experiment at every
+// tick of its cron schedule
+func (r *Havock8sExperimentReconciler) processScheduledExperiment(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, logger logr.Logger) (ctrl.Result, error) {
+	schedule := experiment.Spec.Schedule
+
+	sched, err := cron.ParseStandard(schedule.Cron)
+	if err != nil {
+		experiment.Status.Phase = "Failed"
+		experiment.Status.FailureReason = fmt.Sprintf("invalid cron schedule %q: %v", schedule.Cron, err)
+		r.event(experiment, corev1.EventTypeWarning, "Failed", "Invalid cron schedule %q: %v", schedule.Cron, err)
+		if err := r.Status().Update(ctx, experiment); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	runs, err := r.listRuns(ctx, experiment)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var active, finished []chaosv1alpha1.Havock8sExperiment
+	for _, run := range runs {
+		if isFinished(&run) {
+			finished = append(finished, run)
+		} else {
+			active = append(active, run)
+		}
+	}
+
+	// Only keep the most recent finished runs
+	historyLimit := defaultHistoryLimit
+	if schedule.HistoryLimit != nil {
+		historyLimit = int(*schedule.HistoryLimit)
+	}
+	for i := 0; i < len(finished)-historyLimit; i++ {
+		logger.Info("Deleting old experiment run", "run", finished[i].Name)
+		if err := r.Delete(ctx, &finished[i]); client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	now := r.now()
+	result := ctrl.Result{}
+
+	if scheduledTime, due := nextRunTime(sched, experiment, now); due {
+		started, err := r.startRun(ctx, experiment, scheduledTime, active, logger)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if started != nil {
+			experiment.Status.LastScheduleTime = &metav1.Time{Time: scheduledTime}
+			if schedule.ConcurrencyPolicy == chaosv1alpha1.ConcurrencyPolicyReplace {
+				active = nil
+			}
+			active = append(active, *started)
+		} else {
+			result.RequeueAfter = scheduleRetryInterval
+		}
+	}
+
+	experiment.Status.ActiveRuns = nil
+	for _, run := range active {
+		experiment.Status.ActiveRuns = append(experiment.Status.ActiveRuns, run.Name)
+	}
+
+	if schedule.Once && experiment.Status.LastScheduleTime != nil {
+		// The single run has been started, the schedule is done once it finishes
+		experiment.Status.NextScheduleTime = nil
+		if len(active) == 0 {
+			r.event(experiment, corev1.EventTypeNormal, "Completed", "The single scheduled run finished")
+			experiment.Status.Phase = "Completed"
+			experiment.Status.EndTime = &metav1.Time{Time: now}
+		}
+	} else {
+		next := sched.Next(now)
+		experiment.Status.NextScheduleTime = &metav1.Time{Time: next}
+		if result.RequeueAfter == 0 {
+			result.RequeueAfter = next.Sub(now)
+		}
+	}
+
+	if err := r.Status().Update(ctx, experiment); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return result, nil
+}
+
+// nextRunTime returns the most recent tick of the schedule that has not been
+// run yet and whether such a tick is due. Ticks missed while the controller
+// was down collapse into a single run.
+func nextRunTime(sched cron.Schedule, experiment *chaosv1alpha1.Havock8sExperiment, now time.Time) (time.Time, bool) {
+	// Immediate schedules start their first run right away
+	if experiment.Spec.Schedule.Immediate && experiment.Status.LastScheduleTime == nil {
+		return now, true
+	}
+
+	earliest := experiment.CreationTimestamp.Time
+	if experiment.Status.LastScheduleTime != nil {
+		earliest = experiment.Status.LastScheduleTime.Time
+	} else if experiment.Status.StartTime != nil {
+		earliest = experiment.Status.StartTime.Time
+	}
+
+	var due time.Time
+	for t := sched.Next(earliest); !t.After(now); t = sched.Next(t) {
+		due = t
+	}
+	return due, !due.IsZero()
+}
+
+// startRun creates a run of a scheduled experiment honouring its concurrency
+// policy. It returns nil when the policy holds the run back.
+func (r *Havock8sExperimentReconciler) startRun(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, scheduledTime time.Time, active []chaosv1alpha1.Havock8sExperiment, logger logr.Logger) (*chaosv1alpha1.Havock8sExperiment, error) {
+	switch experiment.Spec.Schedule.ConcurrencyPolicy {
+	case chaosv1alpha1.ConcurrencyPolicyAllow:
+		// Runs may overlap
+	case chaosv1alpha1.ConcurrencyPolicyReplace:
+		for i := range active {
+			logger.Info("Replacing active experiment run", "run", active[i].Name)
+			if err := r.Delete(ctx, &active[i]); client.IgnoreNotFound(err) != nil {
+				return nil, err
+			}
+		}
+	default:
+		if len(active) > 0 {
+			logger.Info("Previous experiment run is still active, holding back the next run", "active", len(active))
+			return nil, nil
+		}
+	}
+
+	run := newRun(experiment, scheduledTime)
+	if err := controllerutil.SetControllerReference(experiment, run, r.Scheme); err != nil {
+		return nil, err
+	}
+	if err := r.Create(ctx, run); err != nil {
+		// A previous attempt may have created the run without recording it
+		if !apierrors.IsAlreadyExists(err) {
+			return nil, err
+		}
+	}
+
+	logger.Info("Started experiment run", "run", run.Name, "scheduledTime", scheduledTime)
+	r.event(experiment, corev1.EventTypeNormal, "RunStarted", "Started run %s scheduled for %s", run.Name, scheduledTime.Format(time.RFC3339))
+	return run, nil
+}
+
+// newRun builds a run of a scheduled experiment. The run is a plain
+// experiment with the schedule's spec and no schedule of its own.
+func newRun(experiment *chaosv1alpha1.Havock8sExperiment, scheduledTime time.Time) *chaosv1alpha1.Havock8sExperiment {
+	labels := make(map[string]string, len(experiment.Labels)+1)
+	for k, v := range experiment.Labels {
+		labels[k] = v
+	}
+	labels[scheduleLabel] = experiment.Name
+
+	run := &chaosv1alpha1.Havock8sExperiment{
+		ObjectMeta: metav1.ObjectMeta{
+			// Named after the scheduled minute so each tick creates one run
+			Name:      fmt.Sprintf("%s-%d", experiment.Name, scheduledTime.Unix()/60),
+			Namespace: experiment.Namespace,
+			Labels:    labels,
+		},
+		Spec: *experiment.Spec.DeepCopy(),
+	}
+	run.Spec.Schedule = nil
+	return run
+}
+
+// listRuns returns the runs of a scheduled experiment, oldest first
+func (r *Havock8sExperimentReconciler) listRuns(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) ([]chaosv1alpha1.Havock8sExperiment, error) {
+	runList := &chaosv1alpha1.Havock8sExperimentList{}
+	if err := r.List(ctx, runList,
+		client.InNamespace(experiment.Namespace),
+		client.MatchingLabels{scheduleLabel: experiment.Name},
+	); err != nil {
+		return nil, fmt.Errorf("failed to list runs of experiment %s/%s: %w", experiment.Namespace, experiment.Name, err)
+	}
+
+	var runs []chaosv1alpha1.Havock8sExperiment
+	for _, run := range runList.Items {
+		if metav1.IsControlledBy(&run, experiment) && run.DeletionTimestamp.IsZero() {
+			runs = append(runs, run)
+		}
+	}
+
+	// Run names carry the scheduled minute
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].Name < runs[j].Name
+	})
+	return runs, nil
+}
@@ -0,0 +1,262 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newScheduledExperiment(policy string, historyLimit *int32) *chaosv1alpha1.Havock8sExperiment {
+	return &chaosv1alpha1.Havock8sExperiment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nightly",
+			Namespace: "default",
+			Labels: map[string]string{
+				"team": "storage",
+			},
+		},
+		Spec: chaosv1alpha1.Havock8sExperimentSpec{
+			Target: chaosv1alpha1.TargetSpec{
+				Name:       "test-pod",
+				Namespace:  "default",
+				TargetType: "Pod",
+			},
+			ChaosType: "PodFailure",
+			Duration:  "1m",
+			Schedule: &chaosv1alpha1.ScheduleSpec{
+				Cron:              "*/5 * * * *",
+				ConcurrencyPolicy: policy,
+				HistoryLimit:      historyLimit,
+			},
+		},
+	}
+}
+
+// createRun adds a run of the scheduled experiment in the given phase
+func createRun(t *testing.T, c client.Client, r *Havock8sExperimentReconciler, parent *chaosv1alpha1.Havock8sExperiment, scheduledTime time.Time, phase string) {
+	run := newRun(parent, scheduledTime)
+	if err := controllerutil.SetControllerReference(parent, run, r.Scheme); err != nil {
+		t.Fatalf("Failed to set owner: %v", err)
+	}
+	if err := c.Create(context.Background(), run); err != nil {
+		t.Fatalf("Failed to create run: %v", err)
+	}
+	run.Status.Phase = phase
+	if err := c.Status().Update(context.Background(), run); err != nil {
+		t.Fatalf("Failed to update run status: %v", err)
+	}
+}
+
+func listRunNames(t *testing.T, r *Havock8sExperimentReconciler, parent *chaosv1alpha1.Havock8sExperiment) []string {
+	runs, err := r.listRuns(context.Background(), parent)
+	if err != nil {
+		t.Fatalf("Failed to list runs: %v", err)
+	}
+	var names []string
+	for _, run := range runs {
+		names = append(names, run.Name)
+	}
+	return names
+}
+
+func runName(scheduledTime time.Time) string {
+	return fmt.Sprintf("nightly-%d", scheduledTime.Unix()/60)
+}
+
+func TestHavock8sExperimentReconciler_SpawnsScheduledRuns(t *testing.T) {
+	scheme := setupScheme()
+	fakeClient := setupFakeClient(scheme)
+	ctx := context.Background()
+
+	now := time.Date(2026, 1, 1, 0, 1, 0, 0, time.UTC)
+	reconciler := &Havock8sExperimentReconciler{
+		Client:  fakeClient,
+		Scheme:  scheme,
+		nowFunc: func() time.Time { return now },
+	}
+
+	experiment := newScheduledExperiment("", nil)
+	if err := fakeClient.Create(ctx, experiment); err != nil {
+		t.Fatalf("Failed to create experiment: %v", err)
+	}
+	req := reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: experiment.Name, Namespace: experiment.Namespace},
+	}
+
+	// Initialize, then wait for the first tick
+	for i := 0; i < 2; i++ {
+		if _, err := reconciler.Reconcile(ctx, req); err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+	}
+	if err := fakeClient.Get(ctx, req.NamespacedName, experiment); err != nil {
+		t.Fatalf("Failed to get experiment: %v", err)
+	}
+	if experiment.Status.Phase != "Scheduled" {
+		t.Fatalf("Expected phase Scheduled, got %s", experiment.Status.Phase)
+	}
+	firstTick := time.Date(2026, 1, 1, 0, 5, 0, 0, time.UTC)
+	if experiment.Status.NextScheduleTime == nil || !experiment.Status.NextScheduleTime.Time.Equal(firstTick) {
+		t.Errorf("Expected next schedule time %v, got %v", firstTick, experiment.Status.NextScheduleTime)
+	}
+	if names := listRunNames(t, reconciler, experiment); len(names) != 0 {
+		t.Fatalf("Expected no runs before the first tick, got %v", names)
+	}
+
+	// Pass the first tick
+	now = firstTick.Add(30 * time.Second)
+	result, err := reconciler.Reconcile(ctx, req)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if want := 4*time.Minute + 30*time.Second; result.RequeueAfter != want {
+		t.Errorf("Expected requeue after %v, got %v", want, result.RequeueAfter)
+	}
+
+	if err := fakeClient.Get(ctx, req.NamespacedName, experiment); err != nil {
+		t.Fatalf("Failed to get experiment: %v", err)
+	}
+	if experiment.Status.LastScheduleTime == nil || !experiment.Status.LastScheduleTime.Time.Equal(firstTick) {
+		t.Errorf("Expected last schedule time %v, got %v", firstTick, experiment.Status.LastScheduleTime)
+	}
+	if len(experiment.Status.ActiveRuns) != 1 || experiment.Status.ActiveRuns[0] != runName(firstTick) {
+		t.Errorf("Expected active run %s, got %v", runName(firstTick), experiment.Status.ActiveRuns)
+	}
+
+	run := &chaosv1alpha1.Havock8sExperiment{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Name: runName(firstTick), Namespace: "default"}, run); err != nil {
+		t.Fatalf("Failed to get run: %v", err)
+	}
+	if run.Spec.Schedule != nil {
+		t.Error("Expected run without schedule")
+	}
+	if run.Spec.ChaosType != "PodFailure" || run.Labels["team"] != "storage" || run.Labels[scheduleLabel] != "nightly" {
+		t.Errorf("Run does not match scheduled experiment: %+v", run.ObjectMeta)
+	}
+	if !metav1.IsControlledBy(run, experiment) {
+		t.Error("Expected run to be controlled by the scheduled experiment")
+	}
+}
+
+func TestHavock8sExperimentReconciler_ScheduleConcurrencyPolicy(t *testing.T) {
+	previousTick := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tick := time.Date(2026, 1, 1, 0, 5, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		policy    string
+		wantRuns  []string
+		wantRetry bool
+	}{
+		{
+			name:      "forbid holds back the run",
+			policy:    chaosv1alpha1.ConcurrencyPolicyForbid,
+			wantRuns:  []string{runName(previousTick)},
+			wantRetry: true,
+		},
+		{
+			name:     "allow overlaps runs",
+			policy:   chaosv1alpha1.ConcurrencyPolicyAllow,
+			wantRuns: []string{runName(previousTick), runName(tick)},
+		},
+		{
+			name:     "replace stops the active run",
+			policy:   chaosv1alpha1.ConcurrencyPolicyReplace,
+			wantRuns: []string{runName(tick)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme()
+			fakeClient := setupFakeClient(scheme)
+			ctx := context.Background()
+
+			reconciler := &Havock8sExperimentReconciler{
+				Client:  fakeClient,
+				Scheme:  scheme,
+				nowFunc: func() time.Time { return tick.Add(time.Second) },
+			}
+
+			experiment := newScheduledExperiment(tt.policy, nil)
+			if err := fakeClient.Create(ctx, experiment); err != nil {
+				t.Fatalf("Failed to create experiment: %v", err)
+			}
+			experiment.Status.Phase = "Scheduled"
+			experiment.Status.StartTime = &metav1.Time{Time: previousTick.Add(-time.Minute)}
+			experiment.Status.LastScheduleTime = &metav1.Time{Time: previousTick}
+			if err := fakeClient.Status().Update(ctx, experiment); err != nil {
+				t.Fatalf("Failed to update experiment status: %v", err)
+			}
+			createRun(t, fakeClient, reconciler, experiment, previousTick, "Running")
+
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: experiment.Name, Namespace: experiment.Namespace},
+			})
+			if err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+
+			names := listRunNames(t, reconciler, experiment)
+			if fmt.Sprint(names) != fmt.Sprint(tt.wantRuns) {
+				t.Errorf("Expected runs %v, got %v", tt.wantRuns, names)
+			}
+			if tt.wantRetry != (result.RequeueAfter == scheduleRetryInterval) {
+				t.Errorf("Unexpected requeue after %v", result.RequeueAfter)
+			}
+
+			if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(experiment), experiment); err != nil {
+				t.Fatalf("Failed to get experiment: %v", err)
+			}
+			if fmt.Sprint(experiment.Status.ActiveRuns) != fmt.Sprint(tt.wantRuns) {
+				t.Errorf("Expected active runs %v, got %v", tt.wantRuns, experiment.Status.ActiveRuns)
+			}
+		})
+	}
+}
+
+func TestHavock8sExperimentReconciler_ScheduleHistoryLimit(t *testing.T) {
+	scheme := setupScheme()
+	fakeClient := setupFakeClient(scheme)
+	ctx := context.Background()
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	reconciler := &Havock8sExperimentReconciler{
+		Client:  fakeClient,
+		Scheme:  scheme,
+		nowFunc: func() time.Time { return start.Add(12 * time.Minute) },
+	}
+
+	historyLimit := int32(1)
+	experiment := newScheduledExperiment(chaosv1alpha1.ConcurrencyPolicyForbid, &historyLimit)
+	if err := fakeClient.Create(ctx, experiment); err != nil {
+		t.Fatalf("Failed to create experiment: %v", err)
+	}
+	experiment.Status.Phase = "Scheduled"
+	experiment.Status.LastScheduleTime = &metav1.Time{Time: start.Add(10 * time.Minute)}
+	if err := fakeClient.Status().Update(ctx, experiment); err != nil {
+		t.Fatalf("Failed to update experiment status: %v", err)
+	}
+	createRun(t, fakeClient, reconciler, experiment, start, "Completed")
+	createRun(t, fakeClient, reconciler, experiment, start.Add(5*time.Minute), "Failed")
+	createRun(t, fakeClient, reconciler, experiment, start.Add(10*time.Minute), "Completed")
+
+	if _, err := reconciler.Reconcile(ctx, reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: experiment.Name, Namespace: experiment.Namespace},
+	}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	names := listRunNames(t, reconciler, experiment)
+	if want := []string{runName(start.Add(10 * time.Minute))}; fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Errorf("Expected runs %v, got %v", want, names)
+	}
+}
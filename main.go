@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -14,8 +16,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	chaosv1alpha2 "github.com/havock8s/havock8s/api/v1alpha2"
 	"github.com/havock8s/havock8s/controllers"
 	_ "github.com/havock8s/havock8s/pkg/chaos"
+	"github.com/havock8s/havock8s/pkg/metrics"
+	"github.com/havock8s/havock8s/pkg/tracing"
+	"github.com/havock8s/havock8s/pkg/utils"
+	"github.com/havock8s/havock8s/webhooks"
 )
 
 var (
@@ -26,6 +33,7 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(chaosv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(chaosv1alpha2.AddToScheme(scheme))
 }
 
 func main() {
@@ -33,6 +41,11 @@ func main() {
 	var enableLeaderElection bool
 	var probeAddr string
 	var enableTracing bool
+	var otlpEndpoint string
+	var otlpInsecure bool
+	var prometheusURL string
+	var enableWebhooks bool
+	var maxConcurrentReconciles int
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -40,6 +53,16 @@ func main() {
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.BoolVar(&enableTracing, "enable-tracing", false, "Enable OpenTelemetry tracing")
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", "",
+		"The host:port of the OTLP gRPC collector traces are exported to. "+
+			"Defaults to OTEL_EXPORTER_OTLP_ENDPOINT or localhost:4317.")
+	flag.BoolVar(&otlpInsecure, "otlp-insecure", false, "Export traces to the OTLP collector without TLS.")
+	flag.StringVar(&prometheusURL, "prometheus-url", "", "The Prometheus server metric pause conditions are evaluated against.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1,
+		"The number of experiments reconciled in parallel.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Serve the defaulting and validating admission webhooks. "+
+			"Requires a serving certificate in /tmp/k8s-webhook-server/serving-certs.")
 
 	opts := zap.Options{
 		Development: true,
@@ -68,14 +91,46 @@ func main() {
 	}
 
 	// Set up controller
+	podExecutor, err := utils.NewPodExecutor(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create pod executor")
+		os.Exit(1)
+	}
+
 	if err = (&controllers.Havock8sExperimentReconciler{
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		PrometheusURL:           prometheusURL,
+		PodExecutor:             podExecutor,
+		Recorder:                mgr.GetEventRecorderFor("havock8s-controller"),
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "havock8sExperiment")
+		os.Exit(1)
+	}
+
+	if err = (&controllers.ChaosPluginReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "havock8sExperiment")
+		setupLog.Error(err, "unable to create controller", "controller", "chaosPlugin")
 		os.Exit(1)
 	}
 
+	// Report experiments on the metrics endpoint
+	if err = metrics.RegisterExperimentCollector(mgr.GetClient()); err != nil {
+		setupLog.Error(err, "unable to register experiment metrics")
+		os.Exit(1)
+	}
+
+	// Set up admission webhooks
+	if enableWebhooks {
+		if err = webhooks.SetupHavock8sExperimentWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "havock8sExperiment")
+			os.Exit(1)
+		}
+	}
+
 	// Set up health and readiness checks
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
@@ -87,13 +142,29 @@ func main() {
 	}
 
 	// Set up OpenTelemetry tracing if enabled
+	shutdownTracing := func(context.Context) error { return nil }
 	if enableTracing {
-		setupLog.Info("Setting up OpenTelemetry tracing")
-		// In a real implementation, set up OpenTelemetry tracers here
+		setupLog.Info("Setting up OpenTelemetry tracing", "endpoint", otlpEndpoint)
+		shutdownTracing, err = tracing.Setup(context.Background(), tracing.Options{
+			Endpoint: otlpEndpoint,
+			Insecure: otlpInsecure,
+		})
+		if err != nil {
+			setupLog.Error(err, "unable to set up tracing")
+			os.Exit(1)
+		}
 	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	err = mgr.Start(ctrl.SetupSignalHandler())
+
+	// Flush the spans of the last reconciles before exiting
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		setupLog.Error(err, "problem shutting down tracing")
+	}
+	if err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
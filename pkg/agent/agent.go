@@ -0,0 +1,175 @@
+// Package agent implements the havock8s node agent. The agent runs on every
+// node as a DaemonSet, watches the pods scheduled on its node and applies the
+// chaos requested through havock8s annotations inside the pods' namespaces.
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// Fault is a kind of chaos the agent knows how to apply to a pod
+type Fault interface {
+	// Name identifies the fault in logs
+	Name() string
+
+	// AckAnnotation is the pod annotation the agent uses to acknowledge the fault
+	AckAnnotation() string
+
+	// Requested reports whether the pod's annotations ask for this fault
+	Requested(pod *corev1.Pod) bool
+
+	// Apply injects the fault into the pod. The returned state is stored on the
+	// pod and handed back to Remove, since the request annotations are gone by
+	// the time the fault is removed.
+	Apply(ctx context.Context, target Target, pod *corev1.Pod) (string, error)
+
+	// Remove reverts the fault
+	Remove(ctx context.Context, target Target, pod *corev1.Pod, state string) error
+}
+
+// StateAnnotation returns the annotation holding the state of an applied fault
+func StateAnnotation(fault Fault) string {
+	return fault.AckAnnotation() + "-state"
+}
+
+// PodReconciler applies and removes faults on the pods running on one node
+type PodReconciler struct {
+	client.Client
+
+	// NodeName is the node the agent runs on
+	NodeName string
+
+	// ProcRoot is where the host's /proc is mounted
+	ProcRoot string
+
+	// Exec runs commands on the node
+	Exec Executor
+
+	// Faults lists the faults the agent handles
+	Faults []Fault
+}
+
+// CacheOptions restricts the manager's pod cache to the given node
+func CacheOptions(nodeName string) cache.Options {
+	return cache.Options{
+		ByObject: map[client.Object]cache.ByObject{
+			&corev1.Pod{}: {
+				Field: fields.OneTermEqualSelector("spec.nodeName", nodeName),
+			},
+		},
+	}
+}
+
+// SetupWithManager sets up the reconciler with the Manager
+func (r *PodReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("havock8s-agent").
+		For(&corev1.Pod{}, builder.WithPredicates(predicate.AnnotationChangedPredicate{})).
+		Complete(r)
+}
+
+// Reconcile brings the faults applied to a pod in line with its annotations
+func (r *PodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	pod := &corev1.Pod{}
+	if err := r.Get(ctx, req.NamespacedName, pod); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// Faults die with the pod's namespaces, nothing to do for terminating pods
+	if pod.Spec.NodeName != r.NodeName || !pod.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	for _, fault := range r.Faults {
+		requested := fault.Requested(pod)
+		ack, acked := pod.Annotations[fault.AckAnnotation()]
+
+		switch {
+		case requested && !acked:
+			logger.Info("Applying fault", "fault", fault.Name(), "pod", pod.Name)
+			ack = AckApplied
+			state, err := r.apply(ctx, fault, pod)
+			if err != nil {
+				logger.Error(err, "Failed to apply fault", "fault", fault.Name(), "pod", pod.Name)
+				ack = AckFailedPrefix + err.Error()
+			}
+			if err := r.setAck(ctx, pod, fault, &ack, state); err != nil {
+				return ctrl.Result{}, err
+			}
+
+		case !requested && acked:
+			logger.Info("Removing fault", "fault", fault.Name(), "pod", pod.Name)
+			if err := r.remove(ctx, fault, pod); err != nil {
+				// Keep the acknowledgement so removal is retried
+				return ctrl.Result{}, fmt.Errorf("failed to remove %s from pod %s/%s: %w", fault.Name(), pod.Namespace, pod.Name, err)
+			}
+			if err := r.setAck(ctx, pod, fault, nil, ""); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// apply injects a fault into the pod's namespaces
+func (r *PodReconciler) apply(ctx context.Context, fault Fault, pod *corev1.Pod) (string, error) {
+	target, err := r.target(pod)
+	if err != nil {
+		return "", err
+	}
+	return fault.Apply(ctx, target, pod)
+}
+
+// remove reverts a fault from the pod's namespaces
+func (r *PodReconciler) remove(ctx context.Context, fault Fault, pod *corev1.Pod) error {
+	target, err := r.target(pod)
+	if err != nil {
+		return err
+	}
+	return fault.Remove(ctx, target, pod, pod.Annotations[StateAnnotation(fault)])
+}
+
+// target locates the pod's processes on the node
+func (r *PodReconciler) target(pod *corev1.Pod) (Target, error) {
+	pid, err := FindPodPID(r.ProcRoot, string(pod.UID))
+	if err != nil {
+		return Target{}, err
+	}
+	return Target{PID: pid, PodUID: string(pod.UID), ProcRoot: r.ProcRoot, Exec: r.Exec}, nil
+}
+
+// setAck sets or, when value is nil, removes the acknowledgement and state
+// annotations of a fault
+func (r *PodReconciler) setAck(ctx context.Context, pod *corev1.Pod, fault Fault, value *string, state string) error {
+	patch := client.MergeFrom(pod.DeepCopy())
+	if value == nil {
+		delete(pod.Annotations, fault.AckAnnotation())
+		delete(pod.Annotations, StateAnnotation(fault))
+	} else {
+		if pod.Annotations == nil {
+			pod.Annotations = make(map[string]string)
+		}
+		pod.Annotations[fault.AckAnnotation()] = *value
+		if state != "" {
+			pod.Annotations[StateAnnotation(fault)] = state
+		}
+	}
+
+	if err := r.Patch(ctx, pod, patch); err != nil {
+		return fmt.Errorf("failed to update pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeExecutor records commands instead of running them
+type fakeExecutor struct {
+	commands []string
+	outputs  map[string]string
+	err      error
+}
+
+func (e *fakeExecutor) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	command := name + " " + strings.Join(args, " ")
+	e.commands = append(e.commands, command)
+	// Deleting a missing qdisc is expected when nothing was applied yet
+	if strings.Contains(command, "qdisc del") {
+		return []byte("Error: Cannot delete qdisc with handle of zero."), errors.New("exit status 2")
+	}
+	if out, ok := e.outputs[name]; ok {
+		return []byte(out), e.err
+	}
+	return nil, e.err
+}
+
+func setupProcRoot(t *testing.T, pid, podUID string) string {
+	procRoot := t.TempDir()
+	dir := filepath.Join(procRoot, pid)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("Failed to create proc dir: %v", err)
+	}
+	cgroup := "0::/kubepods/pod" + podUID + "/container\n"
+	if err := os.WriteFile(filepath.Join(dir, "cgroup"), []byte(cgroup), 0o600); err != nil {
+		t.Fatalf("Failed to write cgroup file: %v", err)
+	}
+	return procRoot
+}
+
+func TestPodReconciler_Reconcile(t *testing.T) {
+	tests := []struct {
+		name         string
+		annotations  map[string]string
+		nodeName     string
+		execErr      error
+		wantAck      string
+		wantCommands []string
+	}{
+		{
+			name: "applies requested latency",
+			annotations: map[string]string{
+				NetworkLatencyAnnotation:      "true",
+				NetworkLatencyValueAnnotation: "100ms",
+			},
+			nodeName: "node-1",
+			wantAck:  AckApplied,
+			wantCommands: []string{
+				"nsenter --target 42 --net -- tc qdisc del dev eth0 root",
+				"nsenter --target 42 --net -- tc qdisc replace dev eth0 root handle 1: netem delay 100ms",
+			},
+		},
+		{
+			name: "reports failure",
+			annotations: map[string]string{
+				NetworkLatencyAnnotation:      "true",
+				NetworkLatencyValueAnnotation: "100ms",
+			},
+			nodeName: "node-1",
+			execErr:  errors.New("tc not found"),
+			wantAck:  AckFailedPrefix + "tc not found",
+		},
+		{
+			name: "removes latency when request is gone",
+			annotations: map[string]string{
+				NetworkLatencyAckAnnotation: AckApplied,
+			},
+			nodeName: "node-1",
+			wantAck:  "",
+			wantCommands: []string{
+				"nsenter --target 42 --net -- tc qdisc del dev eth0 root",
+			},
+		},
+		{
+			name: "already applied",
+			annotations: map[string]string{
+				NetworkLatencyAnnotation:      "true",
+				NetworkLatencyValueAnnotation: "100ms",
+				NetworkLatencyAckAnnotation:   AckApplied,
+			},
+			nodeName: "node-1",
+			wantAck:  AckApplied,
+		},
+		{
+			name: "ignores pods on other nodes",
+			annotations: map[string]string{
+				NetworkLatencyAnnotation:      "true",
+				NetworkLatencyValueAnnotation: "100ms",
+			},
+			nodeName: "node-2",
+			wantAck:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-pod",
+					Namespace:   "default",
+					UID:         types.UID("1234-abcd"),
+					Annotations: tt.annotations,
+				},
+				Spec: corev1.PodSpec{
+					NodeName: tt.nodeName,
+				},
+			}
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(pod).
+				Build()
+
+			executor := &fakeExecutor{err: tt.execErr}
+			reconciler := &PodReconciler{
+				Client:   fakeClient,
+				NodeName: "node-1",
+				ProcRoot: setupProcRoot(t, "42", "1234-abcd"),
+				Exec:     executor,
+				Faults:   []Fault{&NetworkLatencyFault{Device: "eth0"}},
+			}
+
+			_, err := reconciler.Reconcile(context.Background(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Namespace: "default", Name: "test-pod"},
+			})
+			if err != nil {
+				t.Fatalf("PodReconciler.Reconcile() error = %v", err)
+			}
+
+			updatedPod := &corev1.Pod{}
+			if err := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(pod), updatedPod); err != nil {
+				t.Fatalf("Failed to get updated pod: %v", err)
+			}
+
+			if got := updatedPod.Annotations[NetworkLatencyAckAnnotation]; !strings.HasPrefix(got, tt.wantAck) || (tt.wantAck == "" && got != "") {
+				t.Errorf("Ack annotation = %q, want %q", got, tt.wantAck)
+			}
+
+			if tt.wantCommands != nil && strings.Join(executor.commands, "\n") != strings.Join(tt.wantCommands, "\n") {
+				t.Errorf("Commands = %v, want %v", executor.commands, tt.wantCommands)
+			}
+		})
+	}
+}
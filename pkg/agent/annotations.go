@@ -0,0 +1,264 @@
+package agent
+
+// Annotations written by the havock8s controller to request chaos from the
+// node agent, and by the agent to acknowledge it
+const (
+	// NetworkLatencyAnnotation requests netem network chaos on a pod: latency,
+	// loss, duplication, corruption, reordering or a rate limit
+	NetworkLatencyAnnotation = "havock8s.io/network-latency"
+
+	// NetworkLatencyValueAnnotation holds the latency to add
+	NetworkLatencyValueAnnotation = "havock8s.io/network-latency-value"
+
+	// NetworkJitterValueAnnotation holds the latency jitter
+	NetworkJitterValueAnnotation = "havock8s.io/network-jitter-value"
+
+	// NetworkCorrelationValueAnnotation holds the latency correlation percentage
+	NetworkCorrelationValueAnnotation = "havock8s.io/network-correlation-value"
+
+	// NetworkLossValueAnnotation holds the percentage of packets to drop
+	NetworkLossValueAnnotation = "havock8s.io/network-loss-value"
+
+	// NetworkDuplicateValueAnnotation holds the percentage of packets to duplicate
+	NetworkDuplicateValueAnnotation = "havock8s.io/network-duplicate-value"
+
+	// NetworkCorruptValueAnnotation holds the percentage of packets to corrupt
+	NetworkCorruptValueAnnotation = "havock8s.io/network-corrupt-value"
+
+	// NetworkReorderValueAnnotation holds the percentage of packets sent
+	// ahead of the delayed ones
+	NetworkReorderValueAnnotation = "havock8s.io/network-reorder-value"
+
+	// NetworkRateValueAnnotation holds the bandwidth limit in tc units, e.g. 1mbit
+	NetworkRateValueAnnotation = "havock8s.io/network-rate-value"
+
+	// NetworkPortsAnnotation restricts network chaos to a comma separated list of ports
+	NetworkPortsAnnotation = "havock8s.io/network-ports"
+
+	// NetworkLatencyAckAnnotation is set by the agent once the netem chaos is applied
+	NetworkLatencyAckAnnotation = "havock8s.io/network-latency-ack"
+
+	// NetworkPartitionAnnotation requests a network partition on a pod
+	NetworkPartitionAnnotation = "havock8s.io/network-partition"
+
+	// NetworkPartitionDirectionAnnotation holds the partitioned direction:
+	// ingress, egress or both
+	NetworkPartitionDirectionAnnotation = "havock8s.io/network-partition-direction"
+
+	// NetworkPartitionCIDRsAnnotation holds a comma separated list of the CIDRs
+	// the pod is cut off from. The pod is cut off from everything when empty.
+	NetworkPartitionCIDRsAnnotation = "havock8s.io/network-partition-cidrs"
+
+	// NetworkPartitionAckAnnotation is set by the agent once the partition is applied
+	NetworkPartitionAckAnnotation = "havock8s.io/network-partition-ack"
+
+	// DiskFailureAnnotation requests a disk fault on a pod
+	DiskFailureAnnotation = "havock8s.io/disk-failure"
+
+	// DiskFailureMountAnnotation holds the path whose volume should fail
+	DiskFailureMountAnnotation = "havock8s.io/disk-failure-mount"
+
+	// DiskFailureModeAnnotation holds the disk failure mode
+	DiskFailureModeAnnotation = "havock8s.io/disk-failure-mode"
+
+	// DiskFailureContainerAnnotation optionally names the container mounting the path
+	DiskFailureContainerAnnotation = "havock8s.io/disk-failure-container"
+
+	// DiskFailurePercentAnnotation holds the I/O error rate or the fill target, in percent
+	DiskFailurePercentAnnotation = "havock8s.io/disk-failure-percent"
+
+	// DiskFailureWriteIOPSAnnotation holds the write IOPS cap of write-throttle mode
+	DiskFailureWriteIOPSAnnotation = "havock8s.io/disk-failure-write-iops"
+
+	// DiskFailureAckAnnotation is set by the agent once the disk fault is applied
+	DiskFailureAckAnnotation = "havock8s.io/disk-failure-ack"
+
+	// VolumeFailureAnnotation requests a volume of a pod to fail
+	VolumeFailureAnnotation = "havock8s.io/volume-failure"
+
+	// VolumeFailureModeAnnotation holds the volume failure mode: readonly or unmount
+	VolumeFailureModeAnnotation = "havock8s.io/volume-failure-mode"
+
+	// VolumeFailureVolumeAnnotation names the pod volume that fails in every
+	// container mounting it
+	VolumeFailureVolumeAnnotation = "havock8s.io/volume-failure-volume"
+
+	// VolumeFailureAckAnnotation is set by the agent once the volume failure is applied
+	VolumeFailureAckAnnotation = "havock8s.io/volume-failure-ack"
+
+	// KillAnnotation requests a signal to be sent to processes of a container
+	KillAnnotation = "havock8s.io/kill"
+
+	// KillContainerAnnotation names the container whose processes are signalled
+	KillContainerAnnotation = "havock8s.io/kill-container"
+
+	// KillSignalAnnotation holds the signal to send, e.g. SIGKILL
+	KillSignalAnnotation = "havock8s.io/kill-signal"
+
+	// KillProcessAnnotation holds a regular expression matched against the
+	// command lines of the container's processes. The container's main process
+	// is signalled when empty.
+	KillProcessAnnotation = "havock8s.io/kill-process"
+
+	// KillRestartCountAnnotation records the container's restart count when
+	// the kill was requested, so later restarts can be attributed to the chaos
+	KillRestartCountAnnotation = "havock8s.io/kill-restart-count"
+
+	// KillAckAnnotation is set by the agent once the signal is sent
+	KillAckAnnotation = "havock8s.io/kill-ack"
+
+	// DataCorruptionAnnotation requests corruption of files on a pod's volume
+	DataCorruptionAnnotation = "havock8s.io/data-corruption"
+
+	// DataCorruptionModeAnnotation holds the corruption mode
+	DataCorruptionModeAnnotation = "havock8s.io/data-corruption-mode"
+
+	// DataCorruptionMountAnnotation holds the mount path of the corrupted volume
+	DataCorruptionMountAnnotation = "havock8s.io/data-corruption-mount"
+
+	// DataCorruptionFilesAnnotation holds a glob of the files to corrupt,
+	// relative to the mount path
+	DataCorruptionFilesAnnotation = "havock8s.io/data-corruption-files"
+
+	// DataCorruptionContainerAnnotation optionally names the container mounting the path
+	DataCorruptionContainerAnnotation = "havock8s.io/data-corruption-container"
+
+	// DataCorruptionPercentAnnotation holds the percentage of matching files to corrupt
+	DataCorruptionPercentAnnotation = "havock8s.io/data-corruption-percent"
+
+	// DataCorruptionBytesAnnotation holds the number of bytes flipped per file
+	DataCorruptionBytesAnnotation = "havock8s.io/data-corruption-bytes"
+
+	// DataCorruptionAckAnnotation is set by the agent once the files are corrupted
+	DataCorruptionAckAnnotation = "havock8s.io/data-corruption-ack"
+
+	// StateDelayAnnotation requests the protocol aware proxy in front of a
+	// pod's database port
+	StateDelayAnnotation = "havock8s.io/state-delay"
+
+	// StateDelayProtocolAnnotation holds the database wire protocol:
+	// postgres, mongodb or redis
+	StateDelayProtocolAnnotation = "havock8s.io/state-delay-protocol"
+
+	// StateDelayPortAnnotation holds the port the database listens on
+	StateDelayPortAnnotation = "havock8s.io/state-delay-port"
+
+	// StateDelayOperationsAnnotation holds a comma separated list of the
+	// selected operations, by category or command name
+	StateDelayOperationsAnnotation = "havock8s.io/state-delay-operations"
+
+	// StateDelayActionAnnotation holds the action applied to the selected
+	// operations: delay, drop or error
+	StateDelayActionAnnotation = "havock8s.io/state-delay-action"
+
+	// StateDelayDelayAnnotation holds how long delayed operations are held back
+	StateDelayDelayAnnotation = "havock8s.io/state-delay-delay"
+
+	// StateDelayPercentAnnotation holds the percentage of the selected
+	// operations the action applies to
+	StateDelayPercentAnnotation = "havock8s.io/state-delay-percent"
+
+	// StateDelayAckAnnotation is set by the agent once the proxy intercepts traffic
+	StateDelayAckAnnotation = "havock8s.io/state-delay-ack"
+
+	// ResourcePressureAnnotation requests stressors in a container's cgroup
+	ResourcePressureAnnotation = "havock8s.io/resource-pressure"
+
+	// ResourcePressureTypeAnnotation holds the stressed resource: cpu, memory or io
+	ResourcePressureTypeAnnotation = "havock8s.io/resource-pressure-type"
+
+	// ResourcePressureContainerAnnotation names the container whose cgroup is stressed
+	ResourcePressureContainerAnnotation = "havock8s.io/resource-pressure-container"
+
+	// ResourcePressureWorkersAnnotation holds the number of stressor processes
+	ResourcePressureWorkersAnnotation = "havock8s.io/resource-pressure-workers"
+
+	// ResourcePressureLoadAnnotation holds the CPU load of each cpu worker, in percent
+	ResourcePressureLoadAnnotation = "havock8s.io/resource-pressure-load"
+
+	// ResourcePressureBytesAnnotation holds the memory allocated by all memory workers
+	ResourcePressureBytesAnnotation = "havock8s.io/resource-pressure-bytes"
+
+	// ResourcePressurePathAnnotation holds the directory inside the container
+	// the io workers write to
+	ResourcePressurePathAnnotation = "havock8s.io/resource-pressure-path"
+
+	// ResourcePressureDeadlineAnnotation holds the RFC 3339 time the
+	// stressors stop at, even if the request is never removed
+	ResourcePressureDeadlineAnnotation = "havock8s.io/resource-pressure-deadline"
+
+	// ResourcePressureAckAnnotation is set by the agent once the stressors run
+	ResourcePressureAckAnnotation = "havock8s.io/resource-pressure-ack"
+)
+
+// Disk failure modes
+const (
+	// DiskFailureModeReadOnly remounts the path read-only
+	DiskFailureModeReadOnly = "readonly"
+
+	// DiskFailureModeEIO fails a percentage of block I/O with EIO
+	DiskFailureModeEIO = "eio"
+
+	// DiskFailureModeWriteThrottle caps the write IOPS of the container on the
+	// volume's device. It does not delay fsync itself, writes only wait for
+	// their turn under the cap.
+	DiskFailureModeWriteThrottle = "write-throttle"
+
+	// DiskFailureModeFill fills the volume up to a percentage of its capacity
+	DiskFailureModeFill = "fill"
+)
+
+// Volume failure modes
+const (
+	// VolumeFailureModeReadOnly remounts the volume read-only
+	VolumeFailureModeReadOnly = "readonly"
+
+	// VolumeFailureModeUnmount hides the volume behind an empty directory
+	VolumeFailureModeUnmount = "unmount"
+
+	// VolumeFailureModeDetach deletes the volume's VolumeAttachments. It is
+	// applied by the controller, not the node agent.
+	VolumeFailureModeDetach = "detach"
+)
+
+// Data corruption modes
+const (
+	// DataCorruptionModeByte flips bytes at random offsets
+	DataCorruptionModeByte = "byte"
+
+	// DataCorruptionModeTruncate cuts files to half their size
+	DataCorruptionModeTruncate = "truncate"
+)
+
+// Resource pressure types
+const (
+	// ResourceTypeCPU keeps CPUs busy
+	ResourceTypeCPU = "cpu"
+
+	// ResourceTypeMemory allocates and touches memory
+	ResourceTypeMemory = "memory"
+
+	// ResourceTypeIO writes and syncs files
+	ResourceTypeIO = "io"
+)
+
+// Network partition directions
+const (
+	// PartitionDirectionIngress drops traffic coming from the peers
+	PartitionDirectionIngress = "ingress"
+
+	// PartitionDirectionEgress drops traffic sent to the peers
+	PartitionDirectionEgress = "egress"
+
+	// PartitionDirectionBoth drops traffic in both directions
+	PartitionDirectionBoth = "both"
+)
+
+// Acknowledgement values written by the agent
+const (
+	// AckApplied means the agent applied the requested chaos
+	AckApplied = "applied"
+
+	// AckFailedPrefix prefixes the error message when the agent failed to apply chaos
+	AckFailedPrefix = "failed: "
+)
@@ -0,0 +1,105 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// containerResolve makes paths resolve below the directory they are opened
+// in, the way the container sees it, and refuses symlinks in any component,
+// so a workload can't point the agent at files of the node
+const containerResolve = unix.RESOLVE_IN_ROOT | unix.RESOLVE_NO_SYMLINKS | unix.RESOLVE_NO_MAGICLINKS
+
+// openContainerDir opens a directory of a container by resolving its path in
+// the container's root filesystem
+func openContainerDir(procRoot string, pid int, path string) (*os.File, error) {
+	root, err := os.Open(filepath.Join(procRoot, strconv.Itoa(pid), "root"))
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+	return openIn(root, path, unix.O_RDONLY|unix.O_DIRECTORY, 0)
+}
+
+// openIn opens a path below dir without leaving it or following symlinks
+func openIn(dir *os.File, path string, flags int, mode uint32) (*os.File, error) {
+	fd, err := unix.Openat2(int(dir.Fd()), path, &unix.OpenHow{
+		Flags:   uint64(flags | unix.O_CLOEXEC),
+		Mode:    uint64(mode),
+		Resolve: containerResolve,
+	})
+	name := filepath.Join(dir.Name(), path)
+	if errors.Is(err, unix.ELOOP) {
+		return nil, &os.PathError{Op: "open", Path: name, Err: fmt.Errorf("path passes through a symlink")}
+	}
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	return os.NewFile(uintptr(fd), name), nil
+}
+
+// globIn returns the paths below dir matching a relative pattern, in lexical
+// order. Symlinks are neither followed nor matched.
+func globIn(dir *os.File, pattern string) ([]string, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	matches := []string{"."}
+	for _, component := range strings.Split(filepath.Clean(pattern), string(filepath.Separator)) {
+		var next []string
+		for _, parent := range matches {
+			names, err := readDirIn(dir, parent)
+			if err != nil {
+				continue
+			}
+			for _, name := range names {
+				if ok, _ := filepath.Match(component, name); ok {
+					next = append(next, filepath.Join(parent, name))
+				}
+			}
+		}
+		matches = next
+	}
+	slices.Sort(matches)
+	return matches, nil
+}
+
+// readDirIn returns the names of the entries of a directory below dir that
+// aren't symlinks
+func readDirIn(dir *os.File, path string) ([]string, error) {
+	sub, err := openIn(dir, path, unix.O_RDONLY|unix.O_DIRECTORY, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer sub.Close()
+
+	entries, err := sub.ReadDir(-1)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.Type()&os.ModeSymlink == 0 {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// statIn returns the file info of a path below dir without following symlinks
+func statIn(dir *os.File, path string) (os.FileInfo, error) {
+	file, err := openIn(dir, path, unix.O_PATH, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return file.Stat()
+}
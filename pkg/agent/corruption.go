@@ -0,0 +1,445 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Caps keeping the recorded state small enough for a pod annotation
+const (
+	// MaxCorruptedFiles caps the number of files corrupted per pod
+	MaxCorruptedFiles = 100
+
+	// MaxCorruptedBytes caps the number of bytes flipped per file
+	MaxCorruptedBytes = 64
+)
+
+// corruptionBackupDir holds the truncated tails of files, relative to the
+// mount path
+const corruptionBackupDir = ".havock8s-corruption"
+
+// corruptedFile records how a file was corrupted, with the checksums of its
+// content before and after
+type corruptedFile struct {
+	Path      string  `json:"path"`
+	Size      int64   `json:"size"`
+	Original  string  `json:"originalSha256"`
+	Corrupted string  `json:"corruptedSha256"`
+	Offsets   []int64 `json:"offsets,omitempty"`
+	Bytes     []byte  `json:"bytes,omitempty"`
+	Backup    string  `json:"backup,omitempty"`
+}
+
+// dataCorruptionState records what the agent changed so it can be reverted
+type dataCorruptionState struct {
+	Mode      string          `json:"mode"`
+	MountPath string          `json:"mountPath"`
+	Container string          `json:"container"`
+	Files     []corruptedFile `json:"files"`
+}
+
+// DataCorruptionFault corrupts files on a pod's volume, either by flipping
+// bytes at random offsets or by truncating them, and restores them on
+// removal. Files the workload rewrote in the meantime are left alone and
+// reported, keeping their backup.
+type DataCorruptionFault struct {
+	// random returns a random int in [0, n), rand.IntN when nil
+	random func(n int) int
+}
+
+// Name returns the name of the fault
+func (f *DataCorruptionFault) Name() string {
+	return "data-corruption"
+}
+
+// AckAnnotation returns the annotation used to acknowledge the fault
+func (f *DataCorruptionFault) AckAnnotation() string {
+	return DataCorruptionAckAnnotation
+}
+
+// Requested reports whether the pod asks for data corruption
+func (f *DataCorruptionFault) Requested(pod *corev1.Pod) bool {
+	return pod.Annotations[DataCorruptionAnnotation] == "true"
+}
+
+// Apply corrupts the files. The returned state lists every corrupted file,
+// even when applying fails part way, so that Remove can restore them.
+func (f *DataCorruptionFault) Apply(ctx context.Context, target Target, pod *corev1.Pod) (string, error) {
+	state := dataCorruptionState{
+		Mode:      pod.Annotations[DataCorruptionModeAnnotation],
+		MountPath: pod.Annotations[DataCorruptionMountAnnotation],
+	}
+	if state.Mode != DataCorruptionModeByte && state.Mode != DataCorruptionModeTruncate {
+		return "", fmt.Errorf("unsupported data corruption mode: %s", state.Mode)
+	}
+	if state.MountPath == "" || !filepath.IsAbs(state.MountPath) {
+		return "", fmt.Errorf("annotation %s must be an absolute path", DataCorruptionMountAnnotation)
+	}
+	pattern := pod.Annotations[DataCorruptionFilesAnnotation]
+	if pattern == "" || !filepath.IsLocal(pattern) {
+		return "", fmt.Errorf("annotation %s must be a pattern relative to the mount path", DataCorruptionFilesAnnotation)
+	}
+	percent, err := parsePercentAnnotation(pod.Annotations[DataCorruptionPercentAnnotation])
+	if err != nil {
+		return "", err
+	}
+	flips := 1
+	if value := pod.Annotations[DataCorruptionBytesAnnotation]; value != "" {
+		flips, err = strconv.Atoi(value)
+		if err != nil || flips < 1 || flips > MaxCorruptedBytes {
+			return "", fmt.Errorf("invalid byte count %q", value)
+		}
+	}
+
+	container, err := mountingContainer(pod, pod.Annotations[DataCorruptionContainerAnnotation], state.MountPath)
+	if err != nil {
+		return "", err
+	}
+	state.Container = container
+	pid, err := containerPID(target, pod, container)
+	if err != nil {
+		return "", err
+	}
+
+	root, err := openContainerDir(target.ProcRoot, pid, state.MountPath)
+	if err != nil {
+		return "", err
+	}
+	defer root.Close()
+	files, err := f.pickFiles(root, pattern, percent)
+	if err != nil {
+		return "", err
+	}
+
+	for _, file := range files {
+		var corrupted *corruptedFile
+		if state.Mode == DataCorruptionModeByte {
+			corrupted, err = f.flipBytes(root, file, flips)
+		} else {
+			corrupted, err = truncateFile(root, file)
+		}
+		if corrupted != nil {
+			corrupted.Path = filepath.Join(state.MountPath, file)
+			state.Files = append(state.Files, *corrupted)
+		}
+		if err != nil {
+			return encodeCorruptionState(state), err
+		}
+	}
+
+	return encodeCorruptionState(state), nil
+}
+
+// Remove restores the files recorded in state that still hold the corrupted
+// content. Files that changed since are left alone and make it fail, so
+// removal is retried until they are dealt with.
+func (f *DataCorruptionFault) Remove(ctx context.Context, target Target, pod *corev1.Pod, state string) error {
+	if state == "" {
+		// Nothing was changed
+		return nil
+	}
+
+	var applied dataCorruptionState
+	if err := json.Unmarshal([]byte(state), &applied); err != nil {
+		return fmt.Errorf("invalid data corruption state: %w", err)
+	}
+	if len(applied.Files) == 0 {
+		return nil
+	}
+
+	pid, err := containerPID(target, pod, applied.Container)
+	if err != nil {
+		return err
+	}
+	root, err := openContainerDir(target.ProcRoot, pid, applied.MountPath)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	var changed []string
+	for _, file := range applied.Files {
+		rel, err := filepath.Rel(applied.MountPath, file.Path)
+		if err != nil {
+			return err
+		}
+		err = restoreFile(root, rel, file)
+		if errors.Is(err, errFileChanged) {
+			if file.Backup != "" {
+				changed = append(changed, fmt.Sprintf("%s (truncated tail kept in %s)", file.Path, filepath.Join(applied.MountPath, file.Backup)))
+			} else {
+				changed = append(changed, file.Path)
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(changed) > 0 {
+		return fmt.Errorf("not restoring files changed since they were corrupted: %s", strings.Join(changed, ", "))
+	}
+	// Only removed once every backup is gone
+	_ = unix.Unlinkat(int(root.Fd()), corruptionBackupDir, unix.AT_REMOVEDIR)
+	return nil
+}
+
+// pickFiles returns a random percentage of the regular files matching the
+// pattern below root, relative to root and sorted
+func (f *DataCorruptionFault) pickFiles(root *os.File, pattern string, percent int) ([]string, error) {
+	matches, err := globIn(root, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file pattern %q: %w", pattern, err)
+	}
+
+	var files []string
+	for _, rel := range matches {
+		if rel == corruptionBackupDir || strings.HasPrefix(rel, corruptionBackupDir+string(filepath.Separator)) {
+			continue
+		}
+		if info, err := statIn(root, rel); err == nil && info.Mode().IsRegular() {
+			files = append(files, rel)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no file matches %q", pattern)
+	}
+
+	count := (len(files)*percent + 99) / 100
+	count = min(count, MaxCorruptedFiles)
+
+	// Shuffle the front of the list, then keep it in a stable order
+	for idx := 0; idx < count; idx++ {
+		other := idx + f.randomInt(len(files)-idx)
+		files[idx], files[other] = files[other], files[idx]
+	}
+	files = files[:count]
+	slices.Sort(files)
+	return files, nil
+}
+
+// flipBytes inverts bytes at random distinct offsets of a file. Empty files
+// are left alone.
+func (f *DataCorruptionFault) flipBytes(root *os.File, rel string, flips int) (*corruptedFile, error) {
+	file, err := openIn(root, rel, unix.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	original, err := fileSHA256(file)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() == 0 {
+		return nil, nil
+	}
+
+	offsets := map[int64]bool{}
+	for len(offsets) < int(min(int64(flips), info.Size())) {
+		offsets[int64(f.randomInt(int(info.Size())))] = true
+	}
+	corrupted := &corruptedFile{Size: info.Size(), Original: original}
+	for offset := range offsets {
+		corrupted.Offsets = append(corrupted.Offsets, offset)
+	}
+	slices.Sort(corrupted.Offsets)
+
+	for _, offset := range corrupted.Offsets {
+		b := make([]byte, 1)
+		if _, err := file.ReadAt(b, offset); err != nil {
+			return nil, err
+		}
+		corrupted.Bytes = append(corrupted.Bytes, b[0])
+		if _, err := file.WriteAt([]byte{^b[0]}, offset); err != nil {
+			// Record the bytes flipped so far so they can be restored
+			corrupted.Offsets = corrupted.Offsets[:len(corrupted.Bytes)]
+			corrupted.Corrupted, _ = fileSHA256(file)
+			return corrupted, err
+		}
+	}
+	if err := file.Sync(); err != nil {
+		return corrupted, err
+	}
+
+	corrupted.Corrupted, err = fileSHA256(file)
+	return corrupted, err
+}
+
+// truncateFile cuts a file to half its size after saving the tail in the
+// backup directory. Files shorter than two bytes are left alone.
+func truncateFile(root *os.File, rel string) (*corruptedFile, error) {
+	file, err := openIn(root, rel, unix.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 2 {
+		return nil, nil
+	}
+
+	sum := sha256.Sum256([]byte(rel))
+	backup := filepath.Join(corruptionBackupDir, hex.EncodeToString(sum[:8]))
+	keep := len(data) / 2
+	if err := unix.Mkdirat(int(root.Fd()), corruptionBackupDir, 0o700); err != nil && !errors.Is(err, unix.EEXIST) {
+		return nil, &os.PathError{Op: "mkdir", Path: filepath.Join(root.Name(), corruptionBackupDir), Err: err}
+	}
+	if err := writeFileIn(root, backup, data[keep:]); err != nil {
+		return nil, err
+	}
+
+	corrupted := &corruptedFile{
+		Size:     int64(len(data)),
+		Original: sha256Hex(data),
+		Backup:   backup,
+	}
+	if err := file.Truncate(int64(keep)); err != nil {
+		return corrupted, err
+	}
+	corrupted.Corrupted = sha256Hex(data[:keep])
+	return corrupted, nil
+}
+
+// errFileChanged is returned by restoreFile for files that hold neither the
+// corrupted nor the original content
+var errFileChanged = errors.New("file changed since it was corrupted")
+
+// restoreFile puts back the original content of a corrupted file and removes
+// its backup. Files that changed since they were corrupted, or were deleted,
+// are left alone with their backup and reported with errFileChanged.
+func restoreFile(root *os.File, rel string, file corruptedFile) error {
+	handle, err := openIn(root, rel, unix.O_RDWR, 0)
+	if os.IsNotExist(err) {
+		return errFileChanged
+	}
+	if err != nil {
+		return err
+	}
+	defer handle.Close()
+
+	current, err := fileSHA256(handle)
+	if err != nil {
+		return err
+	}
+	switch current {
+	case file.Original:
+		// Restored by an earlier attempt
+	case file.Corrupted:
+		if file.Backup != "" {
+			tail, err := readFileIn(root, file.Backup)
+			if err != nil {
+				return err
+			}
+			if _, err := handle.WriteAt(tail, file.Size-int64(len(tail))); err != nil {
+				return err
+			}
+		}
+		for idx, offset := range file.Offsets {
+			if _, err := handle.WriteAt(file.Bytes[idx:idx+1], offset); err != nil {
+				return err
+			}
+		}
+		if err := handle.Sync(); err != nil {
+			return err
+		}
+		if restored, err := fileSHA256(handle); err != nil || restored != file.Original {
+			return fmt.Errorf("failed to restore %s: checksum mismatch", file.Path)
+		}
+	default:
+		return errFileChanged
+	}
+
+	if file.Backup != "" {
+		if err := removeIn(root, file.Backup); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// readFileIn reads a file below dir
+func readFileIn(dir *os.File, path string) ([]byte, error) {
+	file, err := openIn(dir, path, unix.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// writeFileIn creates or replaces a file below dir
+func writeFileIn(dir *os.File, path string, data []byte) error {
+	file, err := openIn(dir, path, unix.O_WRONLY|unix.O_CREAT|unix.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// removeIn removes a file below dir. Only the file's parent is resolved, so
+// a symlink in its place is removed rather than followed.
+func removeIn(dir *os.File, path string) error {
+	parent, err := openIn(dir, filepath.Dir(path), unix.O_RDONLY|unix.O_DIRECTORY, 0)
+	if err != nil {
+		return err
+	}
+	defer parent.Close()
+	if err := unix.Unlinkat(int(parent.Fd()), filepath.Base(path), 0); err != nil {
+		return &os.PathError{Op: "remove", Path: filepath.Join(dir.Name(), path), Err: err}
+	}
+	return nil
+}
+
+// randomInt returns a random int in [0, n)
+func (f *DataCorruptionFault) randomInt(n int) int {
+	if f.random != nil {
+		return f.random(n)
+	}
+	return rand.IntN(n)
+}
+
+// fileSHA256 returns the hex encoded SHA-256 checksum of a file's content
+func fileSHA256(file *os.File) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, io.NewSectionReader(file, 0, 1<<62)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// sha256Hex returns the hex encoded SHA-256 checksum of data
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// encodeCorruptionState serialises the data corruption state
+func encodeCorruptionState(state dataCorruptionState) string {
+	data, _ := json.Marshal(state)
+	return string(data)
+}
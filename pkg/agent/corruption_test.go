@@ -0,0 +1,329 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// setupCorruptionFiles creates the data files of the db container
+func setupCorruptionFiles(t *testing.T, procRoot string) map[string]string {
+	files := map[string]string{
+		"base/1/16384":  "page one of the heap",
+		"base/1/16385":  "page two of the heap",
+		"base/1/16386":  "",
+		"pg_wal/000001": "write ahead log segment",
+	}
+	for name, content := range files {
+		path := filepath.Join(procRoot, "40", "root", "var/lib/db", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	return files
+}
+
+func TestDataCorruptionFault(t *testing.T) {
+	tests := []struct {
+		name          string
+		annotations   map[string]string
+		wantFiles     []string
+		rewrite       string
+		wantErr       bool
+		wantRemoveErr bool
+	}{
+		{
+			name: "flips bytes of every matching file",
+			annotations: map[string]string{
+				DataCorruptionModeAnnotation:  "byte",
+				DataCorruptionFilesAnnotation: "base/1/*",
+				DataCorruptionBytesAnnotation: "3",
+			},
+			wantFiles: []string{"/var/lib/db/base/1/16384", "/var/lib/db/base/1/16385"},
+		},
+		{
+			name: "truncates a share of the files",
+			annotations: map[string]string{
+				DataCorruptionModeAnnotation:    "truncate",
+				DataCorruptionFilesAnnotation:   "base/1/*",
+				DataCorruptionPercentAnnotation: "30",
+			},
+			wantFiles: []string{"/var/lib/db/base/1/16385"},
+		},
+		{
+			name: "leaves files the workload rewrote alone and keeps their backup",
+			annotations: map[string]string{
+				DataCorruptionModeAnnotation:  "truncate",
+				DataCorruptionFilesAnnotation: "pg_wal/*",
+			},
+			wantFiles:     []string{"/var/lib/db/pg_wal/000001"},
+			rewrite:       "pg_wal/000001",
+			wantRemoveErr: true,
+		},
+		{
+			name: "no matching file",
+			annotations: map[string]string{
+				DataCorruptionModeAnnotation:  "byte",
+				DataCorruptionFilesAnnotation: "*.sst",
+			},
+			wantErr: true,
+		},
+		{
+			name: "pattern escaping the mount path",
+			annotations: map[string]string{
+				DataCorruptionModeAnnotation:  "byte",
+				DataCorruptionFilesAnnotation: "../*",
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported mode",
+			annotations: map[string]string{
+				DataCorruptionModeAnnotation:  "schema",
+				DataCorruptionFilesAnnotation: "*",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations := map[string]string{
+				DataCorruptionAnnotation:        "true",
+				DataCorruptionMountAnnotation:   "/var/lib/db",
+				DataCorruptionPercentAnnotation: "100",
+			}
+			for k, v := range tt.annotations {
+				annotations[k] = v
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-pod",
+					Namespace:   "default",
+					UID:         types.UID("1234-abcd"),
+					Annotations: annotations,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:         "db",
+						VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/var/lib/db"}},
+					}},
+				},
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{{Name: "db", ContainerID: "containerd://abc123"}},
+				},
+			}
+
+			procRoot := setupKillProcRoot(t)
+			originals := setupCorruptionFiles(t, procRoot)
+			root := filepath.Join(procRoot, "40", "root", "var/lib/db")
+			target := Target{PID: 40, PodUID: "1234-abcd", ProcRoot: procRoot, Exec: &fakeExecutor{}}
+			calls := 0
+			fault := &DataCorruptionFault{random: func(n int) int { calls++; return calls % n }}
+
+			state, err := fault.Apply(context.Background(), target, pod)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DataCorruptionFault.Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var applied dataCorruptionState
+			if err := json.Unmarshal([]byte(state), &applied); err != nil {
+				t.Fatalf("Invalid state %q: %v", state, err)
+			}
+			var got []string
+			for _, file := range applied.Files {
+				got = append(got, file.Path)
+				rel, _ := filepath.Rel("/var/lib/db", file.Path)
+				content, err := os.ReadFile(filepath.Join(root, rel))
+				if err != nil {
+					t.Fatalf("Failed to read %s: %v", file.Path, err)
+				}
+				if string(content) == originals[rel] || sha256Hex(content) != file.Corrupted {
+					t.Errorf("File %s = %q, want corrupted content", file.Path, content)
+				}
+				if sha256Hex([]byte(originals[rel])) != file.Original {
+					t.Errorf("Original checksum of %s = %s, want the checksum of %q", file.Path, file.Original, originals[rel])
+				}
+			}
+			if len(got) != len(tt.wantFiles) || (len(got) > 0 && got[0] != tt.wantFiles[0]) {
+				t.Errorf("Corrupted files = %v, want %v", got, tt.wantFiles)
+			}
+
+			if tt.rewrite != "" {
+				originals[tt.rewrite] = "repaired by the workload"
+				if err := os.WriteFile(filepath.Join(root, tt.rewrite), []byte(originals[tt.rewrite]), 0o600); err != nil {
+					t.Fatalf("Failed to rewrite file: %v", err)
+				}
+			}
+
+			err = fault.Remove(context.Background(), target, pod, state)
+			if (err != nil) != tt.wantRemoveErr {
+				t.Fatalf("DataCorruptionFault.Remove() error = %v, wantErr %v", err, tt.wantRemoveErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "/var/lib/db/"+tt.rewrite) {
+				t.Errorf("DataCorruptionFault.Remove() error = %v, want it to name %s", err, tt.rewrite)
+			}
+			for rel, want := range originals {
+				content, err := os.ReadFile(filepath.Join(root, rel))
+				if err != nil {
+					t.Fatalf("Failed to read %s: %v", rel, err)
+				}
+				if string(content) != want {
+					t.Errorf("File %s after removal = %q, want %q", rel, content, want)
+				}
+			}
+			backups, _ := os.ReadDir(filepath.Join(root, corruptionBackupDir))
+			if tt.wantRemoveErr && len(backups) != 1 {
+				t.Errorf("Kept %d backups, want the backup of the rewritten file", len(backups))
+			}
+			if !tt.wantRemoveErr {
+				if _, err := os.Stat(filepath.Join(root, corruptionBackupDir)); !os.IsNotExist(err) {
+					t.Errorf("Backup directory left behind, err = %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestDataCorruptionFault_Symlinks(t *testing.T) {
+	tests := []struct {
+		name string
+		// link creates a symlink below the container's root pointing at the
+		// node's file or directory
+		link         func(t *testing.T, containerRoot, nodeFile string)
+		files        string
+		swapAfter    string
+		wantApplyErr bool
+		wantFiles    int
+	}{
+		{
+			name: "skips symlinked files",
+			link: func(t *testing.T, containerRoot, nodeFile string) {
+				mustSymlink(t, nodeFile, filepath.Join(containerRoot, "var/lib/db/base/1/16387"))
+			},
+			files:     "base/1/*",
+			wantFiles: 2,
+		},
+		{
+			name: "skips symlinked directories",
+			link: func(t *testing.T, containerRoot, nodeFile string) {
+				mustSymlink(t, filepath.Dir(nodeFile), filepath.Join(containerRoot, "var/lib/db/base/2"))
+			},
+			files:     "base/*/*",
+			wantFiles: 2,
+		},
+		{
+			name: "refuses a mount path through a symlink",
+			link: func(t *testing.T, containerRoot, nodeFile string) {
+				if err := os.Rename(filepath.Join(containerRoot, "var/lib"), filepath.Join(containerRoot, "lib")); err != nil {
+					t.Fatalf("Failed to move dir: %v", err)
+				}
+				mustSymlink(t, "/lib", filepath.Join(containerRoot, "var/lib"))
+			},
+			files:        "base/1/*",
+			wantApplyErr: true,
+		},
+		{
+			name:      "doesn't restore through a symlink",
+			files:     "pg_wal/*",
+			swapAfter: "pg_wal/000001",
+			wantFiles: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-pod",
+					Namespace: "default",
+					UID:       types.UID("1234-abcd"),
+					Annotations: map[string]string{
+						DataCorruptionAnnotation:        "true",
+						DataCorruptionModeAnnotation:    "truncate",
+						DataCorruptionMountAnnotation:   "/var/lib/db",
+						DataCorruptionFilesAnnotation:   tt.files,
+						DataCorruptionPercentAnnotation: "100",
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:         "db",
+						VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/var/lib/db"}},
+					}},
+				},
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{{Name: "db", ContainerID: "containerd://abc123"}},
+				},
+			}
+
+			procRoot := setupKillProcRoot(t)
+			setupCorruptionFiles(t, procRoot)
+			containerRoot := filepath.Join(procRoot, "40", "root")
+			nodeFile := filepath.Join(t.TempDir(), "shadow")
+			const nodeContent = "root:*:19000:0:99999:7:::"
+			if err := os.WriteFile(nodeFile, []byte(nodeContent), 0o600); err != nil {
+				t.Fatalf("Failed to write node file: %v", err)
+			}
+			if tt.link != nil {
+				tt.link(t, containerRoot, nodeFile)
+			}
+			target := Target{PID: 40, PodUID: "1234-abcd", ProcRoot: procRoot, Exec: &fakeExecutor{}}
+			fault := &DataCorruptionFault{random: func(n int) int { return 0 }}
+
+			state, err := fault.Apply(context.Background(), target, pod)
+			if (err != nil) != tt.wantApplyErr {
+				t.Fatalf("DataCorruptionFault.Apply() error = %v, wantErr %v", err, tt.wantApplyErr)
+			}
+			if !tt.wantApplyErr {
+				var applied dataCorruptionState
+				if err := json.Unmarshal([]byte(state), &applied); err != nil {
+					t.Fatalf("Invalid state %q: %v", state, err)
+				}
+				if len(applied.Files) != tt.wantFiles {
+					t.Errorf("Corrupted %d files, want %d", len(applied.Files), tt.wantFiles)
+				}
+			}
+
+			if tt.swapAfter != "" {
+				path := filepath.Join(containerRoot, "var/lib/db", tt.swapAfter)
+				if err := os.Remove(path); err != nil {
+					t.Fatalf("Failed to remove file: %v", err)
+				}
+				mustSymlink(t, nodeFile, path)
+				if err := fault.Remove(context.Background(), target, pod, state); err == nil {
+					t.Error("Expected DataCorruptionFault.Remove() to refuse the symlink")
+				}
+			}
+
+			content, err := os.ReadFile(nodeFile)
+			if err != nil {
+				t.Fatalf("Failed to read node file: %v", err)
+			}
+			if string(content) != nodeContent {
+				t.Errorf("Node file = %q, want it untouched", content)
+			}
+		})
+	}
+}
+
+// mustSymlink creates a symlink or fails the test
+func mustSymlink(t *testing.T, oldname, newname string) {
+	t.Helper()
+	if err := os.Symlink(oldname, newname); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+}
@@ -0,0 +1,386 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// diskFillFile is the file created on the volume to fill it up
+const diskFillFile = ".havock8s-fill"
+
+// diskFailureState records what the agent changed so it can be reverted
+type diskFailureState struct {
+	Mode      string `json:"mode"`
+	MountPath string `json:"mountPath"`
+	Container string `json:"container"`
+	Device    string `json:"device,omitempty"`
+	Cgroup    string `json:"cgroup,omitempty"`
+	File      string `json:"file,omitempty"`
+}
+
+// DiskFailureFault makes the volume behind a mount path fail. Depending on the
+// mode it remounts the path read-only, fails block I/O through the kernel's
+// fail_make_request fault injection, caps the write IOPS of the container with
+// the cgroup v2 io controller, or fills the volume.
+type DiskFailureFault struct {
+	// SysRoot is where the host's /sys is mounted
+	SysRoot string
+
+	// CgroupRoot is where the host's cgroup v2 hierarchy is mounted
+	CgroupRoot string
+
+	// KubeletRoot is the kubelet's root directory on the host, below which
+	// it mounts the volumes of pods
+	KubeletRoot string
+}
+
+// Name returns the name of the fault
+func (f *DiskFailureFault) Name() string {
+	return "disk-failure"
+}
+
+// AckAnnotation returns the annotation used to acknowledge the fault
+func (f *DiskFailureFault) AckAnnotation() string {
+	return DiskFailureAckAnnotation
+}
+
+// Requested reports whether the pod asks for a disk failure
+func (f *DiskFailureFault) Requested(pod *corev1.Pod) bool {
+	return pod.Annotations[DiskFailureAnnotation] == "true"
+}
+
+// Apply injects the disk failure. The returned state is set even when applying
+// fails part way so that Remove can undo whatever was changed.
+func (f *DiskFailureFault) Apply(ctx context.Context, target Target, pod *corev1.Pod) (string, error) {
+	state := diskFailureState{
+		Mode:      pod.Annotations[DiskFailureModeAnnotation],
+		MountPath: pod.Annotations[DiskFailureMountAnnotation],
+	}
+	if state.MountPath == "" || !filepath.IsAbs(state.MountPath) {
+		return "", fmt.Errorf("annotation %s must be an absolute path", DiskFailureMountAnnotation)
+	}
+
+	container, err := mountingContainer(pod, pod.Annotations[DiskFailureContainerAnnotation], state.MountPath)
+	if err != nil {
+		return "", err
+	}
+	state.Container = container
+
+	pid, err := containerPID(target, pod, container)
+	if err != nil {
+		return "", err
+	}
+
+	switch state.Mode {
+	case DiskFailureModeReadOnly:
+		_, options, err := mountPoint(target.ProcRoot, pid, state.MountPath)
+		if err != nil {
+			return "", err
+		}
+		if slices.Contains(strings.Split(options, ","), "ro") {
+			// Already read-only, Remove must not make it writable
+			return "", nil
+		}
+		_, err = target.RunInMountNS(ctx, pid, "mount", "-o", "remount,bind,ro", state.MountPath)
+		if err != nil {
+			return "", err
+		}
+
+	case DiskFailureModeEIO:
+		percent, err := parsePercentAnnotation(pod.Annotations[DiskFailurePercentAnnotation])
+		if err != nil {
+			return "", err
+		}
+		device, err := blockDevice(target.ProcRoot, pid, state.MountPath)
+		if err != nil {
+			return "", err
+		}
+		// fail_make_request fails every request to the device, so it must
+		// not back anything but the pod's volumes
+		if err := f.checkDeviceExclusive(target, device); err != nil {
+			return "", err
+		}
+		if err := f.configureFailMakeRequest(percent); err != nil {
+			return "", err
+		}
+		state.Device = device
+		if err := writeControlFile(filepath.Join(f.SysRoot, "dev", "block", device, "make-it-fail"), "1"); err != nil {
+			return encodeDiskState(state), err
+		}
+
+	case DiskFailureModeWriteThrottle:
+		iops, err := strconv.Atoi(pod.Annotations[DiskFailureWriteIOPSAnnotation])
+		if err != nil || iops < 1 {
+			return "", fmt.Errorf("invalid write IOPS %q", pod.Annotations[DiskFailureWriteIOPSAnnotation])
+		}
+		device, err := blockDevice(target.ProcRoot, pid, state.MountPath)
+		if err != nil {
+			return "", err
+		}
+		cgroup, err := CgroupPath(target.ProcRoot, pid)
+		if err != nil {
+			return "", err
+		}
+		state.Device, state.Cgroup = device, cgroup
+		limit := fmt.Sprintf("%s wiops=%d", device, iops)
+		if err := writeControlFile(filepath.Join(f.CgroupRoot, cgroup, "io.max"), limit); err != nil {
+			return encodeDiskState(state), err
+		}
+
+	case DiskFailureModeFill:
+		percent, err := parsePercentAnnotation(pod.Annotations[DiskFailurePercentAnnotation])
+		if err != nil {
+			return "", err
+		}
+		size, err := fillSize(ctx, target, pid, state.MountPath, percent)
+		if err != nil {
+			return "", err
+		}
+		if size > 0 {
+			state.File = filepath.Join(state.MountPath, diskFillFile)
+			hostPath := containerPath(target.ProcRoot, pid, state.File)
+			if _, err := target.Exec.Run(ctx, "fallocate", "-l", strconv.FormatInt(size, 10), hostPath); err != nil {
+				return encodeDiskState(state), err
+			}
+		}
+
+	default:
+		return "", fmt.Errorf("unsupported disk failure mode: %s", state.Mode)
+	}
+
+	return encodeDiskState(state), nil
+}
+
+// Remove reverts the disk failure recorded in state
+func (f *DiskFailureFault) Remove(ctx context.Context, target Target, pod *corev1.Pod, state string) error {
+	if state == "" {
+		// Nothing was changed
+		return nil
+	}
+
+	var applied diskFailureState
+	if err := json.Unmarshal([]byte(state), &applied); err != nil {
+		return fmt.Errorf("invalid disk failure state: %w", err)
+	}
+
+	switch applied.Mode {
+	case DiskFailureModeReadOnly:
+		pid, err := containerPID(target, pod, applied.Container)
+		if err != nil {
+			return err
+		}
+		_, err = target.RunInMountNS(ctx, pid, "mount", "-o", "remount,bind,rw", applied.MountPath)
+		return err
+
+	case DiskFailureModeEIO:
+		if applied.Device == "" {
+			return nil
+		}
+		if err := writeControlFile(filepath.Join(f.SysRoot, "dev", "block", applied.Device, "make-it-fail"), "0"); err != nil {
+			return err
+		}
+		return f.resetFailMakeRequest()
+
+	case DiskFailureModeWriteThrottle:
+		if applied.Device == "" {
+			return nil
+		}
+		err := writeControlFile(filepath.Join(f.CgroupRoot, applied.Cgroup, "io.max"), applied.Device+" wiops=max")
+		if os.IsNotExist(err) {
+			// The container's cgroup went away together with the limit
+			return nil
+		}
+		return err
+
+	case DiskFailureModeFill:
+		if applied.File == "" {
+			return nil
+		}
+		pid, err := containerPID(target, pod, applied.Container)
+		if err != nil {
+			return err
+		}
+		err = os.Remove(containerPath(target.ProcRoot, pid, applied.File))
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
+
+// configureFailMakeRequest sets the probability of the fail_make_request
+// fault injection attribute in debugfs
+func (f *DiskFailureFault) configureFailMakeRequest(percent int) error {
+	dir := filepath.Join(f.SysRoot, "kernel", "debug", "fail_make_request")
+	settings := []struct {
+		file  string
+		value string
+	}{
+		{"probability", strconv.Itoa(percent)},
+		{"interval", "1"},
+		{"times", "-1"},
+		{"verbose", "0"},
+	}
+	for _, setting := range settings {
+		if err := writeControlFile(filepath.Join(dir, setting.file), setting.value); err != nil {
+			return fmt.Errorf("fail_make_request is not available, the kernel needs CONFIG_FAIL_MAKE_REQUEST and debugfs: %w", err)
+		}
+	}
+	return nil
+}
+
+// resetFailMakeRequest restores the kernel's defaults of the fail_make_request
+// attribute once no device is set to fail anymore. The attribute is shared by
+// all devices, so it stays configured while another eio fault is active.
+func (f *DiskFailureFault) resetFailMakeRequest() error {
+	flags, err := filepath.Glob(filepath.Join(f.SysRoot, "dev", "block", "*", "make-it-fail"))
+	if err != nil {
+		return err
+	}
+	for _, flag := range flags {
+		data, err := os.ReadFile(flag)
+		if err == nil && strings.TrimSpace(string(data)) == "1" {
+			return nil
+		}
+	}
+
+	dir := filepath.Join(f.SysRoot, "kernel", "debug", "fail_make_request")
+	if err := writeControlFile(filepath.Join(dir, "probability"), "0"); err != nil {
+		return err
+	}
+	return writeControlFile(filepath.Join(dir, "times"), "1")
+}
+
+// checkDeviceExclusive refuses a device that backs a mount of the host other
+// than the volumes of the target pod, such as the node's root filesystem or
+// the volume of another pod. The kubelet mounts pod volumes below
+// <kubelet root>/pods/<pod UID> and stages CSI volumes below
+// <kubelet root>/plugins.
+func (f *DiskFailureFault) checkDeviceExclusive(target Target, device string) error {
+	data, err := os.ReadFile(filepath.Join(target.ProcRoot, "1", "mountinfo"))
+	if err != nil {
+		return fmt.Errorf("failed to read the host's mountinfo: %w", err)
+	}
+
+	podDir := filepath.Join(f.KubeletRoot, "pods", target.PodUID)
+	pluginsDir := filepath.Join(f.KubeletRoot, "plugins")
+	// mountinfo fields: id parent major:minor root mountpoint options ...
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 || fields[2] != device {
+			continue
+		}
+		mountPoint := fields[4]
+		if pathWithin(mountPoint, podDir) || pathWithin(mountPoint, pluginsDir) {
+			continue
+		}
+		return fmt.Errorf("device %s also backs %s on the node, failing its requests would not be limited to the volume", device, mountPoint)
+	}
+	return nil
+}
+
+// mountingContainer returns the named container or, when name is empty, picks
+// the container whose volume mounts cover the path
+func mountingContainer(pod *corev1.Pod, name, mountPath string) (string, error) {
+	if name != "" {
+		return name, nil
+	}
+	for _, container := range pod.Spec.Containers {
+		for _, mount := range container.VolumeMounts {
+			if pathWithin(mountPath, mount.MountPath) {
+				return container.Name, nil
+			}
+		}
+	}
+	if len(pod.Spec.Containers) == 0 {
+		return "", fmt.Errorf("pod %s/%s has no containers", pod.Namespace, pod.Name)
+	}
+	return pod.Spec.Containers[0].Name, nil
+}
+
+// containerPID finds a process of the named container of the pod
+func containerPID(target Target, pod *corev1.Pod, container string) (int, error) {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == container && status.ContainerID != "" {
+			return target.ContainerPID(status.ContainerID)
+		}
+	}
+	return 0, fmt.Errorf("container %s of pod %s/%s is not running", container, pod.Namespace, pod.Name)
+}
+
+// blockDevice returns the block device backing a path inside a container
+func blockDevice(procRoot string, pid int, path string) (string, error) {
+	device, err := MountDevice(procRoot, pid, path)
+	if err != nil {
+		return "", err
+	}
+	// Major number 0 is used by virtual filesystems such as overlay and tmpfs
+	if strings.HasPrefix(device, "0:") {
+		return "", fmt.Errorf("%s is not backed by a block device", path)
+	}
+	return device, nil
+}
+
+// fillSize returns how many bytes to allocate so the filesystem holding
+// mountPath reaches percent of its capacity
+func fillSize(ctx context.Context, target Target, pid int, mountPath string, percent int) (int64, error) {
+	out, err := target.Exec.Run(ctx, "df", "-B1", "--output=size,used", containerPath(target.ProcRoot, pid, mountPath))
+	if err != nil {
+		return 0, err
+	}
+
+	// df prints a header line followed by the values
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) < 2 {
+		return 0, fmt.Errorf("unexpected df output: %q", string(out))
+	}
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) != 2 {
+		return 0, fmt.Errorf("unexpected df output: %q", string(out))
+	}
+	size, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected df output: %q", string(out))
+	}
+	used, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected df output: %q", string(out))
+	}
+
+	return size*int64(percent)/100 - used, nil
+}
+
+// containerPath returns the host path of a path inside a container
+func containerPath(procRoot string, pid int, path string) string {
+	return filepath.Join(procRoot, strconv.Itoa(pid), "root", path)
+}
+
+// parsePercentAnnotation parses an integer percentage between 0 and 100
+func parsePercentAnnotation(value string) (int, error) {
+	percent, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+	if err != nil || percent < 0 || percent > 100 {
+		return 0, fmt.Errorf("invalid percentage %q", value)
+	}
+	return percent, nil
+}
+
+// writeControlFile writes a value to a sysfs, debugfs or cgroupfs file
+func writeControlFile(path, value string) error {
+	return os.WriteFile(path, []byte(value), 0o644)
+}
+
+// encodeDiskState serialises the disk failure state
+func encodeDiskState(state diskFailureState) string {
+	data, _ := json.Marshal(state)
+	return string(data)
+}
@@ -0,0 +1,319 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	testContainerCgroup = "/kubepods/pod1234-abcd/cri-containerd-abc123.scope"
+	testMountInfo       = "25 1 0:50 / / rw - overlay overlay rw\n" +
+		"30 25 8:16 / /data rw - ext4 /dev/sdb rw\n" +
+		"31 25 0:60 / /tmp rw - tmpfs tmpfs rw\n"
+	testHostMountInfo = "22 1 8:1 / / rw - ext4 /dev/sda1 rw\n" +
+		"40 22 8:16 / /var/lib/kubelet/plugins/kubernetes.io/csi/ebs.csi.aws.com/abc/globalmount rw - ext4 /dev/sdb rw\n" +
+		"41 22 8:16 / /var/lib/kubelet/pods/1234-abcd/volumes/kubernetes.io~csi/pvc-1/mount rw - ext4 /dev/sdb rw\n"
+)
+
+// setupDiskRoots creates fake proc, sys and cgroup trees for a container
+// with PID 42 whose /data is backed by block device 8:16, which the host
+// mounts as in hostMountInfo
+func setupDiskRoots(t *testing.T, hostMountInfo string) (procRoot, sysRoot, cgroupRoot string) {
+	procRoot, sysRoot, cgroupRoot = t.TempDir(), t.TempDir(), t.TempDir()
+
+	files := map[string]string{
+		filepath.Join(procRoot, "1", "mountinfo"):                               hostMountInfo,
+		filepath.Join(procRoot, "42", "cgroup"):                                 "0::" + testContainerCgroup + "\n",
+		filepath.Join(procRoot, "42", "mountinfo"):                              testMountInfo,
+		filepath.Join(sysRoot, "dev", "block", "8:16", "make-it-fail"):          "0",
+		filepath.Join(sysRoot, "kernel", "debug", "fail_make_request", "times"): "1",
+		filepath.Join(cgroupRoot, testContainerCgroup, "io.max"):                "",
+		filepath.Join(procRoot, "42", "root", "data", "keep"):                   "",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	return procRoot, sysRoot, cgroupRoot
+}
+
+func readFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestDiskFailureFault_ApplyAndRemove(t *testing.T) {
+	tests := []struct {
+		name           string
+		annotations    map[string]string
+		hostMountInfo  string
+		mountInfo      string
+		outputs        map[string]string
+		wantErr        bool
+		wantCommands   []string
+		checkApplied   func(t *testing.T, procRoot, sysRoot, cgroupRoot string)
+		checkRemoved   func(t *testing.T, procRoot, sysRoot, cgroupRoot string)
+		wantRemoveCmds []string
+	}{
+		{
+			name: "readonly remounts the path",
+			annotations: map[string]string{
+				DiskFailureModeAnnotation: DiskFailureModeReadOnly,
+			},
+			wantCommands: []string{
+				"nsenter --target 42 --mount -- mount -o remount,bind,ro /data",
+			},
+			wantRemoveCmds: []string{
+				"nsenter --target 42 --mount -- mount -o remount,bind,rw /data",
+			},
+		},
+		{
+			name: "readonly leaves a read-only mount alone",
+			annotations: map[string]string{
+				DiskFailureModeAnnotation: DiskFailureModeReadOnly,
+			},
+			mountInfo:      "25 1 0:50 / / rw - overlay overlay rw\n30 25 8:16 / /data ro - ext4 /dev/sdb ro\n",
+			wantCommands:   []string{},
+			wantRemoveCmds: []string{},
+		},
+		{
+			name: "eio fails block requests",
+			annotations: map[string]string{
+				DiskFailureModeAnnotation:    DiskFailureModeEIO,
+				DiskFailurePercentAnnotation: "30",
+			},
+			checkApplied: func(t *testing.T, procRoot, sysRoot, cgroupRoot string) {
+				if got := readFile(t, filepath.Join(sysRoot, "kernel", "debug", "fail_make_request", "probability")); got != "30" {
+					t.Errorf("probability = %q, want 30", got)
+				}
+				if got := readFile(t, filepath.Join(sysRoot, "dev", "block", "8:16", "make-it-fail")); got != "1" {
+					t.Errorf("make-it-fail = %q, want 1", got)
+				}
+			},
+			checkRemoved: func(t *testing.T, procRoot, sysRoot, cgroupRoot string) {
+				if got := readFile(t, filepath.Join(sysRoot, "dev", "block", "8:16", "make-it-fail")); got != "0" {
+					t.Errorf("make-it-fail = %q, want 0", got)
+				}
+				if got := readFile(t, filepath.Join(sysRoot, "kernel", "debug", "fail_make_request", "probability")); got != "0" {
+					t.Errorf("probability = %q, want 0", got)
+				}
+				if got := readFile(t, filepath.Join(sysRoot, "kernel", "debug", "fail_make_request", "times")); got != "1" {
+					t.Errorf("times = %q, want 1", got)
+				}
+			},
+		},
+		{
+			name: "eio keeps fail_make_request configured for another failing device",
+			annotations: map[string]string{
+				DiskFailureModeAnnotation:    DiskFailureModeEIO,
+				DiskFailurePercentAnnotation: "30",
+			},
+			checkApplied: func(t *testing.T, procRoot, sysRoot, cgroupRoot string) {
+				// The eio fault of another pod
+				path := filepath.Join(sysRoot, "dev", "block", "8:32", "make-it-fail")
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatalf("Failed to create dir: %v", err)
+				}
+				if err := os.WriteFile(path, []byte("1"), 0o600); err != nil {
+					t.Fatalf("Failed to write make-it-fail: %v", err)
+				}
+			},
+			checkRemoved: func(t *testing.T, procRoot, sysRoot, cgroupRoot string) {
+				if got := readFile(t, filepath.Join(sysRoot, "dev", "block", "8:16", "make-it-fail")); got != "0" {
+					t.Errorf("make-it-fail = %q, want 0", got)
+				}
+				if got := readFile(t, filepath.Join(sysRoot, "kernel", "debug", "fail_make_request", "probability")); got != "30" {
+					t.Errorf("probability = %q, want 30", got)
+				}
+			},
+		},
+		{
+			name: "write throttle caps write IOPS",
+			annotations: map[string]string{
+				DiskFailureModeAnnotation:      DiskFailureModeWriteThrottle,
+				DiskFailureWriteIOPSAnnotation: "10",
+			},
+			checkApplied: func(t *testing.T, procRoot, sysRoot, cgroupRoot string) {
+				if got := readFile(t, filepath.Join(cgroupRoot, testContainerCgroup, "io.max")); got != "8:16 wiops=10" {
+					t.Errorf("io.max = %q, want %q", got, "8:16 wiops=10")
+				}
+			},
+			checkRemoved: func(t *testing.T, procRoot, sysRoot, cgroupRoot string) {
+				if got := readFile(t, filepath.Join(cgroupRoot, testContainerCgroup, "io.max")); got != "8:16 wiops=max" {
+					t.Errorf("io.max = %q, want %q", got, "8:16 wiops=max")
+				}
+			},
+		},
+		{
+			name: "fill allocates up to the requested usage",
+			annotations: map[string]string{
+				DiskFailureModeAnnotation:    DiskFailureModeFill,
+				DiskFailurePercentAnnotation: "50",
+			},
+			outputs: map[string]string{
+				"df": "1B-blocks Used\n1000 100\n",
+			},
+			checkApplied: func(t *testing.T, procRoot, sysRoot, cgroupRoot string) {
+				// Stand in for fallocate so Remove has a file to delete
+				path := filepath.Join(procRoot, "42", "root", "data", diskFillFile)
+				if err := os.WriteFile(path, nil, 0o600); err != nil {
+					t.Fatalf("Failed to write fill file: %v", err)
+				}
+			},
+			checkRemoved: func(t *testing.T, procRoot, sysRoot, cgroupRoot string) {
+				if _, err := os.Stat(filepath.Join(procRoot, "42", "root", "data", diskFillFile)); !os.IsNotExist(err) {
+					t.Errorf("Fill file still present after removal")
+				}
+			},
+		},
+		{
+			name: "eio on a path without block device",
+			annotations: map[string]string{
+				DiskFailureModeAnnotation:    DiskFailureModeEIO,
+				DiskFailureMountAnnotation:   "/tmp/cache",
+				DiskFailurePercentAnnotation: "30",
+			},
+			wantErr: true,
+		},
+		{
+			name: "eio on a device also backing another pod's volume",
+			annotations: map[string]string{
+				DiskFailureModeAnnotation:    DiskFailureModeEIO,
+				DiskFailurePercentAnnotation: "30",
+			},
+			hostMountInfo: testHostMountInfo +
+				"42 22 8:16 /shared /var/lib/kubelet/pods/5678-efgh/volumes/kubernetes.io~local-volume/pv-2 rw - ext4 /dev/sdb rw\n",
+			wantErr: true,
+		},
+		{
+			name: "eio on a device also backing the node's root filesystem",
+			annotations: map[string]string{
+				DiskFailureModeAnnotation:    DiskFailureModeEIO,
+				DiskFailurePercentAnnotation: "30",
+			},
+			hostMountInfo: "22 1 8:16 / / rw - ext4 /dev/sdb rw\n",
+			wantErr:       true,
+		},
+		{
+			name: "write throttle without a cap",
+			annotations: map[string]string{
+				DiskFailureModeAnnotation:      DiskFailureModeWriteThrottle,
+				DiskFailureWriteIOPSAnnotation: "0",
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported mode",
+			annotations: map[string]string{
+				DiskFailureModeAnnotation: "writeonly",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hostMountInfo := tt.hostMountInfo
+			if hostMountInfo == "" {
+				hostMountInfo = testHostMountInfo
+			}
+			procRoot, sysRoot, cgroupRoot := setupDiskRoots(t, hostMountInfo)
+			if tt.mountInfo != "" {
+				if err := os.WriteFile(filepath.Join(procRoot, "42", "mountinfo"), []byte(tt.mountInfo), 0o600); err != nil {
+					t.Fatalf("Failed to write mountinfo: %v", err)
+				}
+			}
+
+			annotations := map[string]string{
+				DiskFailureAnnotation:      "true",
+				DiskFailureMountAnnotation: "/data",
+			}
+			for k, v := range tt.annotations {
+				annotations[k] = v
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-pod",
+					Namespace:   "default",
+					UID:         types.UID("1234-abcd"),
+					Annotations: annotations,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "sidecar"},
+						{Name: "db", VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/data"}}},
+					},
+				},
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{
+						{Name: "sidecar", ContainerID: "containerd://def456"},
+						{Name: "db", ContainerID: "containerd://abc123"},
+					},
+				},
+			}
+
+			executor := &fakeExecutor{outputs: tt.outputs}
+			target := Target{PID: 1, PodUID: "1234-abcd", ProcRoot: procRoot, Exec: executor}
+			fault := &DiskFailureFault{SysRoot: sysRoot, CgroupRoot: cgroupRoot, KubeletRoot: "/var/lib/kubelet"}
+
+			state, err := fault.Apply(context.Background(), target, pod)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DiskFailureFault.Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if tt.wantCommands != nil && strings.Join(executor.commands, "\n") != strings.Join(tt.wantCommands, "\n") {
+				t.Errorf("Commands = %v, want %v", executor.commands, tt.wantCommands)
+			}
+			if tt.checkApplied != nil {
+				tt.checkApplied(t, procRoot, sysRoot, cgroupRoot)
+			}
+
+			// The request annotations are gone by the time the fault is removed
+			pod.Annotations = nil
+			executor.commands = nil
+			if err := fault.Remove(context.Background(), target, pod, state); err != nil {
+				t.Fatalf("DiskFailureFault.Remove() error = %v", err)
+			}
+
+			if tt.wantRemoveCmds != nil && strings.Join(executor.commands, "\n") != strings.Join(tt.wantRemoveCmds, "\n") {
+				t.Errorf("Remove commands = %v, want %v", executor.commands, tt.wantRemoveCmds)
+			}
+			if tt.checkRemoved != nil {
+				tt.checkRemoved(t, procRoot, sysRoot, cgroupRoot)
+			}
+		})
+	}
+}
+
+func TestFillSize(t *testing.T) {
+	executor := &fakeExecutor{outputs: map[string]string{"df": "1B-blocks Used\n1000 100\n"}}
+	target := Target{ProcRoot: "/proc", Exec: executor}
+
+	size, err := fillSize(context.Background(), target, 42, "/data", 50)
+	if err != nil {
+		t.Fatalf("fillSize() error = %v", err)
+	}
+	if size != 400 {
+		t.Errorf("fillSize() = %d, want 400", size)
+	}
+	if want := "df -B1 --output=size,used /proc/42/root/data"; executor.commands[0] != want {
+		t.Errorf("Command = %q, want %q", executor.commands[0], want)
+	}
+}
@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Executor runs commands on the node
+type Executor interface {
+	// Run executes a command and returns its combined output
+	Run(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+// CommandExecutor runs commands with os/exec
+type CommandExecutor struct{}
+
+// Run executes a command and returns its combined output
+func (CommandExecutor) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		return out, fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
+
+// Target identifies the pod process the agent acts upon
+type Target struct {
+	// PID is a process running inside the pod's sandbox
+	PID int
+
+	// PodUID is the UID of the target pod
+	PodUID string
+
+	// ProcRoot is where the host's /proc is mounted
+	ProcRoot string
+
+	// Exec runs commands on the node
+	Exec Executor
+}
+
+// RunInNetNS runs a command inside the target's network namespace
+func (t Target) RunInNetNS(ctx context.Context, name string, args ...string) ([]byte, error) {
+	nsArgs := append([]string{"--target", strconv.Itoa(t.PID), "--net", "--", name}, args...)
+	return t.Exec.Run(ctx, "nsenter", nsArgs...)
+}
+
+// RunInMountNS runs a command inside the mount namespace of the given process
+func (t Target) RunInMountNS(ctx context.Context, pid int, name string, args ...string) ([]byte, error) {
+	nsArgs := append([]string{"--target", strconv.Itoa(pid), "--mount", "--", name}, args...)
+	return t.Exec.Run(ctx, "nsenter", nsArgs...)
+}
+
+// ContainerPID returns the PID of a process running in the given container of
+// the target pod
+func (t Target) ContainerPID(containerID string) (int, error) {
+	return FindContainerPID(t.ProcRoot, t.PodUID, containerID)
+}
@@ -0,0 +1,182 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DefaultSignal is sent when a kill request does not name a signal
+const DefaultSignal = "SIGKILL"
+
+// signals are the signals a kill may send, by name and number. Signals that
+// stop a process are left out as nothing would resume it.
+var signals = map[string]int{
+	"SIGHUP":  1,
+	"SIGINT":  2,
+	"SIGQUIT": 3,
+	"SIGKILL": 9,
+	"SIGUSR1": 10,
+	"SIGUSR2": 12,
+	"SIGTERM": 15,
+}
+
+// ParseSignal returns the canonical name of a signal given by name, with or
+// without the SIG prefix, or by number
+func ParseSignal(value string) (string, error) {
+	if value == "" {
+		return DefaultSignal, nil
+	}
+
+	name := strings.ToUpper(value)
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	if _, ok := signals[name]; ok {
+		return name, nil
+	}
+	if number, err := strconv.Atoi(value); err == nil {
+		for name, n := range signals {
+			if n == number {
+				return name, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unsupported signal %q", value)
+}
+
+// KillFault sends a signal to processes of a container. Without a process
+// pattern the container's main process is signalled, which makes the kubelet
+// restart the container in place. The kill happens once per request, so there
+// is nothing to revert on removal.
+type KillFault struct{}
+
+// Name returns the name of the fault
+func (f *KillFault) Name() string {
+	return "kill"
+}
+
+// AckAnnotation returns the annotation used to acknowledge the fault
+func (f *KillFault) AckAnnotation() string {
+	return KillAckAnnotation
+}
+
+// Requested reports whether the pod asks for processes to be killed
+func (f *KillFault) Requested(pod *corev1.Pod) bool {
+	return pod.Annotations[KillAnnotation] == "true"
+}
+
+// Apply sends the requested signal
+func (f *KillFault) Apply(ctx context.Context, target Target, pod *corev1.Pod) (string, error) {
+	signal, err := ParseSignal(pod.Annotations[KillSignalAnnotation])
+	if err != nil {
+		return "", err
+	}
+
+	container := pod.Annotations[KillContainerAnnotation]
+	if container == "" {
+		if len(pod.Spec.Containers) == 0 {
+			return "", fmt.Errorf("pod %s/%s has no containers", pod.Namespace, pod.Name)
+		}
+		container = pod.Spec.Containers[0].Name
+	}
+
+	var pids []int
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == container && status.ContainerID != "" {
+			pids, err = FindContainerPIDs(target.ProcRoot, target.PodUID, status.ContainerID)
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+	if len(pids) == 0 {
+		return "", fmt.Errorf("container %s of pod %s/%s is not running", container, pod.Namespace, pod.Name)
+	}
+
+	var victims []int
+	if pattern := pod.Annotations[KillProcessAnnotation]; pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return "", fmt.Errorf("invalid process pattern %q: %w", pattern, err)
+		}
+		victims = matchingProcesses(target.ProcRoot, pids, re)
+		if len(victims) == 0 {
+			return "", fmt.Errorf("no process of container %s matches %q", container, pattern)
+		}
+	} else {
+		victims = []int{mainProcess(target.ProcRoot, pids)}
+	}
+
+	// The agent shares the host's PID namespace, so the PIDs found under
+	// ProcRoot can be signalled directly
+	args := []string{"-s", strings.TrimPrefix(signal, "SIG")}
+	for _, pid := range victims {
+		args = append(args, strconv.Itoa(pid))
+	}
+	_, err = target.Exec.Run(ctx, "kill", args...)
+	return "", err
+}
+
+// Remove does nothing, a signal cannot be taken back
+func (f *KillFault) Remove(ctx context.Context, target Target, pod *corev1.Pod, state string) error {
+	return nil
+}
+
+// mainProcess returns the process of a container whose parent lives outside
+// the container, which is the process the container runtime started
+func mainProcess(procRoot string, pids []int) int {
+	inContainer := make(map[int]bool, len(pids))
+	for _, pid := range pids {
+		inContainer[pid] = true
+	}
+	for _, pid := range pids {
+		ppid, err := parentPID(procRoot, pid)
+		if err == nil && !inContainer[ppid] {
+			return pid
+		}
+	}
+	// The oldest process is the best guess otherwise
+	return pids[0]
+}
+
+// parentPID reads the parent of a process from its status file
+func parentPID(procRoot string, pid int) (int, error) {
+	file, err := os.Open(filepath.Join(procRoot, strconv.Itoa(pid), "status"))
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "PPid:"); ok {
+			return strconv.Atoi(strings.TrimSpace(value))
+		}
+	}
+	return 0, fmt.Errorf("no parent found for process %d", pid)
+}
+
+// matchingProcesses returns the processes whose command line matches re
+func matchingProcesses(procRoot string, pids []int, re *regexp.Regexp) []int {
+	var matches []int
+	for _, pid := range pids {
+		data, err := os.ReadFile(filepath.Join(procRoot, strconv.Itoa(pid), "cmdline"))
+		if err != nil {
+			// The process may have exited since the scan
+			continue
+		}
+		cmdline := strings.TrimSpace(strings.ReplaceAll(string(data), "\x00", " "))
+		if re.MatchString(cmdline) {
+			matches = append(matches, pid)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,175 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// setupKillProcRoot creates a fake proc tree for a container running a
+// shell (PID 40, started by the shim with PID 7) with two children
+func setupKillProcRoot(t *testing.T) string {
+	procRoot := t.TempDir()
+	processes := []struct {
+		pid     string
+		ppid    string
+		cgroup  string
+		cmdline string
+	}{
+		{"7", "1", "0::/system.slice/containerd.service\n", "containerd-shim-runc-v2\x00-id\x00abc123\x00"},
+		{"40", "7", "0::/kubepods/pod1234-abcd/cri-containerd-abc123.scope\n", "/bin/sh\x00-c\x00/start.sh\x00"},
+		{"41", "40", "0::/kubepods/pod1234-abcd/cri-containerd-abc123.scope\n", "postgres\x00-D\x00/data\x00"},
+		{"42", "41", "0::/kubepods/pod1234-abcd/cri-containerd-abc123.scope\n", "postgres: checkpointer\x00"},
+		{"50", "7", "0::/kubepods/pod1234-abcd/cri-containerd-def456.scope\n", "envoy\x00"},
+	}
+	for _, p := range processes {
+		files := map[string]string{
+			"cgroup":  p.cgroup,
+			"status":  "Name:\tproc\nPid:\t" + p.pid + "\nPPid:\t" + p.ppid + "\n",
+			"cmdline": p.cmdline,
+		}
+		for name, content := range files {
+			path := filepath.Join(procRoot, p.pid, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatalf("Failed to create dir: %v", err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatalf("Failed to write %s: %v", path, err)
+			}
+		}
+	}
+	return procRoot
+}
+
+func TestKillFault_Apply(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		wantCommand string
+		wantErr     bool
+	}{
+		{
+			name:        "kills the main process with SIGKILL by default",
+			annotations: map[string]string{},
+			wantCommand: "kill -s KILL 40",
+		},
+		{
+			name:        "sends the requested signal",
+			annotations: map[string]string{KillSignalAnnotation: "term"},
+			wantCommand: "kill -s TERM 40",
+		},
+		{
+			name:        "kills processes matching the pattern",
+			annotations: map[string]string{KillProcessAnnotation: "^postgres"},
+			wantCommand: "kill -s KILL 41 42",
+		},
+		{
+			name: "targets the named container",
+			annotations: map[string]string{
+				KillContainerAnnotation: "proxy",
+				KillSignalAnnotation:    "15",
+			},
+			wantCommand: "kill -s TERM 50",
+		},
+		{
+			name:        "no process matches",
+			annotations: map[string]string{KillProcessAnnotation: "^mysqld"},
+			wantErr:     true,
+		},
+		{
+			name:        "unsupported signal",
+			annotations: map[string]string{KillSignalAnnotation: "SIGSTOP"},
+			wantErr:     true,
+		},
+		{
+			name:        "container not running",
+			annotations: map[string]string{KillContainerAnnotation: "init"},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations := map[string]string{KillAnnotation: "true"}
+			for k, v := range tt.annotations {
+				annotations[k] = v
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-pod",
+					Namespace:   "default",
+					UID:         types.UID("1234-abcd"),
+					Annotations: annotations,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "db"}, {Name: "proxy"}, {Name: "init"}},
+				},
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{
+						{Name: "db", ContainerID: "containerd://abc123"},
+						{Name: "proxy", ContainerID: "containerd://def456"},
+						{Name: "init"},
+					},
+				},
+			}
+
+			executor := &fakeExecutor{}
+			target := Target{PID: 40, PodUID: "1234-abcd", ProcRoot: setupKillProcRoot(t), Exec: executor}
+			fault := &KillFault{}
+
+			state, err := fault.Apply(context.Background(), target, pod)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("KillFault.Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if len(executor.commands) != 0 {
+					t.Errorf("Commands = %v, want none", executor.commands)
+				}
+				return
+			}
+
+			if got := strings.Join(executor.commands, "\n"); got != tt.wantCommand {
+				t.Errorf("Commands = %q, want %q", got, tt.wantCommand)
+			}
+			if state != "" {
+				t.Errorf("State = %q, want none", state)
+			}
+			if err := fault.Remove(context.Background(), target, pod, state); err != nil {
+				t.Errorf("KillFault.Remove() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestParseSignal(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "", want: "SIGKILL"},
+		{value: "SIGTERM", want: "SIGTERM"},
+		{value: "hup", want: "SIGHUP"},
+		{value: "9", want: "SIGKILL"},
+		{value: "SIGSTOP", wantErr: true},
+		{value: "99", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseSignal(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSignal(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSignal(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
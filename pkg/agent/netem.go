@@ -0,0 +1,271 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NetemSpec describes a netem queueing discipline
+type NetemSpec struct {
+	// Latency added to every packet (e.g. "100ms")
+	Latency string
+
+	// Jitter applied to the latency (e.g. "10ms")
+	Jitter string
+
+	// Correlation between consecutive delays, in percent
+	Correlation string
+
+	// Loss is the percentage of packets dropped
+	Loss string
+
+	// Duplicate is the percentage of packets sent twice
+	Duplicate string
+
+	// Corrupt is the percentage of packets with a flipped bit
+	Corrupt string
+
+	// Reorder is the percentage of packets sent immediately while the others
+	// are delayed. It requires a latency.
+	Reorder string
+
+	// Rate limits the bandwidth, in tc units (e.g. "1mbit")
+	Rate string
+
+	// Ports limits netem to traffic to or from these ports. Empty means all traffic.
+	Ports []int
+}
+
+// rateUnits are the bandwidth units tc accepts
+var rateUnits = []string{"bit", "kbit", "mbit", "gbit", "tbit", "bps", "kbps", "mbps", "gbps", "tbps"}
+
+// ParseNetemSpec builds a NetemSpec from the network chaos annotations on a pod
+func ParseNetemSpec(annotations map[string]string) (NetemSpec, error) {
+	spec := NetemSpec{
+		Latency:     annotations[NetworkLatencyValueAnnotation],
+		Jitter:      annotations[NetworkJitterValueAnnotation],
+		Correlation: strings.TrimSuffix(annotations[NetworkCorrelationValueAnnotation], "%"),
+		Loss:        strings.TrimSuffix(annotations[NetworkLossValueAnnotation], "%"),
+		Duplicate:   strings.TrimSuffix(annotations[NetworkDuplicateValueAnnotation], "%"),
+		Corrupt:     strings.TrimSuffix(annotations[NetworkCorruptValueAnnotation], "%"),
+		Reorder:     strings.TrimSuffix(annotations[NetworkReorderValueAnnotation], "%"),
+		Rate:        annotations[NetworkRateValueAnnotation],
+	}
+
+	if spec.Latency == "" && spec.Loss == "" && spec.Duplicate == "" && spec.Corrupt == "" && spec.Rate == "" {
+		return spec, fmt.Errorf("one of annotations %s, %s, %s, %s or %s is required",
+			NetworkLatencyValueAnnotation, NetworkLossValueAnnotation, NetworkDuplicateValueAnnotation,
+			NetworkCorruptValueAnnotation, NetworkRateValueAnnotation)
+	}
+	if spec.Latency != "" {
+		if _, err := time.ParseDuration(spec.Latency); err != nil {
+			return spec, fmt.Errorf("invalid latency %q: %w", spec.Latency, err)
+		}
+	}
+	if spec.Jitter != "" {
+		if _, err := time.ParseDuration(spec.Jitter); err != nil {
+			return spec, fmt.Errorf("invalid jitter %q: %w", spec.Jitter, err)
+		}
+	}
+	if spec.Reorder != "" && spec.Latency == "" {
+		return spec, fmt.Errorf("reordering requires annotation %s", NetworkLatencyValueAnnotation)
+	}
+	if (spec.Jitter != "" || spec.Correlation != "") && spec.Latency == "" {
+		return spec, fmt.Errorf("jitter and correlation require annotation %s", NetworkLatencyValueAnnotation)
+	}
+	for _, percentage := range []struct{ name, value string }{
+		{"correlation", spec.Correlation},
+		{"loss", spec.Loss},
+		{"duplicate", spec.Duplicate},
+		{"corrupt", spec.Corrupt},
+		{"reorder", spec.Reorder},
+	} {
+		if err := validatePercentage(percentage.name, percentage.value); err != nil {
+			return spec, err
+		}
+	}
+	if spec.Rate != "" {
+		if err := ValidateRate(spec.Rate); err != nil {
+			return spec, err
+		}
+	}
+
+	ports, err := ParsePorts(annotations[NetworkPortsAnnotation])
+	if err != nil {
+		return spec, err
+	}
+	spec.Ports = ports
+
+	return spec, nil
+}
+
+// validatePercentage checks an optional percentage lies between 0 and 100
+func validatePercentage(name, value string) error {
+	if value == "" {
+		return nil
+	}
+	percentage, err := strconv.ParseFloat(value, 64)
+	if err != nil || percentage < 0 || percentage > 100 {
+		return fmt.Errorf("invalid %s %q: must be between 0 and 100", name, value)
+	}
+	return nil
+}
+
+// ValidateRate checks a bandwidth is a positive number followed by a tc unit
+func ValidateRate(value string) error {
+	lower := strings.ToLower(value)
+	for _, unit := range rateUnits {
+		number, ok := strings.CutSuffix(lower, unit)
+		if !ok {
+			continue
+		}
+		if rate, err := strconv.ParseFloat(number, 64); err == nil && rate > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid rate %q: must be a positive number followed by bit, kbit, mbit, gbit, bps, kbps, mbps or gbps", value)
+}
+
+// ParsePorts parses a comma separated list of ports
+func ParsePorts(value string) ([]int, error) {
+	var ports []int
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		port, err := strconv.Atoi(field)
+		if err != nil || port < 1 || port > 65535 {
+			return nil, fmt.Errorf("invalid port %q", field)
+		}
+		ports = append(ports, port)
+	}
+	return ports, nil
+}
+
+// Args returns the netem arguments for the spec
+func (s NetemSpec) Args() []string {
+	args := []string{"netem"}
+	if s.Latency != "" {
+		args = append(args, "delay", s.Latency)
+		if s.Jitter != "" {
+			args = append(args, s.Jitter)
+			if s.Correlation != "" {
+				args = append(args, s.Correlation+"%")
+			}
+		}
+	}
+	for _, option := range []struct{ name, value string }{
+		{"loss", s.Loss},
+		{"duplicate", s.Duplicate},
+		{"corrupt", s.Corrupt},
+		{"reorder", s.Reorder},
+	} {
+		if option.value != "" {
+			args = append(args, option.name, option.value+"%")
+		}
+	}
+	if s.Rate != "" {
+		args = append(args, "rate", s.Rate)
+	}
+	return args
+}
+
+// Commands returns the tc invocations that install the spec on a device. When
+// ports are given, a four band prio qdisc sends matching IPv4 and IPv6 traffic
+// to a netem band while everything else keeps flowing through the default
+// bands.
+func (s NetemSpec) Commands(device string) [][]string {
+	if len(s.Ports) == 0 {
+		return [][]string{
+			append([]string{"qdisc", "replace", "dev", device, "root", "handle", "1:"}, s.Args()...),
+		}
+	}
+
+	commands := [][]string{
+		{"qdisc", "replace", "dev", device, "root", "handle", "1:", "prio", "bands", "4"},
+		append([]string{"qdisc", "replace", "dev", device, "parent", "1:4", "handle", "40:"}, s.Args()...),
+	}
+	// Filters of one priority must share a protocol
+	families := []struct{ protocol, prio, match string }{
+		{"ip", "1", "ip"},
+		{"ipv6", "2", "ip6"},
+	}
+	for _, family := range families {
+		for _, port := range s.Ports {
+			for _, direction := range []string{"dport", "sport"} {
+				commands = append(commands, []string{
+					"filter", "add", "dev", device, "parent", "1:0", "protocol", family.protocol, "prio", family.prio,
+					"u32", "match", family.match, direction, strconv.Itoa(port), "0xffff", "flowid", "1:4",
+				})
+			}
+		}
+	}
+	return commands
+}
+
+// NetworkLatencyFault applies tc netem latency, loss, duplication,
+// corruption, reordering and rate limiting inside a pod's network namespace
+type NetworkLatencyFault struct {
+	// Device is the network interface inside the pod
+	Device string
+}
+
+// Name returns the name of the fault
+func (f *NetworkLatencyFault) Name() string {
+	return "network-latency"
+}
+
+// AckAnnotation returns the annotation used to acknowledge the fault
+func (f *NetworkLatencyFault) AckAnnotation() string {
+	return NetworkLatencyAckAnnotation
+}
+
+// Requested reports whether the pod asks for netem network chaos
+func (f *NetworkLatencyFault) Requested(pod *corev1.Pod) bool {
+	return pod.Annotations[NetworkLatencyAnnotation] == "true"
+}
+
+// Apply installs the netem qdisc
+func (f *NetworkLatencyFault) Apply(ctx context.Context, target Target, pod *corev1.Pod) (string, error) {
+	spec, err := ParseNetemSpec(pod.Annotations)
+	if err != nil {
+		return "", err
+	}
+
+	// Start from a clean root qdisc so re-applying is idempotent
+	if err := f.removeRootQdisc(ctx, target); err != nil {
+		return "", err
+	}
+
+	for _, args := range spec.Commands(f.Device) {
+		if _, err := target.RunInNetNS(ctx, "tc", args...); err != nil {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// Remove deletes the netem qdisc
+func (f *NetworkLatencyFault) Remove(ctx context.Context, target Target, pod *corev1.Pod, state string) error {
+	return f.removeRootQdisc(ctx, target)
+}
+
+// removeRootQdisc deletes the root qdisc, ignoring the error tc reports when
+// there is nothing to delete
+func (f *NetworkLatencyFault) removeRootQdisc(ctx context.Context, target Target) error {
+	out, err := target.RunInNetNS(ctx, "tc", "qdisc", "del", "dev", f.Device, "root")
+	if err != nil {
+		msg := string(out) + err.Error()
+		if strings.Contains(msg, "No such file or directory") || strings.Contains(msg, "handle of zero") {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
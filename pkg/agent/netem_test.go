@@ -0,0 +1,177 @@
+package agent
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNetemSpec(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        NetemSpec
+		wantErr     bool
+	}{
+		{
+			name: "full spec",
+			annotations: map[string]string{
+				NetworkLatencyValueAnnotation:     "200ms",
+				NetworkJitterValueAnnotation:      "50ms",
+				NetworkCorrelationValueAnnotation: "75%",
+				NetworkPortsAnnotation:            "6379, 6380",
+			},
+			want: NetemSpec{
+				Latency:     "200ms",
+				Jitter:      "50ms",
+				Correlation: "75",
+				Ports:       []int{6379, 6380},
+			},
+		},
+		{
+			name: "latency only",
+			annotations: map[string]string{
+				NetworkLatencyValueAnnotation: "100ms",
+			},
+			want: NetemSpec{Latency: "100ms"},
+		},
+		{
+			name: "lossy link",
+			annotations: map[string]string{
+				NetworkLossValueAnnotation:      "5%",
+				NetworkDuplicateValueAnnotation: "1",
+				NetworkCorruptValueAnnotation:   "0.5",
+				NetworkRateValueAnnotation:      "1mbit",
+			},
+			want: NetemSpec{Loss: "5", Duplicate: "1", Corrupt: "0.5", Rate: "1mbit"},
+		},
+		{
+			name:        "missing latency",
+			annotations: map[string]string{},
+			wantErr:     true,
+		},
+		{
+			name: "reordering without latency",
+			annotations: map[string]string{
+				NetworkReorderValueAnnotation: "25",
+			},
+			wantErr: true,
+		},
+		{
+			name: "jitter without latency",
+			annotations: map[string]string{
+				NetworkLossValueAnnotation:   "5",
+				NetworkJitterValueAnnotation: "10ms",
+			},
+			wantErr: true,
+		},
+		{
+			name: "correlation without latency",
+			annotations: map[string]string{
+				NetworkLossValueAnnotation:        "5",
+				NetworkCorrelationValueAnnotation: "25",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid loss",
+			annotations: map[string]string{
+				NetworkLossValueAnnotation: "120%",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid rate",
+			annotations: map[string]string{
+				NetworkRateValueAnnotation: "fast",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid latency",
+			annotations: map[string]string{
+				NetworkLatencyValueAnnotation: "slow",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid correlation",
+			annotations: map[string]string{
+				NetworkLatencyValueAnnotation:     "100ms",
+				NetworkCorrelationValueAnnotation: "150",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid port",
+			annotations: map[string]string{
+				NetworkLatencyValueAnnotation: "100ms",
+				NetworkPortsAnnotation:        "70000",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNetemSpec(tt.annotations)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseNetemSpec() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseNetemSpec() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetemSpec_Commands(t *testing.T) {
+	tests := []struct {
+		name string
+		spec NetemSpec
+		want [][]string
+	}{
+		{
+			name: "all traffic",
+			spec: NetemSpec{Latency: "100ms", Jitter: "10ms", Correlation: "75"},
+			want: [][]string{
+				{"qdisc", "replace", "dev", "eth0", "root", "handle", "1:", "netem", "delay", "100ms", "10ms", "75%"},
+			},
+		},
+		{
+			name: "reordering and loss",
+			spec: NetemSpec{Latency: "10ms", Reorder: "25", Loss: "5"},
+			want: [][]string{
+				{"qdisc", "replace", "dev", "eth0", "root", "handle", "1:", "netem", "delay", "10ms", "loss", "5%", "reorder", "25%"},
+			},
+		},
+		{
+			name: "rate limit",
+			spec: NetemSpec{Rate: "512kbit"},
+			want: [][]string{
+				{"qdisc", "replace", "dev", "eth0", "root", "handle", "1:", "netem", "rate", "512kbit"},
+			},
+		},
+		{
+			name: "port filter",
+			spec: NetemSpec{Latency: "100ms", Ports: []int{5432}},
+			want: [][]string{
+				{"qdisc", "replace", "dev", "eth0", "root", "handle", "1:", "prio", "bands", "4"},
+				{"qdisc", "replace", "dev", "eth0", "parent", "1:4", "handle", "40:", "netem", "delay", "100ms"},
+				{"filter", "add", "dev", "eth0", "parent", "1:0", "protocol", "ip", "prio", "1", "u32", "match", "ip", "dport", "5432", "0xffff", "flowid", "1:4"},
+				{"filter", "add", "dev", "eth0", "parent", "1:0", "protocol", "ip", "prio", "1", "u32", "match", "ip", "sport", "5432", "0xffff", "flowid", "1:4"},
+				{"filter", "add", "dev", "eth0", "parent", "1:0", "protocol", "ipv6", "prio", "2", "u32", "match", "ip6", "dport", "5432", "0xffff", "flowid", "1:4"},
+				{"filter", "add", "dev", "eth0", "parent", "1:0", "protocol", "ipv6", "prio", "2", "u32", "match", "ip6", "sport", "5432", "0xffff", "flowid", "1:4"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.spec.Commands("eth0")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NetemSpec.Commands() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
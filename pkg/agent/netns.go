@@ -0,0 +1,168 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FindPodPID returns the PID of a process belonging to the pod with the given
+// UID by scanning the cgroup membership of every process under procRoot. Both
+// the cgroupfs ("pod<uid>") and systemd ("pod<uid_with_underscores>") cgroup
+// drivers are recognised.
+func FindPodPID(procRoot, podUID string) (int, error) {
+	return findPID(procRoot, podUID, "")
+}
+
+// FindContainerPID returns the PID of a process running in the given container
+// of a pod. containerID may carry the runtime prefix reported in the pod
+// status, e.g. "containerd://<id>".
+func FindContainerPID(procRoot, podUID, containerID string) (int, error) {
+	containerID = trimRuntimePrefix(containerID)
+	if containerID == "" {
+		return 0, fmt.Errorf("container ID is required")
+	}
+	return findPID(procRoot, podUID, containerID)
+}
+
+// FindContainerPIDs returns the PIDs of all processes running in the given
+// container of a pod, in ascending order
+func FindContainerPIDs(procRoot, podUID, containerID string) ([]int, error) {
+	containerID = trimRuntimePrefix(containerID)
+	if containerID == "" {
+		return nil, fmt.Errorf("container ID is required")
+	}
+	pids, err := scanPIDs(procRoot, podUID, containerID, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(pids) == 0 {
+		return nil, fmt.Errorf("no process found for container %s of pod %s", containerID, podUID)
+	}
+	sort.Ints(pids)
+	return pids, nil
+}
+
+// trimRuntimePrefix strips the runtime prefix from a container ID
+func trimRuntimePrefix(containerID string) string {
+	if i := strings.Index(containerID, "://"); i >= 0 {
+		return containerID[i+3:]
+	}
+	return containerID
+}
+
+// findPID scans procRoot for a process whose cgroup belongs to the pod and,
+// when containerID is set, to that container
+func findPID(procRoot, podUID, containerID string) (int, error) {
+	pids, err := scanPIDs(procRoot, podUID, containerID, true)
+	if err != nil {
+		return 0, err
+	}
+	if len(pids) > 0 {
+		return pids[0], nil
+	}
+
+	if containerID != "" {
+		return 0, fmt.Errorf("no process found for container %s of pod %s", containerID, podUID)
+	}
+	return 0, fmt.Errorf("no process found for pod %s", podUID)
+}
+
+// scanPIDs returns the processes under procRoot whose cgroup belongs to the
+// pod and, when containerID is set, to that container. With first set the
+// scan stops at the first match.
+func scanPIDs(procRoot, podUID, containerID string, first bool) ([]int, error) {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", procRoot, err)
+	}
+
+	patterns := []string{
+		"pod" + podUID,
+		"pod" + strings.ReplaceAll(podUID, "-", "_"),
+	}
+
+	var pids []int
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(procRoot, entry.Name(), "cgroup"))
+		if err != nil {
+			// The process may have exited while scanning
+			continue
+		}
+
+		cgroups := string(data)
+		if containerID != "" && !strings.Contains(cgroups, containerID) {
+			continue
+		}
+		for _, pattern := range patterns {
+			if strings.Contains(cgroups, pattern) {
+				pids = append(pids, pid)
+				break
+			}
+		}
+		if first && len(pids) > 0 {
+			break
+		}
+	}
+
+	return pids, nil
+}
+
+// CgroupPath returns the unified (cgroup v2) hierarchy path of a process
+func CgroupPath(procRoot string, pid int) (string, error) {
+	data, err := os.ReadFile(filepath.Join(procRoot, strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read cgroup of process %d: %w", pid, err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "0::") {
+			return strings.TrimPrefix(line, "0::"), nil
+		}
+	}
+	return "", fmt.Errorf("process %d is not in a cgroup v2 hierarchy", pid)
+}
+
+// MountDevice returns the "major:minor" device number backing the mount point
+// that contains path, as seen from the mount namespace of the given process
+func MountDevice(procRoot string, pid int, path string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(procRoot, strconv.Itoa(pid), "mountinfo"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read mountinfo of process %d: %w", pid, err)
+	}
+
+	// mountinfo fields: id parent major:minor root mountpoint options ...
+	device, longest := "", -1
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+		mountPoint := fields[4]
+		if !pathWithin(path, mountPoint) || len(mountPoint) <= longest {
+			continue
+		}
+		device, longest = fields[2], len(mountPoint)
+	}
+
+	if device == "" {
+		return "", fmt.Errorf("no mount found for %s", path)
+	}
+	return device, nil
+}
+
+// pathWithin reports whether path is dir or lies below it
+func pathWithin(path, dir string) bool {
+	path, dir = filepath.Clean(path), filepath.Clean(dir)
+	if dir == "/" || path == dir {
+		return true
+	}
+	return strings.HasPrefix(path, dir+"/")
+}
@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindPodPID(t *testing.T) {
+	tests := []struct {
+		name    string
+		cgroups map[string]string
+		podUID  string
+		wantPID int
+		wantErr bool
+	}{
+		{
+			name: "cgroupfs driver",
+			cgroups: map[string]string{
+				"1":   "0::/init.scope\n",
+				"420": "0::/kubepods/besteffort/pod1234-abcd/0123456789\n",
+			},
+			podUID:  "1234-abcd",
+			wantPID: 420,
+		},
+		{
+			name: "systemd driver",
+			cgroups: map[string]string{
+				"1":   "0::/init.scope\n",
+				"777": "0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1234_abcd.slice/cri-containerd-0123.scope\n",
+			},
+			podUID:  "1234-abcd",
+			wantPID: 777,
+		},
+		{
+			name: "pod not on node",
+			cgroups: map[string]string{
+				"1": "0::/init.scope\n",
+			},
+			podUID:  "1234-abcd",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			procRoot := t.TempDir()
+			for pid, cgroup := range tt.cgroups {
+				dir := filepath.Join(procRoot, pid)
+				if err := os.MkdirAll(dir, 0o755); err != nil {
+					t.Fatalf("Failed to create proc dir: %v", err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, "cgroup"), []byte(cgroup), 0o600); err != nil {
+					t.Fatalf("Failed to write cgroup file: %v", err)
+				}
+			}
+			// Non-process entries must be ignored
+			if err := os.MkdirAll(filepath.Join(procRoot, "sys"), 0o755); err != nil {
+				t.Fatalf("Failed to create proc dir: %v", err)
+			}
+
+			got, err := FindPodPID(procRoot, tt.podUID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FindPodPID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.wantPID {
+				t.Errorf("FindPodPID() = %v, want %v", got, tt.wantPID)
+			}
+		})
+	}
+}
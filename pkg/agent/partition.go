@@ -0,0 +1,209 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// partitionChain is an iptables chain dropping the partitioned traffic of one
+// direction, jumped to from the built-in chain the direction passes through
+type partitionChain struct {
+	name      string
+	hook      string
+	direction string
+	iface     string
+	peer      string
+}
+
+// partitionChains are the chains of the ingress and egress directions
+var partitionChains = []partitionChain{
+	{name: "HAVOCK8S-PARTITION-IN", hook: "INPUT", direction: PartitionDirectionIngress, iface: "-i", peer: "-s"},
+	{name: "HAVOCK8S-PARTITION-OUT", hook: "OUTPUT", direction: PartitionDirectionEgress, iface: "-o", peer: "-d"},
+}
+
+// ParsePartitionDirection validates a partition direction, defaulting to both
+func ParsePartitionDirection(value string) (string, error) {
+	switch value {
+	case "":
+		return PartitionDirectionBoth, nil
+	case PartitionDirectionIngress, PartitionDirectionEgress, PartitionDirectionBoth:
+		return value, nil
+	}
+	return "", fmt.Errorf("invalid partition direction %q: must be ingress, egress or both", value)
+}
+
+// ParseCIDRs parses a comma separated list of CIDRs. Plain addresses are
+// turned into single host CIDRs.
+func ParseCIDRs(value string) ([]string, error) {
+	var cidrs []string
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if ip := net.ParseIP(field); ip != nil {
+			if ip.To4() != nil {
+				field += "/32"
+			} else {
+				field += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q", field)
+		}
+		cidrs = append(cidrs, network.String())
+	}
+	return cidrs, nil
+}
+
+// NetworkPartitionFault drops the traffic between a pod and a set of CIDRs
+// with iptables rules inside the pod's network namespace. Unlike a
+// NetworkPolicy the rules drop every packet, including the replies of
+// connections the pod opened itself, so one direction can be cut on its own.
+type NetworkPartitionFault struct{}
+
+// Name returns the name of the fault
+func (f *NetworkPartitionFault) Name() string {
+	return "network-partition"
+}
+
+// AckAnnotation returns the annotation used to acknowledge the fault
+func (f *NetworkPartitionFault) AckAnnotation() string {
+	return NetworkPartitionAckAnnotation
+}
+
+// Requested reports whether the pod asks for a network partition
+func (f *NetworkPartitionFault) Requested(pod *corev1.Pod) bool {
+	return pod.Annotations[NetworkPartitionAnnotation] == "true"
+}
+
+// Apply installs the partition rules. The returned state lists the iptables
+// commands rules were installed with, even when applying fails part way.
+func (f *NetworkPartitionFault) Apply(ctx context.Context, target Target, pod *corev1.Pod) (string, error) {
+	direction, err := ParsePartitionDirection(pod.Annotations[NetworkPartitionDirectionAnnotation])
+	if err != nil {
+		return "", err
+	}
+	cidrs, err := ParseCIDRs(pod.Annotations[NetworkPartitionCIDRsAnnotation])
+	if err != nil {
+		return "", err
+	}
+	if len(cidrs) == 0 {
+		cidrs = everything(pod)
+	}
+
+	// IPv4 and IPv6 rules live in separate tables
+	byCommand := map[string][]string{}
+	for _, cidr := range cidrs {
+		command := "iptables"
+		if strings.Contains(cidr, ":") {
+			command = "ip6tables"
+		}
+		byCommand[command] = append(byCommand[command], cidr)
+	}
+
+	var applied []string
+	for _, command := range []string{"iptables", "ip6tables"} {
+		if len(byCommand[command]) == 0 {
+			continue
+		}
+		applied = append(applied, command)
+		state := strings.Join(applied, ",")
+
+		// Start from clean chains so re-applying is idempotent
+		if err := f.removeChains(ctx, target, command); err != nil {
+			return state, err
+		}
+		for _, chain := range partitionChains {
+			if direction != PartitionDirectionBoth && direction != chain.direction {
+				continue
+			}
+			if err := f.installChain(ctx, target, command, chain, byCommand[command]); err != nil {
+				return state, err
+			}
+		}
+	}
+	return strings.Join(applied, ","), nil
+}
+
+// Remove deletes the partition rules of the iptables commands listed in state
+func (f *NetworkPartitionFault) Remove(ctx context.Context, target Target, pod *corev1.Pod, state string) error {
+	for _, command := range strings.Split(state, ",") {
+		if command == "" {
+			continue
+		}
+		if err := f.removeChains(ctx, target, command); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// installChain creates a chain dropping traffic to or from the CIDRs and
+// hooks it into the built-in chain. Loopback traffic is left alone.
+func (f *NetworkPartitionFault) installChain(ctx context.Context, target Target, command string, chain partitionChain, cidrs []string) error {
+	rules := [][]string{
+		{"-N", chain.name},
+		{"-A", chain.name, chain.iface, "lo", "-j", "RETURN"},
+	}
+	for _, cidr := range cidrs {
+		rules = append(rules, []string{"-A", chain.name, chain.peer, cidr, "-j", "DROP"})
+	}
+	rules = append(rules, []string{"-I", chain.hook, "-j", chain.name})
+
+	for _, args := range rules {
+		if _, err := target.RunInNetNS(ctx, command, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeChains unhooks, flushes and deletes the partition chains, ignoring
+// the errors iptables reports for chains that do not exist
+func (f *NetworkPartitionFault) removeChains(ctx context.Context, target Target, command string) error {
+	for _, chain := range partitionChains {
+		for _, args := range [][]string{
+			{"-D", chain.hook, "-j", chain.name},
+			{"-F", chain.name},
+			{"-X", chain.name},
+		} {
+			out, err := target.RunInNetNS(ctx, command, args...)
+			if err != nil && !missingChain(string(out)+err.Error()) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// missingChain reports whether an iptables error says the chain or rule does not exist
+func missingChain(msg string) bool {
+	return strings.Contains(msg, "No chain/target/match") ||
+		strings.Contains(msg, "does a matching rule exist") ||
+		strings.Contains(msg, "doesn't exist")
+}
+
+// everything returns the CIDRs covering all addresses of the pod's IP families
+func everything(pod *corev1.Pod) []string {
+	var cidrs []string
+	for _, podIP := range pod.Status.PodIPs {
+		ip := net.ParseIP(podIP.IP)
+		switch {
+		case ip == nil:
+		case ip.To4() != nil:
+			cidrs = append(cidrs, "0.0.0.0/0")
+		default:
+			cidrs = append(cidrs, "::/0")
+		}
+	}
+	if len(cidrs) == 0 {
+		cidrs = []string{"0.0.0.0/0"}
+	}
+	return cidrs
+}
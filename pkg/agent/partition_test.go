@@ -0,0 +1,144 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// missingChainExecutor fails deleting chains that were never created
+type missingChainExecutor struct {
+	fakeExecutor
+}
+
+func (e *missingChainExecutor) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	out, err := e.fakeExecutor.Run(ctx, name, args...)
+	for _, arg := range args {
+		if arg == "-D" || arg == "-F" || arg == "-X" {
+			return []byte("iptables: No chain/target/match by that name."), errors.New("exit status 1")
+		}
+	}
+	return out, err
+}
+
+func TestNetworkPartitionFault_Apply(t *testing.T) {
+	const ns = "nsenter --target 42 --net -- "
+	cleanup := func(command string) []string {
+		return []string{
+			ns + command + " -D INPUT -j HAVOCK8S-PARTITION-IN",
+			ns + command + " -F HAVOCK8S-PARTITION-IN",
+			ns + command + " -X HAVOCK8S-PARTITION-IN",
+			ns + command + " -D OUTPUT -j HAVOCK8S-PARTITION-OUT",
+			ns + command + " -F HAVOCK8S-PARTITION-OUT",
+			ns + command + " -X HAVOCK8S-PARTITION-OUT",
+		}
+	}
+
+	tests := []struct {
+		name         string
+		annotations  map[string]string
+		podIPs       []corev1.PodIP
+		wantCommands []string
+		wantState    string
+		wantErr      bool
+	}{
+		{
+			name: "ingress from peers",
+			annotations: map[string]string{
+				NetworkPartitionDirectionAnnotation: "ingress",
+				NetworkPartitionCIDRsAnnotation:     "10.0.0.7,10.0.1.0/24",
+			},
+			wantCommands: append(cleanup("iptables"),
+				ns+"iptables -N HAVOCK8S-PARTITION-IN",
+				ns+"iptables -A HAVOCK8S-PARTITION-IN -i lo -j RETURN",
+				ns+"iptables -A HAVOCK8S-PARTITION-IN -s 10.0.0.7/32 -j DROP",
+				ns+"iptables -A HAVOCK8S-PARTITION-IN -s 10.0.1.0/24 -j DROP",
+				ns+"iptables -I INPUT -j HAVOCK8S-PARTITION-IN",
+			),
+			wantState: "iptables",
+		},
+		{
+			name:        "both directions from everything on a dual stack pod",
+			annotations: map[string]string{},
+			podIPs:      []corev1.PodIP{{IP: "10.0.0.5"}, {IP: "fd00::5"}},
+			wantCommands: append(append(append(cleanup("iptables"),
+				ns+"iptables -N HAVOCK8S-PARTITION-IN",
+				ns+"iptables -A HAVOCK8S-PARTITION-IN -i lo -j RETURN",
+				ns+"iptables -A HAVOCK8S-PARTITION-IN -s 0.0.0.0/0 -j DROP",
+				ns+"iptables -I INPUT -j HAVOCK8S-PARTITION-IN",
+				ns+"iptables -N HAVOCK8S-PARTITION-OUT",
+				ns+"iptables -A HAVOCK8S-PARTITION-OUT -o lo -j RETURN",
+				ns+"iptables -A HAVOCK8S-PARTITION-OUT -d 0.0.0.0/0 -j DROP",
+				ns+"iptables -I OUTPUT -j HAVOCK8S-PARTITION-OUT",
+			), cleanup("ip6tables")...),
+				ns+"ip6tables -N HAVOCK8S-PARTITION-IN",
+				ns+"ip6tables -A HAVOCK8S-PARTITION-IN -i lo -j RETURN",
+				ns+"ip6tables -A HAVOCK8S-PARTITION-IN -s ::/0 -j DROP",
+				ns+"ip6tables -I INPUT -j HAVOCK8S-PARTITION-IN",
+				ns+"ip6tables -N HAVOCK8S-PARTITION-OUT",
+				ns+"ip6tables -A HAVOCK8S-PARTITION-OUT -o lo -j RETURN",
+				ns+"ip6tables -A HAVOCK8S-PARTITION-OUT -d ::/0 -j DROP",
+				ns+"ip6tables -I OUTPUT -j HAVOCK8S-PARTITION-OUT",
+			),
+			wantState: "iptables,ip6tables",
+		},
+		{
+			name:        "invalid direction",
+			annotations: map[string]string{NetworkPartitionDirectionAnnotation: "sideways"},
+			wantErr:     true,
+		},
+		{
+			name:        "invalid CIDR",
+			annotations: map[string]string{NetworkPartitionCIDRsAnnotation: "10.0.0.0/33"},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations := map[string]string{NetworkPartitionAnnotation: "true"}
+			for k, v := range tt.annotations {
+				annotations[k] = v
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default", Annotations: annotations},
+				Status:     corev1.PodStatus{PodIPs: tt.podIPs},
+			}
+
+			executor := &missingChainExecutor{}
+			target := Target{PID: 42, PodUID: "1234-abcd", Exec: executor}
+			fault := &NetworkPartitionFault{}
+
+			state, err := fault.Apply(context.Background(), target, pod)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NetworkPartitionFault.Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if got, want := strings.Join(executor.commands, "\n"), strings.Join(tt.wantCommands, "\n"); got != want {
+				t.Errorf("Commands =\n%s\nwant\n%s", got, want)
+			}
+			if state != tt.wantState {
+				t.Errorf("State = %q, want %q", state, tt.wantState)
+			}
+
+			executor.commands = nil
+			if err := fault.Remove(context.Background(), target, pod, state); err != nil {
+				t.Fatalf("NetworkPartitionFault.Remove() error = %v", err)
+			}
+			var wantRemove []string
+			for _, command := range strings.Split(tt.wantState, ",") {
+				wantRemove = append(wantRemove, cleanup(command)...)
+			}
+			if got, want := strings.Join(executor.commands, "\n"), strings.Join(wantRemove, "\n"); got != want {
+				t.Errorf("Remove commands =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/havock8s/havock8s/pkg/proxy"
+)
+
+// StateDelayProxyPort is the port the proxy listens on inside the pod
+const StateDelayProxyPort = 15999
+
+// stateDelayChain is the nat chain redirecting the database port to the proxy
+const stateDelayChain = "HAVOCK8S-STATE-DELAY"
+
+// proxyStartTimeout is how long Apply waits for the proxy to listen by default
+const proxyStartTimeout = 5 * time.Second
+
+// StateDelayFault puts the havock8s-proxy in front of a pod's database. The
+// proxy runs in the pod's network namespace and iptables redirects the
+// connections reaching the database port to it, so the workload needs no
+// sidecar. Connections made over loopback inside the pod are not redirected.
+type StateDelayFault struct {
+	// ProxyBinary is the path of the havock8s-proxy binary
+	ProxyBinary string
+
+	// startTimeout bounds how long Apply waits for the proxy to listen,
+	// proxyStartTimeout when zero
+	startTimeout time.Duration
+}
+
+// Name returns the name of the fault
+func (f *StateDelayFault) Name() string {
+	return "state-delay"
+}
+
+// AckAnnotation returns the annotation used to acknowledge the fault
+func (f *StateDelayFault) AckAnnotation() string {
+	return StateDelayAckAnnotation
+}
+
+// Requested reports whether the pod asks for state delay
+func (f *StateDelayFault) Requested(pod *corev1.Pod) bool {
+	return pod.Annotations[StateDelayAnnotation] == "true"
+}
+
+// Apply starts the proxy and redirects the database port to it once it
+// listens. The returned state is the redirected port.
+func (f *StateDelayFault) Apply(ctx context.Context, target Target, pod *corev1.Pod) (string, error) {
+	config, port, err := StateDelayConfig(pod.Annotations)
+	if err != nil {
+		return "", err
+	}
+
+	// Start from no proxy so re-applying is idempotent
+	if err := f.Remove(ctx, target, pod, port); err != nil {
+		return "", err
+	}
+
+	args := []string{
+		"nsenter", "--target", strconv.Itoa(target.PID), "--net", "--",
+		f.ProxyBinary,
+		"--listen", ":" + strconv.Itoa(StateDelayProxyPort),
+		"--upstream", config.Upstream,
+		"--protocol", config.Protocol,
+		"--operations", strings.Join(config.Operations, ","),
+		"--action", config.Action,
+		"--delay", config.Delay.String(),
+		"--percentage", strconv.Itoa(config.Percentage),
+		"--pod-uid", string(pod.UID),
+	}
+	quoted := make([]string, len(args))
+	for idx, arg := range args {
+		quoted[idx] = shellQuote(arg)
+	}
+	// setsid detaches the proxy from the agent, so it keeps serving the
+	// connections it holds if the agent restarts
+	script := "exec </dev/null >/dev/null 2>&1; exec " + strings.Join(quoted, " ")
+	if _, err := target.Exec.Run(ctx, "setsid", "-f", "sh", "-c", script); err != nil {
+		return port, err
+	}
+
+	timeout := f.startTimeout
+	if timeout == 0 {
+		timeout = proxyStartTimeout
+	}
+	if err := waitListening(ctx, target, StateDelayProxyPort, timeout); err != nil {
+		return port, err
+	}
+
+	for _, args := range [][]string{
+		{"-t", "nat", "-N", stateDelayChain},
+		{"-t", "nat", "-A", stateDelayChain, "-p", "tcp", "--dport", port,
+			"-j", "REDIRECT", "--to-ports", strconv.Itoa(StateDelayProxyPort)},
+		{"-t", "nat", "-I", "PREROUTING", "-j", stateDelayChain},
+	} {
+		if _, err := target.RunInNetNS(ctx, "iptables", args...); err != nil {
+			return port, err
+		}
+	}
+	return port, nil
+}
+
+// Remove deletes the redirect, then stops the pod's proxies
+func (f *StateDelayFault) Remove(ctx context.Context, target Target, pod *corev1.Pod, state string) error {
+	for _, args := range [][]string{
+		{"-t", "nat", "-D", "PREROUTING", "-j", stateDelayChain},
+		{"-t", "nat", "-F", stateDelayChain},
+		{"-t", "nat", "-X", stateDelayChain},
+	} {
+		out, err := target.RunInNetNS(ctx, "iptables", args...)
+		if err != nil && !missingChain(string(out)+err.Error()) {
+			return err
+		}
+	}
+
+	pids, err := f.proxyPIDs(target.ProcRoot, string(pod.UID))
+	if err != nil || len(pids) == 0 {
+		return err
+	}
+	out, err := target.Exec.Run(ctx, "kill", append([]string{"-s", "TERM"}, pids...)...)
+	if err != nil && !strings.Contains(string(out)+err.Error(), "No such process") {
+		return err
+	}
+	return nil
+}
+
+// proxyPIDs returns the PIDs of the proxies started for a pod
+func (f *StateDelayFault) proxyPIDs(procRoot, podUID string) ([]string, error) {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	marker := []byte("--pod-uid\x00" + podUID + "\x00")
+	var pids []string
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+		cmdline, err := os.ReadFile(filepath.Join(procRoot, entry.Name(), "cmdline"))
+		if err != nil {
+			// The process may have exited since the listing
+			continue
+		}
+		if bytes.HasPrefix(cmdline, []byte(f.ProxyBinary+"\x00")) && bytes.Contains(cmdline, marker) {
+			pids = append(pids, entry.Name())
+		}
+	}
+	return pids, nil
+}
+
+// StateDelayConfig returns the proxy configuration and the database port
+// requested by a pod's annotations
+func StateDelayConfig(annotations map[string]string) (proxy.Config, string, error) {
+	config := proxy.Config{
+		Protocol:   annotations[StateDelayProtocolAnnotation],
+		Operations: proxy.ParseOperations(annotations[StateDelayOperationsAnnotation]),
+		Action:     annotations[StateDelayActionAnnotation],
+	}
+
+	port := annotations[StateDelayPortAnnotation]
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 || n == StateDelayProxyPort {
+		return config, "", fmt.Errorf("invalid database port %q", port)
+	}
+	config.Upstream = "127.0.0.1:" + port
+
+	if value := annotations[StateDelayDelayAnnotation]; value != "" {
+		delay, err := time.ParseDuration(value)
+		if err != nil {
+			return config, "", fmt.Errorf("invalid delay %q: %w", value, err)
+		}
+		config.Delay = delay
+	}
+	percent, err := parsePercentAnnotation(annotations[StateDelayPercentAnnotation])
+	if err != nil {
+		return config, "", err
+	}
+	config.Percentage = percent
+
+	if err := config.Validate(); err != nil {
+		return config, "", err
+	}
+	return config, port, nil
+}
+
+// waitListening waits until a TCP port listens in the target's network namespace
+func waitListening(ctx context.Context, target Target, port int, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		if listening(target.ProcRoot, target.PID, port) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("nothing listens on port %d after %v", port, timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// listening reports whether the network namespace of a process has a TCP
+// socket listening on the port
+func listening(procRoot string, pid, port int) bool {
+	suffix := fmt.Sprintf(":%04X", port)
+	for _, name := range []string{"tcp", "tcp6"} {
+		data, err := os.ReadFile(filepath.Join(procRoot, strconv.Itoa(pid), "net", name))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			// sl local_address rem_address st ..., 0A is LISTEN
+			fields := strings.Fields(line)
+			if len(fields) > 3 && strings.HasSuffix(fields[1], suffix) && fields[3] == "0A" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// setupProxyProcRoot adds a proxy left over from an earlier request, a proxy
+// of another pod, and the sockets of the db pod's network namespace
+func setupProxyProcRoot(t *testing.T, listening bool) string {
+	procRoot := setupKillProcRoot(t)
+	files := map[string]string{
+		"60/cmdline": "/havock8s-proxy\x00--listen\x00:15999\x00--pod-uid\x001234-abcd\x00",
+		"61/cmdline": "/havock8s-proxy\x00--listen\x00:15999\x00--pod-uid\x00other\x00",
+		"40/net/tcp": "  sl  local_address rem_address   st\n" +
+			"   0: 00000000:1538 00000000:0000 0A 00000000:00000000 00:00000000 00000000\n",
+		"40/net/tcp6": "  sl  local_address rem_address   st\n",
+	}
+	if listening {
+		files["40/net/tcp6"] += "   0: 00000000000000000000000000000000:3E7F 00000000000000000000000000000000:0000 0A\n"
+	}
+	for name, content := range files {
+		path := filepath.Join(procRoot, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	return procRoot
+}
+
+func TestStateDelayFault_Apply(t *testing.T) {
+	cleanup := []string{
+		"nsenter --target 40 --net -- iptables -t nat -D PREROUTING -j HAVOCK8S-STATE-DELAY",
+		"nsenter --target 40 --net -- iptables -t nat -F HAVOCK8S-STATE-DELAY",
+		"nsenter --target 40 --net -- iptables -t nat -X HAVOCK8S-STATE-DELAY",
+		"kill -s TERM 60",
+	}
+	redirect := []string{
+		"nsenter --target 40 --net -- iptables -t nat -N HAVOCK8S-STATE-DELAY",
+		"nsenter --target 40 --net -- iptables -t nat -A HAVOCK8S-STATE-DELAY -p tcp --dport 5432 -j REDIRECT --to-ports 15999",
+		"nsenter --target 40 --net -- iptables -t nat -I PREROUTING -j HAVOCK8S-STATE-DELAY",
+	}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		listening   bool
+		wantProxy   string
+		wantErr     bool
+	}{
+		{
+			name: "delays commits",
+			annotations: map[string]string{
+				StateDelayOperationsAnnotation: "commit",
+				StateDelayActionAnnotation:     "delay",
+				StateDelayDelayAnnotation:      "2s",
+			},
+			listening: true,
+			wantProxy: "'--upstream' '127.0.0.1:5432' '--protocol' 'postgres' '--operations' 'commit' " +
+				"'--action' 'delay' '--delay' '2s' '--percentage' '50' '--pod-uid' '1234-abcd'",
+		},
+		{
+			name: "fails writes",
+			annotations: map[string]string{
+				StateDelayOperationsAnnotation: "INSERT, update",
+				StateDelayActionAnnotation:     "error",
+			},
+			listening: true,
+			wantProxy: "'--operations' 'insert,update' '--action' 'error' '--delay' '0s'",
+		},
+		{
+			name: "proxy never listens",
+			annotations: map[string]string{
+				StateDelayOperationsAnnotation: "all",
+				StateDelayActionAnnotation:     "drop",
+			},
+			wantErr: true,
+		},
+		{
+			name: "delay without duration",
+			annotations: map[string]string{
+				StateDelayOperationsAnnotation: "all",
+				StateDelayActionAnnotation:     "delay",
+			},
+			wantErr: true,
+		},
+		{
+			name: "port taken by the proxy",
+			annotations: map[string]string{
+				StateDelayPortAnnotation:       "15999",
+				StateDelayOperationsAnnotation: "all",
+				StateDelayActionAnnotation:     "drop",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations := map[string]string{
+				StateDelayAnnotation:         "true",
+				StateDelayProtocolAnnotation: "postgres",
+				StateDelayPortAnnotation:     "5432",
+				StateDelayPercentAnnotation:  "50",
+			}
+			for k, v := range tt.annotations {
+				annotations[k] = v
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-pod",
+					Namespace:   "default",
+					UID:         types.UID("1234-abcd"),
+					Annotations: annotations,
+				},
+			}
+
+			procRoot := setupProxyProcRoot(t, tt.listening)
+			exec := &fakeExecutor{}
+			target := Target{PID: 40, PodUID: "1234-abcd", ProcRoot: procRoot, Exec: exec}
+			fault := &StateDelayFault{ProxyBinary: "/havock8s-proxy", startTimeout: 200 * time.Millisecond}
+
+			state, err := fault.Apply(context.Background(), target, pod)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("StateDelayFault.Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				for _, command := range exec.commands {
+					if strings.Contains(command, "-I PREROUTING") {
+						t.Errorf("Redirect installed although Apply() failed: %s", command)
+					}
+				}
+				return
+			}
+			if state != "5432" {
+				t.Errorf("State = %q, want the database port", state)
+			}
+
+			if len(exec.commands) != len(cleanup)+1+len(redirect) {
+				t.Fatalf("Commands = %v, want cleanup, proxy start and redirect", exec.commands)
+			}
+			for idx, want := range cleanup {
+				if exec.commands[idx] != want {
+					t.Errorf("Command %d = %q, want %q", idx, exec.commands[idx], want)
+				}
+			}
+			start := exec.commands[len(cleanup)]
+			if !strings.HasPrefix(start, "setsid -f sh -c exec </dev/null >/dev/null 2>&1; exec 'nsenter' '--target' '40' '--net' '--' '/havock8s-proxy' '--listen' ':15999'") ||
+				!strings.Contains(start, tt.wantProxy) {
+				t.Errorf("Proxy started with %q, want %q", start, tt.wantProxy)
+			}
+			for idx, want := range redirect {
+				if got := exec.commands[len(cleanup)+1+idx]; got != want {
+					t.Errorf("Redirect command %d = %q, want %q", idx, got, want)
+				}
+			}
+		})
+	}
+}
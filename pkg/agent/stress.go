@@ -0,0 +1,201 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// stressIOBytes is how much each io worker writes before starting over
+const stressIOBytes = "256m"
+
+// ResourcePressureFault runs stress-ng inside the cgroup of a container, so
+// the stressors compete with the container for its CPU quota, memory limit
+// and I/O bandwidth. The stressors exit by themselves at the requested
+// deadline, even if the agent is gone by then.
+type ResourcePressureFault struct {
+	// CgroupRoot is where the host's cgroup v2 hierarchy is mounted
+	CgroupRoot string
+
+	// now returns the current time, time.Now when nil
+	now func() time.Time
+}
+
+// Name returns the name of the fault
+func (f *ResourcePressureFault) Name() string {
+	return "resource-pressure"
+}
+
+// AckAnnotation returns the annotation used to acknowledge the fault
+func (f *ResourcePressureFault) AckAnnotation() string {
+	return ResourcePressureAckAnnotation
+}
+
+// Requested reports whether the pod asks for resource pressure
+func (f *ResourcePressureFault) Requested(pod *corev1.Pod) bool {
+	return pod.Annotations[ResourcePressureAnnotation] == "true"
+}
+
+// Apply starts the stressors. The returned state is the container's cgroup,
+// where Remove finds the stressors again.
+func (f *ResourcePressureFault) Apply(ctx context.Context, target Target, pod *corev1.Pod) (string, error) {
+	deadline, err := time.Parse(time.RFC3339, pod.Annotations[ResourcePressureDeadlineAnnotation])
+	if err != nil {
+		return "", fmt.Errorf("annotation %s must be an RFC 3339 time", ResourcePressureDeadlineAnnotation)
+	}
+	timeout := int64(math.Ceil(deadline.Sub(f.currentTime()).Seconds()))
+	if timeout <= 0 {
+		// The pressure period is already over
+		return "", nil
+	}
+
+	container := pod.Annotations[ResourcePressureContainerAnnotation]
+	if container == "" {
+		if len(pod.Spec.Containers) == 0 {
+			return "", fmt.Errorf("pod %s/%s has no containers", pod.Namespace, pod.Name)
+		}
+		container = pod.Spec.Containers[0].Name
+	}
+	pid, err := containerPID(target, pod, container)
+	if err != nil {
+		return "", err
+	}
+	cgroup, err := CgroupPath(target.ProcRoot, pid)
+	if err != nil {
+		return "", err
+	}
+
+	args, err := stressArgs(pod.Annotations, target.ProcRoot, pid)
+	if err != nil {
+		return "", err
+	}
+	args = append(args, "--timeout", strconv.FormatInt(timeout, 10)+"s")
+
+	// Start from no stressors so re-applying is idempotent
+	if err := f.stopStressors(ctx, target, cgroup); err != nil {
+		return "", err
+	}
+
+	procs := filepath.Join(f.CgroupRoot, cgroup, "cgroup.procs")
+	if _, err := os.Stat(procs); err != nil {
+		return "", fmt.Errorf("cgroup of container %s is not available: %w", container, err)
+	}
+
+	// The shell moves itself into the container's cgroup before turning into
+	// stress-ng, so the stressors are accounted to the container from their
+	// first allocation. setsid detaches them from the agent.
+	quoted := make([]string, len(args))
+	for idx, arg := range args {
+		quoted[idx] = shellQuote(arg)
+	}
+	script := fmt.Sprintf("exec </dev/null >/dev/null 2>&1; echo $$ > %s && exec stress-ng %s",
+		shellQuote(procs), strings.Join(quoted, " "))
+	if _, err := target.Exec.Run(ctx, "setsid", "-f", "sh", "-c", script); err != nil {
+		return cgroup, err
+	}
+	return cgroup, nil
+}
+
+// Remove stops the stressors running in the cgroup recorded in state
+func (f *ResourcePressureFault) Remove(ctx context.Context, target Target, pod *corev1.Pod, state string) error {
+	if state == "" {
+		// Nothing was started
+		return nil
+	}
+	return f.stopStressors(ctx, target, state)
+}
+
+// stopStressors kills the stress-ng processes in a cgroup
+func (f *ResourcePressureFault) stopStressors(ctx context.Context, target Target, cgroup string) error {
+	data, err := os.ReadFile(filepath.Join(f.CgroupRoot, cgroup, "cgroup.procs"))
+	if os.IsNotExist(err) {
+		// The cgroup went away together with the stressors
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	args := []string{"-s", "KILL"}
+	for _, field := range strings.Fields(string(data)) {
+		comm, err := os.ReadFile(filepath.Join(target.ProcRoot, field, "comm"))
+		if err != nil {
+			// The process may have exited since the read
+			continue
+		}
+		if strings.HasPrefix(string(comm), "stress-ng") {
+			args = append(args, field)
+		}
+	}
+	if len(args) == 2 {
+		return nil
+	}
+
+	out, err := target.Exec.Run(ctx, "kill", args...)
+	if err != nil && !strings.Contains(string(out)+err.Error(), "No such process") {
+		return err
+	}
+	return nil
+}
+
+// currentTime returns the current time
+func (f *ResourcePressureFault) currentTime() time.Time {
+	if f.now != nil {
+		return f.now()
+	}
+	return time.Now()
+}
+
+// stressArgs returns the stress-ng stressor arguments for the requested
+// pressure. io workers write below the container's root, found through pid.
+func stressArgs(annotations map[string]string, procRoot string, pid int) ([]string, error) {
+	workers := 1
+	if value := annotations[ResourcePressureWorkersAnnotation]; value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid worker count %q", value)
+		}
+		workers = n
+	}
+
+	switch resourceType := annotations[ResourcePressureTypeAnnotation]; resourceType {
+	case ResourceTypeCPU:
+		load, err := parsePercentAnnotation(annotations[ResourcePressureLoadAnnotation])
+		if err != nil {
+			return nil, err
+		}
+		return []string{"--cpu", strconv.Itoa(workers), "--cpu-load", strconv.Itoa(load)}, nil
+
+	case ResourceTypeMemory:
+		total, err := strconv.ParseInt(annotations[ResourcePressureBytesAnnotation], 10, 64)
+		if err != nil || total < int64(workers) {
+			return nil, fmt.Errorf("invalid memory size %q", annotations[ResourcePressureBytesAnnotation])
+		}
+		// Each worker allocates its share and keeps it mapped
+		perWorker := total / int64(workers)
+		return []string{"--vm", strconv.Itoa(workers), "--vm-bytes", strconv.FormatInt(perWorker, 10), "--vm-keep"}, nil
+
+	case ResourceTypeIO:
+		path := annotations[ResourcePressurePathAnnotation]
+		if path == "" || !filepath.IsAbs(path) {
+			return nil, fmt.Errorf("annotation %s must be an absolute path", ResourcePressurePathAnnotation)
+		}
+		return []string{"--hdd", strconv.Itoa(workers), "--hdd-bytes", stressIOBytes,
+			"--temp-path", containerPath(procRoot, pid, path)}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported resource type: %s", resourceType)
+	}
+}
+
+// shellQuote quotes a word for sh
+func shellQuote(word string) string {
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
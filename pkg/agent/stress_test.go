@@ -0,0 +1,176 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const stressCgroup = "/kubepods/pod1234-abcd/cri-containerd-abc123.scope"
+
+// setupStressCgroupRoot creates the db container's cgroup holding its shell
+// and stressors left over from an earlier request
+func setupStressCgroupRoot(t *testing.T, procRoot string) string {
+	cgroupRoot := t.TempDir()
+	dir := filepath.Join(cgroupRoot, stressCgroup)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("Failed to create cgroup dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cgroup.procs"), []byte("40\n60\n61\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write cgroup.procs: %v", err)
+	}
+	for pid, comm := range map[string]string{"40": "sh\n", "60": "stress-ng\n", "61": "stress-ng-vm\n"} {
+		path := filepath.Join(procRoot, pid, "comm")
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(comm), 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	return cgroupRoot
+}
+
+func TestResourcePressureFault_Apply(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	const kill = "kill -s KILL 60 61"
+
+	tests := []struct {
+		name         string
+		annotations  map[string]string
+		wantCommands []string
+		wantState    string
+		wantErr      bool
+	}{
+		{
+			name: "cpu workers",
+			annotations: map[string]string{
+				ResourcePressureTypeAnnotation:    "cpu",
+				ResourcePressureWorkersAnnotation: "2",
+				ResourcePressureLoadAnnotation:    "80",
+			},
+			wantCommands: []string{kill, "setsid -f sh -c exec </dev/null >/dev/null 2>&1; echo $$ > 'CGROUP/cgroup.procs' && " +
+				"exec stress-ng '--cpu' '2' '--cpu-load' '80' '--timeout' '300s'"},
+			wantState: stressCgroup,
+		},
+		{
+			name: "memory split across workers",
+			annotations: map[string]string{
+				ResourcePressureTypeAnnotation:    "memory",
+				ResourcePressureWorkersAnnotation: "2",
+				ResourcePressureBytesAnnotation:   "1073741824",
+			},
+			wantCommands: []string{kill, "setsid -f sh -c exec </dev/null >/dev/null 2>&1; echo $$ > 'CGROUP/cgroup.procs' && " +
+				"exec stress-ng '--vm' '2' '--vm-bytes' '536870912' '--vm-keep' '--timeout' '300s'"},
+			wantState: stressCgroup,
+		},
+		{
+			name: "io below the container's root",
+			annotations: map[string]string{
+				ResourcePressureTypeAnnotation: "io",
+				ResourcePressurePathAnnotation: "/var/lib/data",
+			},
+			wantCommands: []string{kill, "setsid -f sh -c exec </dev/null >/dev/null 2>&1; echo $$ > 'CGROUP/cgroup.procs' && " +
+				"exec stress-ng '--hdd' '1' '--hdd-bytes' '256m' '--temp-path' 'PROC/40/root/var/lib/data' '--timeout' '300s'"},
+			wantState: stressCgroup,
+		},
+		{
+			name: "deadline passed",
+			annotations: map[string]string{
+				ResourcePressureTypeAnnotation:     "cpu",
+				ResourcePressureLoadAnnotation:     "80",
+				ResourcePressureDeadlineAnnotation: "2024-05-01T11:00:00Z",
+			},
+		},
+		{
+			name: "missing deadline",
+			annotations: map[string]string{
+				ResourcePressureTypeAnnotation:     "cpu",
+				ResourcePressureLoadAnnotation:     "80",
+				ResourcePressureDeadlineAnnotation: "",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid load",
+			annotations: map[string]string{
+				ResourcePressureTypeAnnotation: "cpu",
+				ResourcePressureLoadAnnotation: "120",
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported resource type",
+			annotations: map[string]string{
+				ResourcePressureTypeAnnotation: "gpu",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations := map[string]string{
+				ResourcePressureAnnotation:         "true",
+				ResourcePressureDeadlineAnnotation: "2024-05-01T12:05:00Z",
+			}
+			for k, v := range tt.annotations {
+				annotations[k] = v
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-pod",
+					Namespace:   "default",
+					UID:         types.UID("1234-abcd"),
+					Annotations: annotations,
+				},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "db"}}},
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{{Name: "db", ContainerID: "containerd://abc123"}},
+				},
+			}
+
+			procRoot := setupKillProcRoot(t)
+			cgroupRoot := setupStressCgroupRoot(t, procRoot)
+			executor := &fakeExecutor{}
+			target := Target{PID: 40, PodUID: "1234-abcd", ProcRoot: procRoot, Exec: executor}
+			fault := &ResourcePressureFault{CgroupRoot: cgroupRoot, now: func() time.Time { return now }}
+
+			state, err := fault.Apply(context.Background(), target, pod)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResourcePressureFault.Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			replacer := strings.NewReplacer("CGROUP", filepath.Join(cgroupRoot, stressCgroup), "PROC", procRoot)
+			want := replacer.Replace(strings.Join(tt.wantCommands, "\n"))
+			if got := strings.Join(executor.commands, "\n"); got != want {
+				t.Errorf("Commands =\n%s\nwant\n%s", got, want)
+			}
+			if state != tt.wantState {
+				t.Errorf("State = %q, want %q", state, tt.wantState)
+			}
+
+			executor.commands = nil
+			if err := fault.Remove(context.Background(), target, pod, state); err != nil {
+				t.Fatalf("ResourcePressureFault.Remove() error = %v", err)
+			}
+			var wantRemove []string
+			if state != "" {
+				wantRemove = []string{kill}
+			}
+			if got, want := strings.Join(executor.commands, "\n"), strings.Join(wantRemove, "\n"); got != want {
+				t.Errorf("Remove commands = %q, want %q", got, want)
+			}
+		})
+	}
+}
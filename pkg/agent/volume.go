@@ -0,0 +1,194 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// volumeUnmountedDir is the empty directory bind mounted over the mount
+// points of an unmounted volume, relative to the mount path
+const volumeUnmountedDir = ".havock8s-unmounted"
+
+// failedMount records a mount point the agent changed
+type failedMount struct {
+	Container string `json:"container"`
+	MountPath string `json:"mountPath"`
+}
+
+// volumeFailureState records what the agent changed so it can be reverted
+type volumeFailureState struct {
+	Mode   string        `json:"mode"`
+	Mounts []failedMount `json:"mounts"`
+}
+
+// VolumeFailureFault makes a pod volume fail in every container mounting it,
+// either by remounting it read-only or by bind mounting an empty directory
+// over its mount points, which looks like the volume was unmounted. Mount
+// points that were read-only already are left alone.
+type VolumeFailureFault struct{}
+
+// Name returns the name of the fault
+func (f *VolumeFailureFault) Name() string {
+	return "volume-failure"
+}
+
+// AckAnnotation returns the annotation used to acknowledge the fault
+func (f *VolumeFailureFault) AckAnnotation() string {
+	return VolumeFailureAckAnnotation
+}
+
+// Requested reports whether the pod asks for a volume failure
+func (f *VolumeFailureFault) Requested(pod *corev1.Pod) bool {
+	return pod.Annotations[VolumeFailureAnnotation] == "true"
+}
+
+// Apply injects the volume failure. The returned state lists every changed
+// mount point, even when applying fails part way, so that Remove can revert
+// them.
+func (f *VolumeFailureFault) Apply(ctx context.Context, target Target, pod *corev1.Pod) (string, error) {
+	state := volumeFailureState{Mode: pod.Annotations[VolumeFailureModeAnnotation]}
+	if state.Mode != VolumeFailureModeReadOnly && state.Mode != VolumeFailureModeUnmount {
+		return "", fmt.Errorf("unsupported volume failure mode: %s", state.Mode)
+	}
+
+	volume := pod.Annotations[VolumeFailureVolumeAnnotation]
+	mounts := volumeMounts(pod, volume)
+	if len(mounts) == 0 {
+		return "", fmt.Errorf("no container of pod %s/%s mounts volume %q", pod.Namespace, pod.Name, volume)
+	}
+
+	for _, mount := range mounts {
+		pid, err := containerPID(target, pod, mount.Container)
+		if err != nil {
+			return encodeVolumeState(state), err
+		}
+		_, options, err := mountPoint(target.ProcRoot, pid, mount.MountPath)
+		if err != nil {
+			return encodeVolumeState(state), err
+		}
+		if slices.Contains(strings.Split(options, ","), "ro") {
+			// Nothing to fail, and nothing to restore
+			continue
+		}
+
+		switch state.Mode {
+		case VolumeFailureModeReadOnly:
+			if _, err := target.RunInMountNS(ctx, pid, "mount", "-o", "remount,bind,ro", mount.MountPath); err != nil {
+				return encodeVolumeState(state), err
+			}
+		case VolumeFailureModeUnmount:
+			empty := filepath.Join(mount.MountPath, volumeUnmountedDir)
+			if err := os.Mkdir(containerPath(target.ProcRoot, pid, empty), 0o755); err != nil && !os.IsExist(err) {
+				return encodeVolumeState(state), fmt.Errorf("failed to create %s: %w", empty, err)
+			}
+			// Recorded before mounting so the directory is removed even
+			// when the mount fails
+			state.Mounts = append(state.Mounts, mount)
+			if _, err := target.RunInMountNS(ctx, pid, "mount", "--bind", empty, mount.MountPath); err != nil {
+				return encodeVolumeState(state), err
+			}
+			continue
+		}
+		state.Mounts = append(state.Mounts, mount)
+	}
+
+	return encodeVolumeState(state), nil
+}
+
+// Remove reverts the volume failure recorded in state. Containers that
+// restarted in the meantime came back with the volume mounted as before.
+func (f *VolumeFailureFault) Remove(ctx context.Context, target Target, pod *corev1.Pod, state string) error {
+	if state == "" {
+		// Nothing was changed
+		return nil
+	}
+
+	var applied volumeFailureState
+	if err := json.Unmarshal([]byte(state), &applied); err != nil {
+		return fmt.Errorf("invalid volume failure state: %w", err)
+	}
+
+	for _, mount := range applied.Mounts {
+		pid, err := containerPID(target, pod, mount.Container)
+		if err != nil {
+			return err
+		}
+
+		switch applied.Mode {
+		case VolumeFailureModeReadOnly:
+			if _, err := target.RunInMountNS(ctx, pid, "mount", "-o", "remount,bind,rw", mount.MountPath); err != nil {
+				return err
+			}
+		case VolumeFailureModeUnmount:
+			// Only unmount the empty directory, never the volume beneath it
+			root, _, err := mountPoint(target.ProcRoot, pid, mount.MountPath)
+			if err != nil {
+				return err
+			}
+			if strings.HasSuffix(root, "/"+volumeUnmountedDir) {
+				if _, err := target.RunInMountNS(ctx, pid, "umount", mount.MountPath); err != nil {
+					return err
+				}
+			}
+			empty := containerPath(target.ProcRoot, pid, filepath.Join(mount.MountPath, volumeUnmountedDir))
+			if err := os.Remove(empty); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// volumeMounts returns where the containers of a pod mount a volume
+func volumeMounts(pod *corev1.Pod, volume string) []failedMount {
+	var mounts []failedMount
+	for _, container := range pod.Spec.Containers {
+		for _, mount := range container.VolumeMounts {
+			if mount.Name == volume {
+				mounts = append(mounts, failedMount{Container: container.Name, MountPath: mount.MountPath})
+			}
+		}
+	}
+	return mounts
+}
+
+// mountPoint returns the root within its filesystem and the mount options of
+// the topmost mount at path, as seen from the mount namespace of the given
+// process
+func mountPoint(procRoot string, pid int, path string) (root, options string, err error) {
+	data, err := os.ReadFile(filepath.Join(procRoot, strconv.Itoa(pid), "mountinfo"))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read mountinfo of process %d: %w", pid, err)
+	}
+
+	// mountinfo fields: id parent major:minor root mountpoint options ...
+	// Later lines are mounted on top of earlier ones.
+	found := false
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 6 || filepath.Clean(fields[4]) != filepath.Clean(path) {
+			continue
+		}
+		root, options, found = fields[3], fields[5], true
+	}
+
+	if !found {
+		return "", "", fmt.Errorf("%s is not a mount point", path)
+	}
+	return root, options, nil
+}
+
+// encodeVolumeState serialises the volume failure state
+func encodeVolumeState(state volumeFailureState) string {
+	data, _ := json.Marshal(state)
+	return string(data)
+}
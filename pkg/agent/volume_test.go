@@ -0,0 +1,147 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestVolumeFailureFault_ApplyAndRemove(t *testing.T) {
+	tests := []struct {
+		name           string
+		mode           string
+		volume         string
+		mountInfo      string
+		appliedMount   string
+		wantErr        bool
+		wantCommands   []string
+		wantRemoveCmds []string
+	}{
+		{
+			name:           "readonly remounts every mount point",
+			mode:           VolumeFailureModeReadOnly,
+			volume:         "data",
+			wantCommands:   []string{"nsenter --target 42 --mount -- mount -o remount,bind,ro /data"},
+			wantRemoveCmds: []string{"nsenter --target 42 --mount -- mount -o remount,bind,rw /data"},
+		},
+		{
+			name:      "read-only mount points are left alone",
+			mode:      VolumeFailureModeReadOnly,
+			volume:    "data",
+			mountInfo: "25 1 0:50 / / rw - overlay overlay rw\n30 25 8:16 / /data ro - ext4 /dev/sdb ro\n",
+		},
+		{
+			name:           "unmount hides the volume behind an empty directory",
+			mode:           VolumeFailureModeUnmount,
+			volume:         "data",
+			appliedMount:   "40 30 8:16 /" + volumeUnmountedDir + " /data rw - ext4 /dev/sdb rw\n",
+			wantCommands:   []string{"nsenter --target 42 --mount -- mount --bind /data/" + volumeUnmountedDir + " /data"},
+			wantRemoveCmds: []string{"nsenter --target 42 --mount -- umount /data"},
+		},
+		{
+			name:   "unmount does not unmount a remounted volume",
+			mode:   VolumeFailureModeUnmount,
+			volume: "data",
+			// The container restarted, so the volume is mounted as before
+			wantCommands: []string{"nsenter --target 42 --mount -- mount --bind /data/" + volumeUnmountedDir + " /data"},
+		},
+		{
+			name:    "volume not mounted by any container",
+			mode:    VolumeFailureModeReadOnly,
+			volume:  "logs",
+			wantErr: true,
+		},
+		{
+			name:    "detach is not applied by the agent",
+			mode:    VolumeFailureModeDetach,
+			volume:  "data",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			procRoot, _, _ := setupDiskRoots(t, testHostMountInfo)
+			mountInfoPath := filepath.Join(procRoot, "42", "mountinfo")
+			if tt.mountInfo != "" {
+				if err := os.WriteFile(mountInfoPath, []byte(tt.mountInfo), 0o600); err != nil {
+					t.Fatalf("Failed to write mountinfo: %v", err)
+				}
+			}
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-pod",
+					Namespace: "default",
+					UID:       types.UID("1234-abcd"),
+					Annotations: map[string]string{
+						VolumeFailureAnnotation:       "true",
+						VolumeFailureModeAnnotation:   tt.mode,
+						VolumeFailureVolumeAnnotation: tt.volume,
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "sidecar"},
+						{Name: "db", VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/data"}}},
+					},
+				},
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{
+						{Name: "sidecar", ContainerID: "containerd://def456"},
+						{Name: "db", ContainerID: "containerd://abc123"},
+					},
+				},
+			}
+
+			executor := &fakeExecutor{}
+			target := Target{PID: 1, PodUID: "1234-abcd", ProcRoot: procRoot, Exec: executor}
+			fault := &VolumeFailureFault{}
+
+			state, err := fault.Apply(context.Background(), target, pod)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VolumeFailureFault.Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if strings.Join(executor.commands, "\n") != strings.Join(tt.wantCommands, "\n") {
+				t.Errorf("Commands = %v, want %v", executor.commands, tt.wantCommands)
+			}
+
+			empty := filepath.Join(procRoot, "42", "root", "data", volumeUnmountedDir)
+			if tt.mode == VolumeFailureModeUnmount {
+				if _, err := os.Stat(empty); err != nil {
+					t.Errorf("Empty directory not created: %v", err)
+				}
+			}
+			if tt.appliedMount != "" {
+				f, err := os.OpenFile(mountInfoPath, os.O_APPEND|os.O_WRONLY, 0)
+				if err != nil {
+					t.Fatalf("Failed to open mountinfo: %v", err)
+				}
+				_, _ = f.WriteString(tt.appliedMount)
+				f.Close()
+			}
+
+			// The request annotations are gone by the time the fault is removed
+			pod.Annotations = nil
+			executor.commands = nil
+			if err := fault.Remove(context.Background(), target, pod, state); err != nil {
+				t.Fatalf("VolumeFailureFault.Remove() error = %v", err)
+			}
+			if strings.Join(executor.commands, "\n") != strings.Join(tt.wantRemoveCmds, "\n") {
+				t.Errorf("Remove commands = %v, want %v", executor.commands, tt.wantRemoveCmds)
+			}
+			if _, err := os.Stat(empty); !os.IsNotExist(err) {
+				t.Errorf("Empty directory still present after removal")
+			}
+		})
+	}
+}
@@ -0,0 +1,370 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/loadgen"
+	"github.com/havock8s/havock8s/pkg/proxy"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultLoadGeneratorImage is the image load generator Jobs run unless the
+// experiment names another. The node agent image ships havock8s-loadgen.
+const DefaultLoadGeneratorImage = "havock8s-agent:latest"
+
+// OverloadLabel marks the load generator Jobs of an experiment and their
+// pods. Its value is the UID of the experiment.
+const OverloadLabel = "havock8s.io/overloaded-by"
+
+// Caps on the connections of a connection overload
+const (
+	// maxOverloadConnections caps the connections opened per experiment
+	maxOverloadConnections = 50000
+
+	// connectionsPerLoadGenerator is how many connections a load generator
+	// pod holds at most, more connections are spread over more pods
+	connectionsPerLoadGenerator = 2000
+)
+
+// ConnectionOverloadInjector implements the Injector interface for
+// connection overload chaos. A Job in each targeted namespace runs
+// havock8s-loadgen, which opens and holds connections against the target
+// Service or pods to exhaust the database's connection slots. The Jobs are
+// deleted on cleanup and stop by themselves when the experiment is due to
+// end.
+type ConnectionOverloadInjector struct {
+	eventRecorder
+	client client.Client
+	log    logr.Logger
+}
+
+// connectionOverloadParams holds the validated connection overload parameters
+type connectionOverloadParams struct {
+	connections    int
+	connectionType string
+	protocol       string
+	port           string
+	service        string
+	user           string
+	interval       time.Duration
+	duration       time.Duration
+	image          string
+}
+
+// NewConnectionOverloadInjector creates a connection overload injector using the given dependencies
+func NewConnectionOverloadInjector(deps Dependencies) *ConnectionOverloadInjector {
+	return &ConnectionOverloadInjector{
+		eventRecorder: eventRecorder{recorder: deps.Recorder},
+		client:        deps.Client,
+		log:           deps.Log,
+	}
+}
+
+// Inject starts the load generator Jobs
+func (i *ConnectionOverloadInjector) Inject(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Injecting connection overload chaos")
+
+	params, err := parseConnectionOverloadParams(experiment)
+	if err != nil {
+		return err
+	}
+
+	i.log.Info("Connection overload parameters",
+		"connections", params.connections,
+		"connectionType", params.connectionType,
+		"protocol", params.protocol,
+		"port", params.port)
+
+	addresses, err := i.targetAddresses(ctx, experiment, params)
+	if err != nil {
+		return err
+	}
+
+	// The connections are shared between the namespaces, in a stable order
+	namespaces := make([]string, 0, len(addresses))
+	for namespace := range addresses {
+		namespaces = append(namespaces, namespace)
+	}
+	slices.Sort(namespaces)
+	for idx, namespace := range namespaces {
+		connections := params.connections / len(namespaces)
+		if idx < params.connections%len(namespaces) {
+			connections++
+		}
+		if connections == 0 {
+			continue
+		}
+		if err := i.createLoadGenerator(ctx, experiment, namespace, addresses[namespace], connections, params); err != nil {
+			return err
+		}
+	}
+
+	i.log.Info("Connection overload chaos injection completed", "namespaces", len(namespaces))
+	return nil
+}
+
+// Cleanup deletes the load generator Jobs together with their pods, which
+// closes the connections
+func (i *ConnectionOverloadInjector) Cleanup(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Cleaning up connection overload chaos")
+
+	namespaces := make(map[string]bool)
+	for _, target := range experiment.Status.TargetResources {
+		namespaces[target.Namespace] = true
+	}
+	for namespace := range namespaces {
+		job := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: overloadJobName(experiment), Namespace: namespace},
+		}
+		err := i.client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to delete Job %s/%s: %w", namespace, job.Name, err)
+		}
+
+		i.event(experiment, job, corev1.EventTypeNormal, EventReasonOverloadStopped,
+			"Deleted load generator Job %s/%s", namespace, job.Name)
+		i.log.Info("Deleted load generator Job", "job", job.Name, "namespace", namespace)
+	}
+
+	i.log.Info("Connection overload chaos cleanup completed")
+	return nil
+}
+
+// Acknowledged reports whether every load generator pod is running
+func (i *ConnectionOverloadInjector) Acknowledged(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) (bool, error) {
+	jobs := &batchv1.JobList{}
+	if err := i.client.List(ctx, jobs, client.MatchingLabels{OverloadLabel: string(experiment.UID)}); err != nil {
+		return false, fmt.Errorf("failed to list load generator Jobs: %w", err)
+	}
+	if len(jobs.Items) == 0 {
+		return false, nil
+	}
+
+	for _, job := range jobs.Items {
+		for _, condition := range job.Status.Conditions {
+			if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+				return false, fmt.Errorf("load generator Job %s/%s failed: %s", job.Namespace, job.Name, condition.Message)
+			}
+		}
+		wanted := int32(1)
+		if job.Spec.Parallelism != nil {
+			wanted = *job.Spec.Parallelism
+		}
+		if job.Status.Ready == nil || *job.Status.Ready < wanted {
+			i.log.Info("Waiting for load generator pods", "job", job.Name, "namespace", job.Namespace)
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// ValidateParameters checks the connection overload parameters of an experiment
+func (i *ConnectionOverloadInjector) ValidateParameters(experiment *chaosv1alpha1.Havock8sExperiment) error {
+	_, err := parseConnectionOverloadParams(experiment)
+	return err
+}
+
+// parseConnectionOverloadParams validates the connection overload parameters of an experiment
+func parseConnectionOverloadParams(experiment *chaosv1alpha1.Havock8sExperiment) (connectionOverloadParams, error) {
+	params := connectionOverloadParams{
+		connectionType: loadgen.ConnectionMixed,
+		protocol:       experiment.Spec.Parameters["protocol"],
+		port:           experiment.Spec.Parameters["port"],
+		service:        experiment.Spec.Parameters["service"],
+		user:           "postgres",
+		interval:       10 * time.Second,
+		image:          DefaultLoadGeneratorImage,
+	}
+
+	switch params.protocol {
+	case "", loadgen.ProtocolPostgres, loadgen.ProtocolMongoDB, loadgen.ProtocolRedis:
+	default:
+		return params, fmt.Errorf("unsupported protocol: %s", params.protocol)
+	}
+
+	if val, ok := experiment.Spec.Parameters["connectionType"]; ok {
+		params.connectionType = val
+	}
+	switch params.connectionType {
+	case loadgen.ConnectionIdle, loadgen.ConnectionSlowLoris, loadgen.ConnectionMixed:
+	case loadgen.ConnectionActive:
+		if params.protocol == "" {
+			return params, fmt.Errorf("connectionType active requires the protocol parameter")
+		}
+	default:
+		return params, fmt.Errorf("unsupported connectionType: %s", params.connectionType)
+	}
+
+	if params.port == "" && params.protocol != "" {
+		params.port = strconv.Itoa(proxy.DefaultPorts[params.protocol])
+	}
+	if port, err := strconv.Atoi(params.port); err != nil || port < 1 || port > 65535 {
+		return params, fmt.Errorf("port parameter must be a port number, or protocol must be set")
+	}
+
+	if experiment.Spec.Intensity <= 0 || experiment.Spec.Intensity > 1 {
+		return params, fmt.Errorf("intensity must be greater than 0 and at most 1")
+	}
+	count := 1000
+	if val, ok := experiment.Spec.Parameters["connectionCount"]; ok {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 1 {
+			return params, fmt.Errorf("connectionCount must be a positive number")
+		}
+		count = n
+	}
+	params.connections = int(math.Ceil(float64(count) * experiment.Spec.Intensity))
+	if params.connections > maxOverloadConnections {
+		return params, fmt.Errorf("at most %d connections can be opened, got %d", maxOverloadConnections, params.connections)
+	}
+
+	if val, ok := experiment.Spec.Parameters["interval"]; ok {
+		interval, err := time.ParseDuration(val)
+		if err != nil || interval <= 0 {
+			return params, fmt.Errorf("invalid interval %q", val)
+		}
+		params.interval = interval
+	}
+	if val, ok := experiment.Spec.Parameters["user"]; ok {
+		params.user = val
+	}
+	if val, ok := experiment.Spec.Parameters["image"]; ok {
+		params.image = val
+	}
+
+	// The load generators stop by themselves when the experiment is due to end
+	duration, err := time.ParseDuration(experiment.Spec.Duration)
+	if err != nil || duration <= 0 {
+		return params, fmt.Errorf("invalid duration %q", experiment.Spec.Duration)
+	}
+	params.duration = duration
+
+	return params, nil
+}
+
+// targetAddresses returns the addresses to connect to in each targeted
+// namespace: the Service named by the service parameter, targeted
+// Services, or the IPs of targeted pods
+func (i *ConnectionOverloadInjector) targetAddresses(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, params connectionOverloadParams) (map[string][]string, error) {
+	addresses := make(map[string][]string)
+	add := func(namespace, host string) {
+		address := net.JoinHostPort(host, params.port)
+		if !slices.Contains(addresses[namespace], address) {
+			addresses[namespace] = append(addresses[namespace], address)
+		}
+	}
+
+	for _, target := range experiment.Status.TargetResources {
+		switch {
+		case params.service != "":
+			add(target.Namespace, serviceHost(params.service, target.Namespace))
+		case target.Kind == "Service":
+			add(target.Namespace, serviceHost(target.Name, target.Namespace))
+		default:
+			pods, err := podsOfTarget(ctx, i.client, target)
+			if err != nil {
+				return nil, err
+			}
+			for _, pod := range pods {
+				if pod.Status.PodIP != "" {
+					add(pod.Namespace, pod.Status.PodIP)
+				}
+			}
+		}
+	}
+
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("no Service or running pod to overload, set the service parameter")
+	}
+	return addresses, nil
+}
+
+// createLoadGenerator creates the Job holding connections against addresses
+func (i *ConnectionOverloadInjector) createLoadGenerator(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, namespace string, addresses []string, connections int, params connectionOverloadParams) error {
+	generators := int32((connections + connectionsPerLoadGenerator - 1) / connectionsPerLoadGenerator)
+	perGenerator := (connections + int(generators) - 1) / int(generators)
+	// Give the generators a minute past the experiment's end to stop
+	deadline := int64(params.duration.Seconds()) + 60
+	backoffLimit := int32(3)
+	ttl := int32(300)
+	labels := map[string]string{OverloadLabel: string(experiment.UID)}
+
+	args := []string{
+		"--targets", strings.Join(addresses, ","),
+		"--connections", strconv.Itoa(perGenerator),
+		"--connection-type", params.connectionType,
+		"--interval", params.interval.String(),
+		"--duration", params.duration.String(),
+	}
+	if params.protocol != "" {
+		args = append(args, "--protocol", params.protocol, "--user", params.user)
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      overloadJobName(experiment),
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			Parallelism:             &generators,
+			Completions:             &generators,
+			BackoffLimit:            &backoffLimit,
+			ActiveDeadlineSeconds:   &deadline,
+			TTLSecondsAfterFinished: &ttl,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyOnFailure,
+					Containers: []corev1.Container{{
+						Name:    "loadgen",
+						Image:   params.image,
+						Command: []string{"/havock8s-loadgen"},
+						Args:    args,
+					}},
+				},
+			},
+		},
+	}
+
+	if err := i.client.Create(ctx, job); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			// Left by an earlier attempt of this experiment
+			i.log.Info("Load generator Job already exists", "job", job.Name, "namespace", namespace)
+			return nil
+		}
+		return fmt.Errorf("failed to create Job %s/%s: %w", namespace, job.Name, err)
+	}
+
+	i.event(experiment, job, corev1.EventTypeNormal, EventReasonOverloadStarted,
+		"Started %d load generators holding %d %s connections to %s",
+		generators, connections, params.connectionType, strings.Join(addresses, ", "))
+	i.log.Info("Created load generator Job", "job", job.Name, "namespace", namespace, "connections", connections)
+	return nil
+}
+
+// overloadJobName returns the name of an experiment's load generator Jobs
+func overloadJobName(experiment *chaosv1alpha1.Havock8sExperiment) string {
+	return "havock8s-overload-" + experiment.Name
+}
+
+// serviceHost returns the cluster DNS name of a Service
+func serviceHost(name, namespace string) string {
+	return name + "." + namespace + ".svc"
+}
@@ -0,0 +1,178 @@
+package chaos
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestConnectionOverloadInjector_Inject(t *testing.T) {
+	tests := []struct {
+		name            string
+		parameters      map[string]string
+		intensity       float64
+		target          chaosv1alpha1.TargetResourceStatus
+		wantErr         bool
+		wantParallelism int32
+		wantArgs        []string
+	}{
+		{
+			name:            "mixed connections to the targeted pod",
+			parameters:      map[string]string{"protocol": "postgres"},
+			intensity:       0.5,
+			target:          chaosv1alpha1.TargetResourceStatus{Kind: "Pod", Name: "db-0", Namespace: "default"},
+			wantParallelism: 1,
+			wantArgs: []string{
+				"--targets", "10.0.0.5:5432",
+				"--connections", "500",
+				"--connection-type", "mixed",
+				"--protocol", "postgres",
+			},
+		},
+		{
+			name: "idle connections to a Service spread over generators",
+			parameters: map[string]string{
+				"connectionCount": "5000",
+				"connectionType":  "idle",
+				"port":            "27017",
+				"service":         "mongo",
+			},
+			intensity:       1.0,
+			target:          chaosv1alpha1.TargetResourceStatus{Kind: "Pod", Name: "db-0", Namespace: "default"},
+			wantParallelism: 3,
+			wantArgs: []string{
+				"--targets", "mongo.default.svc:27017",
+				"--connections", "1667",
+				"--connection-type", "idle",
+			},
+		},
+		{
+			name:            "targeted Service",
+			parameters:      map[string]string{"protocol": "redis", "connectionType": "active", "interval": "1s"},
+			intensity:       0.1,
+			target:          chaosv1alpha1.TargetResourceStatus{Kind: "Service", Name: "cache", Namespace: "default"},
+			wantParallelism: 1,
+			wantArgs: []string{
+				"--targets", "cache.default.svc:6379",
+				"--connections", "100",
+				"--interval", "1s",
+			},
+		},
+		{
+			name:       "active connections without a protocol",
+			parameters: map[string]string{"connectionType": "active", "port": "5432"},
+			intensity:  1.0,
+			target:     chaosv1alpha1.TargetResourceStatus{Kind: "Pod", Name: "db-0", Namespace: "default"},
+			wantErr:    true,
+		},
+		{
+			name:       "no port",
+			parameters: map[string]string{},
+			intensity:  1.0,
+			target:     chaosv1alpha1.TargetResourceStatus{Kind: "Pod", Name: "db-0", Namespace: "default"},
+			wantErr:    true,
+		},
+		{
+			name:       "too many connections",
+			parameters: map[string]string{"protocol": "postgres", "connectionCount": "100000"},
+			intensity:  1.0,
+			target:     chaosv1alpha1.TargetResourceStatus{Kind: "Pod", Name: "db-0", Namespace: "default"},
+			wantErr:    true,
+		},
+		{
+			name:       "unsupported connection type",
+			parameters: map[string]string{"protocol": "postgres", "connectionType": "burst"},
+			intensity:  1.0,
+			target:     chaosv1alpha1.TargetResourceStatus{Kind: "Pod", Name: "db-0", Namespace: "default"},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			_ = batchv1.AddToScheme(scheme)
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "default"},
+				Status:     corev1.PodStatus{PodIP: "10.0.0.5"},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod).Build()
+
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				ObjectMeta: metav1.ObjectMeta{Name: "overload", Namespace: "default", UID: "uid-1"},
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					ChaosType:  "ConnectionOverload",
+					Duration:   "5m",
+					Intensity:  tt.intensity,
+					Parameters: tt.parameters,
+				},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{tt.target},
+				},
+			}
+
+			injector := NewConnectionOverloadInjector(Dependencies{Client: fakeClient})
+			err := injector.Inject(context.Background(), experiment)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Inject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			job := &batchv1.Job{}
+			jobKey := types.NamespacedName{Namespace: "default", Name: "havock8s-overload-overload"}
+			if err := fakeClient.Get(context.Background(), jobKey, job); err != nil {
+				t.Fatalf("Failed to get Job: %v", err)
+			}
+			if job.Labels[OverloadLabel] != "uid-1" {
+				t.Errorf("Labels = %v, want the experiment UID", job.Labels)
+			}
+			if *job.Spec.Parallelism != tt.wantParallelism {
+				t.Errorf("Parallelism = %d, want %d", *job.Spec.Parallelism, tt.wantParallelism)
+			}
+			if *job.Spec.ActiveDeadlineSeconds != 360 {
+				t.Errorf("ActiveDeadlineSeconds = %d, want 360", *job.Spec.ActiveDeadlineSeconds)
+			}
+			args := job.Spec.Template.Spec.Containers[0].Args
+			for i := 0; i < len(tt.wantArgs); i += 2 {
+				idx := slices.Index(args, tt.wantArgs[i])
+				if idx < 0 || idx+1 >= len(args) || args[idx+1] != tt.wantArgs[i+1] {
+					t.Errorf("Args = %s, want %s %s", strings.Join(args, " "), tt.wantArgs[i], tt.wantArgs[i+1])
+				}
+			}
+
+			acknowledged, err := injector.Acknowledged(context.Background(), experiment)
+			if err != nil || acknowledged {
+				t.Errorf("Acknowledged() before the pods are ready = %v, %v, want false", acknowledged, err)
+			}
+			ready := tt.wantParallelism
+			job.Status.Ready = &ready
+			if err := fakeClient.Status().Update(context.Background(), job); err != nil {
+				t.Fatalf("Failed to update Job: %v", err)
+			}
+			acknowledged, err = injector.Acknowledged(context.Background(), experiment)
+			if err != nil || !acknowledged {
+				t.Errorf("Acknowledged() with the pods ready = %v, %v, want true", acknowledged, err)
+			}
+
+			if err := injector.Cleanup(context.Background(), experiment); err != nil {
+				t.Fatalf("Cleanup() error = %v", err)
+			}
+			if err := fakeClient.Get(context.Background(), jobKey, job); !apierrors.IsNotFound(err) {
+				t.Errorf("Get() after cleanup error = %v, want NotFound", err)
+			}
+		})
+	}
+}
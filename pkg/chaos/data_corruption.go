@@ -0,0 +1,296 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/agent"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// volumeSnapshotListGVK identifies the CSI VolumeSnapshot list. The snapshot
+// API is an optional CRD, so snapshots are read as unstructured objects.
+var volumeSnapshotListGVK = schema.GroupVersionKind{
+	Group:   "snapshot.storage.k8s.io",
+	Version: "v1",
+	Kind:    "VolumeSnapshotList",
+}
+
+// DataCorruptionInjector implements the Injector interface for data
+// corruption chaos. The node agent flips bytes in or truncates files on the
+// targeted volume, records their checksums, and restores the original content
+// on cleanup. Corruption is refused unless the volume has a ready
+// VolumeSnapshot or the experiment acknowledges the risk of data loss.
+type DataCorruptionInjector struct {
+	eventRecorder
+	client client.Client
+	log    logr.Logger
+}
+
+// dataCorruptionParams holds the validated data corruption parameters
+type dataCorruptionParams struct {
+	corruptionType string
+	mountPath      string
+	files          string
+	container      string
+	percentage     string
+	bytes          string
+	acknowledged   bool
+}
+
+// NewDataCorruptionInjector creates a data corruption injector using the given dependencies
+func NewDataCorruptionInjector(deps Dependencies) *DataCorruptionInjector {
+	return &DataCorruptionInjector{
+		eventRecorder: eventRecorder{recorder: deps.Recorder},
+		client:        deps.Client,
+		log:           deps.Log,
+	}
+}
+
+// Inject asks the node agent to corrupt files on the targeted pods' volume
+func (i *DataCorruptionInjector) Inject(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Injecting data corruption chaos")
+
+	params, err := parseDataCorruptionParams(experiment)
+	if err != nil {
+		return err
+	}
+
+	i.log.Info("Data corruption parameters",
+		"corruptionType", params.corruptionType,
+		"mountPath", params.mountPath,
+		"files", params.files,
+		"percentage", params.percentage)
+
+	pods, err := targetPods(ctx, i.client, experiment)
+	if err != nil {
+		return err
+	}
+
+	// Check every pod may be corrupted before corrupting any of them
+	for idx := range pods {
+		if err := i.checkProtected(ctx, &pods[idx], params); err != nil {
+			return err
+		}
+	}
+	for idx := range pods {
+		if err := i.injectPodCorruption(ctx, experiment, &pods[idx], params); err != nil {
+			return err
+		}
+	}
+
+	i.log.Info("Data corruption chaos injection completed", "pods", len(pods))
+	return nil
+}
+
+// Cleanup removes the corruption request from the targeted pods. The node
+// agent restores the corrupted files once it sees the request is gone.
+func (i *DataCorruptionInjector) Cleanup(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Cleaning up data corruption chaos")
+
+	for _, target := range experiment.Status.TargetResources {
+		pods, err := podsOfTarget(ctx, i.client, target)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				i.log.Info("Pod no longer exists, skipping cleanup", "pod", target.Name)
+				continue
+			}
+			return err
+		}
+
+		for idx := range pods {
+			pod := &pods[idx]
+			if _, ok := pod.Annotations[agent.DataCorruptionAnnotation]; !ok {
+				continue
+			}
+			remove := []string{
+				agent.DataCorruptionAnnotation,
+				agent.DataCorruptionModeAnnotation,
+				agent.DataCorruptionMountAnnotation,
+				agent.DataCorruptionFilesAnnotation,
+				agent.DataCorruptionContainerAnnotation,
+				agent.DataCorruptionPercentAnnotation,
+				agent.DataCorruptionBytesAnnotation,
+			}
+			if err := patchPodAnnotations(ctx, i.client, pod, nil, remove); err != nil {
+				return fmt.Errorf("failed to update pod %s/%s: %w", pod.Namespace, pod.Name, err)
+			}
+
+			i.event(experiment, pod, corev1.EventTypeNormal, EventReasonDataRestored,
+				"Requested restore of the files corrupted in pod %s/%s", pod.Namespace, pod.Name)
+			i.log.Info("Removed data corruption request from pod", "pod", pod.Name)
+		}
+	}
+
+	i.log.Info("Data corruption chaos cleanup completed")
+	return nil
+}
+
+// Acknowledged reports whether the node agent has corrupted the files of every targeted pod
+func (i *DataCorruptionInjector) Acknowledged(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) (bool, error) {
+	return podsAcknowledged(ctx, i.client, experiment, agent.DataCorruptionAckAnnotation, i.log)
+}
+
+// ValidateParameters checks the data corruption parameters of an experiment
+func (i *DataCorruptionInjector) ValidateParameters(experiment *chaosv1alpha1.Havock8sExperiment) error {
+	_, err := parseDataCorruptionParams(experiment)
+	return err
+}
+
+// parseDataCorruptionParams validates the data corruption parameters of an experiment
+func parseDataCorruptionParams(experiment *chaosv1alpha1.Havock8sExperiment) (dataCorruptionParams, error) {
+	params := dataCorruptionParams{
+		corruptionType: experiment.Spec.Parameters["corruptionType"],
+		mountPath:      experiment.Spec.Parameters["mountPath"],
+		files:          experiment.Spec.Parameters["files"],
+		container:      experiment.Spec.Parameters["container"],
+		percentage:     strconv.Itoa(int(experiment.Spec.Intensity * 100)),
+		bytes:          "1",
+		acknowledged:   experiment.Spec.Parameters["acknowledgeDataLoss"] == "true",
+	}
+
+	switch params.corruptionType {
+	case agent.DataCorruptionModeByte, agent.DataCorruptionModeTruncate:
+	case "":
+		return params, fmt.Errorf("corruptionType parameter is required")
+	default:
+		return params, fmt.Errorf("unsupported corruptionType: %s", params.corruptionType)
+	}
+
+	if params.mountPath == "" || !filepath.IsAbs(params.mountPath) {
+		return params, fmt.Errorf("mountPath parameter must be an absolute path")
+	}
+	if params.files == "" || !filepath.IsLocal(params.files) {
+		return params, fmt.Errorf("files parameter must be a pattern relative to mountPath")
+	}
+	if _, err := filepath.Match(params.files, ""); err != nil {
+		return params, fmt.Errorf("invalid files pattern %q: %w", params.files, err)
+	}
+
+	if val, ok := experiment.Spec.Parameters["percentage"]; ok {
+		params.percentage = strings.TrimSuffix(val, "%")
+	}
+	if percentage, err := strconv.Atoi(params.percentage); err != nil || percentage < 1 || percentage > 100 {
+		return params, fmt.Errorf("percentage must be between 1 and 100, or intensity greater than 0")
+	}
+
+	if val, ok := experiment.Spec.Parameters["bytes"]; ok {
+		if params.corruptionType != agent.DataCorruptionModeByte {
+			return params, fmt.Errorf("bytes parameter is only supported by corruptionType byte")
+		}
+		if n, err := strconv.Atoi(val); err != nil || n < 1 || n > agent.MaxCorruptedBytes {
+			return params, fmt.Errorf("bytes must be between 1 and %d", agent.MaxCorruptedBytes)
+		}
+		params.bytes = val
+	}
+
+	return params, nil
+}
+
+// checkProtected refuses to corrupt a pod's volume unless the experiment
+// acknowledges the risk of data loss or the volume's claim has a ready snapshot
+func (i *DataCorruptionInjector) checkProtected(ctx context.Context, pod *corev1.Pod, params dataCorruptionParams) error {
+	if params.acknowledged {
+		return nil
+	}
+
+	claim := mountedClaim(pod, params.container, params.mountPath)
+	if claim != "" {
+		snapshotted, err := i.hasReadySnapshot(ctx, pod.Namespace, claim)
+		if err != nil {
+			return err
+		}
+		if snapshotted {
+			return nil
+		}
+	}
+	return fmt.Errorf("refusing to corrupt %s in pod %s/%s: its volume has no ready VolumeSnapshot, set acknowledgeDataLoss to \"true\" to proceed",
+		params.mountPath, pod.Namespace, pod.Name)
+}
+
+// hasReadySnapshot reports whether a ready VolumeSnapshot of the claim exists
+func (i *DataCorruptionInjector) hasReadySnapshot(ctx context.Context, namespace, claim string) (bool, error) {
+	snapshots := &unstructured.UnstructuredList{}
+	snapshots.SetGroupVersionKind(volumeSnapshotListGVK)
+	if err := i.client.List(ctx, snapshots, client.InNamespace(namespace)); err != nil {
+		if meta.IsNoMatchError(err) {
+			// The snapshot API is not installed
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to list volume snapshots in namespace %s: %w", namespace, err)
+	}
+
+	for _, snapshot := range snapshots.Items {
+		source, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+		ready, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+		if source == claim && ready {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// mountedClaim returns the claim of the volume mounted at or above mountPath
+// in the named container, or in any container when no name is given
+func mountedClaim(pod *corev1.Pod, container, mountPath string) string {
+	claims := map[string]string{}
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			claims[volume.Name] = volume.PersistentVolumeClaim.ClaimName
+		}
+	}
+
+	best, claim := "", ""
+	for _, c := range pod.Spec.Containers {
+		if container != "" && c.Name != container {
+			continue
+		}
+		for _, mount := range c.VolumeMounts {
+			within := mountPath == mount.MountPath || strings.HasPrefix(mountPath, strings.TrimSuffix(mount.MountPath, "/")+"/")
+			// The deepest mount covering the path is the one holding it
+			if within && len(mount.MountPath) > len(best) {
+				best, claim = mount.MountPath, claims[mount.Name]
+			}
+		}
+	}
+	return claim
+}
+
+// injectPodCorruption asks the node agent to corrupt files of a pod's volume
+func (i *DataCorruptionInjector) injectPodCorruption(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, pod *corev1.Pod, params dataCorruptionParams) error {
+	// Set data corruption annotations for the node agent, dropping any
+	// acknowledgement left over from a previous experiment
+	set := map[string]string{
+		agent.DataCorruptionAnnotation:        "true",
+		agent.DataCorruptionModeAnnotation:    params.corruptionType,
+		agent.DataCorruptionMountAnnotation:   params.mountPath,
+		agent.DataCorruptionFilesAnnotation:   params.files,
+		agent.DataCorruptionPercentAnnotation: params.percentage,
+		agent.DataCorruptionBytesAnnotation:   params.bytes,
+	}
+	remove := []string{agent.DataCorruptionAckAnnotation}
+	if params.container != "" {
+		set[agent.DataCorruptionContainerAnnotation] = params.container
+	} else {
+		remove = append(remove, agent.DataCorruptionContainerAnnotation)
+	}
+	if err := patchPodAnnotations(ctx, i.client, pod, set, remove); err != nil {
+		return fmt.Errorf("failed to update pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+
+	i.event(experiment, pod, corev1.EventTypeWarning, EventReasonDataCorrupted,
+		"Requested %s corruption of %s%% of the files matching %s under %s in pod %s/%s",
+		params.corruptionType, params.percentage, params.files, params.mountPath, pod.Namespace, pod.Name)
+	i.log.Info("Requested data corruption of pod", "pod", pod.Name, "corruptionType", params.corruptionType)
+	return nil
+}
@@ -0,0 +1,205 @@
+package chaos
+
+import (
+	"context"
+	"testing"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/agent"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// newCorruptionTestPod returns a database pod with its data on a claim
+func newCorruptionTestPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: "db",
+				VolumeMounts: []corev1.VolumeMount{
+					{Name: "config", MountPath: "/etc/db"},
+					{Name: "data", MountPath: "/var/lib/db"},
+				},
+			}},
+			Volumes: []corev1.Volume{
+				{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{}}},
+				{Name: "data", VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-db-0"},
+				}},
+			},
+		},
+	}
+}
+
+// newVolumeSnapshot returns a snapshot of a claim
+func newVolumeSnapshot(name, claim string, ready bool) *unstructured.Unstructured {
+	snapshot := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec":   map[string]interface{}{"source": map[string]interface{}{"persistentVolumeClaimName": claim}},
+		"status": map[string]interface{}{"readyToUse": ready},
+	}}
+	snapshot.SetGroupVersionKind(volumeSnapshotListGVK.GroupVersion().WithKind("VolumeSnapshot"))
+	snapshot.SetName(name)
+	snapshot.SetNamespace("default")
+	return snapshot
+}
+
+func TestDataCorruptionInjector_Inject(t *testing.T) {
+	tests := []struct {
+		name            string
+		parameters      map[string]string
+		snapshots       []client.Object
+		wantErr         bool
+		wantAnnotations map[string]string
+	}{
+		{
+			name: "acknowledged data loss",
+			parameters: map[string]string{
+				"corruptionType":      "byte",
+				"mountPath":           "/var/lib/db",
+				"files":               "base/*/*",
+				"bytes":               "8",
+				"acknowledgeDataLoss": "true",
+			},
+			wantAnnotations: map[string]string{
+				agent.DataCorruptionModeAnnotation:    "byte",
+				agent.DataCorruptionMountAnnotation:   "/var/lib/db",
+				agent.DataCorruptionFilesAnnotation:   "base/*/*",
+				agent.DataCorruptionPercentAnnotation: "50",
+				agent.DataCorruptionBytesAnnotation:   "8",
+			},
+		},
+		{
+			name: "volume protected by a ready snapshot",
+			parameters: map[string]string{
+				"corruptionType": "truncate",
+				"mountPath":      "/var/lib/db/pg_wal",
+				"files":          "*",
+				"percentage":     "10%",
+			},
+			snapshots: []client.Object{
+				newVolumeSnapshot("data-db-0-pending", "data-db-0", false),
+				newVolumeSnapshot("data-db-0-ready", "data-db-0", true),
+			},
+			wantAnnotations: map[string]string{
+				agent.DataCorruptionModeAnnotation:    "truncate",
+				agent.DataCorruptionPercentAnnotation: "10",
+			},
+		},
+		{
+			name: "snapshot not ready",
+			parameters: map[string]string{
+				"corruptionType": "byte",
+				"mountPath":      "/var/lib/db",
+				"files":          "*",
+			},
+			snapshots: []client.Object{newVolumeSnapshot("data-db-0-pending", "data-db-0", false)},
+			wantErr:   true,
+		},
+		{
+			name: "volume without a claim",
+			parameters: map[string]string{
+				"corruptionType": "byte",
+				"mountPath":      "/etc/db",
+				"files":          "*.conf",
+			},
+			snapshots: []client.Object{newVolumeSnapshot("data-db-0-ready", "data-db-0", true)},
+			wantErr:   true,
+		},
+		{
+			name: "pattern escaping the mount path",
+			parameters: map[string]string{
+				"corruptionType":      "byte",
+				"mountPath":           "/var/lib/db",
+				"files":               "../../etc/*",
+				"acknowledgeDataLoss": "true",
+			},
+			wantErr: true,
+		},
+		{
+			name: "bytes with truncation",
+			parameters: map[string]string{
+				"corruptionType":      "truncate",
+				"mountPath":           "/var/lib/db",
+				"files":               "*",
+				"bytes":               "8",
+				"acknowledgeDataLoss": "true",
+			},
+			wantErr: true,
+		},
+		{
+			name: "schema corruption is not supported",
+			parameters: map[string]string{
+				"corruptionType":      "schema",
+				"mountPath":           "/var/lib/db",
+				"files":               "*",
+				"acknowledgeDataLoss": "true",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			snapshotGV := volumeSnapshotListGVK.GroupVersion()
+			scheme.AddKnownTypeWithName(snapshotGV.WithKind("VolumeSnapshot"), &unstructured.Unstructured{})
+			scheme.AddKnownTypeWithName(volumeSnapshotListGVK, &unstructured.UnstructuredList{})
+			fakeClient := newKubeletRacingClient(scheme, append([]client.Object{newCorruptionTestPod()}, tt.snapshots...)...)
+
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					ChaosType:  "DataCorruption",
+					Intensity:  0.5,
+					Parameters: tt.parameters,
+				},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{
+						{Kind: "Pod", Name: "db-0", Namespace: "default"},
+					},
+				},
+			}
+
+			injector := NewDataCorruptionInjector(Dependencies{Client: fakeClient})
+			err := injector.Inject(context.Background(), experiment)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Inject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			pod := &corev1.Pod{}
+			podKey := types.NamespacedName{Namespace: "default", Name: "db-0"}
+			if err := fakeClient.Get(context.Background(), podKey, pod); err != nil {
+				t.Fatalf("Failed to get pod: %v", err)
+			}
+			if tt.wantErr {
+				if _, ok := pod.Annotations[agent.DataCorruptionAnnotation]; ok {
+					t.Errorf("Corruption requested although Inject() failed")
+				}
+				return
+			}
+			if pod.Annotations[agent.DataCorruptionAnnotation] != "true" {
+				t.Errorf("Annotations = %v, want a data corruption request", pod.Annotations)
+			}
+			for key, want := range tt.wantAnnotations {
+				if got := pod.Annotations[key]; got != want {
+					t.Errorf("Annotation %s = %q, want %q", key, got, want)
+				}
+			}
+
+			if err := injector.Cleanup(context.Background(), experiment); err != nil {
+				t.Fatalf("Cleanup() error = %v", err)
+			}
+			if err := fakeClient.Get(context.Background(), podKey, pod); err != nil {
+				t.Fatalf("Failed to get pod: %v", err)
+			}
+			if len(pod.Annotations) != 0 {
+				t.Errorf("Annotations after cleanup = %v, want none", pod.Annotations)
+			}
+		})
+	}
+}
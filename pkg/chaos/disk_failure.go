@@ -3,196 +3,230 @@ package chaos
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/go-logr/logr"
 	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/agent"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// DiskFailureInjector implements the Injector interface for disk failure chaos
+// DiskFailureInjector implements the Injector interface for disk failure chaos.
+// The failure itself is applied by the node agent on the node running each pod.
 type DiskFailureInjector struct {
+	eventRecorder
 	client client.Client
+	log    logr.Logger
 }
 
-// SetClient sets the Kubernetes client
-func (i *DiskFailureInjector) SetClient(c client.Client) {
-	i.client = c
+// diskFailureParams holds the validated disk failure parameters
+type diskFailureParams struct {
+	failureMode string
+	mountPath   string
+	container   string
+	failureRate string
+	writeIOPS   string
+	fillPercent string
+}
+
+// NewDiskFailureInjector creates a disk failure injector using the given dependencies
+func NewDiskFailureInjector(deps Dependencies) *DiskFailureInjector {
+	return &DiskFailureInjector{
+		eventRecorder: eventRecorder{recorder: deps.Recorder},
+		client:        deps.Client,
+		log:           deps.Log,
+	}
 }
 
 // Inject applies disk failure chaos
-func (i *DiskFailureInjector) Inject(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, log logr.Logger) error {
-	log.Info("Injecting disk failure chaos")
+func (i *DiskFailureInjector) Inject(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Injecting disk failure chaos")
 
-	for _, target := range experiment.Status.TargetResources {
-		log.Info("Processing target for disk failure", "kind", target.Kind, "name", target.Name, "namespace", target.Namespace)
+	params, err := parseDiskFailureParams(experiment)
+	if err != nil {
+		return err
+	}
 
-		// Different actions based on the target kind
-		switch target.Kind {
-		case "Pod":
-			// Inject sidecar container or use fault injection tools
-			if err := i.injectPodDiskFailure(ctx, experiment, target, log); err != nil {
-				return err
-			}
-		case "StatefulSet":
-			// For StatefulSets, we might want to affect the PVCs
-			if err := i.injectStatefulSetDiskFailure(ctx, experiment, target, log); err != nil {
-				return err
-			}
-		case "PersistentVolumeClaim":
-			// Directly affect the PVC if possible
-			if err := i.injectPVCDiskFailure(ctx, experiment, target, log); err != nil {
-				return err
-			}
-		default:
-			log.Info("Unsupported target kind for disk failure", "kind", target.Kind)
+	i.log.Info("Disk failure parameters",
+		"failureMode", params.failureMode,
+		"mountPath", params.mountPath,
+		"failureRate", params.failureRate,
+		"writeIOPS", params.writeIOPS,
+		"fillPercentage", params.fillPercent)
+
+	// Fail the volume in every targeted pod, including the pods of targeted
+	// workloads and the pods mounting targeted claims
+	pods, err := targetPods(ctx, i.client, experiment)
+	if err != nil {
+		return err
+	}
+	for idx := range pods {
+		if err := i.injectPodDiskFailure(ctx, experiment, &pods[idx], params); err != nil {
+			return err
 		}
 	}
 
-	log.Info("Disk failure chaos injection completed")
+	i.log.Info("Disk failure chaos injection completed", "pods", len(pods))
 	return nil
 }
 
 // Cleanup removes disk failure chaos
-func (i *DiskFailureInjector) Cleanup(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, log logr.Logger) error {
-	log.Info("Cleaning up disk failure chaos")
+func (i *DiskFailureInjector) Cleanup(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Cleaning up disk failure chaos")
 
 	for _, target := range experiment.Status.TargetResources {
-		log.Info("Processing target for disk failure cleanup", "kind", target.Kind, "name", target.Name, "namespace", target.Namespace)
-
-		// Different actions based on the target kind
-		switch target.Kind {
-		case "Pod":
-			// Remove sidecar container or reset fault injection
-			if err := i.cleanupPodDiskFailure(ctx, experiment, target, log); err != nil {
-				return err
+		pods, err := podsOfTarget(ctx, i.client, target)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				// The failure went away with the pod
+				i.log.Info("Pod no longer exists, skipping cleanup", "pod", target.Name)
+				continue
 			}
-		case "StatefulSet":
-			// Cleanup for StatefulSets
-			if err := i.cleanupStatefulSetDiskFailure(ctx, experiment, target, log); err != nil {
-				return err
-			}
-		case "PersistentVolumeClaim":
-			// Cleanup for PVCs
-			if err := i.cleanupPVCDiskFailure(ctx, experiment, target, log); err != nil {
+			return err
+		}
+
+		for idx := range pods {
+			if err := i.cleanupPodDiskFailure(ctx, experiment, &pods[idx]); err != nil {
 				return err
 			}
-		default:
-			log.Info("Unsupported target kind for disk failure cleanup", "kind", target.Kind)
 		}
 	}
 
-	log.Info("Disk failure chaos cleanup completed")
+	i.log.Info("Disk failure chaos cleanup completed")
 	return nil
 }
 
-// injectPodDiskFailure applies disk failure to a pod
-func (i *DiskFailureInjector) injectPodDiskFailure(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, target chaosv1alpha1.TargetResourceStatus, log logr.Logger) error {
-	// Get the pod
-	pod := &corev1.Pod{}
-	err := i.client.Get(ctx, types.NamespacedName{
-		Namespace: target.Namespace,
-		Name:      target.Name,
-	}, pod)
-	if err != nil {
-		return fmt.Errorf("failed to get pod %s/%s: %w", target.Namespace, target.Name, err)
+// Acknowledged reports whether the node agent has applied the disk failure to every targeted pod
+func (i *DiskFailureInjector) Acknowledged(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) (bool, error) {
+	return podsAcknowledged(ctx, i.client, experiment, agent.DiskFailureAckAnnotation, i.log)
+}
+
+// ValidateParameters checks the disk failure parameters of an experiment
+func (i *DiskFailureInjector) ValidateParameters(experiment *chaosv1alpha1.Havock8sExperiment) error {
+	_, err := parseDiskFailureParams(experiment)
+	return err
+}
+
+// legacyDiskFailureModes maps the failure modes of the original API, which
+// only annotated the pod, to the mode the agent applies. Both fail reads, and
+// eio is the only mode that does.
+var legacyDiskFailureModes = map[string]string{
+	"writeonly": agent.DiskFailureModeEIO,
+	"readwrite": agent.DiskFailureModeEIO,
+}
+
+// parseDiskFailureParams validates the disk failure parameters of an experiment
+func parseDiskFailureParams(experiment *chaosv1alpha1.Havock8sExperiment) (diskFailureParams, error) {
+	params := diskFailureParams{
+		failureRate: strconv.Itoa(int(experiment.Spec.Intensity * 100)),
+		writeIOPS:   "10",
+		fillPercent: "95",
 	}
 
 	// Validate failure mode
 	failureMode, ok := experiment.Spec.Parameters["failureMode"]
 	if !ok {
-		return fmt.Errorf("failureMode parameter is required")
+		return params, fmt.Errorf("failureMode parameter is required")
+	}
+	if mode, ok := legacyDiskFailureModes[failureMode]; ok {
+		failureMode = mode
 	}
-	if failureMode != "readonly" && failureMode != "writeonly" && failureMode != "readwrite" {
-		return fmt.Errorf("invalid failure mode: %s", failureMode)
+	switch failureMode {
+	case agent.DiskFailureModeReadOnly, agent.DiskFailureModeEIO, agent.DiskFailureModeWriteThrottle, agent.DiskFailureModeFill:
+		params.failureMode = failureMode
+	default:
+		return params, fmt.Errorf("invalid failure mode: %s", failureMode)
 	}
 
 	// Get mount path
 	mountPath, ok := experiment.Spec.Parameters["mountPath"]
 	if !ok {
-		return fmt.Errorf("mountPath parameter is required")
+		return params, fmt.Errorf("mountPath parameter is required")
 	}
-
-	// Initialize annotations if nil
-	if pod.Annotations == nil {
-		pod.Annotations = make(map[string]string)
+	if !strings.HasPrefix(mountPath, "/") {
+		return params, fmt.Errorf("mountPath must be an absolute path: %s", mountPath)
 	}
+	params.mountPath = mountPath
+	params.container = experiment.Spec.Parameters["container"]
 
-	// Set disk failure annotations
-	pod.Annotations["havock8s.io/disk-failure"] = "true"
-	pod.Annotations["havock8s.io/disk-failure-mount"] = mountPath
-	pod.Annotations["havock8s.io/disk-failure-mode"] = failureMode
-
-	// Update the pod with new annotations
-	if err := i.client.Update(ctx, pod); err != nil {
-		return fmt.Errorf("failed to update pod %s/%s: %w", target.Namespace, target.Name, err)
+	if val, ok := experiment.Spec.Parameters["failureRate"]; ok {
+		params.failureRate = strings.TrimSuffix(val, "%")
+	}
+	if val, ok := experiment.Spec.Parameters["writeIOPS"]; ok {
+		params.writeIOPS = val
+	}
+	if val, ok := experiment.Spec.Parameters["fillPercentage"]; ok {
+		params.fillPercent = strings.TrimSuffix(val, "%")
 	}
 
-	log.Info("Applied disk failure to pod", "pod", target.Name)
-	return nil
-}
-
-// injectStatefulSetDiskFailure applies disk failure to a StatefulSet's PVCs
-func (i *DiskFailureInjector) injectStatefulSetDiskFailure(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, target chaosv1alpha1.TargetResourceStatus, log logr.Logger) error {
-	// In a real implementation, this would find PVCs associated with the StatefulSet
-	// and apply disk failures to them
-
-	log.Info("Applied disk failure to StatefulSet", "statefulset", target.Name)
-	return nil
-}
-
-// injectPVCDiskFailure applies disk failure to a PVC
-func (i *DiskFailureInjector) injectPVCDiskFailure(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, target chaosv1alpha1.TargetResourceStatus, log logr.Logger) error {
-	// In a real implementation, this would:
-	// 1. Identify the PVC and associated PV
-	// 2. Depending on the storage class, apply appropriate failure mechanisms
-	// 3. For cloud providers, could use provider APIs to inject storage failures
+	for name, value := range map[string]string{"failureRate": params.failureRate, "fillPercentage": params.fillPercent} {
+		percent, err := strconv.Atoi(value)
+		if err != nil || percent < 0 || percent > 100 {
+			return params, fmt.Errorf("%s must be a percentage between 0 and 100: %s", name, value)
+		}
+	}
+	if iops, err := strconv.Atoi(params.writeIOPS); err != nil || iops < 1 {
+		return params, fmt.Errorf("writeIOPS must be a positive integer: %s", params.writeIOPS)
+	}
 
-	log.Info("Applied disk failure to PVC", "pvc", target.Name)
-	return nil
+	return params, nil
 }
 
-// cleanupPodDiskFailure removes disk failure from a pod
-func (i *DiskFailureInjector) cleanupPodDiskFailure(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, target chaosv1alpha1.TargetResourceStatus, log logr.Logger) error {
-	// Get the pod
-	pod := &corev1.Pod{}
-	err := i.client.Get(ctx, types.NamespacedName{
-		Namespace: target.Namespace,
-		Name:      target.Name,
-	}, pod)
-	if err != nil {
-		return fmt.Errorf("failed to get pod %s/%s: %w", target.Namespace, target.Name, err)
+// injectPodDiskFailure asks the node agent to apply disk failure to a pod
+func (i *DiskFailureInjector) injectPodDiskFailure(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, pod *corev1.Pod, params diskFailureParams) error {
+	// Set disk failure annotations for the node agent, dropping any
+	// acknowledgement left over from a previous experiment
+	set := map[string]string{
+		agent.DiskFailureAnnotation:      "true",
+		agent.DiskFailureMountAnnotation: params.mountPath,
+		agent.DiskFailureModeAnnotation:  params.failureMode,
 	}
-
-	// Remove disk failure annotations if they exist
-	if pod.Annotations != nil {
-		delete(pod.Annotations, "havock8s.io/disk-failure")
-		delete(pod.Annotations, "havock8s.io/disk-failure-mount")
-		delete(pod.Annotations, "havock8s.io/disk-failure-mode")
+	switch params.failureMode {
+	case agent.DiskFailureModeEIO:
+		set[agent.DiskFailurePercentAnnotation] = params.failureRate
+	case agent.DiskFailureModeFill:
+		set[agent.DiskFailurePercentAnnotation] = params.fillPercent
+	case agent.DiskFailureModeWriteThrottle:
+		set[agent.DiskFailureWriteIOPSAnnotation] = params.writeIOPS
 	}
-
-	// Update the pod to remove annotations
-	if err := i.client.Update(ctx, pod); err != nil {
-		return fmt.Errorf("failed to update pod %s/%s: %w", target.Namespace, target.Name, err)
+	if params.container != "" {
+		set[agent.DiskFailureContainerAnnotation] = params.container
+	}
+	if err := patchPodAnnotations(ctx, i.client, pod, set, []string{agent.DiskFailureAckAnnotation}); err != nil {
+		return fmt.Errorf("failed to update pod %s/%s: %w", pod.Namespace, pod.Name, err)
 	}
 
-	log.Info("Removed disk failure from pod", "pod", target.Name)
+	i.event(experiment, pod, corev1.EventTypeNormal, EventReasonDiskFailureApplied,
+		"Requested %s disk failure of %s on pod %s/%s", params.failureMode, params.mountPath, pod.Namespace, pod.Name)
+	i.log.Info("Applied disk failure to pod", "pod", pod.Name, "mode", params.failureMode)
 	return nil
 }
 
-// cleanupStatefulSetDiskFailure removes disk failure from a StatefulSet's PVCs
-func (i *DiskFailureInjector) cleanupStatefulSetDiskFailure(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, target chaosv1alpha1.TargetResourceStatus, log logr.Logger) error {
-	// Clean up whatever was done in injectStatefulSetDiskFailure
-
-	log.Info("Removed disk failure from StatefulSet", "statefulset", target.Name)
-	return nil
-}
+// cleanupPodDiskFailure removes the disk failure request from a pod. The node
+// agent reverts the failure and drops its acknowledgement once the request is gone.
+func (i *DiskFailureInjector) cleanupPodDiskFailure(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, pod *corev1.Pod) error {
+	if _, ok := pod.Annotations[agent.DiskFailureAnnotation]; !ok {
+		return nil
+	}
 
-// cleanupPVCDiskFailure removes disk failure from a PVC
-func (i *DiskFailureInjector) cleanupPVCDiskFailure(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, target chaosv1alpha1.TargetResourceStatus, log logr.Logger) error {
-	// Clean up whatever was done in injectPVCDiskFailure
+	// Remove disk failure annotations
+	remove := []string{
+		agent.DiskFailureAnnotation,
+		agent.DiskFailureMountAnnotation,
+		agent.DiskFailureModeAnnotation,
+		agent.DiskFailureContainerAnnotation,
+		agent.DiskFailurePercentAnnotation,
+		agent.DiskFailureWriteIOPSAnnotation,
+	}
+	if err := patchPodAnnotations(ctx, i.client, pod, nil, remove); err != nil {
+		return fmt.Errorf("failed to update pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
 
-	log.Info("Removed disk failure from PVC", "pvc", target.Name)
+	i.event(experiment, pod, corev1.EventTypeNormal, EventReasonDiskFailureRemoved,
+		"Removed disk failure from pod %s/%s", pod.Namespace, pod.Name)
+	i.log.Info("Removed disk failure from pod", "pod", pod.Name)
 	return nil
 }
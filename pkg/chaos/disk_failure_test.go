@@ -4,8 +4,8 @@ import (
 	"context"
 	"testing"
 
-	"github.com/go-logr/logr"
 	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -72,6 +72,149 @@ func TestDiskFailureInjector_Inject(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "eio with failure rate",
+			experiment: &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					Parameters: map[string]string{
+						"mountPath":   "/data",
+						"failureMode": "eio",
+						"failureRate": "30%",
+					},
+				},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{
+						{
+							Kind:      "Pod",
+							Name:      "test-pod",
+							Namespace: "default",
+						},
+					},
+				},
+			},
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-pod",
+					Namespace: "default",
+				},
+			},
+			wantErr:   false,
+			wantMount: "/data",
+			wantMode:  "eio",
+		},
+		{
+			name: "original readwrite mode runs as eio",
+			experiment: &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					Parameters: map[string]string{
+						"mountPath":   "/data",
+						"failureMode": "readwrite",
+					},
+				},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{
+						{
+							Kind:      "Pod",
+							Name:      "test-pod",
+							Namespace: "default",
+						},
+					},
+				},
+			},
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-pod",
+					Namespace: "default",
+				},
+			},
+			wantErr:   false,
+			wantMount: "/data",
+			wantMode:  "eio",
+		},
+		{
+			name: "write throttle with write IOPS",
+			experiment: &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					Parameters: map[string]string{
+						"mountPath":   "/data",
+						"failureMode": "write-throttle",
+						"writeIOPS":   "50",
+					},
+				},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{
+						{
+							Kind:      "Pod",
+							Name:      "test-pod",
+							Namespace: "default",
+						},
+					},
+				},
+			},
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-pod",
+					Namespace: "default",
+				},
+			},
+			wantErr:   false,
+			wantMount: "/data",
+			wantMode:  "write-throttle",
+		},
+		{
+			name: "write throttle with invalid write IOPS",
+			experiment: &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					Parameters: map[string]string{
+						"mountPath":   "/data",
+						"failureMode": "write-throttle",
+						"writeIOPS":   "0",
+					},
+				},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{
+						{
+							Kind:      "Pod",
+							Name:      "test-pod",
+							Namespace: "default",
+						},
+					},
+				},
+			},
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-pod",
+					Namespace: "default",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing mount path",
+			experiment: &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					Parameters: map[string]string{
+						"failureMode": "fill",
+					},
+				},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{
+						{
+							Kind:      "Pod",
+							Name:      "test-pod",
+							Namespace: "default",
+						},
+					},
+				},
+			},
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-pod",
+					Namespace: "default",
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid failure mode",
 			experiment: &chaosv1alpha1.Havock8sExperiment{
@@ -117,10 +260,9 @@ func TestDiskFailureInjector_Inject(t *testing.T) {
 				WithObjects(objs...).
 				Build()
 
-			injector := &DiskFailureInjector{}
-			injector.SetClient(fakeClient)
+			injector := NewDiskFailureInjector(Dependencies{Client: fakeClient})
 
-			err := injector.Inject(context.Background(), tt.experiment, logr.Discard())
+			err := injector.Inject(context.Background(), tt.experiment)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("DiskFailureInjector.Inject() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -201,10 +343,9 @@ func TestDiskFailureInjector_Cleanup(t *testing.T) {
 				WithObjects(tt.experiment, tt.pod).
 				Build()
 
-			injector := &DiskFailureInjector{}
-			injector.SetClient(fakeClient)
+			injector := NewDiskFailureInjector(Dependencies{Client: fakeClient})
 
-			err := injector.Cleanup(context.Background(), tt.experiment, logr.Discard())
+			err := injector.Cleanup(context.Background(), tt.experiment)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("DiskFailureInjector.Cleanup() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -227,4 +368,149 @@ func TestDiskFailureInjector_Cleanup(t *testing.T) {
 			}
 		})
 	}
-} 
\ No newline at end of file
+}
+
+func TestDiskFailureInjector_DeploymentTarget(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+	_ = chaosv1alpha1.AddToScheme(scheme)
+
+	replicaSet := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "api-5d8f7",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "api"}},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "api-5d8f7-q2x9v",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "api-5d8f7"}},
+		},
+	}
+	experiment := &chaosv1alpha1.Havock8sExperiment{
+		Spec: chaosv1alpha1.Havock8sExperimentSpec{
+			Parameters: map[string]string{"failureMode": "readonly", "mountPath": "/data"},
+		},
+		Status: chaosv1alpha1.Havock8sExperimentStatus{
+			TargetResources: []chaosv1alpha1.TargetResourceStatus{
+				{Kind: "Deployment", Name: "api", Namespace: "default"},
+			},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(replicaSet, pod).
+		Build()
+	injector := NewDiskFailureInjector(Dependencies{Client: fakeClient})
+	ctx := context.Background()
+
+	if err := injector.Inject(ctx, experiment); err != nil {
+		t.Fatalf("DiskFailureInjector.Inject() error = %v", err)
+	}
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(pod), pod); err != nil {
+		t.Fatalf("Failed to get pod: %v", err)
+	}
+	if pod.Annotations["havock8s.io/disk-failure-mode"] != "readonly" {
+		t.Errorf("Disk failure mode = %q, want readonly", pod.Annotations["havock8s.io/disk-failure-mode"])
+	}
+
+	if err := injector.Cleanup(ctx, experiment); err != nil {
+		t.Fatalf("DiskFailureInjector.Cleanup() error = %v", err)
+	}
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(pod), pod); err != nil {
+		t.Fatalf("Failed to get pod: %v", err)
+	}
+	if _, ok := pod.Annotations["havock8s.io/disk-failure"]; ok {
+		t.Error("Disk failure annotation still present after cleanup")
+	}
+}
+
+func TestDiskFailureInjector_Acknowledged(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        bool
+		wantErr     bool
+	}{
+		{
+			name: "waiting for agent",
+			annotations: map[string]string{
+				"havock8s.io/disk-failure": "true",
+			},
+			want: false,
+		},
+		{
+			name: "applied by agent",
+			annotations: map[string]string{
+				"havock8s.io/disk-failure":     "true",
+				"havock8s.io/disk-failure-ack": "applied",
+			},
+			want: true,
+		},
+		{
+			name: "agent failure",
+			annotations: map[string]string{
+				"havock8s.io/disk-failure":     "true",
+				"havock8s.io/disk-failure-ack": "failed: /data is not backed by a block device",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			_ = chaosv1alpha1.AddToScheme(scheme)
+
+			// The pod is reached through the PVC it mounts
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-pod",
+					Namespace:   "default",
+					Annotations: tt.annotations,
+				},
+				Spec: corev1.PodSpec{
+					Volumes: []corev1.Volume{
+						{
+							Name: "data",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-claim"},
+							},
+						},
+					},
+				},
+			}
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{
+						{
+							Kind:      "PersistentVolumeClaim",
+							Name:      "data-claim",
+							Namespace: "default",
+						},
+					},
+				},
+			}
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(pod).
+				Build()
+
+			injector := NewDiskFailureInjector(Dependencies{Client: fakeClient})
+
+			got, err := injector.Acknowledged(context.Background(), experiment)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DiskFailureInjector.Acknowledged() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("DiskFailureInjector.Acknowledged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package chaos
+
+import (
+	"fmt"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+// Reasons of the events injectors record
+const (
+	EventReasonPodTerminated      = "PodTerminated"
+	EventReasonLatencyApplied     = "LatencyApplied"
+	EventReasonLatencyRemoved     = "LatencyRemoved"
+	EventReasonNetemApplied       = "NetemApplied"
+	EventReasonNetemRemoved       = "NetemRemoved"
+	EventReasonDiskFailureApplied = "DiskFailureApplied"
+	EventReasonDiskFailureRemoved = "DiskFailureRemoved"
+	EventReasonReplicasScaled     = "ReplicasScaled"
+	EventReasonReplicasRestored   = "ReplicasRestored"
+	EventReasonKillRequested      = "KillRequested"
+	EventReasonPartitionApplied   = "PartitionApplied"
+	EventReasonPartitionRemoved   = "PartitionRemoved"
+	EventReasonPressureApplied    = "PressureApplied"
+	EventReasonPressureRemoved    = "PressureRemoved"
+	EventReasonDataCorrupted      = "DataCorrupted"
+	EventReasonDataRestored       = "DataRestored"
+	EventReasonStateDelayApplied  = "StateDelayApplied"
+	EventReasonStateDelayRemoved  = "StateDelayRemoved"
+	EventReasonOverloadStarted    = "OverloadStarted"
+	EventReasonOverloadStopped    = "OverloadStopped"
+	EventReasonVolumeFailed       = "VolumeFailed"
+	EventReasonVolumeRestored     = "VolumeRestored"
+)
+
+// eventRecorder records an injector's events on the experiment and on the
+// object the chaos was applied to. The zero value drops all events.
+type eventRecorder struct {
+	recorder record.EventRecorder
+}
+
+// event records an event on the experiment and, if not nil, on the object.
+// The object's event names the experiment so it can be traced back.
+func (e *eventRecorder) event(experiment *chaosv1alpha1.Havock8sExperiment, object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	if e.recorder == nil {
+		return
+	}
+
+	message := fmt.Sprintf(messageFmt, args...)
+	e.recorder.Event(experiment, eventtype, reason, message)
+	if object != nil {
+		e.recorder.Eventf(object, eventtype, reason, "%s (havock8s experiment %s/%s)",
+			message, experiment.Namespace, experiment.Name)
+	}
+}
+
+// targetAPIVersions maps target kinds to the API version of the kind
+var targetAPIVersions = map[string]string{
+	"Pod":                   "v1",
+	"PersistentVolume":      "v1",
+	"PersistentVolumeClaim": "v1",
+	"Service":               "v1",
+	"StatefulSet":           "apps/v1",
+	"Deployment":            "apps/v1",
+	"ReplicaSet":            "apps/v1",
+	"DaemonSet":             "apps/v1",
+	"Job":                   "batch/v1",
+}
+
+// TargetReference returns an object reference events about a target can be
+// recorded on without fetching the target
+func TargetReference(target chaosv1alpha1.TargetResourceStatus) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		APIVersion: targetAPIVersions[target.Kind],
+		Kind:       target.Kind,
+		Namespace:  target.Namespace,
+		Name:       target.Name,
+		UID:        types.UID(target.UID),
+	}
+}
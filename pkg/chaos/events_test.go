@@ -0,0 +1,125 @@
+package chaos
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// drainEvents returns the events recorded so far
+func drainEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestEventRecorder_Event(t *testing.T) {
+	experiment := &chaosv1alpha1.Havock8sExperiment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-experiment", Namespace: "default"},
+	}
+	pod := &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default"},
+	}
+
+	// Without a recorder events are dropped
+	var events eventRecorder
+	events.event(experiment, pod, corev1.EventTypeNormal, EventReasonPodTerminated, "Terminated pod %s", pod.Name)
+
+	recorder := record.NewFakeRecorder(10)
+	recorder.IncludeObject = true
+	events = eventRecorder{recorder: recorder}
+
+	events.event(experiment, pod, corev1.EventTypeNormal, EventReasonPodTerminated, "Terminated pod %s", pod.Name)
+	events.event(experiment, nil, corev1.EventTypeWarning, "Failed", "No pod")
+
+	want := []string{
+		"Normal PodTerminated Terminated pod test-pod involvedObject{kind=,apiVersion=}",
+		"Normal PodTerminated Terminated pod test-pod (havock8s experiment default/test-experiment) involvedObject{kind=Pod,apiVersion=v1}",
+		"Warning Failed No pod involvedObject{kind=,apiVersion=}",
+	}
+	if got := drainEvents(recorder); !reflect.DeepEqual(got, want) {
+		t.Errorf("recorded events = %q, want %q", got, want)
+	}
+}
+
+func TestTargetReference(t *testing.T) {
+	ref := TargetReference(chaosv1alpha1.TargetResourceStatus{
+		Kind:      "StatefulSet",
+		Name:      "test-sts",
+		Namespace: "default",
+		UID:       "1234",
+	})
+
+	want := &corev1.ObjectReference{
+		APIVersion: "apps/v1",
+		Kind:       "StatefulSet",
+		Name:       "test-sts",
+		Namespace:  "default",
+		UID:        "1234",
+	}
+	if !reflect.DeepEqual(ref, want) {
+		t.Errorf("TargetReference() = %+v, want %+v", ref, want)
+	}
+}
+
+func TestStatefulSetScalingInjector_RecordsEvents(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = appsv1.AddToScheme(scheme)
+	_ = chaosv1alpha1.AddToScheme(scheme)
+
+	experiment := &chaosv1alpha1.Havock8sExperiment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-experiment", Namespace: "default"},
+		Spec: chaosv1alpha1.Havock8sExperimentSpec{
+			Parameters: map[string]string{"scaleMode": "down"},
+		},
+		Status: chaosv1alpha1.Havock8sExperimentStatus{
+			TargetResources: []chaosv1alpha1.TargetResourceStatus{
+				{Kind: "StatefulSet", Name: "test-sts", Namespace: "default"},
+			},
+		},
+	}
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-sts", Namespace: "default"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(3)},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(statefulSet).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+
+	injector := NewStatefulSetScalingInjector(Dependencies{Client: fakeClient, Recorder: recorder})
+
+	if err := injector.Inject(context.Background(), experiment); err != nil {
+		t.Fatalf("Inject() error = %v", err)
+	}
+	if err := injector.Cleanup(context.Background(), experiment); err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+
+	want := []string{
+		"Normal ReplicasScaled Scaled StatefulSet default/test-sts from 3 to 2 replicas",
+		"Normal ReplicasScaled Scaled StatefulSet default/test-sts from 3 to 2 replicas (havock8s experiment default/test-experiment)",
+		"Normal ReplicasRestored Restored StatefulSet default/test-sts to 3 replicas",
+		"Normal ReplicasRestored Restored StatefulSet default/test-sts to 3 replicas (havock8s experiment default/test-experiment)",
+	}
+	if got := drainEvents(recorder); !reflect.DeepEqual(got, want) {
+		t.Errorf("recorded events = %q, want %q", got, want)
+	}
+}
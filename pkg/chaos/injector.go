@@ -3,36 +3,109 @@ package chaos
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
 
 	"github.com/go-logr/logr"
 	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// Injector defines the interface for chaos injection
+// Injector defines the interface for chaos injection. Injectors are built
+// per experiment by their InjectorFactory and must not keep state between
+// calls; everything they need to clean up is recorded on the cluster.
 type Injector interface {
-	// SetClient sets the Kubernetes client
-	SetClient(c client.Client)
-
 	// Inject applies chaos to the target resources
-	Inject(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, log logr.Logger) error
+	Inject(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error
 
 	// Cleanup removes chaos from the target resources
-	Cleanup(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, log logr.Logger) error
+	Cleanup(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error
+}
+
+// Acknowledger is implemented by injectors whose chaos is applied
+// asynchronously by the havock8s node agent
+type Acknowledger interface {
+	// Acknowledged reports whether the node agent has applied the chaos to every target
+	Acknowledged(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) (bool, error)
+}
+
+// TargetObserver is implemented by injectors that report how the targets
+// reacted to the chaos
+type TargetObserver interface {
+	// ObserveTargets records what was observed on the targets in the
+	// experiment's TargetResources status
+	ObserveTargets(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error
+}
+
+// ParameterValidator is implemented by injectors that can check an
+// experiment's parameters before the experiment is admitted
+type ParameterValidator interface {
+	// ValidateParameters returns an error describing the first invalid parameter
+	ValidateParameters(experiment *chaosv1alpha1.Havock8sExperiment) error
+}
+
+// Dependencies are the collaborators an injector is built with
+type Dependencies struct {
+	// Client is the Kubernetes client chaos is applied with
+	Client client.Client
+
+	// Log is the logger of the reconcile the injector is built for
+	Log logr.Logger
+
+	// Recorder receives the injector's events. Events are dropped when nil.
+	Recorder record.EventRecorder
+}
+
+// InjectorFactory builds a new injector for a single experiment
+type InjectorFactory func(deps Dependencies) Injector
+
+var (
+	injectorsMu sync.RWMutex
+	injectors   = make(map[string]InjectorFactory)
+)
+
+// RegisterInjector registers the factory of a chaos type's injectors
+func RegisterInjector(name string, factory InjectorFactory) {
+	injectorsMu.Lock()
+	defer injectorsMu.Unlock()
+	injectors[name] = factory
 }
 
-var injectors = make(map[string]Injector)
+// UnregisterInjector removes a chaos type from the registry
+func UnregisterInjector(name string) {
+	injectorsMu.Lock()
+	defer injectorsMu.Unlock()
+	delete(injectors, name)
+}
 
-// RegisterInjector registers a new chaos injector
-func RegisterInjector(name string, injector Injector) {
-	injectors[name] = injector
+// IsRegistered reports whether an injector is registered for a chaos type
+func IsRegistered(name string) bool {
+	injectorsMu.RLock()
+	defer injectorsMu.RUnlock()
+	_, ok := injectors[name]
+	return ok
 }
 
-// GetInjector returns a chaos injector by name
-func GetInjector(name string) (Injector, error) {
-	injector, ok := injectors[name]
+// NewInjector builds an injector for a chaos type by name
+func NewInjector(name string, deps Dependencies) (Injector, error) {
+	injectorsMu.RLock()
+	factory, ok := injectors[name]
+	injectorsMu.RUnlock()
 	if !ok {
 		return nil, fmt.Errorf("no injector registered for chaos type: %s", name)
 	}
-	return injector, nil
+	return factory(deps), nil
+}
+
+// RegisteredChaosTypes returns the names of all registered injectors
+func RegisteredChaosTypes() []string {
+	injectorsMu.RLock()
+	defer injectorsMu.RUnlock()
+	names := make([]string, 0, len(injectors))
+	for name := range injectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
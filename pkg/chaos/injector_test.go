@@ -0,0 +1,75 @@
+package chaos
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNewInjector(t *testing.T) {
+	fakeClient := fake.NewClientBuilder().Build()
+	recorder := record.NewFakeRecorder(1)
+
+	tests := []struct {
+		name      string
+		chaosType string
+		wantErr   bool
+	}{
+		{
+			name:      "registered chaos type",
+			chaosType: "PodFailure",
+		},
+		{
+			name:      "unknown chaos type",
+			chaosType: "Meteor",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := Dependencies{Client: fakeClient, Recorder: recorder}
+			injector, err := NewInjector(tt.chaosType, deps)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewInjector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			podFailure, ok := injector.(*PodFailureInjector)
+			if !ok {
+				t.Fatalf("NewInjector() = %T, want *PodFailureInjector", injector)
+			}
+			if podFailure.client != fakeClient || podFailure.recorder != recorder {
+				t.Error("Injector was not built with the given dependencies")
+			}
+
+			// Every experiment gets its own injector
+			other, _ := NewInjector(tt.chaosType, deps)
+			if other == injector {
+				t.Error("NewInjector() returned a shared injector")
+			}
+		})
+	}
+}
+
+func TestRegisterInjector_Concurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for n := 0; n < 10; n++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			name := fmt.Sprintf("Concurrent%d", n)
+			RegisterInjector(name, func(deps Dependencies) Injector { return NewPodFailureInjector(deps) })
+			if _, err := NewInjector(name, Dependencies{}); err != nil {
+				t.Errorf("NewInjector(%q) error = %v", name, err)
+			}
+			RegisteredChaosTypes()
+		}(n)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,255 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/agent"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// KillInjector implements the Injector interface for the ContainerKill and
+// ProcessKill chaos types. The node agent on the node running each pod sends
+// the signal, so the container is restarted in place by the kubelet instead of
+// the pod being rescheduled.
+type KillInjector struct {
+	eventRecorder
+	client client.Client
+	log    logr.Logger
+
+	// processes is set for ProcessKill, which signals the processes matching
+	// a pattern instead of the container's main process
+	processes bool
+}
+
+// killParams holds the validated kill parameters
+type killParams struct {
+	container string
+	signal    string
+	process   string
+}
+
+// NewContainerKillInjector creates a container kill injector using the given dependencies
+func NewContainerKillInjector(deps Dependencies) *KillInjector {
+	return &KillInjector{
+		eventRecorder: eventRecorder{recorder: deps.Recorder},
+		client:        deps.Client,
+		log:           deps.Log,
+	}
+}
+
+// NewProcessKillInjector creates a process kill injector using the given dependencies
+func NewProcessKillInjector(deps Dependencies) *KillInjector {
+	injector := NewContainerKillInjector(deps)
+	injector.processes = true
+	return injector
+}
+
+// Inject asks the node agent to signal the targeted containers
+func (i *KillInjector) Inject(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Injecting kill chaos", "chaosType", experiment.Spec.ChaosType)
+
+	params, err := i.parseParams(experiment)
+	if err != nil {
+		return err
+	}
+
+	i.log.Info("Kill parameters", "container", params.container, "signal", params.signal, "process", params.process)
+
+	pods, err := targetPods(ctx, i.client, experiment)
+	if err != nil {
+		return err
+	}
+	for idx := range pods {
+		if err := i.injectPodKill(ctx, experiment, &pods[idx], params); err != nil {
+			return err
+		}
+	}
+
+	i.log.Info("Kill chaos injection completed", "pods", len(pods))
+	return nil
+}
+
+// Cleanup removes the kill request from the targeted pods. Killed processes
+// are restarted by the kubelet, so there is nothing else to revert.
+func (i *KillInjector) Cleanup(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Cleaning up kill chaos", "chaosType", experiment.Spec.ChaosType)
+
+	for _, target := range experiment.Status.TargetResources {
+		pods, err := podsOfTarget(ctx, i.client, target)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				i.log.Info("Pod no longer exists, skipping cleanup", "pod", target.Name)
+				continue
+			}
+			return err
+		}
+
+		for idx := range pods {
+			pod := &pods[idx]
+			if _, ok := pod.Annotations[agent.KillAnnotation]; !ok {
+				continue
+			}
+			remove := []string{
+				agent.KillAnnotation,
+				agent.KillContainerAnnotation,
+				agent.KillSignalAnnotation,
+				agent.KillProcessAnnotation,
+				agent.KillRestartCountAnnotation,
+			}
+			if err := patchPodAnnotations(ctx, i.client, pod, nil, remove); err != nil {
+				return fmt.Errorf("failed to update pod %s/%s: %w", pod.Namespace, pod.Name, err)
+			}
+			i.log.Info("Removed kill request from pod", "pod", pod.Name)
+		}
+	}
+
+	i.log.Info("Kill chaos cleanup completed")
+	return nil
+}
+
+// Acknowledged reports whether the node agent has signalled every targeted pod
+func (i *KillInjector) Acknowledged(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) (bool, error) {
+	return podsAcknowledged(ctx, i.client, experiment, agent.KillAckAnnotation, i.log)
+}
+
+// ObserveTargets reports how often the killed containers of each target
+// restarted since the kill was requested
+func (i *KillInjector) ObserveTargets(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	for idx := range experiment.Status.TargetResources {
+		target := &experiment.Status.TargetResources[idx]
+		pods, err := podsOfTarget(ctx, i.client, *target)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+
+		var restarts int32
+		observed := false
+		for _, pod := range pods {
+			if count, ok := containerRestarts(pod); ok {
+				restarts += count
+				observed = true
+			}
+		}
+		if observed {
+			target.RestartCount = &restarts
+		}
+	}
+	return nil
+}
+
+// ValidateParameters checks the kill parameters of an experiment
+func (i *KillInjector) ValidateParameters(experiment *chaosv1alpha1.Havock8sExperiment) error {
+	_, err := i.parseParams(experiment)
+	return err
+}
+
+// parseParams validates the kill parameters of an experiment
+func (i *KillInjector) parseParams(experiment *chaosv1alpha1.Havock8sExperiment) (killParams, error) {
+	params := killParams{
+		container: experiment.Spec.Parameters["container"],
+		process:   experiment.Spec.Parameters["process"],
+	}
+
+	signal, err := agent.ParseSignal(experiment.Spec.Parameters["signal"])
+	if err != nil {
+		return params, err
+	}
+	params.signal = signal
+
+	if !i.processes {
+		if params.process != "" {
+			return params, fmt.Errorf("process parameter is only supported by ProcessKill")
+		}
+		return params, nil
+	}
+
+	if params.process == "" {
+		return params, fmt.Errorf("process parameter is required")
+	}
+	if _, err := regexp.Compile(params.process); err != nil {
+		return params, fmt.Errorf("invalid process pattern %q: %w", params.process, err)
+	}
+	return params, nil
+}
+
+// injectPodKill asks the node agent to signal the processes of a pod's container
+func (i *KillInjector) injectPodKill(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, pod *corev1.Pod, params killParams) error {
+	container := params.container
+	if container == "" {
+		if len(pod.Spec.Containers) == 0 {
+			return fmt.Errorf("pod %s/%s has no containers", pod.Namespace, pod.Name)
+		}
+		container = pod.Spec.Containers[0].Name
+	}
+	status, ok := containerStatus(pod, container)
+	if !ok {
+		return fmt.Errorf("pod %s/%s has no container %s", pod.Namespace, pod.Name, container)
+	}
+
+	// Set kill annotations for the node agent, dropping any acknowledgement
+	// left over from a previous experiment. The restart count lets later
+	// restarts be attributed to this kill.
+	set := map[string]string{
+		agent.KillAnnotation:             "true",
+		agent.KillContainerAnnotation:    container,
+		agent.KillSignalAnnotation:       params.signal,
+		agent.KillRestartCountAnnotation: strconv.Itoa(int(status.RestartCount)),
+	}
+	remove := []string{agent.KillAckAnnotation}
+	if params.process != "" {
+		set[agent.KillProcessAnnotation] = params.process
+	} else {
+		remove = append(remove, agent.KillProcessAnnotation)
+	}
+	if err := patchPodAnnotations(ctx, i.client, pod, set, remove); err != nil {
+		return fmt.Errorf("failed to update pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+
+	what := "main process"
+	if params.process != "" {
+		what = fmt.Sprintf("processes matching %q", params.process)
+	}
+	i.event(experiment, pod, corev1.EventTypeNormal, EventReasonKillRequested,
+		"Requested %s of the %s of container %s in pod %s/%s", params.signal, what, container, pod.Namespace, pod.Name)
+	i.log.Info("Requested kill of pod container", "pod", pod.Name, "container", container, "signal", params.signal)
+	return nil
+}
+
+// containerStatus returns the status of the named container of a pod
+func containerStatus(pod *corev1.Pod, container string) (corev1.ContainerStatus, bool) {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == container {
+			return status, true
+		}
+	}
+	return corev1.ContainerStatus{}, false
+}
+
+// containerRestarts returns how often the killed container of a pod restarted
+// since the kill was requested. Pods without a kill request report nothing.
+func containerRestarts(pod corev1.Pod) (int32, bool) {
+	baseline, err := strconv.Atoi(pod.Annotations[agent.KillRestartCountAnnotation])
+	if err != nil {
+		return 0, false
+	}
+	status, ok := containerStatus(&pod, pod.Annotations[agent.KillContainerAnnotation])
+	if !ok {
+		return 0, false
+	}
+	restarts := status.RestartCount - int32(baseline)
+	if restarts < 0 {
+		// The counter was reset, e.g. by a pod recreated under the same name
+		restarts = 0
+	}
+	return restarts, true
+}
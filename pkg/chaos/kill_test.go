@@ -0,0 +1,214 @@
+package chaos
+
+import (
+	"context"
+	"testing"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/agent"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newKillTestPod returns a database pod whose db container restarted twice
+func newKillTestPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "db-0",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "StatefulSet", Name: "db"},
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "db"}, {Name: "exporter"}},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "db", RestartCount: 2},
+				{Name: "exporter"},
+			},
+		},
+	}
+}
+
+func TestKillInjector_Inject(t *testing.T) {
+	tests := []struct {
+		name            string
+		chaosType       string
+		parameters      map[string]string
+		target          chaosv1alpha1.TargetResourceStatus
+		wantErr         bool
+		wantAnnotations map[string]string
+	}{
+		{
+			name:      "container kill defaults to SIGKILL of the first container",
+			chaosType: "ContainerKill",
+			target:    chaosv1alpha1.TargetResourceStatus{Kind: "Pod", Name: "db-0", Namespace: "default"},
+			wantAnnotations: map[string]string{
+				agent.KillAnnotation:             "true",
+				agent.KillContainerAnnotation:    "db",
+				agent.KillSignalAnnotation:       "SIGKILL",
+				agent.KillRestartCountAnnotation: "2",
+			},
+		},
+		{
+			name:       "process kill of a StatefulSet's pods",
+			chaosType:  "ProcessKill",
+			parameters: map[string]string{"container": "exporter", "signal": "TERM", "process": "^exporter"},
+			target:     chaosv1alpha1.TargetResourceStatus{Kind: "StatefulSet", Name: "db", Namespace: "default"},
+			wantAnnotations: map[string]string{
+				agent.KillAnnotation:             "true",
+				agent.KillContainerAnnotation:    "exporter",
+				agent.KillSignalAnnotation:       "SIGTERM",
+				agent.KillProcessAnnotation:      "^exporter",
+				agent.KillRestartCountAnnotation: "0",
+			},
+		},
+		{
+			name:       "unknown container",
+			chaosType:  "ContainerKill",
+			parameters: map[string]string{"container": "sidecar"},
+			target:     chaosv1alpha1.TargetResourceStatus{Kind: "Pod", Name: "db-0", Namespace: "default"},
+			wantErr:    true,
+		},
+		{
+			name:       "process kill without a pattern",
+			chaosType:  "ProcessKill",
+			parameters: map[string]string{"container": "db"},
+			target:     chaosv1alpha1.TargetResourceStatus{Kind: "Pod", Name: "db-0", Namespace: "default"},
+			wantErr:    true,
+		},
+		{
+			name:       "unsupported signal",
+			chaosType:  "ContainerKill",
+			parameters: map[string]string{"signal": "SIGSTOP"},
+			target:     chaosv1alpha1.TargetResourceStatus{Kind: "Pod", Name: "db-0", Namespace: "default"},
+			wantErr:    true,
+		},
+		{
+			name:      "missing pod",
+			chaosType: "ContainerKill",
+			target:    chaosv1alpha1.TargetResourceStatus{Kind: "Pod", Name: "db-1", Namespace: "default"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			fakeClient := newKubeletRacingClient(scheme, newKillTestPod())
+
+			injector, err := NewInjector(tt.chaosType, Dependencies{Client: fakeClient})
+			if err != nil {
+				t.Fatalf("NewInjector() error = %v", err)
+			}
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{ChaosType: tt.chaosType, Parameters: tt.parameters},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{tt.target},
+				},
+			}
+
+			err = injector.Inject(context.Background(), experiment)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Inject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			pod := &corev1.Pod{}
+			if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "db-0"}, pod); err != nil {
+				t.Fatalf("Failed to get pod: %v", err)
+			}
+			for key, want := range tt.wantAnnotations {
+				if got := pod.Annotations[key]; got != want {
+					t.Errorf("Annotation %s = %q, want %q", key, got, want)
+				}
+			}
+
+			if err := injector.Cleanup(context.Background(), experiment); err != nil {
+				t.Fatalf("Cleanup() error = %v", err)
+			}
+			if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "db-0"}, pod); err != nil {
+				t.Fatalf("Failed to get pod: %v", err)
+			}
+			for key := range tt.wantAnnotations {
+				if _, ok := pod.Annotations[key]; ok {
+					t.Errorf("Annotation %s still present after cleanup", key)
+				}
+			}
+		})
+	}
+}
+
+func TestKillInjector_ObserveTargets(t *testing.T) {
+	tests := []struct {
+		name         string
+		annotations  map[string]string
+		restartCount int32
+		want         *int32
+	}{
+		{
+			name: "restarts since the kill",
+			annotations: map[string]string{
+				agent.KillContainerAnnotation:    "db",
+				agent.KillRestartCountAnnotation: "2",
+			},
+			restartCount: 3,
+			want:         int32Ptr(1),
+		},
+		{
+			name: "not restarted yet",
+			annotations: map[string]string{
+				agent.KillContainerAnnotation:    "db",
+				agent.KillRestartCountAnnotation: "2",
+			},
+			restartCount: 2,
+			want:         int32Ptr(0),
+		},
+		{
+			name:         "no kill requested",
+			restartCount: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := newKillTestPod()
+			pod.Annotations = tt.annotations
+			pod.Status.ContainerStatuses[0].RestartCount = tt.restartCount
+
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod).Build()
+
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{
+						{Kind: "StatefulSet", Name: "db", Namespace: "default"},
+						{Kind: "Pod", Name: "gone", Namespace: "default"},
+					},
+				},
+			}
+
+			injector := NewContainerKillInjector(Dependencies{Client: fakeClient})
+			if err := injector.ObserveTargets(context.Background(), experiment); err != nil {
+				t.Fatalf("ObserveTargets() error = %v", err)
+			}
+
+			got := experiment.Status.TargetResources[0].RestartCount
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("RestartCount = %v, want %v", got, tt.want)
+			}
+			if experiment.Status.TargetResources[1].RestartCount != nil {
+				t.Errorf("RestartCount of a missing pod = %v, want none", *experiment.Status.TargetResources[1].RestartCount)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/agent"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Netem effects, each named after the experiment parameter that sets it
+const (
+	NetemEffectLatency   = "latency"
+	NetemEffectLoss      = "loss"
+	NetemEffectDuplicate = "duplicate"
+	NetemEffectCorrupt   = "corrupt"
+	NetemEffectReorder   = "reorder"
+	NetemEffectRate      = "rate"
+)
+
+// netemParameters maps the netem experiment parameters to the annotations
+// the node agent reads them from
+var netemParameters = map[string]string{
+	"latency":     agent.NetworkLatencyValueAnnotation,
+	"jitter":      agent.NetworkJitterValueAnnotation,
+	"correlation": agent.NetworkCorrelationValueAnnotation,
+	"loss":        agent.NetworkLossValueAnnotation,
+	"duplicate":   agent.NetworkDuplicateValueAnnotation,
+	"corrupt":     agent.NetworkCorruptValueAnnotation,
+	"reorder":     agent.NetworkReorderValueAnnotation,
+	"rate":        agent.NetworkRateValueAnnotation,
+	"ports":       agent.NetworkPortsAnnotation,
+}
+
+// NetemInjector implements the Injector interface for the network chaos the
+// node agent applies with tc netem. Each chaos type requires one effect but
+// accepts the parameters of the others, so e.g. a lossy link can also be slow.
+type NetemInjector struct {
+	eventRecorder
+	client client.Client
+	log    logr.Logger
+	effect string
+}
+
+// NewNetemInjector creates an injector of a netem effect using the given dependencies
+func NewNetemInjector(effect string, deps Dependencies) *NetemInjector {
+	return &NetemInjector{
+		eventRecorder: eventRecorder{recorder: deps.Recorder},
+		client:        deps.Client,
+		log:           deps.Log,
+		effect:        effect,
+	}
+}
+
+// NewNetworkLatencyInjector creates a network latency injector using the given dependencies
+func NewNetworkLatencyInjector(deps Dependencies) *NetemInjector {
+	return NewNetemInjector(NetemEffectLatency, deps)
+}
+
+// Inject applies network chaos
+func (i *NetemInjector) Inject(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Injecting network chaos", "effect", i.effect)
+
+	annotations, err := i.netemAnnotations(experiment)
+	if err != nil {
+		return err
+	}
+
+	i.log.Info("Network chaos parameters", "annotations", annotations)
+
+	pods, err := targetPods(ctx, i.client, experiment)
+	if err != nil {
+		return err
+	}
+	for idx := range pods {
+		if err := i.injectPodNetem(ctx, experiment, &pods[idx], annotations); err != nil {
+			return err
+		}
+	}
+
+	i.log.Info("Network chaos injection completed", "effect", i.effect, "pods", len(pods))
+	return nil
+}
+
+// ValidateParameters checks the netem parameters the way the node agent will parse them
+func (i *NetemInjector) ValidateParameters(experiment *chaosv1alpha1.Havock8sExperiment) error {
+	annotations, err := i.netemAnnotations(experiment)
+	if err != nil {
+		return err
+	}
+	_, err = agent.ParseNetemSpec(annotations)
+	return err
+}
+
+// netemAnnotations returns the agent annotations for the netem parameters of
+// an experiment with the defaults of the injector's effect applied
+func (i *NetemInjector) netemAnnotations(experiment *chaosv1alpha1.Havock8sExperiment) (map[string]string, error) {
+	params := map[string]string{}
+	switch i.effect {
+	case NetemEffectLatency:
+		params["latency"] = "100ms"
+		params["jitter"] = "10ms"
+		params["correlation"] = "75"
+	case NetemEffectReorder:
+		// netem only reorders packets it delays
+		params["latency"] = "10ms"
+	}
+
+	// Percentage effects default to the experiment's intensity
+	switch i.effect {
+	case NetemEffectLoss, NetemEffectDuplicate, NetemEffectCorrupt, NetemEffectReorder:
+		if experiment.Spec.Intensity > 0 {
+			params[i.effect] = strconv.FormatFloat(experiment.Spec.Intensity*100, 'f', -1, 64)
+		}
+	}
+
+	// Override defaults with experiment parameters if provided
+	for name := range netemParameters {
+		if val, ok := experiment.Spec.Parameters[name]; ok {
+			params[name] = val
+		}
+	}
+	if params[i.effect] == "" {
+		return nil, fmt.Errorf("%s parameter is required", i.effect)
+	}
+
+	annotations := make(map[string]string, len(params))
+	for name, val := range params {
+		if val == "" {
+			continue
+		}
+		if name != "ports" && name != "rate" {
+			val = strings.TrimSuffix(val, "%") // Remove % suffix if present
+		}
+		annotations[netemParameters[name]] = val
+	}
+	return annotations, nil
+}
+
+// Cleanup removes network chaos
+func (i *NetemInjector) Cleanup(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Cleaning up network chaos", "effect", i.effect)
+
+	for _, target := range experiment.Status.TargetResources {
+		pods, err := podsOfTarget(ctx, i.client, target)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				// The qdisc went away with the pod
+				i.log.Info("Pod no longer exists, skipping cleanup", "pod", target.Name)
+				continue
+			}
+			return err
+		}
+
+		for idx := range pods {
+			if err := i.cleanupPodNetem(ctx, experiment, &pods[idx]); err != nil {
+				return err
+			}
+		}
+	}
+
+	i.log.Info("Network chaos cleanup completed", "effect", i.effect)
+	return nil
+}
+
+// eventReasons returns the reasons of the events recorded when the chaos is
+// applied and removed. Latency keeps its own reasons.
+func (i *NetemInjector) eventReasons() (applied, removed string) {
+	if i.effect == NetemEffectLatency {
+		return EventReasonLatencyApplied, EventReasonLatencyRemoved
+	}
+	return EventReasonNetemApplied, EventReasonNetemRemoved
+}
+
+// injectPodNetem requests network chaos on a pod
+func (i *NetemInjector) injectPodNetem(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, pod *corev1.Pod, annotations map[string]string) error {
+	// Set the netem annotations for the node agent, replacing those of a
+	// previous experiment and dropping its acknowledgement
+	set := map[string]string{agent.NetworkLatencyAnnotation: "true"}
+	for annotation, val := range annotations {
+		set[annotation] = val
+	}
+	remove := []string{agent.NetworkLatencyAckAnnotation}
+	for _, annotation := range netemParameters {
+		remove = append(remove, annotation)
+	}
+	if err := patchPodAnnotations(ctx, i.client, pod, set, remove); err != nil {
+		return fmt.Errorf("failed to update pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+
+	applied, _ := i.eventReasons()
+	i.event(experiment, pod, corev1.EventTypeNormal, applied,
+		"Requested %s on pod %s/%s", describeNetem(annotations), pod.Namespace, pod.Name)
+	i.log.Info("Applied network chaos to pod", "pod", pod.Name, "effect", i.effect)
+	return nil
+}
+
+// cleanupPodNetem removes network chaos from a pod
+func (i *NetemInjector) cleanupPodNetem(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, pod *corev1.Pod) error {
+	if _, ok := pod.Annotations[agent.NetworkLatencyAnnotation]; !ok {
+		return nil
+	}
+
+	// Remove the netem annotations. The node agent removes the qdisc and its
+	// acknowledgement once it sees the request is gone.
+	remove := []string{agent.NetworkLatencyAnnotation}
+	for _, annotation := range netemParameters {
+		remove = append(remove, annotation)
+	}
+	if err := patchPodAnnotations(ctx, i.client, pod, nil, remove); err != nil {
+		return fmt.Errorf("failed to update pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+
+	_, removed := i.eventReasons()
+	i.event(experiment, pod, corev1.EventTypeNormal, removed,
+		"Removed network chaos from pod %s/%s", pod.Namespace, pod.Name)
+	i.log.Info("Removed network chaos from pod", "pod", pod.Name)
+	return nil
+}
+
+// Acknowledged reports whether the node agent has applied the netem qdisc to every targeted pod
+func (i *NetemInjector) Acknowledged(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) (bool, error) {
+	return podsAcknowledged(ctx, i.client, experiment, agent.NetworkLatencyAckAnnotation, i.log)
+}
+
+// describeNetem summarizes netem annotations for events, e.g. "delay 100ms
+// 10ms 75% loss 5%"
+func describeNetem(annotations map[string]string) string {
+	spec, err := agent.ParseNetemSpec(annotations)
+	if err != nil {
+		return "network chaos"
+	}
+	description := strings.Join(spec.Args(), " ")
+	if len(spec.Ports) > 0 {
+		description += " on ports " + annotations[agent.NetworkPortsAnnotation]
+	}
+	return description
+}
@@ -0,0 +1,492 @@
+package chaos
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/agent"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNetworkLatencyInjector_Inject(t *testing.T) {
+	tests := []struct {
+		name        string
+		experiment  *chaosv1alpha1.Havock8sExperiment
+		pod         *corev1.Pod
+		wantErr     bool
+		wantLatency string
+		wantJitter  string
+	}{
+		{
+			name: "successful injection on pod",
+			experiment: &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					Parameters: map[string]string{
+						"latency":     "200ms",
+						"jitter":      "50ms",
+						"correlation": "75%",
+						"ports":       "6379,6380",
+					},
+				},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{
+						{
+							Kind:      "Pod",
+							Name:      "test-pod",
+							Namespace: "default",
+						},
+					},
+				},
+			},
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-pod",
+					Namespace: "default",
+				},
+			},
+			wantErr:     false,
+			wantLatency: "200ms",
+			wantJitter:  "50ms",
+		},
+		{
+			name: "missing pod",
+			experiment: &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					Parameters: map[string]string{
+						"latency": "200ms",
+					},
+				},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{
+						{
+							Kind:      "Pod",
+							Name:      "non-existent-pod",
+							Namespace: "default",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			_ = chaosv1alpha1.AddToScheme(scheme)
+
+			objs := []client.Object{tt.experiment}
+			if tt.pod != nil {
+				objs = append(objs, tt.pod)
+			}
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(objs...).
+				Build()
+
+			injector := NewNetworkLatencyInjector(Dependencies{Client: fakeClient})
+
+			err := injector.Inject(context.Background(), tt.experiment)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NetemInjector.Inject() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && tt.pod != nil {
+				updatedPod := &corev1.Pod{}
+				err := fakeClient.Get(context.Background(), client.ObjectKey{
+					Namespace: tt.pod.Namespace,
+					Name:      tt.pod.Name,
+				}, updatedPod)
+				if err != nil {
+					t.Errorf("Failed to get updated pod: %v", err)
+					return
+				}
+
+				if updatedPod.Annotations["havock8s.io/network-latency"] != "true" {
+					t.Error("Network latency annotation not set")
+				}
+				if updatedPod.Annotations["havock8s.io/network-latency-value"] != tt.wantLatency {
+					t.Errorf("Latency value = %v, want %v", 
+						updatedPod.Annotations["havock8s.io/network-latency-value"], 
+						tt.wantLatency)
+				}
+				if updatedPod.Annotations["havock8s.io/network-jitter-value"] != tt.wantJitter {
+					t.Errorf("Jitter value = %v, want %v", 
+						updatedPod.Annotations["havock8s.io/network-jitter-value"], 
+						tt.wantJitter)
+				}
+			}
+		})
+	}
+}
+
+func TestNetworkLatencyInjector_Cleanup(t *testing.T) {
+	tests := []struct {
+		name       string
+		experiment *chaosv1alpha1.Havock8sExperiment
+		pod        *corev1.Pod
+		wantErr    bool
+	}{
+		{
+			name: "successful cleanup",
+			experiment: &chaosv1alpha1.Havock8sExperiment{
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{
+						{
+							Kind:      "Pod",
+							Name:      "test-pod",
+							Namespace: "default",
+						},
+					},
+				},
+			},
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-pod",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"havock8s.io/network-latency":           "true",
+						"havock8s.io/network-latency-value":     "200ms",
+						"havock8s.io/network-jitter-value":      "50ms",
+						"havock8s.io/network-correlation-value": "75%",
+						"havock8s.io/network-ports":             "6379,6380",
+					},
+				},
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			_ = chaosv1alpha1.AddToScheme(scheme)
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(tt.experiment, tt.pod).
+				Build()
+
+			injector := NewNetworkLatencyInjector(Dependencies{Client: fakeClient})
+
+			err := injector.Cleanup(context.Background(), tt.experiment)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NetemInjector.Cleanup() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr {
+				updatedPod := &corev1.Pod{}
+				err := fakeClient.Get(context.Background(), client.ObjectKey{
+					Namespace: tt.pod.Namespace,
+					Name:      tt.pod.Name,
+				}, updatedPod)
+				if err != nil {
+					t.Errorf("Failed to get updated pod: %v", err)
+					return
+				}
+
+				if _, ok := updatedPod.Annotations["havock8s.io/network-latency"]; ok {
+					t.Error("Network latency annotation still present after cleanup")
+				}
+			}
+		})
+	}
+} 
+func TestNetworkLatencyInjector_Acknowledged(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        bool
+		wantErr     bool
+	}{
+		{
+			name: "waiting for agent",
+			annotations: map[string]string{
+				"havock8s.io/network-latency": "true",
+			},
+			want: false,
+		},
+		{
+			name: "applied by agent",
+			annotations: map[string]string{
+				"havock8s.io/network-latency":     "true",
+				"havock8s.io/network-latency-ack": "applied",
+			},
+			want: true,
+		},
+		{
+			name: "agent failure",
+			annotations: map[string]string{
+				"havock8s.io/network-latency":     "true",
+				"havock8s.io/network-latency-ack": "failed: tc not found",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			_ = chaosv1alpha1.AddToScheme(scheme)
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-pod",
+					Namespace:   "default",
+					Annotations: tt.annotations,
+				},
+			}
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{
+						{
+							Kind:      "Pod",
+							Name:      "test-pod",
+							Namespace: "default",
+						},
+					},
+				},
+			}
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(pod).
+				Build()
+
+			injector := NewNetworkLatencyInjector(Dependencies{Client: fakeClient})
+
+			got, err := injector.Acknowledged(context.Background(), experiment)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NetemInjector.Acknowledged() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("NetemInjector.Acknowledged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetemInjector_Effects(t *testing.T) {
+	tests := []struct {
+		name            string
+		chaosType       string
+		intensity       float64
+		parameters      map[string]string
+		wantErr         bool
+		wantAnnotations map[string]string
+	}{
+		{
+			name:      "loss defaults to the intensity",
+			chaosType: "NetworkLoss",
+			intensity: 0.3,
+			wantAnnotations: map[string]string{
+				agent.NetworkLossValueAnnotation: "30",
+			},
+		},
+		{
+			name:       "duplication restricted to ports",
+			chaosType:  "NetworkDuplicate",
+			parameters: map[string]string{"duplicate": "5%", "ports": "27017"},
+			wantAnnotations: map[string]string{
+				agent.NetworkDuplicateValueAnnotation: "5",
+				agent.NetworkPortsAnnotation:          "27017",
+			},
+		},
+		{
+			name:       "corruption of a slow link",
+			chaosType:  "NetworkCorrupt",
+			parameters: map[string]string{"corrupt": "1", "latency": "50ms"},
+			wantAnnotations: map[string]string{
+				agent.NetworkCorruptValueAnnotation: "1",
+				agent.NetworkLatencyValueAnnotation: "50ms",
+			},
+		},
+		{
+			name:       "reordering delays the other packets",
+			chaosType:  "NetworkReorder",
+			parameters: map[string]string{"reorder": "25"},
+			wantAnnotations: map[string]string{
+				agent.NetworkReorderValueAnnotation: "25",
+				agent.NetworkLatencyValueAnnotation: "10ms",
+			},
+		},
+		{
+			name:       "bandwidth limit",
+			chaosType:  "NetworkBandwidth",
+			parameters: map[string]string{"rate": "1mbit"},
+			wantAnnotations: map[string]string{
+				agent.NetworkRateValueAnnotation: "1mbit",
+			},
+		},
+		{
+			name:      "bandwidth limit without a rate",
+			chaosType: "NetworkBandwidth",
+			intensity: 0.5,
+			wantErr:   true,
+		},
+		{
+			name:       "invalid loss",
+			chaosType:  "NetworkLoss",
+			parameters: map[string]string{"loss": "150"},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-pod",
+				Namespace: "default",
+				Annotations: map[string]string{
+					agent.NetworkRateValueAnnotation:  "10kbit",
+					agent.NetworkLatencyAckAnnotation: "applied",
+				},
+			}}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod).Build()
+
+			injector, err := NewInjector(tt.chaosType, Dependencies{Client: fakeClient})
+			if err != nil {
+				t.Fatalf("NewInjector() error = %v", err)
+			}
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					ChaosType:  tt.chaosType,
+					Intensity:  tt.intensity,
+					Parameters: tt.parameters,
+				},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{
+						{Kind: "Pod", Name: "test-pod", Namespace: "default"},
+					},
+				},
+			}
+
+			err = injector.(ParameterValidator).ValidateParameters(experiment)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateParameters() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if err := injector.Inject(context.Background(), experiment); err != nil {
+				t.Fatalf("Inject() error = %v", err)
+			}
+
+			if err := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(pod), pod); err != nil {
+				t.Fatalf("Failed to get pod: %v", err)
+			}
+			want := map[string]string{agent.NetworkLatencyAnnotation: "true"}
+			for key, val := range tt.wantAnnotations {
+				want[key] = val
+			}
+			if !reflect.DeepEqual(pod.Annotations, want) {
+				t.Errorf("Annotations = %v, want %v", pod.Annotations, want)
+			}
+
+			if err := injector.Cleanup(context.Background(), experiment); err != nil {
+				t.Fatalf("Cleanup() error = %v", err)
+			}
+			if err := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(pod), pod); err != nil {
+				t.Fatalf("Failed to get pod: %v", err)
+			}
+			if len(pod.Annotations) != 0 {
+				t.Errorf("Annotations after cleanup = %v, want none", pod.Annotations)
+			}
+		})
+	}
+}
+
+func TestNetemInjector_WorkloadAndClaimTargets(t *testing.T) {
+	tests := []struct {
+		name   string
+		target chaosv1alpha1.TargetResourceStatus
+	}{
+		{
+			name:   "DaemonSet",
+			target: chaosv1alpha1.TargetResourceStatus{Kind: "DaemonSet", Name: "cache", Namespace: "default"},
+		},
+		{
+			name:   "PersistentVolumeClaim",
+			target: chaosv1alpha1.TargetResourceStatus{Kind: "PersistentVolumeClaim", Name: "data-cache", Namespace: "default"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			_ = chaosv1alpha1.AddToScheme(scheme)
+
+			target := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "cache-x7k2p",
+					Namespace:       "default",
+					OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: "cache"}},
+				},
+				Spec: corev1.PodSpec{
+					Volumes: []corev1.Volume{{
+						Name: "data",
+						VolumeSource: corev1.VolumeSource{
+							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-cache"},
+						},
+					}},
+				},
+			}
+			bystander := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "default"}}
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					Parameters: map[string]string{"latency": "200ms"},
+				},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{tt.target},
+				},
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(target, bystander).
+				Build()
+			injector := NewNetworkLatencyInjector(Dependencies{Client: fakeClient})
+			ctx := context.Background()
+
+			if err := injector.Inject(ctx, experiment); err != nil {
+				t.Fatalf("NetemInjector.Inject() error = %v", err)
+			}
+			for _, pod := range []*corev1.Pod{target, bystander} {
+				if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(pod), pod); err != nil {
+					t.Fatalf("Failed to get pod: %v", err)
+				}
+			}
+			if target.Annotations["havock8s.io/network-latency-value"] != "200ms" {
+				t.Errorf("Latency value = %q, want 200ms", target.Annotations["havock8s.io/network-latency-value"])
+			}
+			if _, ok := bystander.Annotations["havock8s.io/network-latency"]; ok {
+				t.Error("Network latency requested on a pod that isn't targeted")
+			}
+
+			if err := injector.Cleanup(ctx, experiment); err != nil {
+				t.Fatalf("NetemInjector.Cleanup() error = %v", err)
+			}
+			if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(target), target); err != nil {
+				t.Fatalf("Failed to get pod: %v", err)
+			}
+			if _, ok := target.Annotations["havock8s.io/network-latency"]; ok {
+				t.Error("Network latency annotation still present after cleanup")
+			}
+		})
+	}
+}
@@ -0,0 +1,450 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/agent"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Network partition methods
+const (
+	// PartitionMethodNetworkPolicy partitions pods with a NetworkPolicy
+	PartitionMethodNetworkPolicy = "networkpolicy"
+
+	// PartitionMethodAgent partitions pods with iptables rules installed by the node agent
+	PartitionMethodAgent = "agent"
+)
+
+// PartitionLabel marks the pods a NetworkPolicy partition applies to. Its
+// value is the UID of the experiment.
+const PartitionLabel = "havock8s.io/partitioned-by"
+
+// NetworkPartitionInjector implements the Injector interface for network
+// partition chaos. Pods are cut off from the pods matching a peer selector,
+// from a list of CIDRs, or from everything. Symmetric partitions are applied
+// with a NetworkPolicy. NetworkPolicies only act on new connections, so
+// one-way partitions and CIDRs are applied with iptables by the node agent.
+type NetworkPartitionInjector struct {
+	eventRecorder
+	client client.Client
+	log    logr.Logger
+}
+
+// networkPartitionParams holds the validated network partition parameters
+type networkPartitionParams struct {
+	direction    string
+	method       string
+	peerSelector string
+	cidrs        []string
+}
+
+// NewNetworkPartitionInjector creates a network partition injector using the given dependencies
+func NewNetworkPartitionInjector(deps Dependencies) *NetworkPartitionInjector {
+	return &NetworkPartitionInjector{
+		eventRecorder: eventRecorder{recorder: deps.Recorder},
+		client:        deps.Client,
+		log:           deps.Log,
+	}
+}
+
+// Inject applies network partition chaos
+func (i *NetworkPartitionInjector) Inject(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Injecting network partition chaos")
+
+	params, err := parseNetworkPartitionParams(experiment)
+	if err != nil {
+		return err
+	}
+
+	i.log.Info("Network partition parameters",
+		"direction", params.direction,
+		"method", params.method,
+		"peerSelector", params.peerSelector,
+		"cidrs", params.cidrs)
+
+	pods, err := targetPods(ctx, i.client, experiment)
+	if err != nil {
+		return err
+	}
+
+	// Peers are looked up in the namespace of each partitioned pod
+	peerIPs := make(map[string][]string)
+	for idx := range pods {
+		pod := &pods[idx]
+		ips, ok := peerIPs[pod.Namespace]
+		if !ok {
+			ips, err = i.peerIPs(ctx, pod.Namespace, params.peerSelector)
+			if err != nil {
+				return err
+			}
+			peerIPs[pod.Namespace] = ips
+		}
+
+		if params.method == PartitionMethodAgent {
+			err = i.injectPodPartition(ctx, experiment, pod, params, ips)
+		} else {
+			err = i.labelPartitionedPod(ctx, experiment, pod, params)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if params.method == PartitionMethodNetworkPolicy {
+		for namespace, ips := range peerIPs {
+			if err := i.createNetworkPolicy(ctx, experiment, namespace, params, ips); err != nil {
+				return err
+			}
+		}
+	}
+
+	i.log.Info("Network partition chaos injection completed", "pods", len(pods))
+	return nil
+}
+
+// Cleanup removes network partition chaos. Both methods are cleaned up so
+// nothing is left behind whatever the parameters were changed to.
+func (i *NetworkPartitionInjector) Cleanup(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Cleaning up network partition chaos")
+
+	namespaces := make(map[string]bool)
+	for _, target := range experiment.Status.TargetResources {
+		namespaces[target.Namespace] = true
+
+		pods, err := podsOfTarget(ctx, i.client, target)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				i.log.Info("Pod no longer exists, skipping cleanup", "pod", target.Name)
+				continue
+			}
+			return err
+		}
+		for idx := range pods {
+			if err := i.cleanupPodPartition(ctx, experiment, &pods[idx]); err != nil {
+				return err
+			}
+		}
+	}
+
+	for namespace := range namespaces {
+		policy := &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: partitionPolicyName(experiment), Namespace: namespace},
+		}
+		if err := i.client.Delete(ctx, policy); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete NetworkPolicy %s/%s: %w", namespace, policy.Name, err)
+		}
+	}
+
+	i.log.Info("Network partition chaos cleanup completed")
+	return nil
+}
+
+// Acknowledged reports whether the node agent has partitioned every targeted
+// pod. NetworkPolicy partitions are in place as soon as they are created.
+func (i *NetworkPartitionInjector) Acknowledged(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) (bool, error) {
+	params, err := parseNetworkPartitionParams(experiment)
+	if err != nil {
+		return false, err
+	}
+	if params.method != PartitionMethodAgent {
+		return true, nil
+	}
+	return podsAcknowledged(ctx, i.client, experiment, agent.NetworkPartitionAckAnnotation, i.log)
+}
+
+// ValidateParameters checks the network partition parameters of an experiment
+func (i *NetworkPartitionInjector) ValidateParameters(experiment *chaosv1alpha1.Havock8sExperiment) error {
+	_, err := parseNetworkPartitionParams(experiment)
+	return err
+}
+
+// parseNetworkPartitionParams validates the network partition parameters of an experiment
+func parseNetworkPartitionParams(experiment *chaosv1alpha1.Havock8sExperiment) (networkPartitionParams, error) {
+	params := networkPartitionParams{
+		peerSelector: experiment.Spec.Parameters["peerSelector"],
+	}
+
+	direction, err := agent.ParsePartitionDirection(experiment.Spec.Parameters["direction"])
+	if err != nil {
+		return params, err
+	}
+	params.direction = direction
+
+	if _, err := labels.Parse(params.peerSelector); err != nil {
+		return params, fmt.Errorf("invalid peerSelector: %w", err)
+	}
+
+	cidrs, err := agent.ParseCIDRs(experiment.Spec.Parameters["cidrs"])
+	if err != nil {
+		return params, err
+	}
+	params.cidrs = cidrs
+
+	// NetworkPolicies cannot drop the replies of connections a pod opened
+	// itself, so anything but a symmetric partition from pods needs the agent
+	params.method = PartitionMethodNetworkPolicy
+	if direction != agent.PartitionDirectionBoth || len(cidrs) > 0 {
+		params.method = PartitionMethodAgent
+	}
+	switch method := experiment.Spec.Parameters["method"]; method {
+	case "":
+	case PartitionMethodAgent:
+		params.method = method
+	case PartitionMethodNetworkPolicy:
+		if len(cidrs) > 0 {
+			return params, fmt.Errorf("cidrs are only supported by the %s method", PartitionMethodAgent)
+		}
+		params.method = method
+	default:
+		return params, fmt.Errorf("invalid method: %s", method)
+	}
+
+	return params, nil
+}
+
+// peerIPs returns the IPs of the pods in a namespace matching the peer
+// selector, or nothing when the pods are cut off from everything
+func (i *NetworkPartitionInjector) peerIPs(ctx context.Context, namespace, peerSelector string) ([]string, error) {
+	if peerSelector == "" {
+		return nil, nil
+	}
+	selector, err := labels.Parse(peerSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid peerSelector: %w", err)
+	}
+
+	podList := &corev1.PodList{}
+	if err := i.client.List(ctx, podList, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("failed to list peer pods in namespace %s: %w", namespace, err)
+	}
+
+	var ips []string
+	for _, pod := range podList.Items {
+		for _, podIP := range pod.Status.PodIPs {
+			ips = append(ips, podIP.IP)
+		}
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no running pods in namespace %s match peerSelector %q", namespace, peerSelector)
+	}
+	return ips, nil
+}
+
+// injectPodPartition asks the node agent to cut a pod off from its peers
+func (i *NetworkPartitionInjector) injectPodPartition(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, pod *corev1.Pod, params networkPartitionParams, peerIPs []string) error {
+	cidrs := append(append([]string{}, params.cidrs...), peerIPs...)
+
+	// Set network partition annotations for the node agent, dropping any
+	// acknowledgement left over from a previous experiment
+	set := map[string]string{
+		agent.NetworkPartitionAnnotation:          "true",
+		agent.NetworkPartitionDirectionAnnotation: params.direction,
+		agent.NetworkPartitionCIDRsAnnotation:     strings.Join(cidrs, ","),
+	}
+	if err := patchPodAnnotations(ctx, i.client, pod, set, []string{agent.NetworkPartitionAckAnnotation}); err != nil {
+		return fmt.Errorf("failed to update pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+
+	i.event(experiment, pod, corev1.EventTypeNormal, EventReasonPartitionApplied,
+		"Requested %s network partition of pod %s/%s from %s", params.direction, pod.Namespace, pod.Name, partitionPeers(params))
+	i.log.Info("Requested network partition of pod", "pod", pod.Name, "direction", params.direction, "cidrs", len(cidrs))
+	return nil
+}
+
+// labelPartitionedPod labels a pod so the experiment's NetworkPolicy selects it
+func (i *NetworkPartitionInjector) labelPartitionedPod(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, pod *corev1.Pod, params networkPartitionParams) error {
+	patch := client.MergeFrom(pod.DeepCopy())
+	if pod.Labels == nil {
+		pod.Labels = make(map[string]string)
+	}
+	pod.Labels[PartitionLabel] = string(experiment.UID)
+
+	if err := i.client.Patch(ctx, pod, patch); err != nil {
+		return fmt.Errorf("failed to update pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+
+	i.event(experiment, pod, corev1.EventTypeNormal, EventReasonPartitionApplied,
+		"Partitioned pod %s/%s from %s with NetworkPolicy %s", pod.Namespace, pod.Name, partitionPeers(params), partitionPolicyName(experiment))
+	i.log.Info("Labeled pod for network partition", "pod", pod.Name)
+	return nil
+}
+
+// createNetworkPolicy creates the NetworkPolicy partitioning the labeled
+// pods of a namespace. Without a peer selector the policy allows nothing.
+// Otherwise it allows every peer that does not match the selector: pods of
+// the namespace failing one of its requirements, pods of other namespaces
+// and addresses outside the cluster, less the matching pods' IPs for
+// network plugins that apply IP blocks to pods too.
+func (i *NetworkPartitionInjector) createNetworkPolicy(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, namespace string, params networkPartitionParams, peerIPs []string) error {
+	var allowed []networkingv1.NetworkPolicyPeer
+	if params.peerSelector != "" {
+		selector, err := metav1.ParseToLabelSelector(params.peerSelector)
+		if err != nil {
+			return fmt.Errorf("invalid peerSelector: %w", err)
+		}
+		requirements := selector.MatchExpressions
+		keys := make([]string, 0, len(selector.MatchLabels))
+		for key := range selector.MatchLabels {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			requirements = append(requirements, metav1.LabelSelectorRequirement{
+				Key:      key,
+				Operator: metav1.LabelSelectorOpIn,
+				Values:   []string{selector.MatchLabels[key]},
+			})
+		}
+		for _, requirement := range requirements {
+			allowed = append(allowed, networkingv1.NetworkPolicyPeer{
+				PodSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{negateRequirement(requirement)},
+				},
+			})
+		}
+		allowed = append(allowed, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      corev1.LabelMetadataName,
+					Operator: metav1.LabelSelectorOpNotIn,
+					Values:   []string{namespace},
+				}},
+			},
+		})
+		allowed = append(allowed, ipBlockPeers(peerIPs)...)
+	}
+
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      partitionPolicyName(experiment),
+			Namespace: namespace,
+			Labels:    map[string]string{PartitionLabel: string(experiment.UID)},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{PartitionLabel: string(experiment.UID)},
+			},
+		},
+	}
+	if params.direction != agent.PartitionDirectionEgress {
+		policy.Spec.PolicyTypes = append(policy.Spec.PolicyTypes, networkingv1.PolicyTypeIngress)
+		if allowed != nil {
+			policy.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{{From: allowed}}
+		}
+	}
+	if params.direction != agent.PartitionDirectionIngress {
+		policy.Spec.PolicyTypes = append(policy.Spec.PolicyTypes, networkingv1.PolicyTypeEgress)
+		if allowed != nil {
+			policy.Spec.Egress = []networkingv1.NetworkPolicyEgressRule{{To: allowed}}
+		}
+	}
+
+	if err := i.client.Create(ctx, policy); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create NetworkPolicy %s/%s: %w", namespace, policy.Name, err)
+		}
+		existing := &networkingv1.NetworkPolicy{}
+		if err := i.client.Get(ctx, client.ObjectKeyFromObject(policy), existing); err != nil {
+			return fmt.Errorf("failed to get NetworkPolicy %s/%s: %w", namespace, policy.Name, err)
+		}
+		existing.Labels = policy.Labels
+		existing.Spec = policy.Spec
+		if err := i.client.Update(ctx, existing); err != nil {
+			return fmt.Errorf("failed to update NetworkPolicy %s/%s: %w", namespace, policy.Name, err)
+		}
+	}
+
+	i.log.Info("Created NetworkPolicy for network partition", "networkPolicy", policy.Name, "namespace", namespace)
+	return nil
+}
+
+// cleanupPodPartition removes the partition request and label from a pod.
+// The node agent removes its rules once the request is gone.
+func (i *NetworkPartitionInjector) cleanupPodPartition(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, pod *corev1.Pod) error {
+	_, requested := pod.Annotations[agent.NetworkPartitionAnnotation]
+	_, labeled := pod.Labels[PartitionLabel]
+	if !requested && !labeled {
+		return nil
+	}
+
+	patch := client.MergeFrom(pod.DeepCopy())
+	delete(pod.Annotations, agent.NetworkPartitionAnnotation)
+	delete(pod.Annotations, agent.NetworkPartitionDirectionAnnotation)
+	delete(pod.Annotations, agent.NetworkPartitionCIDRsAnnotation)
+	delete(pod.Labels, PartitionLabel)
+
+	if err := i.client.Patch(ctx, pod, patch); err != nil {
+		return fmt.Errorf("failed to update pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+
+	i.event(experiment, pod, corev1.EventTypeNormal, EventReasonPartitionRemoved,
+		"Removed network partition from pod %s/%s", pod.Namespace, pod.Name)
+	i.log.Info("Removed network partition from pod", "pod", pod.Name)
+	return nil
+}
+
+// partitionPolicyName returns the name of an experiment's NetworkPolicy
+func partitionPolicyName(experiment *chaosv1alpha1.Havock8sExperiment) string {
+	return "havock8s-partition-" + experiment.Name
+}
+
+// partitionPeers describes what pods are cut off from, for events
+func partitionPeers(params networkPartitionParams) string {
+	var peers []string
+	if params.peerSelector != "" {
+		peers = append(peers, fmt.Sprintf("pods matching %q", params.peerSelector))
+	}
+	if len(params.cidrs) > 0 {
+		peers = append(peers, strings.Join(params.cidrs, ", "))
+	}
+	if len(peers) == 0 {
+		return "everything"
+	}
+	return strings.Join(peers, " and ")
+}
+
+// negateRequirement returns the requirement matching exactly the labels the
+// given requirement does not match
+func negateRequirement(requirement metav1.LabelSelectorRequirement) metav1.LabelSelectorRequirement {
+	negated := requirement
+	switch requirement.Operator {
+	case metav1.LabelSelectorOpIn:
+		negated.Operator = metav1.LabelSelectorOpNotIn
+	case metav1.LabelSelectorOpNotIn:
+		negated.Operator = metav1.LabelSelectorOpIn
+	case metav1.LabelSelectorOpExists:
+		negated.Operator = metav1.LabelSelectorOpDoesNotExist
+	case metav1.LabelSelectorOpDoesNotExist:
+		negated.Operator = metav1.LabelSelectorOpExists
+	}
+	return negated
+}
+
+// ipBlockPeers allows all IPv4 and IPv6 addresses except the given pod IPs
+func ipBlockPeers(podIPs []string) []networkingv1.NetworkPolicyPeer {
+	blocks := []*networkingv1.IPBlock{{CIDR: "0.0.0.0/0"}, {CIDR: "::/0"}}
+	for _, ip := range podIPs {
+		if strings.Contains(ip, ":") {
+			blocks[1].Except = append(blocks[1].Except, ip+"/128")
+		} else {
+			blocks[0].Except = append(blocks[0].Except, ip+"/32")
+		}
+	}
+
+	peers := make([]networkingv1.NetworkPolicyPeer, 0, len(blocks))
+	for _, block := range blocks {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{IPBlock: block})
+	}
+	return peers
+}
@@ -0,0 +1,192 @@
+package chaos
+
+import (
+	"context"
+	"testing"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/agent"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// newPartitionTestPod returns a member of the mongo replica set
+func newPartitionTestPod(name, role, ip string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{"app": "mongo", "role": role},
+		},
+		Status: corev1.PodStatus{PodIPs: []corev1.PodIP{{IP: ip}}},
+	}
+}
+
+func TestNetworkPartitionInjector(t *testing.T) {
+	tests := []struct {
+		name            string
+		parameters      map[string]string
+		wantErr         bool
+		wantAnnotations map[string]string
+		checkPolicy     func(t *testing.T, policy *networkingv1.NetworkPolicy)
+	}{
+		{
+			name:       "symmetric partition from peers uses a NetworkPolicy",
+			parameters: map[string]string{"peerSelector": "app=mongo,role=secondary"},
+			checkPolicy: func(t *testing.T, policy *networkingv1.NetworkPolicy) {
+				if len(policy.Spec.PolicyTypes) != 2 || len(policy.Spec.Ingress) != 1 || len(policy.Spec.Egress) != 1 {
+					t.Fatalf("Policy spec = %+v, want ingress and egress rules", policy.Spec)
+				}
+				from := policy.Spec.Ingress[0].From
+				// One negated requirement per selector requirement, other
+				// namespaces, and IPv4 and IPv6 blocks
+				if len(from) != 5 {
+					t.Fatalf("Ingress peers = %+v, want 5", from)
+				}
+				for _, peer := range from[:2] {
+					op := peer.PodSelector.MatchExpressions[0].Operator
+					if op != metav1.LabelSelectorOpNotIn {
+						t.Errorf("Peer requirement operator = %s, want NotIn", op)
+					}
+				}
+				if except := from[3].IPBlock.Except; len(except) != 2 || except[0] != "10.0.0.2/32" {
+					t.Errorf("IPv4 block except = %v, want the secondaries' IPs", except)
+				}
+			},
+		},
+		{
+			name:       "isolation from everything denies all traffic",
+			parameters: map[string]string{"method": "networkpolicy"},
+			checkPolicy: func(t *testing.T, policy *networkingv1.NetworkPolicy) {
+				if len(policy.Spec.PolicyTypes) != 2 || policy.Spec.Ingress != nil || policy.Spec.Egress != nil {
+					t.Errorf("Policy spec = %+v, want no allowed traffic", policy.Spec)
+				}
+			},
+		},
+		{
+			name:       "one-way partition goes through the node agent",
+			parameters: map[string]string{"peerSelector": "role=secondary", "direction": "egress"},
+			wantAnnotations: map[string]string{
+				agent.NetworkPartitionAnnotation:          "true",
+				agent.NetworkPartitionDirectionAnnotation: "egress",
+				agent.NetworkPartitionCIDRsAnnotation:     "10.0.0.2,10.0.0.3",
+			},
+		},
+		{
+			name:       "CIDRs go through the node agent",
+			parameters: map[string]string{"cidrs": "192.168.0.0/16"},
+			wantAnnotations: map[string]string{
+				agent.NetworkPartitionAnnotation:          "true",
+				agent.NetworkPartitionDirectionAnnotation: "both",
+				agent.NetworkPartitionCIDRsAnnotation:     "192.168.0.0/16",
+			},
+		},
+		{
+			name:       "CIDRs are not supported by NetworkPolicies",
+			parameters: map[string]string{"cidrs": "192.168.0.0/16", "method": "networkpolicy"},
+			wantErr:    true,
+		},
+		{
+			name:       "no peer matches",
+			parameters: map[string]string{"peerSelector": "role=arbiter"},
+			wantErr:    true,
+		},
+		{
+			name:       "invalid direction",
+			parameters: map[string]string{"direction": "sideways"},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			_ = networkingv1.AddToScheme(scheme)
+			fakeClient := newKubeletRacingClient(scheme,
+				newPartitionTestPod("mongo-0", "primary", "10.0.0.1"),
+				newPartitionTestPod("mongo-1", "secondary", "10.0.0.2"),
+				newPartitionTestPod("mongo-2", "secondary", "10.0.0.3"),
+			)
+			ctx := context.Background()
+
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				ObjectMeta: metav1.ObjectMeta{Name: "split-brain", Namespace: "default", UID: "1234"},
+				Spec:       chaosv1alpha1.Havock8sExperimentSpec{ChaosType: "NetworkPartition", Parameters: tt.parameters},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{
+						{Kind: "Pod", Name: "mongo-0", Namespace: "default"},
+					},
+				},
+			}
+
+			injector := NewNetworkPartitionInjector(Dependencies{Client: fakeClient})
+			err := injector.Inject(ctx, experiment)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Inject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			pod := &corev1.Pod{}
+			podKey := types.NamespacedName{Namespace: "default", Name: "mongo-0"}
+			if err := fakeClient.Get(ctx, podKey, pod); err != nil {
+				t.Fatalf("Failed to get pod: %v", err)
+			}
+			for key, want := range tt.wantAnnotations {
+				if got := pod.Annotations[key]; got != want {
+					t.Errorf("Annotation %s = %q, want %q", key, got, want)
+				}
+			}
+
+			policy := &networkingv1.NetworkPolicy{}
+			policyKey := types.NamespacedName{Namespace: "default", Name: "havock8s-partition-split-brain"}
+			err = fakeClient.Get(ctx, policyKey, policy)
+			if tt.checkPolicy != nil {
+				if err != nil {
+					t.Fatalf("Failed to get NetworkPolicy: %v", err)
+				}
+				if pod.Labels[PartitionLabel] != "1234" || policy.Spec.PodSelector.MatchLabels[PartitionLabel] != "1234" {
+					t.Errorf("Pod labels = %v, policy selects %v", pod.Labels, policy.Spec.PodSelector.MatchLabels)
+				}
+				tt.checkPolicy(t, policy)
+			} else if !apierrors.IsNotFound(err) {
+				t.Errorf("NetworkPolicy created for an agent partition, err = %v", err)
+			}
+
+			acknowledged, err := injector.Acknowledged(ctx, experiment)
+			if err != nil {
+				t.Fatalf("Acknowledged() error = %v", err)
+			}
+			if acknowledged != (tt.checkPolicy != nil) {
+				t.Errorf("Acknowledged() = %v before the agent acknowledged", acknowledged)
+			}
+
+			if err := injector.Cleanup(ctx, experiment); err != nil {
+				t.Fatalf("Cleanup() error = %v", err)
+			}
+			if err := fakeClient.Get(ctx, policyKey, policy); !apierrors.IsNotFound(err) {
+				t.Errorf("NetworkPolicy still present after cleanup, err = %v", err)
+			}
+			if err := fakeClient.Get(ctx, podKey, pod); err != nil {
+				t.Fatalf("Failed to get pod: %v", err)
+			}
+			if _, ok := pod.Labels[PartitionLabel]; ok {
+				t.Errorf("Partition label still present after cleanup")
+			}
+			if _, ok := pod.Annotations[agent.NetworkPartitionAnnotation]; ok {
+				t.Errorf("Partition request still present after cleanup")
+			}
+
+			// Cleaning up twice is harmless
+			if err := injector.Cleanup(ctx, experiment); err != nil {
+				t.Errorf("Second Cleanup() error = %v", err)
+			}
+		})
+	}
+}
@@ -3,32 +3,81 @@ package chaos
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/go-logr/logr"
 	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // PodFailureInjector implements the Injector interface for pod failure chaos
 type PodFailureInjector struct {
+	eventRecorder
 	client client.Client
+	log    logr.Logger
 }
 
-// SetClient sets the Kubernetes client
-func (i *PodFailureInjector) SetClient(c client.Client) {
-	i.client = c
+// NewPodFailureInjector creates a pod failure injector using the given dependencies
+func NewPodFailureInjector(deps Dependencies) *PodFailureInjector {
+	return &PodFailureInjector{
+		eventRecorder: eventRecorder{recorder: deps.Recorder},
+		client:        deps.Client,
+		log:           deps.Log,
+	}
+}
+
+// ValidateParameters checks the pod failure parameters of an experiment
+func (i *PodFailureInjector) ValidateParameters(experiment *chaosv1alpha1.Havock8sExperiment) error {
+	failureMode, ok := experiment.Spec.Parameters["failureMode"]
+	if !ok {
+		return fmt.Errorf("failureMode parameter is required")
+	}
+	if failureMode != "crash" && failureMode != "terminate" {
+		return fmt.Errorf("invalid failure mode: %s", failureMode)
+	}
+
+	if val, ok := experiment.Spec.Parameters["gracePeriodSeconds"]; ok {
+		if period, err := strconv.ParseInt(val, 10, 64); err != nil || period < 0 {
+			return fmt.Errorf("gracePeriodSeconds must be a non-negative integer: %s", val)
+		}
+	}
+	if val, ok := experiment.Spec.Parameters["podCount"]; ok {
+		if count, err := strconv.Atoi(val); err != nil || count < 1 {
+			return fmt.Errorf("podCount must be a positive integer: %s", val)
+		}
+	}
+	if val, ok := experiment.Spec.Parameters["podPercentage"]; ok {
+		if _, ok := experiment.Spec.Parameters["podCount"]; ok {
+			return fmt.Errorf("podCount and podPercentage cannot be used together")
+		}
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(val, "%"), 64)
+		if err != nil || percent <= 0 || percent > 100 {
+			return fmt.Errorf("podPercentage must be a percentage between 0 and 100: %s", val)
+		}
+	}
+	if val, ok := experiment.Spec.Parameters["forceDelete"]; ok {
+		if _, err := strconv.ParseBool(val); err != nil {
+			return fmt.Errorf("forceDelete must be true or false: %s", val)
+		}
+	}
+
+	return nil
 }
 
 // Inject applies pod failure chaos
-func (i *PodFailureInjector) Inject(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, log logr.Logger) error {
-	log.Info("Injecting pod failure chaos")
+func (i *PodFailureInjector) Inject(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Injecting pod failure chaos")
 
 	// Get parameters with defaults
 	gracePeriod := int64(0) // Default to immediate termination
 	forceDelete := false
-	podCount := 1
+	// Terminate every resolved target unless told otherwise
+	podCount := len(experiment.Status.TargetResources)
 
 	// Override defaults with experiment parameters if provided
 	if val, ok := experiment.Spec.Parameters["gracePeriodSeconds"]; ok {
@@ -52,163 +101,125 @@ func (i *PodFailureInjector) Inject(ctx context.Context, experiment *chaosv1alph
 		}
 	}
 
-	log.Info("Pod failure parameters",
+	// A percentage of each workload's pods replaces podCount
+	podPercentage := experiment.Spec.Parameters["podPercentage"]
+
+	i.log.Info("Pod failure parameters",
 		"gracePeriodSeconds", gracePeriod,
 		"forceDelete", forceDelete,
-		"podCount", podCount)
+		"podCount", podCount,
+		"podPercentage", podPercentage)
 
 	// Track affected pods to record in status
 	podsTerminated := 0
 
 	for _, target := range experiment.Status.TargetResources {
-		log.Info("Processing target for pod failure", "kind", target.Kind, "name", target.Name, "namespace", target.Namespace)
-
-		switch target.Kind {
-		case "Pod":
-			// Get the pod
-			pod := &corev1.Pod{}
-			err := i.client.Get(ctx, types.NamespacedName{
-				Namespace: target.Namespace,
-				Name:      target.Name,
-			}, pod)
+		i.log.Info("Processing target for pod failure", "kind", target.Kind, "name", target.Name, "namespace", target.Namespace)
+
+		var victims []chaosv1alpha1.TargetResourceStatus
+		switch {
+		case target.Kind == "Pod":
+			victims = []chaosv1alpha1.TargetResourceStatus{target}
+		case utils.IsWorkloadKind(target.Kind):
+			// Find the pods owned by the workload and pick the victims among them
+			pods, err := utils.OwnedPods(ctx, i.client, target.Kind, target.Namespace, target.Name)
 			if err != nil {
-				if client.IgnoreNotFound(err) == nil {
-					// Pod is already gone, consider this a success
-					log.Info("Pod already deleted", "pod", target.Name)
-					podsTerminated++
-					if podsTerminated >= podCount {
-						log.Info("Reached desired pod termination count", "count", podCount)
-						return nil
-					}
-					continue
+				i.log.Error(err, "Failed to find pods of workload", "kind", target.Kind, "name", target.Name)
+				return err
+			}
+			for _, pod := range pods {
+				// Pods that are already terminating make poor victims
+				if pod.DeletionTimestamp == nil {
+					victims = append(victims, podTarget(pod))
 				}
-				return fmt.Errorf("failed to get pod %s/%s: %w", target.Namespace, target.Name, err)
 			}
-
-			// Delete the pod
-			deleteOptions := client.DeleteOptions{}
-			if gracePeriod >= 0 {
-				deleteOptions.GracePeriodSeconds = &gracePeriod
+			if podPercentage != "" {
+				if victims, err = utils.SelectTargets(victims, chaosv1alpha1.TargetModeFixedPercent, podPercentage); err != nil {
+					return err
+				}
 			}
+		default:
+			i.log.Info("Unsupported target kind for pod failure", "kind", target.Kind)
+			continue
+		}
 
-			// Delete the pod with specified options
-			if err := i.client.Delete(ctx, pod, &deleteOptions); err != nil {
-				if client.IgnoreNotFound(err) == nil {
-					// Pod is already gone, consider this a success
-					log.Info("Pod already deleted during deletion attempt", "pod", target.Name)
-					podsTerminated++
-					if podsTerminated >= podCount {
-						log.Info("Reached desired pod termination count", "count", podCount)
-						return nil
-					}
-					continue
-				}
-				log.Error(err, "Failed to delete pod", "pod", target.Name)
-				return fmt.Errorf("failed to delete pod %s/%s: %w", target.Namespace, target.Name, err)
+		for _, victim := range victims {
+			if err := i.deletePod(ctx, experiment, victim, gracePeriod); err != nil {
+				return err
 			}
 
 			podsTerminated++
-			if podsTerminated >= podCount {
-				log.Info("Reached desired pod termination count", "count", podCount)
+			if podPercentage == "" && podsTerminated >= podCount {
+				i.log.Info("Reached desired pod termination count", "count", podCount)
 				return nil
 			}
+		}
+	}
 
-		case "StatefulSet":
-			// Find pods belonging to this StatefulSet and terminate them
-			pods, err := i.findStatefulSetPods(ctx, target.Namespace, target.Name)
-			if err != nil {
-				log.Error(err, "Failed to find pods for StatefulSet", "statefulset", target.Name)
-				return err
-			}
-
-			for _, pod := range pods {
-				podTarget := chaosv1alpha1.TargetResourceStatus{
-					Kind:      "Pod",
-					Name:      pod.Name,
-					Namespace: pod.Namespace,
-					UID:       string(pod.UID),
-					Status:    "Targeted",
-				}
-
-				// Get the pod
-				currentPod := &corev1.Pod{}
-				err := i.client.Get(ctx, types.NamespacedName{
-					Namespace: podTarget.Namespace,
-					Name:      podTarget.Name,
-				}, currentPod)
-				if err != nil {
-					if client.IgnoreNotFound(err) == nil {
-						// Pod is already gone, consider this a success
-						log.Info("Pod already deleted", "pod", podTarget.Name)
-						podsTerminated++
-						if podsTerminated >= podCount {
-							log.Info("Reached desired pod termination count", "count", podCount)
-							return nil
-						}
-						continue
-					}
-					return fmt.Errorf("failed to get pod %s/%s: %w", podTarget.Namespace, podTarget.Name, err)
-				}
-
-				// Delete the pod
-				deleteOptions := client.DeleteOptions{}
-				if gracePeriod >= 0 {
-					deleteOptions.GracePeriodSeconds = &gracePeriod
-				}
-
-				if err := i.client.Delete(ctx, currentPod, &deleteOptions); err != nil {
-					if client.IgnoreNotFound(err) == nil {
-						// Pod is already gone, consider this a success
-						log.Info("Pod already deleted during deletion attempt", "pod", podTarget.Name)
-						podsTerminated++
-						if podsTerminated >= podCount {
-							log.Info("Reached desired pod termination count", "count", podCount)
-							return nil
-						}
-						continue
-					}
-					log.Error(err, "Failed to delete pod", "pod", podTarget.Name)
-					return fmt.Errorf("failed to delete pod %s/%s: %w", podTarget.Namespace, podTarget.Name, err)
-				}
+	i.log.Info("Pod failure chaos injection completed", "podsTerminated", podsTerminated)
+	return nil
+}
 
-				podsTerminated++
-				if podsTerminated >= podCount {
-					log.Info("Reached desired pod termination count", "count", podCount)
-					return nil
-				}
-			}
+// deletePod deletes a pod with the given grace period. A pod that is already
+// gone counts as terminated.
+func (i *PodFailureInjector) deletePod(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, target chaosv1alpha1.TargetResourceStatus, gracePeriod int64) error {
+	// Get the pod
+	pod := &corev1.Pod{}
+	err := i.client.Get(ctx, types.NamespacedName{
+		Namespace: target.Namespace,
+		Name:      target.Name,
+	}, pod)
+	if err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			// Pod is already gone, consider this a success
+			i.log.Info("Pod already deleted", "pod", target.Name)
+			return nil
+		}
+		return fmt.Errorf("failed to get pod %s/%s: %w", target.Namespace, target.Name, err)
+	}
 
-		default:
-			log.Info("Unsupported target kind for pod failure", "kind", target.Kind)
+	// Delete the pod with specified options
+	deleteOptions := client.DeleteOptions{GracePeriodSeconds: &gracePeriod}
+	if err := i.client.Delete(ctx, pod, &deleteOptions); err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			// Pod is already gone, consider this a success
+			i.log.Info("Pod already deleted during deletion attempt", "pod", target.Name)
+			return nil
 		}
+		i.log.Error(err, "Failed to delete pod", "pod", target.Name)
+		return fmt.Errorf("failed to delete pod %s/%s: %w", target.Namespace, target.Name, err)
 	}
 
-	log.Info("Pod failure chaos injection completed", "podsTerminated", podsTerminated)
+	i.event(experiment, pod, corev1.EventTypeNormal, EventReasonPodTerminated,
+		"Terminated pod %s/%s with a grace period of %ds", pod.Namespace, pod.Name, gracePeriod)
 	return nil
 }
 
 // Cleanup removes pod failure annotations
-func (i *PodFailureInjector) Cleanup(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, log logr.Logger) error {
-	log.Info("Cleaning up pod failure annotations")
+func (i *PodFailureInjector) Cleanup(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Cleaning up pod failure annotations")
 
 	for _, target := range experiment.Status.TargetResources {
-		log.Info("Processing target for pod failure cleanup", "kind", target.Kind, "name", target.Name, "namespace", target.Namespace)
-
-		switch target.Kind {
-		case "Pod":
-			if err := i.cleanupPodFailure(ctx, experiment, target, log); err != nil {
-				return err
+		i.log.Info("Processing target for pod failure cleanup", "kind", target.Kind, "name", target.Name, "namespace", target.Namespace)
+
+		pods, err := podsOfTarget(ctx, i.client, target)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				// The terminated pod is gone, nothing left to clean up
+				i.log.Info("Pod no longer exists, skipping cleanup", "pod", target.Name)
+				continue
 			}
-		case "StatefulSet":
-			if err := i.cleanupStatefulSetFailure(ctx, experiment, target, log); err != nil {
+			return err
+		}
+
+		for idx := range pods {
+			if err := i.cleanupPodFailure(ctx, &pods[idx]); err != nil {
 				return err
 			}
-		default:
-			log.Info("Unsupported target kind for pod failure cleanup", "kind", target.Kind)
 		}
 	}
 
-	log.Info("Pod failure cleanup completed")
+	i.log.Info("Pod failure cleanup completed")
 	return nil
 }
 
@@ -219,9 +230,8 @@ func (i *PodFailureInjector) terminatePod(
 	target chaosv1alpha1.TargetResourceStatus,
 	gracePeriod int64,
 	forceDelete bool,
-	log logr.Logger,
 ) error {
-	log.Info("Terminating pod", "pod", target.Name, "namespace", target.Namespace)
+	i.log.Info("Terminating pod", "pod", target.Name, "namespace", target.Namespace)
 
 	// Get the pod
 	pod := &corev1.Pod{}
@@ -257,7 +267,7 @@ func (i *PodFailureInjector) terminatePod(
 	}
 
 	// Record pod state before deletion
-	log.Info("Pod details before termination",
+	i.log.Info("Pod details before termination",
 		"pod", pod.Name,
 		"phase", pod.Status.Phase,
 		"containers", len(pod.Spec.Containers))
@@ -273,90 +283,26 @@ func (i *PodFailureInjector) terminatePod(
 
 	// Delete the pod with specified options
 	if err := i.client.Delete(ctx, pod, &deleteOptions); err != nil {
-		log.Error(err, "Failed to delete pod", "pod", target.Name)
+		i.log.Error(err, "Failed to delete pod", "pod", target.Name)
 		return err
 	}
 
-	log.Info("Successfully terminated pod", "pod", target.Name)
+	i.log.Info("Successfully terminated pod", "pod", target.Name)
 	return nil
 }
 
-// findStatefulSetPods finds all pods belonging to a StatefulSet
-func (i *PodFailureInjector) findStatefulSetPods(ctx context.Context, namespace, statefulSetName string) ([]corev1.Pod, error) {
-	podList := &corev1.PodList{}
-
-	// In a real implementation, we would use proper label selectors based on
-	// StatefulSet labels. For this example, we use a simple naming convention
-	// that StatefulSet pods follow: <statefulset-name>-<ordinal>
-
-	// List pods in the namespace
-	err := i.client.List(ctx, podList, client.InNamespace(namespace))
-	if err != nil {
-		return nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
-	}
-
-	var statefulSetPods []corev1.Pod
-	for _, pod := range podList.Items {
-		// Check if pod is owned by the StatefulSet
-		for _, owner := range pod.OwnerReferences {
-			if owner.Kind == "StatefulSet" && owner.Name == statefulSetName {
-				statefulSetPods = append(statefulSetPods, pod)
-				break
-			}
-		}
-	}
-
-	return statefulSetPods, nil
-}
-
 // cleanupPodFailure removes pod failure annotations
-func (i *PodFailureInjector) cleanupPodFailure(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, target chaosv1alpha1.TargetResourceStatus, log logr.Logger) error {
-	// Get the pod
-	pod := &corev1.Pod{}
-	err := i.client.Get(ctx, types.NamespacedName{
-		Namespace: target.Namespace,
-		Name:      target.Name,
-	}, pod)
-	if err != nil {
-		return fmt.Errorf("failed to get pod %s/%s: %w", target.Namespace, target.Name, err)
-	}
-
-	// Remove pod failure annotations if they exist
-	if pod.Annotations != nil {
-		delete(pod.Annotations, "havock8s.io/pod-failure")
-		delete(pod.Annotations, "havock8s.io/pod-failure-mode")
+func (i *PodFailureInjector) cleanupPodFailure(ctx context.Context, pod *corev1.Pod) error {
+	if _, ok := pod.Annotations["havock8s.io/pod-failure"]; !ok {
+		// Not touched by the experiment
+		return nil
 	}
 
-	// Update the pod to remove annotations
-	if err := i.client.Update(ctx, pod); err != nil {
-		return fmt.Errorf("failed to update pod %s/%s: %w", target.Namespace, target.Name, err)
-	}
-
-	log.Info("Removed pod failure annotations", "pod", target.Name)
-	return nil
-}
-
-// cleanupStatefulSetFailure removes pod failure annotations from all pods in a StatefulSet
-func (i *PodFailureInjector) cleanupStatefulSetFailure(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, target chaosv1alpha1.TargetResourceStatus, log logr.Logger) error {
-	// Find all pods belonging to the StatefulSet
-	pods, err := i.findStatefulSetPods(ctx, target.Namespace, target.Name)
-	if err != nil {
-		return fmt.Errorf("failed to find pods for StatefulSet %s/%s: %w", target.Namespace, target.Name, err)
-	}
-
-	// Clean up each pod
-	for _, pod := range pods {
-		podTarget := chaosv1alpha1.TargetResourceStatus{
-			Kind:      "Pod",
-			Name:      pod.Name,
-			Namespace: pod.Namespace,
-			UID:       string(pod.UID),
-		}
-		if err := i.cleanupPodFailure(ctx, experiment, podTarget, log); err != nil {
-			return err
-		}
+	remove := []string{"havock8s.io/pod-failure", "havock8s.io/pod-failure-mode"}
+	if err := patchPodAnnotations(ctx, i.client, pod, nil, remove); err != nil {
+		return fmt.Errorf("failed to update pod %s/%s: %w", pod.Namespace, pod.Name, err)
 	}
 
-	log.Info("Removed pod failure annotations from StatefulSet", "statefulset", target.Name)
+	i.log.Info("Removed pod failure annotations", "pod", pod.Name)
 	return nil
 }
@@ -4,13 +4,14 @@ import (
 	"context"
 	"testing"
 
-	"github.com/go-logr/logr"
 	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 )
 
 func TestPodFailureInjector_Inject(t *testing.T) {
@@ -115,10 +116,9 @@ func TestPodFailureInjector_Inject(t *testing.T) {
 				WithObjects(objs...).
 				Build()
 
-			injector := &PodFailureInjector{}
-			injector.SetClient(fakeClient)
+			injector := NewPodFailureInjector(Dependencies{Client: fakeClient})
 
-			err := injector.Inject(context.Background(), tt.experiment, logr.Discard())
+			err := injector.Inject(context.Background(), tt.experiment)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("PodFailureInjector.Inject() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -142,6 +142,139 @@ func TestPodFailureInjector_Inject(t *testing.T) {
 	}
 }
 
+func TestPodFailureInjector_InjectPodCount(t *testing.T) {
+	tests := []struct {
+		name           string
+		parameters     map[string]string
+		wantTerminated int
+	}{
+		{
+			name:           "every resolved target by default",
+			parameters:     map[string]string{"failureMode": "terminate"},
+			wantTerminated: 3,
+		},
+		{
+			name:           "podCount of the resolved targets",
+			parameters:     map[string]string{"failureMode": "terminate", "podCount": "2"},
+			wantTerminated: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{Parameters: tt.parameters},
+			}
+			var objs []client.Object
+			for _, name := range []string{"web-0", "web-1", "web-2"} {
+				objs = append(objs, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}})
+				experiment.Status.TargetResources = append(experiment.Status.TargetResources,
+					chaosv1alpha1.TargetResourceStatus{Kind: "Pod", Name: name, Namespace: "default"})
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+
+			injector := NewPodFailureInjector(Dependencies{Client: fakeClient})
+			if err := injector.Inject(context.Background(), experiment); err != nil {
+				t.Fatalf("PodFailureInjector.Inject() error = %v", err)
+			}
+
+			pods := &corev1.PodList{}
+			if err := fakeClient.List(context.Background(), pods); err != nil {
+				t.Fatalf("Failed to list pods: %v", err)
+			}
+			if terminated := 3 - len(pods.Items); terminated != tt.wantTerminated {
+				t.Errorf("Terminated %d pods, want %d", terminated, tt.wantTerminated)
+			}
+		})
+	}
+}
+
+func TestPodFailureInjector_InjectWorkload(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         chaosv1alpha1.TargetResourceStatus
+		parameters     map[string]string
+		wantTerminated int
+	}{
+		{
+			name:           "percentage of a deployment's pods",
+			target:         chaosv1alpha1.TargetResourceStatus{Kind: "Deployment", Name: "api-gateway", Namespace: "default"},
+			parameters:     map[string]string{"failureMode": "terminate", "podPercentage": "50%"},
+			wantTerminated: 2,
+		},
+		{
+			name:           "pod count of a replicaset",
+			target:         chaosv1alpha1.TargetResourceStatus{Kind: "ReplicaSet", Name: "api-gateway-7c9f", Namespace: "default"},
+			parameters:     map[string]string{"failureMode": "terminate", "podCount": "3"},
+			wantTerminated: 3,
+		},
+		{
+			name:           "one pod by default",
+			target:         chaosv1alpha1.TargetResourceStatus{Kind: "Deployment", Name: "api-gateway", Namespace: "default"},
+			parameters:     map[string]string{"failureMode": "terminate"},
+			wantTerminated: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			_ = appsv1.AddToScheme(scheme)
+
+			objs := []client.Object{
+				&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "api-gateway-7c9f", Namespace: "default", OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "Deployment", Name: "api-gateway"},
+				}}},
+				// Shares the name prefix but belongs to another Deployment
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api-gateway-admin-0", Namespace: "default", OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "api-gateway-admin-5b2d"},
+				}}},
+			}
+			for _, name := range []string{"a", "b", "c", "d"} {
+				objs = append(objs, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+					Name:      "api-gateway-7c9f-" + name,
+					Namespace: "default",
+					OwnerReferences: []metav1.OwnerReference{
+						{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "api-gateway-7c9f"},
+					},
+				}})
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{ChaosType: "PodFailure", Parameters: tt.parameters},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{tt.target},
+				},
+			}
+
+			injector := NewPodFailureInjector(Dependencies{Client: fakeClient})
+			if err := injector.Inject(context.Background(), experiment); err != nil {
+				t.Fatalf("PodFailureInjector.Inject() error = %v", err)
+			}
+
+			pods := &corev1.PodList{}
+			if err := fakeClient.List(context.Background(), pods); err != nil {
+				t.Fatalf("Failed to list pods: %v", err)
+			}
+			remaining := map[string]bool{}
+			for _, pod := range pods.Items {
+				remaining[pod.Name] = true
+			}
+			if !remaining["api-gateway-admin-0"] {
+				t.Error("Pod of another Deployment was terminated")
+			}
+			if terminated := 5 - len(remaining); terminated != tt.wantTerminated {
+				t.Errorf("Terminated %d pods, want %d", terminated, tt.wantTerminated)
+			}
+		})
+	}
+}
+
 func TestPodFailureInjector_Cleanup(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -186,10 +319,9 @@ func TestPodFailureInjector_Cleanup(t *testing.T) {
 				WithObjects(tt.experiment, tt.pod).
 				Build()
 
-			injector := &PodFailureInjector{}
-			injector.SetClient(fakeClient)
+			injector := NewPodFailureInjector(Dependencies{Client: fakeClient})
 
-			err := injector.Cleanup(context.Background(), tt.experiment, logr.Discard())
+			err := injector.Cleanup(context.Background(), tt.experiment)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("PodFailureInjector.Cleanup() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -213,3 +345,116 @@ func TestPodFailureInjector_Cleanup(t *testing.T) {
 		})
 	}
 }
+
+func TestPodFailureInjector_CleanupWorkload(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+
+	owner := []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "api-gateway-7c9f"}}
+	// Records the pods written to, and how
+	var writes []string
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "api-gateway-7c9f", Namespace: "default", OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "Deployment", Name: "api-gateway"},
+			}}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api-gateway-7c9f-a", Namespace: "default", OwnerReferences: owner,
+				Annotations: map[string]string{"havock8s.io/pod-failure": "true", "havock8s.io/pod-failure-mode": "crash"}}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api-gateway-7c9f-b", Namespace: "default", OwnerReferences: owner}},
+		).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				writes = append(writes, "update "+obj.GetName())
+				return c.Update(ctx, obj, opts...)
+			},
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				writes = append(writes, "patch "+obj.GetName())
+				return c.Patch(ctx, obj, patch, opts...)
+			},
+		}).
+		Build()
+
+	experiment := &chaosv1alpha1.Havock8sExperiment{
+		Spec: chaosv1alpha1.Havock8sExperimentSpec{ChaosType: "PodFailure"},
+		Status: chaosv1alpha1.Havock8sExperimentStatus{
+			TargetResources: []chaosv1alpha1.TargetResourceStatus{
+				{Kind: "Deployment", Name: "api-gateway", Namespace: "default"},
+			},
+		},
+	}
+	injector := NewPodFailureInjector(Dependencies{Client: fakeClient})
+	if err := injector.Cleanup(context.Background(), experiment); err != nil {
+		t.Fatalf("PodFailureInjector.Cleanup() error = %v", err)
+	}
+
+	if len(writes) != 1 || writes[0] != "patch api-gateway-7c9f-a" {
+		t.Errorf("Writes = %v, want a single patch of the annotated pod", writes)
+	}
+	cleaned := &corev1.Pod{}
+	if err := fakeClient.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "api-gateway-7c9f-a"}, cleaned); err != nil {
+		t.Fatalf("Failed to get pod: %v", err)
+	}
+	if len(cleaned.Annotations) != 0 {
+		t.Errorf("Annotations after cleanup = %v, want none", cleaned.Annotations)
+	}
+}
+
+func TestPodFailureInjector_ValidateParameters(t *testing.T) {
+	tests := []struct {
+		name       string
+		parameters map[string]string
+		wantErr    bool
+	}{
+		{
+			name:       "valid parameters",
+			parameters: map[string]string{"failureMode": "crash", "gracePeriodSeconds": "0", "podCount": "2", "forceDelete": "true"},
+		},
+		{
+			name:    "missing failure mode",
+			wantErr: true,
+		},
+		{
+			name:       "invalid failure mode",
+			parameters: map[string]string{"failureMode": "explode"},
+			wantErr:    true,
+		},
+		{
+			name:       "negative grace period",
+			parameters: map[string]string{"failureMode": "terminate", "gracePeriodSeconds": "-1"},
+			wantErr:    true,
+		},
+		{
+			name:       "zero pod count",
+			parameters: map[string]string{"failureMode": "terminate", "podCount": "0"},
+			wantErr:    true,
+		},
+		{
+			name:       "pod percentage",
+			parameters: map[string]string{"failureMode": "terminate", "podPercentage": "30%"},
+		},
+		{
+			name:       "pod count and percentage",
+			parameters: map[string]string{"failureMode": "terminate", "podCount": "2", "podPercentage": "30"},
+			wantErr:    true,
+		},
+		{
+			name:       "zero pod percentage",
+			parameters: map[string]string{"failureMode": "terminate", "podPercentage": "0"},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{Parameters: tt.parameters},
+			}
+			err := (&PodFailureInjector{}).ValidateParameters(experiment)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PodFailureInjector.ValidateParameters() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/agent"
+	"github.com/havock8s/havock8s/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// podTarget returns the target status entry for a pod
+func podTarget(pod corev1.Pod) chaosv1alpha1.TargetResourceStatus {
+	return chaosv1alpha1.TargetResourceStatus{
+		Kind:      "Pod",
+		Name:      pod.Name,
+		Namespace: pod.Namespace,
+		UID:       string(pod.UID),
+	}
+}
+
+// targetPods returns the pods covered by the experiment's targets, expanding
+// workloads and PersistentVolumeClaims into their pods
+func targetPods(ctx context.Context, c client.Client, experiment *chaosv1alpha1.Havock8sExperiment) ([]corev1.Pod, error) {
+	var pods []corev1.Pod
+	for _, target := range experiment.Status.TargetResources {
+		targetPods, err := podsOfTarget(ctx, c, target)
+		if err != nil {
+			return nil, err
+		}
+		pods = append(pods, targetPods...)
+	}
+	return pods, nil
+}
+
+// podsOfTarget returns the pods covered by a single target. Targets of kinds
+// that do not run pods have none.
+func podsOfTarget(ctx context.Context, c client.Client, target chaosv1alpha1.TargetResourceStatus) ([]corev1.Pod, error) {
+	switch target.Kind {
+	case "Pod":
+		pod := &corev1.Pod{}
+		err := c.Get(ctx, types.NamespacedName{
+			Namespace: target.Namespace,
+			Name:      target.Name,
+		}, pod)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pod %s/%s: %w", target.Namespace, target.Name, err)
+		}
+		return []corev1.Pod{*pod}, nil
+	case "PersistentVolumeClaim":
+		return utils.ClaimPods(ctx, c, target.Namespace, target.Name)
+	}
+	if utils.IsWorkloadKind(target.Kind) {
+		return utils.OwnedPods(ctx, c, target.Kind, target.Namespace, target.Name)
+	}
+	return nil, nil
+}
+
+// podsAcknowledged reports whether the node agent has set the given
+// acknowledgement annotation on every targeted pod. A failure reported by the
+// agent is returned as an error.
+func podsAcknowledged(ctx context.Context, c client.Client, experiment *chaosv1alpha1.Havock8sExperiment, ackAnnotation string, log logr.Logger) (bool, error) {
+	pods, err := targetPods(ctx, c, experiment)
+	if err != nil {
+		return false, err
+	}
+
+	for _, pod := range pods {
+		ack, ok := pod.Annotations[ackAnnotation]
+		if !ok {
+			log.Info("Waiting for node agent acknowledgement", "pod", pod.Name, "node", pod.Spec.NodeName)
+			return false, nil
+		}
+		if strings.HasPrefix(ack, agent.AckFailedPrefix) {
+			return false, fmt.Errorf("node agent failed to apply chaos to pod %s/%s: %s",
+				pod.Namespace, pod.Name, strings.TrimPrefix(ack, agent.AckFailedPrefix))
+		}
+	}
+
+	return true, nil
+}
+
+// patchPodAnnotations removes and then sets annotations of a pod. It patches
+// rather than updates the pod, so status updates of the kubelet since the pod
+// was read don't make the write conflict.
+func patchPodAnnotations(ctx context.Context, c client.Client, pod *corev1.Pod, set map[string]string, remove []string) error {
+	patch := client.MergeFrom(pod.DeepCopy())
+	for _, key := range remove {
+		delete(pod.Annotations, key)
+	}
+	if len(set) > 0 && pod.Annotations == nil {
+		pod.Annotations = make(map[string]string, len(set))
+	}
+	for key, value := range set {
+		pod.Annotations[key] = value
+	}
+	return c.Patch(ctx, pod, patch)
+}
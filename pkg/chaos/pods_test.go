@@ -0,0 +1,119 @@
+package chaos
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestPatchPodAnnotations(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		set         map[string]string
+		remove      []string
+		want        map[string]string
+	}{
+		{
+			name: "set on a pod without annotations",
+			set:  map[string]string{"havock8s.io/fault": "true"},
+			want: map[string]string{"havock8s.io/fault": "true"},
+		},
+		{
+			name:        "remove keeps other annotations",
+			annotations: map[string]string{"havock8s.io/fault": "true", "havock8s.io/fault-ack": "applied", "team": "db"},
+			remove:      []string{"havock8s.io/fault", "havock8s.io/fault-ack"},
+			want:        map[string]string{"team": "db"},
+		},
+		{
+			name:        "set replaces what is removed",
+			annotations: map[string]string{"havock8s.io/fault": "true", "havock8s.io/fault-ack": "applied"},
+			set:         map[string]string{"havock8s.io/fault": "true"},
+			remove:      []string{"havock8s.io/fault", "havock8s.io/fault-ack"},
+			want:        map[string]string{"havock8s.io/fault": "true"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "default", Annotations: tt.annotations}}
+			fakeClient := newKubeletRacingClient(scheme, pod)
+			ctx := context.Background()
+
+			// The kubelet updates the status after the pod was read
+			if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(pod), pod); err != nil {
+				t.Fatalf("Failed to get pod: %v", err)
+			}
+			if err := patchPodAnnotations(ctx, fakeClient, pod, tt.set, tt.remove); err != nil {
+				t.Fatalf("patchPodAnnotations() error = %v", err)
+			}
+
+			got := &corev1.Pod{}
+			if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(pod), got); err != nil {
+				t.Fatalf("Failed to get pod: %v", err)
+			}
+			if !reflect.DeepEqual(got.Annotations, tt.want) {
+				t.Errorf("Annotations = %v, want %v", got.Annotations, tt.want)
+			}
+		})
+	}
+}
+
+// newKubeletRacingClient returns a fake client that updates the status of
+// pods and claims every time they are read, the way the kubelet and the
+// volume controllers do, so writes based on the copy that was read conflict
+func newKubeletRacingClient(scheme *runtime.Scheme, objs ...client.Object) client.Client {
+	return fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if err := c.Get(ctx, key, obj, opts...); err != nil {
+					return err
+				}
+				return syncStatus(ctx, c, obj)
+			},
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				if err := c.List(ctx, list, opts...); err != nil {
+					return err
+				}
+				pods, ok := list.(*corev1.PodList)
+				if !ok {
+					return nil
+				}
+				for idx := range pods.Items {
+					if err := syncStatus(ctx, c, &pods.Items[idx]); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		}).
+		Build()
+}
+
+// syncStatus updates the status of a pod or claim behind the caller's back
+func syncStatus(ctx context.Context, c client.Client, obj client.Object) error {
+	switch obj := obj.(type) {
+	case *corev1.Pod:
+		synced := obj.DeepCopy()
+		synced.Status.Message = "synced at " + synced.ResourceVersion
+		return c.Status().Update(ctx, synced)
+	case *corev1.PersistentVolumeClaim:
+		synced := obj.DeepCopy()
+		synced.Status.Conditions = []corev1.PersistentVolumeClaimCondition{
+			{Type: corev1.PersistentVolumeClaimResizing, Message: "synced at " + synced.ResourceVersion},
+		}
+		return c.Status().Update(ctx, synced)
+	}
+	return nil
+}
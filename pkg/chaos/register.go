@@ -2,8 +2,21 @@ package chaos
 
 func init() {
 	// Register all chaos injectors
-	RegisterInjector("PodFailure", &PodFailureInjector{})
-	RegisterInjector("DiskFailure", &DiskFailureInjector{})
-	RegisterInjector("NetworkLatency", &NetworkLatencyInjector{})
-	RegisterInjector("StatefulSetScaling", &StatefulSetScalingInjector{})
+	RegisterInjector("PodFailure", func(deps Dependencies) Injector { return NewPodFailureInjector(deps) })
+	RegisterInjector("DiskFailure", func(deps Dependencies) Injector { return NewDiskFailureInjector(deps) })
+	RegisterInjector("NetworkLatency", func(deps Dependencies) Injector { return NewNetworkLatencyInjector(deps) })
+	RegisterInjector("NetworkLoss", func(deps Dependencies) Injector { return NewNetemInjector(NetemEffectLoss, deps) })
+	RegisterInjector("NetworkDuplicate", func(deps Dependencies) Injector { return NewNetemInjector(NetemEffectDuplicate, deps) })
+	RegisterInjector("NetworkCorrupt", func(deps Dependencies) Injector { return NewNetemInjector(NetemEffectCorrupt, deps) })
+	RegisterInjector("NetworkReorder", func(deps Dependencies) Injector { return NewNetemInjector(NetemEffectReorder, deps) })
+	RegisterInjector("NetworkBandwidth", func(deps Dependencies) Injector { return NewNetemInjector(NetemEffectRate, deps) })
+	RegisterInjector("NetworkPartition", func(deps Dependencies) Injector { return NewNetworkPartitionInjector(deps) })
+	RegisterInjector("StatefulSetScaling", func(deps Dependencies) Injector { return NewStatefulSetScalingInjector(deps) })
+	RegisterInjector("ContainerKill", func(deps Dependencies) Injector { return NewContainerKillInjector(deps) })
+	RegisterInjector("ProcessKill", func(deps Dependencies) Injector { return NewProcessKillInjector(deps) })
+	RegisterInjector("DataCorruption", func(deps Dependencies) Injector { return NewDataCorruptionInjector(deps) })
+	RegisterInjector("ResourcePressure", func(deps Dependencies) Injector { return NewResourcePressureInjector(deps) })
+	RegisterInjector("StateDelay", func(deps Dependencies) Injector { return NewStateDelayInjector(deps) })
+	RegisterInjector("ConnectionOverload", func(deps Dependencies) Injector { return NewConnectionOverloadInjector(deps) })
+	RegisterInjector("VolumeFailure", func(deps Dependencies) Injector { return NewVolumeFailureInjector(deps) })
 }
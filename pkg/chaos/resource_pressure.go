@@ -0,0 +1,328 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/agent"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Hard caps on the pressure, whatever the intensity and parameters ask for
+const (
+	// maxPressureWorkers caps the number of stressor processes per pod
+	maxPressureWorkers = 16
+
+	// maxIOPressureWorkers is the number of io workers at full intensity
+	maxIOPressureWorkers = 4
+
+	// maxMemoryPressure is the largest share of a container's memory limit
+	// the stressors allocate, leaving the workload room to run
+	maxMemoryPressure = 0.9
+
+	// maxNodeMemoryPressure is the largest share of the node's allocatable
+	// memory the stressors allocate, so a container whose limit doesn't
+	// bound them can't exhaust the node
+	maxNodeMemoryPressure = 0.5
+)
+
+// ResourcePressureInjector implements the Injector interface for CPU, memory
+// and I/O pressure chaos. The node agent runs stressors inside the cgroup of
+// the targeted container until the experiment's duration has elapsed.
+type ResourcePressureInjector struct {
+	eventRecorder
+	client client.Client
+	log    logr.Logger
+}
+
+// pressureParams holds the validated resource pressure parameters
+type pressureParams struct {
+	resourceType string
+	container    string
+	workers      int
+	memory       *resource.Quantity
+	path         string
+	deadline     time.Time
+}
+
+// NewResourcePressureInjector creates a resource pressure injector using the given dependencies
+func NewResourcePressureInjector(deps Dependencies) *ResourcePressureInjector {
+	return &ResourcePressureInjector{
+		eventRecorder: eventRecorder{recorder: deps.Recorder},
+		client:        deps.Client,
+		log:           deps.Log,
+	}
+}
+
+// Inject asks the node agent to stress the targeted containers
+func (i *ResourcePressureInjector) Inject(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Injecting resource pressure chaos")
+
+	params, err := parsePressureParams(experiment)
+	if err != nil {
+		return err
+	}
+
+	i.log.Info("Resource pressure parameters",
+		"resourceType", params.resourceType,
+		"intensity", experiment.Spec.Intensity,
+		"deadline", params.deadline)
+
+	pods, err := targetPods(ctx, i.client, experiment)
+	if err != nil {
+		return err
+	}
+	for idx := range pods {
+		if err := i.injectPodPressure(ctx, experiment, &pods[idx], params); err != nil {
+			return err
+		}
+	}
+
+	i.log.Info("Resource pressure chaos injection completed", "pods", len(pods))
+	return nil
+}
+
+// Cleanup removes the pressure request from the targeted pods. The node agent
+// stops the stressors once it sees the request is gone.
+func (i *ResourcePressureInjector) Cleanup(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Cleaning up resource pressure chaos")
+
+	for _, target := range experiment.Status.TargetResources {
+		pods, err := podsOfTarget(ctx, i.client, target)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				i.log.Info("Pod no longer exists, skipping cleanup", "pod", target.Name)
+				continue
+			}
+			return err
+		}
+
+		for idx := range pods {
+			pod := &pods[idx]
+			if _, ok := pod.Annotations[agent.ResourcePressureAnnotation]; !ok {
+				continue
+			}
+			remove := []string{
+				agent.ResourcePressureAnnotation,
+				agent.ResourcePressureTypeAnnotation,
+				agent.ResourcePressureContainerAnnotation,
+				agent.ResourcePressureWorkersAnnotation,
+				agent.ResourcePressureLoadAnnotation,
+				agent.ResourcePressureBytesAnnotation,
+				agent.ResourcePressurePathAnnotation,
+				agent.ResourcePressureDeadlineAnnotation,
+			}
+			if err := patchPodAnnotations(ctx, i.client, pod, nil, remove); err != nil {
+				return fmt.Errorf("failed to update pod %s/%s: %w", pod.Namespace, pod.Name, err)
+			}
+
+			i.event(experiment, pod, corev1.EventTypeNormal, EventReasonPressureRemoved,
+				"Removed resource pressure from pod %s/%s", pod.Namespace, pod.Name)
+			i.log.Info("Removed resource pressure from pod", "pod", pod.Name)
+		}
+	}
+
+	i.log.Info("Resource pressure chaos cleanup completed")
+	return nil
+}
+
+// Acknowledged reports whether the node agent has started the stressors in every targeted pod
+func (i *ResourcePressureInjector) Acknowledged(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) (bool, error) {
+	return podsAcknowledged(ctx, i.client, experiment, agent.ResourcePressureAckAnnotation, i.log)
+}
+
+// ValidateParameters checks the resource pressure parameters of an experiment
+func (i *ResourcePressureInjector) ValidateParameters(experiment *chaosv1alpha1.Havock8sExperiment) error {
+	_, err := parsePressureParams(experiment)
+	return err
+}
+
+// parsePressureParams validates the resource pressure parameters of an experiment
+func parsePressureParams(experiment *chaosv1alpha1.Havock8sExperiment) (pressureParams, error) {
+	params := pressureParams{
+		resourceType: experiment.Spec.Parameters["resourceType"],
+		container:    experiment.Spec.Parameters["container"],
+		path:         "/tmp",
+	}
+
+	switch params.resourceType {
+	case agent.ResourceTypeCPU, agent.ResourceTypeMemory, agent.ResourceTypeIO:
+	case "":
+		return params, fmt.Errorf("resourceType parameter is required")
+	default:
+		return params, fmt.Errorf("unsupported resourceType: %s", params.resourceType)
+	}
+
+	if experiment.Spec.Intensity <= 0 || experiment.Spec.Intensity > 1 {
+		return params, fmt.Errorf("intensity must be greater than 0 and at most 1")
+	}
+
+	if val, ok := experiment.Spec.Parameters["workers"]; ok {
+		workers, err := strconv.Atoi(val)
+		if err != nil || workers < 1 || workers > maxPressureWorkers {
+			return params, fmt.Errorf("workers must be between 1 and %d", maxPressureWorkers)
+		}
+		params.workers = workers
+	}
+
+	if val, ok := experiment.Spec.Parameters["memory"]; ok {
+		memory, err := resource.ParseQuantity(val)
+		if err != nil || memory.Sign() <= 0 {
+			return params, fmt.Errorf("invalid memory %q", val)
+		}
+		params.memory = &memory
+	}
+
+	if val, ok := experiment.Spec.Parameters["path"]; ok {
+		if !filepath.IsAbs(val) {
+			return params, fmt.Errorf("path must be absolute")
+		}
+		params.path = val
+	}
+
+	// The stressors stop by themselves when the experiment is due to end
+	duration, err := time.ParseDuration(experiment.Spec.Duration)
+	if err != nil || duration <= 0 {
+		return params, fmt.Errorf("invalid duration %q", experiment.Spec.Duration)
+	}
+	params.deadline = time.Now().Add(duration)
+
+	return params, nil
+}
+
+// pressureAnnotations returns the agent annotations stressing a container
+// with the experiment's intensity, within the hard caps. nodeMemory is the
+// allocatable memory of the pod's node, only needed for memory pressure.
+func pressureAnnotations(pod *corev1.Pod, container corev1.Container, params pressureParams, intensity float64, nodeMemory int64) (map[string]string, error) {
+	annotations := map[string]string{
+		agent.ResourcePressureTypeAnnotation:      params.resourceType,
+		agent.ResourcePressureContainerAnnotation: container.Name,
+		agent.ResourcePressureDeadlineAnnotation:  params.deadline.UTC().Format(time.RFC3339),
+	}
+
+	workers := params.workers
+	switch params.resourceType {
+	case agent.ResourceTypeCPU:
+		// One worker per CPU of the container's limit, loaded by intensity
+		if workers == 0 {
+			workers = 1
+			if limit, ok := container.Resources.Limits[corev1.ResourceCPU]; ok {
+				workers = int(math.Ceil(float64(limit.MilliValue()) / 1000))
+			}
+		}
+		annotations[agent.ResourcePressureLoadAnnotation] = strconv.Itoa(int(math.Round(intensity * 100)))
+
+	case agent.ResourceTypeMemory:
+		// Intensity is a share of the container's memory limit, or of the
+		// memory parameter for containers without one
+		if workers == 0 {
+			workers = 1
+		}
+		limit, hasLimit := container.Resources.Limits[corev1.ResourceMemory]
+		var bytes int64
+		switch {
+		case params.memory != nil:
+			bytes = int64(float64(params.memory.Value()) * intensity)
+		case hasLimit:
+			bytes = int64(float64(limit.Value()) * intensity)
+		default:
+			return nil, fmt.Errorf("container %s of pod %s/%s has no memory limit, set the memory parameter",
+				container.Name, pod.Namespace, pod.Name)
+		}
+		if hasLimit {
+			bytes = min(bytes, int64(float64(limit.Value())*maxMemoryPressure))
+		}
+		bytes = min(bytes, int64(float64(nodeMemory)*maxNodeMemoryPressure))
+		annotations[agent.ResourcePressureBytesAnnotation] = strconv.FormatInt(bytes, 10)
+
+	case agent.ResourceTypeIO:
+		if workers == 0 {
+			workers = max(1, int(math.Round(intensity*maxIOPressureWorkers)))
+		}
+		annotations[agent.ResourcePressurePathAnnotation] = params.path
+	}
+	annotations[agent.ResourcePressureWorkersAnnotation] = strconv.Itoa(min(workers, maxPressureWorkers))
+
+	return annotations, nil
+}
+
+// nodeAllocatableMemory returns the allocatable memory of the node a pod runs on
+func (i *ResourcePressureInjector) nodeAllocatableMemory(ctx context.Context, pod *corev1.Pod) (int64, error) {
+	if pod.Spec.NodeName == "" {
+		return 0, fmt.Errorf("pod %s/%s is not scheduled to a node", pod.Namespace, pod.Name)
+	}
+	node := &corev1.Node{}
+	if err := i.client.Get(ctx, client.ObjectKey{Name: pod.Spec.NodeName}, node); err != nil {
+		return 0, fmt.Errorf("failed to get node %s: %w", pod.Spec.NodeName, err)
+	}
+	memory, ok := node.Status.Allocatable[corev1.ResourceMemory]
+	if !ok || memory.Value() <= 0 {
+		return 0, fmt.Errorf("node %s reports no allocatable memory", node.Name)
+	}
+	return memory.Value(), nil
+}
+
+// injectPodPressure asks the node agent to stress a pod's container
+func (i *ResourcePressureInjector) injectPodPressure(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, pod *corev1.Pod, params pressureParams) error {
+	if len(pod.Spec.Containers) == 0 {
+		return fmt.Errorf("pod %s/%s has no containers", pod.Namespace, pod.Name)
+	}
+	container := pod.Spec.Containers[0]
+	if params.container != "" {
+		found := false
+		for _, c := range pod.Spec.Containers {
+			if c.Name == params.container {
+				container, found = c, true
+			}
+		}
+		if !found {
+			return fmt.Errorf("pod %s/%s has no container %s", pod.Namespace, pod.Name, params.container)
+		}
+	}
+
+	var nodeMemory int64
+	if params.resourceType == agent.ResourceTypeMemory {
+		memory, err := i.nodeAllocatableMemory(ctx, pod)
+		if err != nil {
+			return err
+		}
+		nodeMemory = memory
+	}
+
+	annotations, err := pressureAnnotations(pod, container, params, experiment.Spec.Intensity, nodeMemory)
+	if err != nil {
+		return err
+	}
+
+	// Set pressure annotations for the node agent, replacing those of a
+	// previous experiment and dropping its acknowledgement
+	set := map[string]string{agent.ResourcePressureAnnotation: "true"}
+	for key, val := range annotations {
+		set[key] = val
+	}
+	remove := []string{
+		agent.ResourcePressureLoadAnnotation,
+		agent.ResourcePressureBytesAnnotation,
+		agent.ResourcePressurePathAnnotation,
+		agent.ResourcePressureAckAnnotation,
+	}
+	if err := patchPodAnnotations(ctx, i.client, pod, set, remove); err != nil {
+		return fmt.Errorf("failed to update pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+
+	i.event(experiment, pod, corev1.EventTypeNormal, EventReasonPressureApplied,
+		"Requested %s pressure with %s workers on container %s in pod %s/%s until %s",
+		params.resourceType, annotations[agent.ResourcePressureWorkersAnnotation], container.Name,
+		pod.Namespace, pod.Name, annotations[agent.ResourcePressureDeadlineAnnotation])
+	i.log.Info("Requested resource pressure on pod container", "pod", pod.Name, "container", container.Name, "resourceType", params.resourceType)
+	return nil
+}
@@ -0,0 +1,197 @@
+package chaos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/agent"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// newPressureTestNode returns a node with 8Gi of allocatable memory
+func newPressureTestNode() *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+		},
+	}
+}
+
+// newPressureTestPod returns a database pod on node-1 limited to 2 CPUs and
+// 1Gi of memory, with an unlimited sidecar
+func newPressureTestPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			NodeName: "node-1",
+			Containers: []corev1.Container{
+				{
+					Name: "db",
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("1500m"),
+							corev1.ResourceMemory: resource.MustParse("1Gi"),
+						},
+					},
+				},
+				{Name: "exporter"},
+			},
+		},
+	}
+}
+
+func TestResourcePressureInjector_Inject(t *testing.T) {
+	tests := []struct {
+		name            string
+		intensity       float64
+		parameters      map[string]string
+		wantErr         bool
+		wantAnnotations map[string]string
+	}{
+		{
+			name:       "cpu workers follow the CPU limit",
+			intensity:  0.8,
+			parameters: map[string]string{"resourceType": "cpu"},
+			wantAnnotations: map[string]string{
+				agent.ResourcePressureContainerAnnotation: "db",
+				agent.ResourcePressureWorkersAnnotation:   "2",
+				agent.ResourcePressureLoadAnnotation:      "80",
+			},
+		},
+		{
+			name:       "memory is a share of the limit",
+			intensity:  0.5,
+			parameters: map[string]string{"resourceType": "memory"},
+			wantAnnotations: map[string]string{
+				agent.ResourcePressureWorkersAnnotation: "1",
+				agent.ResourcePressureBytesAnnotation:   "536870912",
+			},
+		},
+		{
+			name:       "memory is capped below the limit",
+			intensity:  1,
+			parameters: map[string]string{"resourceType": "memory", "memory": "4Gi"},
+			wantAnnotations: map[string]string{
+				agent.ResourcePressureBytesAnnotation: "966367641",
+			},
+		},
+		{
+			name:       "memory of a container without a limit",
+			intensity:  0.5,
+			parameters: map[string]string{"resourceType": "memory", "container": "exporter", "memory": "256Mi"},
+			wantAnnotations: map[string]string{
+				agent.ResourcePressureContainerAnnotation: "exporter",
+				agent.ResourcePressureBytesAnnotation:     "134217728",
+			},
+		},
+		{
+			name:       "memory of a container without a limit is capped by the node",
+			intensity:  1,
+			parameters: map[string]string{"resourceType": "memory", "container": "exporter", "memory": "64Gi"},
+			wantAnnotations: map[string]string{
+				agent.ResourcePressureBytesAnnotation: "4294967296",
+			},
+		},
+		{
+			name:       "io workers scale with intensity",
+			intensity:  0.5,
+			parameters: map[string]string{"resourceType": "io", "path": "/var/lib/db"},
+			wantAnnotations: map[string]string{
+				agent.ResourcePressureWorkersAnnotation: "2",
+				agent.ResourcePressurePathAnnotation:    "/var/lib/db",
+			},
+		},
+		{
+			name:       "memory without a limit or size",
+			intensity:  0.5,
+			parameters: map[string]string{"resourceType": "memory", "container": "exporter"},
+			wantErr:    true,
+		},
+		{
+			name:       "too many workers",
+			intensity:  0.5,
+			parameters: map[string]string{"resourceType": "cpu", "workers": "64"},
+			wantErr:    true,
+		},
+		{
+			name:       "no intensity",
+			parameters: map[string]string{"resourceType": "cpu"},
+			wantErr:    true,
+		},
+		{
+			name:       "unsupported resource type",
+			intensity:  0.5,
+			parameters: map[string]string{"resourceType": "gpu"},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			fakeClient := newKubeletRacingClient(scheme, newPressureTestNode(), newPressureTestPod())
+
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					ChaosType:  "ResourcePressure",
+					Duration:   "10m",
+					Intensity:  tt.intensity,
+					Parameters: tt.parameters,
+				},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{
+						{Kind: "Pod", Name: "db-0", Namespace: "default"},
+					},
+				},
+			}
+
+			injector := NewResourcePressureInjector(Dependencies{Client: fakeClient})
+			err := injector.Inject(context.Background(), experiment)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Inject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			pod := &corev1.Pod{}
+			podKey := types.NamespacedName{Namespace: "default", Name: "db-0"}
+			if err := fakeClient.Get(context.Background(), podKey, pod); err != nil {
+				t.Fatalf("Failed to get pod: %v", err)
+			}
+			if pod.Annotations[agent.ResourcePressureAnnotation] != "true" ||
+				pod.Annotations[agent.ResourcePressureTypeAnnotation] != tt.parameters["resourceType"] {
+				t.Errorf("Annotations = %v, want a %s pressure request", pod.Annotations, tt.parameters["resourceType"])
+			}
+			for key, want := range tt.wantAnnotations {
+				if got := pod.Annotations[key]; got != want {
+					t.Errorf("Annotation %s = %q, want %q", key, got, want)
+				}
+			}
+			deadline, err := time.Parse(time.RFC3339, pod.Annotations[agent.ResourcePressureDeadlineAnnotation])
+			if err != nil || time.Until(deadline) < 9*time.Minute || time.Until(deadline) > 10*time.Minute {
+				t.Errorf("Deadline = %q, want the end of the experiment", pod.Annotations[agent.ResourcePressureDeadlineAnnotation])
+			}
+
+			if err := injector.Cleanup(context.Background(), experiment); err != nil {
+				t.Fatalf("Cleanup() error = %v", err)
+			}
+			if err := fakeClient.Get(context.Background(), podKey, pod); err != nil {
+				t.Fatalf("Failed to get pod: %v", err)
+			}
+			if len(pod.Annotations) != 0 {
+				t.Errorf("Annotations after cleanup = %v, want none", pod.Annotations)
+			}
+		})
+	}
+}
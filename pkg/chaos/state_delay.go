@@ -0,0 +1,191 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/agent"
+	"github.com/havock8s/havock8s/pkg/proxy"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// stateDelayAnnotations lists the annotations of a state delay request
+var stateDelayAnnotations = []string{
+	agent.StateDelayAnnotation,
+	agent.StateDelayProtocolAnnotation,
+	agent.StateDelayPortAnnotation,
+	agent.StateDelayOperationsAnnotation,
+	agent.StateDelayActionAnnotation,
+	agent.StateDelayDelayAnnotation,
+	agent.StateDelayPercentAnnotation,
+}
+
+// StateDelayInjector implements the Injector interface for state delay
+// chaos. The node agent puts a proxy speaking the database's wire protocol
+// in front of the targeted pods' database port, which delays, drops or fails
+// the selected operations, e.g. only writes or only COMMIT.
+type StateDelayInjector struct {
+	eventRecorder
+	client client.Client
+	log    logr.Logger
+}
+
+// NewStateDelayInjector creates a state delay injector using the given dependencies
+func NewStateDelayInjector(deps Dependencies) *StateDelayInjector {
+	return &StateDelayInjector{
+		eventRecorder: eventRecorder{recorder: deps.Recorder},
+		client:        deps.Client,
+		log:           deps.Log,
+	}
+}
+
+// Inject asks the node agent to proxy the targeted pods' database port
+func (i *StateDelayInjector) Inject(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Injecting state delay chaos")
+
+	annotations, err := parseStateDelayParams(experiment)
+	if err != nil {
+		return err
+	}
+
+	i.log.Info("State delay parameters",
+		"protocol", annotations[agent.StateDelayProtocolAnnotation],
+		"port", annotations[agent.StateDelayPortAnnotation],
+		"operations", annotations[agent.StateDelayOperationsAnnotation],
+		"action", annotations[agent.StateDelayActionAnnotation])
+
+	pods, err := targetPods(ctx, i.client, experiment)
+	if err != nil {
+		return err
+	}
+
+	for idx := range pods {
+		if err := i.injectPodStateDelay(ctx, experiment, &pods[idx], annotations); err != nil {
+			return err
+		}
+	}
+
+	i.log.Info("State delay chaos injection completed", "pods", len(pods))
+	return nil
+}
+
+// Cleanup removes the state delay request from the targeted pods. The node
+// agent removes the redirect and stops the proxy once it sees the request
+// is gone.
+func (i *StateDelayInjector) Cleanup(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Cleaning up state delay chaos")
+
+	for _, target := range experiment.Status.TargetResources {
+		pods, err := podsOfTarget(ctx, i.client, target)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				i.log.Info("Pod no longer exists, skipping cleanup", "pod", target.Name)
+				continue
+			}
+			return err
+		}
+
+		for idx := range pods {
+			pod := &pods[idx]
+			if _, ok := pod.Annotations[agent.StateDelayAnnotation]; !ok {
+				continue
+			}
+			if err := patchPodAnnotations(ctx, i.client, pod, nil, stateDelayAnnotations); err != nil {
+				return fmt.Errorf("failed to update pod %s/%s: %w", pod.Namespace, pod.Name, err)
+			}
+
+			i.event(experiment, pod, corev1.EventTypeNormal, EventReasonStateDelayRemoved,
+				"Requested removal of the database proxy from pod %s/%s", pod.Namespace, pod.Name)
+			i.log.Info("Removed state delay request from pod", "pod", pod.Name)
+		}
+	}
+
+	i.log.Info("State delay chaos cleanup completed")
+	return nil
+}
+
+// Acknowledged reports whether the node agent proxies the database port of every targeted pod
+func (i *StateDelayInjector) Acknowledged(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) (bool, error) {
+	return podsAcknowledged(ctx, i.client, experiment, agent.StateDelayAckAnnotation, i.log)
+}
+
+// ValidateParameters checks the state delay parameters of an experiment
+func (i *StateDelayInjector) ValidateParameters(experiment *chaosv1alpha1.Havock8sExperiment) error {
+	_, err := parseStateDelayParams(experiment)
+	return err
+}
+
+// parseStateDelayParams validates the state delay parameters of an
+// experiment and returns the annotations requesting them from the node agent
+func parseStateDelayParams(experiment *chaosv1alpha1.Havock8sExperiment) (map[string]string, error) {
+	params := experiment.Spec.Parameters
+	protocol := params["protocol"]
+	if protocol == "" {
+		return nil, fmt.Errorf("protocol parameter is required")
+	}
+	port, ok := params["port"]
+	if !ok {
+		port = strconv.Itoa(proxy.DefaultPorts[protocol])
+	}
+	operations := params["operations"]
+	if operations == "" {
+		operations = proxy.CategoryAll
+	}
+	action := params["action"]
+	if action == "" {
+		action = proxy.ActionDelay
+	}
+	delay := params["delay"]
+	if delay == "" && action == proxy.ActionDelay {
+		delay = "100ms"
+	}
+	percentage := strconv.Itoa(int(experiment.Spec.Intensity * 100))
+	if val, ok := params["percentage"]; ok {
+		percentage = strings.TrimSuffix(val, "%")
+	}
+
+	annotations := map[string]string{
+		agent.StateDelayAnnotation:           "true",
+		agent.StateDelayProtocolAnnotation:   protocol,
+		agent.StateDelayPortAnnotation:       port,
+		agent.StateDelayOperationsAnnotation: strings.Join(proxy.ParseOperations(operations), ","),
+		agent.StateDelayActionAnnotation:     action,
+		agent.StateDelayDelayAnnotation:      delay,
+		agent.StateDelayPercentAnnotation:    percentage,
+	}
+	// The node agent runs the same checks before starting the proxy
+	if _, _, err := agent.StateDelayConfig(annotations); err != nil {
+		return nil, err
+	}
+	return annotations, nil
+}
+
+// injectPodStateDelay asks the node agent to proxy a pod's database port
+func (i *StateDelayInjector) injectPodStateDelay(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, pod *corev1.Pod, annotations map[string]string) error {
+	// Set state delay annotations for the node agent, replacing those of a
+	// previous experiment and dropping its acknowledgement
+	set := make(map[string]string)
+	for _, annotation := range stateDelayAnnotations {
+		if value := annotations[annotation]; value != "" {
+			set[annotation] = value
+		}
+	}
+	remove := append([]string{agent.StateDelayAckAnnotation}, stateDelayAnnotations...)
+	if err := patchPodAnnotations(ctx, i.client, pod, set, remove); err != nil {
+		return fmt.Errorf("failed to update pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+
+	i.event(experiment, pod, corev1.EventTypeNormal, EventReasonStateDelayApplied,
+		"Requested %s of %s%% of the %s %s operations on port %s of pod %s/%s",
+		annotations[agent.StateDelayActionAnnotation], annotations[agent.StateDelayPercentAnnotation],
+		annotations[agent.StateDelayProtocolAnnotation], annotations[agent.StateDelayOperationsAnnotation],
+		annotations[agent.StateDelayPortAnnotation], pod.Namespace, pod.Name)
+	i.log.Info("Requested state delay of pod", "pod", pod.Name)
+	return nil
+}
@@ -0,0 +1,138 @@
+package chaos
+
+import (
+	"context"
+	"testing"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/agent"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestStateDelayInjector_Inject(t *testing.T) {
+	tests := []struct {
+		name            string
+		parameters      map[string]string
+		wantErr         bool
+		wantAnnotations map[string]string
+	}{
+		{
+			name:       "delays every operation by default",
+			parameters: map[string]string{"protocol": "postgres"},
+			wantAnnotations: map[string]string{
+				agent.StateDelayProtocolAnnotation:   "postgres",
+				agent.StateDelayPortAnnotation:       "5432",
+				agent.StateDelayOperationsAnnotation: "all",
+				agent.StateDelayActionAnnotation:     "delay",
+				agent.StateDelayDelayAnnotation:      "100ms",
+				agent.StateDelayPercentAnnotation:    "50",
+			},
+		},
+		{
+			name: "slow commits",
+			parameters: map[string]string{
+				"protocol":   "postgres",
+				"operations": "COMMIT",
+				"delay":      "2s",
+				"percentage": "100%",
+			},
+			wantAnnotations: map[string]string{
+				agent.StateDelayOperationsAnnotation: "commit",
+				agent.StateDelayDelayAnnotation:      "2s",
+				agent.StateDelayPercentAnnotation:    "100",
+			},
+		},
+		{
+			name: "failed writes on a custom port",
+			parameters: map[string]string{
+				"protocol":   "redis",
+				"port":       "7000",
+				"operations": "write, EXEC",
+				"action":     "error",
+			},
+			wantAnnotations: map[string]string{
+				agent.StateDelayPortAnnotation:       "7000",
+				agent.StateDelayOperationsAnnotation: "write,exec",
+				agent.StateDelayActionAnnotation:     "error",
+				agent.StateDelayDelayAnnotation:      "",
+			},
+		},
+		{
+			name:       "no protocol",
+			parameters: map[string]string{"operations": "write"},
+			wantErr:    true,
+		},
+		{
+			name:       "unsupported protocol",
+			parameters: map[string]string{"protocol": "mysql", "port": "3306"},
+			wantErr:    true,
+		},
+		{
+			name:       "unsupported action",
+			parameters: map[string]string{"protocol": "mongodb", "action": "corrupt"},
+			wantErr:    true,
+		},
+		{
+			name:       "invalid delay",
+			parameters: map[string]string{"protocol": "mongodb", "delay": "slow"},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "default"}}
+			fakeClient := newKubeletRacingClient(scheme, pod)
+
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					ChaosType:  "StateDelay",
+					Intensity:  0.5,
+					Parameters: tt.parameters,
+				},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{
+						{Kind: "Pod", Name: "db-0", Namespace: "default"},
+					},
+				},
+			}
+
+			injector := NewStateDelayInjector(Dependencies{Client: fakeClient})
+			err := injector.Inject(context.Background(), experiment)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Inject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			podKey := types.NamespacedName{Namespace: "default", Name: "db-0"}
+			if err := fakeClient.Get(context.Background(), podKey, pod); err != nil {
+				t.Fatalf("Failed to get pod: %v", err)
+			}
+			if pod.Annotations[agent.StateDelayAnnotation] != "true" {
+				t.Errorf("Annotations = %v, want a state delay request", pod.Annotations)
+			}
+			for key, want := range tt.wantAnnotations {
+				if got := pod.Annotations[key]; got != want {
+					t.Errorf("Annotation %s = %q, want %q", key, got, want)
+				}
+			}
+
+			if err := injector.Cleanup(context.Background(), experiment); err != nil {
+				t.Fatalf("Cleanup() error = %v", err)
+			}
+			if err := fakeClient.Get(context.Background(), podKey, pod); err != nil {
+				t.Fatalf("Failed to get pod: %v", err)
+			}
+			if len(pod.Annotations) != 0 {
+				t.Errorf("Annotations after cleanup = %v, want none", pod.Annotations)
+			}
+		})
+	}
+}
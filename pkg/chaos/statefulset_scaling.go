@@ -8,23 +8,72 @@ import (
 	"github.com/go-logr/logr"
 	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // StatefulSetScalingInjector implements the Injector interface for StatefulSet scaling chaos
 type StatefulSetScalingInjector struct {
+	eventRecorder
 	client client.Client
+	log    logr.Logger
 }
 
-// SetClient sets the Kubernetes client
-func (i *StatefulSetScalingInjector) SetClient(c client.Client) {
-	i.client = c
+// NewStatefulSetScalingInjector creates a StatefulSet scaling injector using the given dependencies
+func NewStatefulSetScalingInjector(deps Dependencies) *StatefulSetScalingInjector {
+	return &StatefulSetScalingInjector{
+		eventRecorder: eventRecorder{recorder: deps.Recorder},
+		client:        deps.Client,
+		log:           deps.Log,
+	}
+}
+
+// ValidateParameters checks the scaling parameters of an experiment
+func (i *StatefulSetScalingInjector) ValidateParameters(experiment *chaosv1alpha1.Havock8sExperiment) error {
+	if val, ok := experiment.Spec.Parameters["scaleMode"]; ok {
+		if val != "up" && val != "down" && val != "random" {
+			return fmt.Errorf("scaleMode must be one of up, down or random: %s", val)
+		}
+	}
+
+	if val, ok := experiment.Spec.Parameters["scaleCount"]; ok {
+		if count, err := strconv.Atoi(val); err != nil || count < 1 {
+			return fmt.Errorf("scaleCount must be a positive integer: %s", val)
+		}
+	}
+
+	scaleMin, scaleMax := -1, -1
+	if val, ok := experiment.Spec.Parameters["scaleMin"]; ok {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 0 {
+			return fmt.Errorf("scaleMin must be a non-negative integer: %s", val)
+		}
+		scaleMin = n
+	}
+	if val, ok := experiment.Spec.Parameters["scaleMax"]; ok {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 1 {
+			return fmt.Errorf("scaleMax must be a positive integer: %s", val)
+		}
+		scaleMax = n
+	}
+	if scaleMin >= 0 && scaleMax >= 0 && scaleMin > scaleMax {
+		return fmt.Errorf("scaleMin %d must not exceed scaleMax %d", scaleMin, scaleMax)
+	}
+
+	if val, ok := experiment.Spec.Parameters["allowZero"]; ok {
+		if _, err := strconv.ParseBool(val); err != nil {
+			return fmt.Errorf("allowZero must be true or false: %s", val)
+		}
+	}
+
+	return nil
 }
 
 // Inject applies StatefulSet scaling chaos
-func (i *StatefulSetScalingInjector) Inject(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, log logr.Logger) error {
-	log.Info("Injecting StatefulSet scaling chaos")
+func (i *StatefulSetScalingInjector) Inject(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Injecting StatefulSet scaling chaos")
 
 	// Default scaling behavior is to scale down by 1
 	scaleMode := "down"
@@ -68,7 +117,7 @@ func (i *StatefulSetScalingInjector) Inject(ctx context.Context, experiment *cha
 		}
 	}
 
-	log.Info("StatefulSet scaling parameters",
+	i.log.Info("StatefulSet scaling parameters",
 		"scaleMode", scaleMode,
 		"scaleCount", scaleCount,
 		"scaleMin", scaleMin,
@@ -77,10 +126,10 @@ func (i *StatefulSetScalingInjector) Inject(ctx context.Context, experiment *cha
 
 	// Apply chaos to each target
 	for _, target := range experiment.Status.TargetResources {
-		log.Info("Processing target for StatefulSet scaling", "kind", target.Kind, "name", target.Name, "namespace", target.Namespace)
+		i.log.Info("Processing target for StatefulSet scaling", "kind", target.Kind, "name", target.Name, "namespace", target.Namespace)
 
 		if target.Kind != "StatefulSet" {
-			log.Info("Skipping non-StatefulSet target", "kind", target.Kind)
+			i.log.Info("Skipping non-StatefulSet target", "kind", target.Kind)
 			continue
 		}
 
@@ -91,7 +140,7 @@ func (i *StatefulSetScalingInjector) Inject(ctx context.Context, experiment *cha
 			Name:      target.Name,
 		}, sts)
 		if err != nil {
-			log.Error(err, "Failed to get StatefulSet", "StatefulSet", target.Name)
+			i.log.Error(err, "Failed to get StatefulSet", "StatefulSet", target.Name)
 			return fmt.Errorf("failed to get StatefulSet %s/%s: %w", target.Namespace, target.Name, err)
 		}
 
@@ -128,23 +177,25 @@ func (i *StatefulSetScalingInjector) Inject(ctx context.Context, experiment *cha
 		// Update the StatefulSet with new replica count
 		sts.Spec.Replicas = &newReplicas
 		if err := i.client.Update(ctx, sts); err != nil {
-			log.Error(err, "Failed to update StatefulSet replicas", "StatefulSet", target.Name)
+			i.log.Error(err, "Failed to update StatefulSet replicas", "StatefulSet", target.Name)
 			return err
 		}
 
-		log.Info("Successfully scaled StatefulSet",
+		i.event(experiment, sts, corev1.EventTypeNormal, EventReasonReplicasScaled,
+			"Scaled StatefulSet %s/%s from %d to %d replicas", sts.Namespace, sts.Name, originalReplicas, newReplicas)
+		i.log.Info("Successfully scaled StatefulSet",
 			"StatefulSet", target.Name,
 			"originalReplicas", originalReplicas,
 			"newReplicas", newReplicas)
 	}
 
-	log.Info("StatefulSet scaling chaos injection completed")
+	i.log.Info("StatefulSet scaling chaos injection completed")
 	return nil
 }
 
 // Cleanup reverts StatefulSet scaling changes
-func (i *StatefulSetScalingInjector) Cleanup(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, log logr.Logger) error {
-	log.Info("Cleaning up StatefulSet scaling chaos")
+func (i *StatefulSetScalingInjector) Cleanup(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Cleaning up StatefulSet scaling chaos")
 
 	for _, target := range experiment.Status.TargetResources {
 		if target.Kind != "StatefulSet" {
@@ -158,7 +209,12 @@ func (i *StatefulSetScalingInjector) Cleanup(ctx context.Context, experiment *ch
 			Name:      target.Name,
 		}, sts)
 		if err != nil {
-			log.Error(err, "Failed to get StatefulSet for cleanup", "StatefulSet", target.Name)
+			if client.IgnoreNotFound(err) == nil {
+				// Nothing left to restore
+				i.log.Info("StatefulSet no longer exists, skipping cleanup", "StatefulSet", target.Name)
+				continue
+			}
+			i.log.Error(err, "Failed to get StatefulSet for cleanup", "StatefulSet", target.Name)
 			return fmt.Errorf("failed to get StatefulSet %s/%s: %w", target.Namespace, target.Name, err)
 		}
 
@@ -167,7 +223,7 @@ func (i *StatefulSetScalingInjector) Cleanup(ctx context.Context, experiment *ch
 			if originalReplicasStr, ok := sts.Annotations["havock8s.io/original-replicas"]; ok {
 				originalReplicas, err := strconv.Atoi(originalReplicasStr)
 				if err != nil {
-					log.Error(err, "Failed to parse original replicas", "value", originalReplicasStr)
+					i.log.Error(err, "Failed to parse original replicas", "value", originalReplicasStr)
 					continue
 				}
 
@@ -177,24 +233,26 @@ func (i *StatefulSetScalingInjector) Cleanup(ctx context.Context, experiment *ch
 
 				// Update the StatefulSet
 				if err := i.client.Update(ctx, sts); err != nil {
-					log.Error(err, "Failed to restore StatefulSet replicas", "StatefulSet", target.Name)
+					i.log.Error(err, "Failed to restore StatefulSet replicas", "StatefulSet", target.Name)
 					return err
 				}
 
 				// Remove our annotation
 				delete(sts.Annotations, "havock8s.io/original-replicas")
 				if err := i.client.Update(ctx, sts); err != nil {
-					log.Error(err, "Failed to update StatefulSet annotations", "StatefulSet", target.Name)
+					i.log.Error(err, "Failed to update StatefulSet annotations", "StatefulSet", target.Name)
 					return err
 				}
 
-				log.Info("Successfully restored StatefulSet replicas",
+				i.event(experiment, sts, corev1.EventTypeNormal, EventReasonReplicasRestored,
+					"Restored StatefulSet %s/%s to %d replicas", sts.Namespace, sts.Name, originalReplicas)
+				i.log.Info("Successfully restored StatefulSet replicas",
 					"StatefulSet", target.Name,
 					"replicas", originalReplicas)
 			}
 		}
 	}
 
-	log.Info("StatefulSet scaling chaos cleanup completed")
+	i.log.Info("StatefulSet scaling chaos cleanup completed")
 	return nil
 }
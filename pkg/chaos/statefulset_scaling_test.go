@@ -4,7 +4,6 @@ import (
 	"context"
 	"testing"
 
-	"github.com/go-logr/logr"
 	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -101,10 +100,9 @@ func TestStatefulSetScalingInjector_Inject(t *testing.T) {
 				WithObjects(tt.experiment, tt.statefulSet).
 				Build()
 
-			injector := &StatefulSetScalingInjector{}
-			injector.SetClient(fakeClient)
+			injector := NewStatefulSetScalingInjector(Dependencies{Client: fakeClient})
 
-			err := injector.Inject(context.Background(), tt.experiment, logr.Discard())
+			err := injector.Inject(context.Background(), tt.experiment)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("StatefulSetScalingInjector.Inject() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -184,10 +182,9 @@ func TestStatefulSetScalingInjector_Cleanup(t *testing.T) {
 				WithObjects(tt.experiment, tt.statefulSet).
 				Build()
 
-			injector := &StatefulSetScalingInjector{}
-			injector.SetClient(fakeClient)
+			injector := NewStatefulSetScalingInjector(Dependencies{Client: fakeClient})
 
-			err := injector.Cleanup(context.Background(), tt.experiment, logr.Discard())
+			err := injector.Cleanup(context.Background(), tt.experiment)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("StatefulSetScalingInjector.Cleanup() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -218,4 +215,46 @@ func TestStatefulSetScalingInjector_Cleanup(t *testing.T) {
 
 func int32Ptr(i int32) *int32 {
 	return &i
-} 
\ No newline at end of file
+} 
+func TestStatefulSetScalingInjector_ValidateParameters(t *testing.T) {
+	tests := []struct {
+		name       string
+		parameters map[string]string
+		wantErr    bool
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name:       "valid parameters",
+			parameters: map[string]string{"scaleMode": "random", "scaleCount": "2", "scaleMin": "1", "scaleMax": "5", "allowZero": "false"},
+		},
+		{
+			name:       "invalid scale mode",
+			parameters: map[string]string{"scaleMode": "sideways"},
+			wantErr:    true,
+		},
+		{
+			name:       "invalid scale count",
+			parameters: map[string]string{"scaleCount": "two"},
+			wantErr:    true,
+		},
+		{
+			name:       "minimum above maximum",
+			parameters: map[string]string{"scaleMin": "5", "scaleMax": "3"},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{Parameters: tt.parameters},
+			}
+			err := (&StatefulSetScalingInjector{}).ValidateParameters(experiment)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("StatefulSetScalingInjector.ValidateParameters() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
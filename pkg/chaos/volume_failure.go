@@ -0,0 +1,339 @@
+package chaos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/go-logr/logr"
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/agent"
+	"github.com/havock8s/havock8s/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DetachedAttachmentsAnnotation records on a PersistentVolumeClaim the
+// VolumeAttachments deleted by VolumeFailure chaos, so they can be recreated
+// on cleanup
+const DetachedAttachmentsAnnotation = "havock8s.io/detached-attachments"
+
+// VolumeFailureInjector implements the Injector interface for volume failure
+// chaos on PersistentVolumeClaims. The readonly and unmount modes are applied
+// by the node agent in every pod mounting the claim. The detach mode deletes
+// the VolumeAttachments of the claim's volume, as if the cloud disk was
+// detached from its node, and recreates them on cleanup.
+type VolumeFailureInjector struct {
+	eventRecorder
+	client client.Client
+	log    logr.Logger
+}
+
+// detachedAttachment records a deleted VolumeAttachment
+type detachedAttachment struct {
+	Name     string `json:"name"`
+	Attacher string `json:"attacher"`
+	NodeName string `json:"nodeName"`
+}
+
+// NewVolumeFailureInjector creates a volume failure injector using the given dependencies
+func NewVolumeFailureInjector(deps Dependencies) *VolumeFailureInjector {
+	return &VolumeFailureInjector{
+		eventRecorder: eventRecorder{recorder: deps.Recorder},
+		client:        deps.Client,
+		log:           deps.Log,
+	}
+}
+
+// Inject applies volume failure chaos
+func (i *VolumeFailureInjector) Inject(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Injecting volume failure chaos")
+
+	failureMode, err := parseVolumeFailureMode(experiment)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range experiment.Status.TargetResources {
+		if target.Kind != "PersistentVolumeClaim" {
+			i.log.Info("Unsupported target kind for volume failure", "kind", target.Kind)
+			continue
+		}
+
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := i.client.Get(ctx, types.NamespacedName{Namespace: target.Namespace, Name: target.Name}, pvc); err != nil {
+			return fmt.Errorf("failed to get PVC %s/%s: %w", target.Namespace, target.Name, err)
+		}
+
+		if failureMode == agent.VolumeFailureModeDetach {
+			err = i.detachVolume(ctx, experiment, pvc)
+		} else {
+			err = i.failPodVolumes(ctx, experiment, pvc, failureMode)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	i.log.Info("Volume failure chaos injection completed")
+	return nil
+}
+
+// Cleanup removes volume failure chaos
+func (i *VolumeFailureInjector) Cleanup(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Cleaning up volume failure chaos")
+
+	for _, target := range experiment.Status.TargetResources {
+		if target.Kind != "PersistentVolumeClaim" {
+			continue
+		}
+
+		pvc := &corev1.PersistentVolumeClaim{}
+		err := i.client.Get(ctx, types.NamespacedName{Namespace: target.Namespace, Name: target.Name}, pvc)
+		if apierrors.IsNotFound(err) {
+			// The failure went away with the claim
+			i.log.Info("PVC no longer exists, skipping cleanup", "pvc", target.Name)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get PVC %s/%s: %w", target.Namespace, target.Name, err)
+		}
+
+		// Both are cleaned up whatever the mode, so a changed mode cannot
+		// leave a failure behind
+		if err := i.reattachVolume(ctx, experiment, pvc); err != nil {
+			return err
+		}
+		if err := i.restorePodVolumes(ctx, experiment, pvc); err != nil {
+			return err
+		}
+	}
+
+	i.log.Info("Volume failure chaos cleanup completed")
+	return nil
+}
+
+// Acknowledged reports whether the node agent has applied the volume failure
+// to every pod mounting a targeted claim. Detached volumes need no agent.
+func (i *VolumeFailureInjector) Acknowledged(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) (bool, error) {
+	if failureMode, _ := parseVolumeFailureMode(experiment); failureMode == agent.VolumeFailureModeDetach {
+		return true, nil
+	}
+	return podsAcknowledged(ctx, i.client, experiment, agent.VolumeFailureAckAnnotation, i.log)
+}
+
+// ValidateParameters checks the volume failure parameters of an experiment
+func (i *VolumeFailureInjector) ValidateParameters(experiment *chaosv1alpha1.Havock8sExperiment) error {
+	_, err := parseVolumeFailureMode(experiment)
+	return err
+}
+
+// parseVolumeFailureMode validates the failure mode of an experiment
+func parseVolumeFailureMode(experiment *chaosv1alpha1.Havock8sExperiment) (string, error) {
+	failureMode, ok := experiment.Spec.Parameters["failureMode"]
+	if !ok {
+		return "", fmt.Errorf("failureMode parameter is required")
+	}
+	switch failureMode {
+	case agent.VolumeFailureModeReadOnly, agent.VolumeFailureModeUnmount, agent.VolumeFailureModeDetach:
+		return failureMode, nil
+	default:
+		return "", fmt.Errorf("invalid failure mode: %s", failureMode)
+	}
+}
+
+// failPodVolumes asks the node agent to fail the claim's volume in every pod mounting it
+func (i *VolumeFailureInjector) failPodVolumes(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, pvc *corev1.PersistentVolumeClaim, failureMode string) error {
+	pods, err := utils.ClaimPods(ctx, i.client, pvc.Namespace, pvc.Name)
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range pods {
+		// Set volume failure annotations for the node agent, dropping any
+		// acknowledgement left over from a previous experiment
+		set := map[string]string{
+			agent.VolumeFailureAnnotation:       "true",
+			agent.VolumeFailureModeAnnotation:   failureMode,
+			agent.VolumeFailureVolumeAnnotation: claimVolume(pod, pvc.Name),
+		}
+		if err := patchPodAnnotations(ctx, i.client, &pod, set, []string{agent.VolumeFailureAckAnnotation}); err != nil {
+			return fmt.Errorf("failed to update pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+
+		i.event(experiment, &pod, corev1.EventTypeNormal, EventReasonVolumeFailed,
+			"Requested %s failure of PVC %s on pod %s/%s", failureMode, pvc.Name, pod.Namespace, pod.Name)
+	}
+
+	i.log.Info("Applied volume failure to PVC", "pvc", pvc.Name, "mode", failureMode, "pods", len(pods))
+	return nil
+}
+
+// restorePodVolumes removes the volume failure request from the pods
+// mounting the claim. The node agent reverts the failure and drops its
+// acknowledgement once the request is gone.
+func (i *VolumeFailureInjector) restorePodVolumes(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, pvc *corev1.PersistentVolumeClaim) error {
+	pods, err := utils.ClaimPods(ctx, i.client, pvc.Namespace, pvc.Name)
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range pods {
+		if _, ok := pod.Annotations[agent.VolumeFailureAnnotation]; !ok {
+			continue
+		}
+		remove := []string{
+			agent.VolumeFailureAnnotation,
+			agent.VolumeFailureModeAnnotation,
+			agent.VolumeFailureVolumeAnnotation,
+		}
+		if err := patchPodAnnotations(ctx, i.client, &pod, nil, remove); err != nil {
+			return fmt.Errorf("failed to update pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+
+		i.event(experiment, &pod, corev1.EventTypeNormal, EventReasonVolumeRestored,
+			"Removed volume failure of PVC %s from pod %s/%s", pvc.Name, pod.Namespace, pod.Name)
+		i.log.Info("Removed volume failure from pod", "pod", pod.Name)
+	}
+	return nil
+}
+
+// detachVolume deletes the VolumeAttachments of the claim's volume after
+// recording them on the claim. The attach/detach controller attaches the
+// volume again while pods still use it, so the volume stays detached for as
+// long as the CSI driver takes to detach and attach it.
+func (i *VolumeFailureInjector) detachVolume(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, pvc *corev1.PersistentVolumeClaim) error {
+	if pvc.Spec.VolumeName == "" {
+		return fmt.Errorf("PVC %s/%s is not bound to a volume", pvc.Namespace, pvc.Name)
+	}
+
+	attachments := &storagev1.VolumeAttachmentList{}
+	if err := i.client.List(ctx, attachments); err != nil {
+		return fmt.Errorf("failed to list VolumeAttachments: %w", err)
+	}
+
+	// Keep what an earlier attempt recorded, those attachments may be gone by now
+	detached, err := detachedAttachments(pvc)
+	if err != nil {
+		return err
+	}
+	var toDelete []storagev1.VolumeAttachment
+	for _, attachment := range attachments.Items {
+		source := attachment.Spec.Source.PersistentVolumeName
+		if source == nil || *source != pvc.Spec.VolumeName || attachment.DeletionTimestamp != nil {
+			continue
+		}
+		toDelete = append(toDelete, attachment)
+		recorded := slices.ContainsFunc(detached, func(d detachedAttachment) bool { return d.Name == attachment.Name })
+		if !recorded {
+			detached = append(detached, detachedAttachment{
+				Name:     attachment.Name,
+				Attacher: attachment.Spec.Attacher,
+				NodeName: attachment.Spec.NodeName,
+			})
+		}
+	}
+	if len(detached) == 0 {
+		return fmt.Errorf("volume %s of PVC %s/%s has no VolumeAttachment, only attached CSI volumes can be detached",
+			pvc.Spec.VolumeName, pvc.Namespace, pvc.Name)
+	}
+
+	// Record the attachments before deleting them so they can always be restored
+	data, err := json.Marshal(detached)
+	if err != nil {
+		return fmt.Errorf("failed to record VolumeAttachments: %w", err)
+	}
+	patch := client.MergeFrom(pvc.DeepCopy())
+	if pvc.Annotations == nil {
+		pvc.Annotations = make(map[string]string)
+	}
+	pvc.Annotations[DetachedAttachmentsAnnotation] = string(data)
+	if err := i.client.Patch(ctx, pvc, patch); err != nil {
+		return fmt.Errorf("failed to update PVC %s/%s: %w", pvc.Namespace, pvc.Name, err)
+	}
+
+	for _, attachment := range toDelete {
+		if err := i.client.Delete(ctx, &attachment); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete VolumeAttachment %s: %w", attachment.Name, err)
+		}
+		i.event(experiment, pvc, corev1.EventTypeWarning, EventReasonVolumeFailed,
+			"Detached volume %s of PVC %s/%s from node %s", pvc.Spec.VolumeName, pvc.Namespace, pvc.Name, attachment.Spec.NodeName)
+		i.log.Info("Deleted VolumeAttachment", "attachment", attachment.Name, "node", attachment.Spec.NodeName)
+	}
+	return nil
+}
+
+// reattachVolume recreates the VolumeAttachments recorded on the claim that
+// were not recreated by the attach/detach controller already
+func (i *VolumeFailureInjector) reattachVolume(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, pvc *corev1.PersistentVolumeClaim) error {
+	detached, err := detachedAttachments(pvc)
+	if err != nil || len(detached) == 0 {
+		return err
+	}
+
+	for _, d := range detached {
+		existing := &storagev1.VolumeAttachment{}
+		err := i.client.Get(ctx, types.NamespacedName{Name: d.Name}, existing)
+		if err == nil {
+			if existing.DeletionTimestamp != nil {
+				// The attachment cannot be recreated until the driver detached the volume
+				return fmt.Errorf("VolumeAttachment %s is still being detached", d.Name)
+			}
+			continue
+		}
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get VolumeAttachment %s: %w", d.Name, err)
+		}
+
+		volumeName := pvc.Spec.VolumeName
+		attachment := &storagev1.VolumeAttachment{
+			ObjectMeta: metav1.ObjectMeta{Name: d.Name},
+			Spec: storagev1.VolumeAttachmentSpec{
+				Attacher: d.Attacher,
+				NodeName: d.NodeName,
+				Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: &volumeName},
+			},
+		}
+		if err := i.client.Create(ctx, attachment); err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create VolumeAttachment %s: %w", d.Name, err)
+		}
+		i.event(experiment, pvc, corev1.EventTypeNormal, EventReasonVolumeRestored,
+			"Attached volume %s of PVC %s/%s to node %s again", volumeName, pvc.Namespace, pvc.Name, d.NodeName)
+		i.log.Info("Recreated VolumeAttachment", "attachment", d.Name, "node", d.NodeName)
+	}
+
+	patch := client.MergeFrom(pvc.DeepCopy())
+	delete(pvc.Annotations, DetachedAttachmentsAnnotation)
+	if err := i.client.Patch(ctx, pvc, patch); err != nil {
+		return fmt.Errorf("failed to update PVC %s/%s: %w", pvc.Namespace, pvc.Name, err)
+	}
+	return nil
+}
+
+// detachedAttachments returns the VolumeAttachments recorded on a claim
+func detachedAttachments(pvc *corev1.PersistentVolumeClaim) ([]detachedAttachment, error) {
+	value, ok := pvc.Annotations[DetachedAttachmentsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	var detached []detachedAttachment
+	if err := json.Unmarshal([]byte(value), &detached); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation on PVC %s/%s: %w", DetachedAttachmentsAnnotation, pvc.Namespace, pvc.Name, err)
+	}
+	return detached, nil
+}
+
+// claimVolume returns the name of the pod volume backed by a claim
+func claimVolume(pod corev1.Pod, claimName string) string {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == claimName {
+			return volume.Name
+		}
+	}
+	return ""
+}
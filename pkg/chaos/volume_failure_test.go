@@ -0,0 +1,178 @@
+package chaos
+
+import (
+	"context"
+	"testing"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/agent"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func volumeAttachment(name, volume, node string) *storagev1.VolumeAttachment {
+	return &storagev1.VolumeAttachment{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: storagev1.VolumeAttachmentSpec{
+			Attacher: "ebs.csi.aws.com",
+			NodeName: node,
+			Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: &volume},
+		},
+	}
+}
+
+func TestVolumeFailureInjector(t *testing.T) {
+	tests := []struct {
+		name            string
+		parameters      map[string]string
+		attachments     []client.Object
+		wantErr         bool
+		wantAnnotations map[string]string
+		wantDetached    bool
+	}{
+		{
+			name:       "readonly is requested from the agent",
+			parameters: map[string]string{"failureMode": "readonly"},
+			wantAnnotations: map[string]string{
+				agent.VolumeFailureAnnotation:       "true",
+				agent.VolumeFailureModeAnnotation:   "readonly",
+				agent.VolumeFailureVolumeAnnotation: "data",
+			},
+		},
+		{
+			name:       "unmount is requested from the agent",
+			parameters: map[string]string{"failureMode": "unmount"},
+			wantAnnotations: map[string]string{
+				agent.VolumeFailureModeAnnotation:   "unmount",
+				agent.VolumeFailureVolumeAnnotation: "data",
+			},
+		},
+		{
+			name:       "detach deletes the volume's attachments",
+			parameters: map[string]string{"failureMode": "detach"},
+			attachments: []client.Object{
+				volumeAttachment("csi-a", "pv-1", "node-a"),
+				volumeAttachment("csi-b", "pv-2", "node-a"),
+			},
+			wantDetached: true,
+		},
+		{
+			name:       "detach without attachments",
+			parameters: map[string]string{"failureMode": "detach"},
+			wantErr:    true,
+		},
+		{
+			name:       "missing failure mode",
+			parameters: map[string]string{},
+			wantErr:    true,
+		},
+		{
+			name:       "unsupported failure mode",
+			parameters: map[string]string{"failureMode": "corrupt"},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			_ = storagev1.AddToScheme(scheme)
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "data-db-0", Namespace: "default"},
+				Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-1"},
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "default"},
+				Spec: corev1.PodSpec{
+					Volumes: []corev1.Volume{{
+						Name: "data",
+						VolumeSource: corev1.VolumeSource{
+							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-db-0"},
+						},
+					}},
+				},
+			}
+			objects := append([]client.Object{pvc, pod}, tt.attachments...)
+			fakeClient := newKubeletRacingClient(scheme, objects...)
+
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					ChaosType:  "VolumeFailure",
+					Parameters: tt.parameters,
+				},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{
+						{Kind: "PersistentVolumeClaim", Name: "data-db-0", Namespace: "default"},
+					},
+				},
+			}
+
+			injector := NewVolumeFailureInjector(Dependencies{Client: fakeClient})
+			err := injector.Inject(context.Background(), experiment)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Inject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			ctx := context.Background()
+			podKey := types.NamespacedName{Namespace: "default", Name: "db-0"}
+			pvcKey := types.NamespacedName{Namespace: "default", Name: "data-db-0"}
+			if err := fakeClient.Get(ctx, podKey, pod); err != nil {
+				t.Fatalf("Failed to get pod: %v", err)
+			}
+			for key, want := range tt.wantAnnotations {
+				if got := pod.Annotations[key]; got != want {
+					t.Errorf("Annotation %s = %q, want %q", key, got, want)
+				}
+			}
+			if tt.wantDetached {
+				err := fakeClient.Get(ctx, types.NamespacedName{Name: "csi-a"}, &storagev1.VolumeAttachment{})
+				if !apierrors.IsNotFound(err) {
+					t.Errorf("Get(csi-a) error = %v, want NotFound", err)
+				}
+				if err := fakeClient.Get(ctx, types.NamespacedName{Name: "csi-b"}, &storagev1.VolumeAttachment{}); err != nil {
+					t.Errorf("Attachment of another volume was deleted: %v", err)
+				}
+				if err := fakeClient.Get(ctx, pvcKey, pvc); err != nil {
+					t.Fatalf("Failed to get PVC: %v", err)
+				}
+				if pvc.Annotations[DetachedAttachmentsAnnotation] == "" {
+					t.Errorf("PVC annotations = %v, want the detached attachments recorded", pvc.Annotations)
+				}
+			}
+
+			if err := injector.Cleanup(ctx, experiment); err != nil {
+				t.Fatalf("Cleanup() error = %v", err)
+			}
+			if err := fakeClient.Get(ctx, podKey, pod); err != nil {
+				t.Fatalf("Failed to get pod: %v", err)
+			}
+			if _, ok := pod.Annotations[agent.VolumeFailureAnnotation]; ok {
+				t.Errorf("Pod annotations after cleanup = %v, want no volume failure", pod.Annotations)
+			}
+			if tt.wantDetached {
+				attachment := &storagev1.VolumeAttachment{}
+				if err := fakeClient.Get(ctx, types.NamespacedName{Name: "csi-a"}, attachment); err != nil {
+					t.Fatalf("VolumeAttachment not recreated: %v", err)
+				}
+				if attachment.Spec.NodeName != "node-a" || *attachment.Spec.Source.PersistentVolumeName != "pv-1" {
+					t.Errorf("Recreated attachment spec = %+v", attachment.Spec)
+				}
+				if err := fakeClient.Get(ctx, pvcKey, pvc); err != nil {
+					t.Fatalf("Failed to get PVC: %v", err)
+				}
+				if _, ok := pvc.Annotations[DetachedAttachmentsAnnotation]; ok {
+					t.Errorf("PVC annotations after cleanup = %v, want none", pvc.Annotations)
+				}
+			}
+		})
+	}
+}
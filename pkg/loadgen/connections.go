@@ -0,0 +1,226 @@
+package loadgen
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// postgresProtocolVersion is protocol 3.0, as sent in startup messages
+const postgresProtocolVersion = 196608
+
+// slowLorisLength is the size announced by slow loris requests, large
+// enough to keep the server reading for a long while and small enough for
+// it to accept
+var slowLorisLength = map[string]int{
+	ProtocolPostgres: 10000,
+	ProtocolMongoDB:  1 << 20,
+	ProtocolRedis:    1 << 20,
+}
+
+// idle waits for the server to close the connection
+func idle(conn net.Conn) error {
+	_, err := io.Copy(io.Discard, conn)
+	return err
+}
+
+// slowLoris sends the header of a large request, then a byte of its body
+// per interval
+func slowLoris(ctx context.Context, conn net.Conn, protocol string, interval time.Duration) error {
+	if _, err := conn.Write(slowLorisHeader(protocol)); err != nil {
+		return err
+	}
+	// Notice the server closing the connection between writes
+	go func() {
+		_, _ = io.Copy(io.Discard, conn)
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+		if _, err := conn.Write([]byte{'a'}); err != nil {
+			return err
+		}
+	}
+}
+
+// slowLorisHeader returns the start of a request whose body never completes
+func slowLorisHeader(protocol string) []byte {
+	length := slowLorisLength[protocol]
+	switch protocol {
+	case ProtocolPostgres:
+		// A startup message, whose parameters trickle in
+		header := binary.BigEndian.AppendUint32(nil, uint32(length))
+		return binary.BigEndian.AppendUint32(header, postgresProtocolVersion)
+	case ProtocolMongoDB:
+		// An OP_MSG header
+		header := binary.LittleEndian.AppendUint32(nil, uint32(length))
+		header = binary.LittleEndian.AppendUint32(header, 1)
+		header = binary.LittleEndian.AppendUint32(header, 0)
+		return binary.LittleEndian.AppendUint32(header, 2013)
+	case ProtocolRedis:
+		// A SET whose value trickles in
+		return []byte(fmt.Sprintf("*3\r\n$3\r\nSET\r\n$17\r\nhavock8s-overload\r\n$%d\r\n", length))
+	default:
+		return nil
+	}
+}
+
+// active performs the protocol's handshake, then sends a lightweight
+// request per interval and reads its answer. Connections that cannot log
+// in still hold a connection slot, so they stay open until the server
+// closes them.
+func active(ctx context.Context, conn net.Conn, protocol, user string, interval time.Duration) error {
+	r := bufio.NewReader(conn)
+
+	var request []byte
+	var readAnswer func(r *bufio.Reader) error
+	switch protocol {
+	case ProtocolPostgres:
+		ready, err := postgresLogin(conn, r, user)
+		if err != nil {
+			return err
+		}
+		if !ready {
+			return idle(conn)
+		}
+		request = postgresQuery("SELECT 1")
+		readAnswer = readPostgresAnswer
+	case ProtocolMongoDB:
+		request = mongoHello()
+		readAnswer = readMongoAnswer
+	default:
+		request = []byte("*1\r\n$4\r\nPING\r\n")
+		readAnswer = readRedisAnswer
+	}
+
+	for {
+		if _, err := conn.Write(request); err != nil {
+			return err
+		}
+		if err := readAnswer(r); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// postgresLogin sends a startup message and reports whether the server let
+// the user in without a password
+func postgresLogin(conn net.Conn, r *bufio.Reader, user string) (bool, error) {
+	body := binary.BigEndian.AppendUint32(nil, postgresProtocolVersion)
+	for _, param := range []string{"user", user, "database", user, "application_name", "havock8s-loadgen"} {
+		body = append(body, param...)
+		body = append(body, 0)
+	}
+	body = append(body, 0)
+	startup := binary.BigEndian.AppendUint32(nil, uint32(4+len(body)))
+	if _, err := conn.Write(append(startup, body...)); err != nil {
+		return false, err
+	}
+
+	for {
+		kind, body, err := readPostgresMessage(r)
+		if err != nil {
+			return false, err
+		}
+		switch kind {
+		case 'R':
+			// Anything but AuthenticationOk asks for credentials
+			if len(body) < 4 || binary.BigEndian.Uint32(body) != 0 {
+				return false, nil
+			}
+		case 'E':
+			return false, fmt.Errorf("login refused")
+		case 'Z':
+			return true, nil
+		}
+	}
+}
+
+// postgresQuery returns a simple query message
+func postgresQuery(query string) []byte {
+	msg := []byte{'Q'}
+	msg = binary.BigEndian.AppendUint32(msg, uint32(4+len(query)+1))
+	msg = append(msg, query...)
+	return append(msg, 0)
+}
+
+// readPostgresAnswer reads messages up to ReadyForQuery
+func readPostgresAnswer(r *bufio.Reader) error {
+	for {
+		kind, _, err := readPostgresMessage(r)
+		if err != nil || kind == 'Z' {
+			return err
+		}
+	}
+}
+
+// readPostgresMessage reads a typed message
+func readPostgresMessage(r *bufio.Reader) (byte, []byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+	length := binary.BigEndian.Uint32(header[1:])
+	if length < 4 || length > 1<<24 {
+		return 0, nil, fmt.Errorf("invalid message length %d", length)
+	}
+	body := make([]byte, length-4)
+	_, err := io.ReadFull(r, body)
+	return header[0], body, err
+}
+
+// mongoHello returns an OP_MSG running {hello: 1, $db: "admin"}
+func mongoHello() []byte {
+	doc := []byte{0, 0, 0, 0}
+	doc = append(doc, 0x10)
+	doc = append(doc, "hello\x00"...)
+	doc = binary.LittleEndian.AppendUint32(doc, 1)
+	doc = append(doc, 0x02)
+	doc = append(doc, "$db\x00"...)
+	doc = binary.LittleEndian.AppendUint32(doc, 6)
+	doc = append(doc, "admin\x00"...)
+	doc = append(doc, 0)
+	binary.LittleEndian.PutUint32(doc, uint32(len(doc)))
+
+	msg := binary.LittleEndian.AppendUint32(nil, uint32(21+len(doc)))
+	msg = binary.LittleEndian.AppendUint32(msg, 1)
+	msg = binary.LittleEndian.AppendUint32(msg, 0)
+	msg = binary.LittleEndian.AppendUint32(msg, 2013)
+	// No flags, then the body section
+	msg = append(msg, 0, 0, 0, 0, 0)
+	return append(msg, doc...)
+}
+
+// readMongoAnswer reads a reply message
+func readMongoAnswer(r *bufio.Reader) error {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return err
+	}
+	length := binary.LittleEndian.Uint32(header)
+	if length < 16 || length > 48<<20 {
+		return fmt.Errorf("invalid message length %d", length)
+	}
+	_, err := io.CopyN(io.Discard, r, int64(length-4))
+	return err
+}
+
+// readRedisAnswer reads the single line reply to a PING
+func readRedisAnswer(r *bufio.Reader) error {
+	_, err := r.ReadString('\n')
+	return err
+}
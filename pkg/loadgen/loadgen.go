@@ -0,0 +1,183 @@
+// Package loadgen implements the load generator behind ConnectionOverload
+// chaos. It opens a number of TCP connections against a database and holds
+// them, reconnecting whenever the server closes one, so the database's
+// connection slots stay taken for as long as the generator runs.
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Connection types
+const (
+	// ConnectionIdle opens connections and sends nothing
+	ConnectionIdle = "idle"
+
+	// ConnectionSlowLoris starts a request and trickles its bytes, keeping
+	// servers that wait for complete requests busy
+	ConnectionSlowLoris = "slowloris"
+
+	// ConnectionActive performs the protocol's handshake and keeps sending
+	// lightweight requests
+	ConnectionActive = "active"
+
+	// ConnectionMixed spreads the connections evenly over the other types
+	ConnectionMixed = "mixed"
+)
+
+// Supported protocols
+const (
+	// ProtocolPostgres is the PostgreSQL frontend/backend protocol
+	ProtocolPostgres = "postgres"
+
+	// ProtocolMongoDB is the MongoDB wire protocol
+	ProtocolMongoDB = "mongodb"
+
+	// ProtocolRedis is the Redis serialization protocol
+	ProtocolRedis = "redis"
+)
+
+// reconnectDelay is how long a connection waits before dialing again after
+// a failure
+const reconnectDelay = time.Second
+
+// Config configures the load generator
+type Config struct {
+	// Targets are the addresses connections are spread over
+	Targets []string
+
+	// Connections is the number of connections to hold
+	Connections int
+
+	// ConnectionType is how connections are used
+	ConnectionType string
+
+	// Protocol is the wire protocol of the database, optional unless
+	// connections are active
+	Protocol string
+
+	// User is the user active PostgreSQL connections log in as
+	User string
+
+	// Interval is the pause between the bytes of slow loris connections
+	// and between the requests of active ones
+	Interval time.Duration
+}
+
+// Validate checks the configuration
+func (c Config) Validate() error {
+	if len(c.Targets) == 0 {
+		return fmt.Errorf("at least one target is required")
+	}
+	for _, target := range c.Targets {
+		if _, _, err := net.SplitHostPort(target); err != nil {
+			return fmt.Errorf("invalid target %q: %w", target, err)
+		}
+	}
+	if c.Connections < 1 {
+		return fmt.Errorf("connections must be positive")
+	}
+	switch c.Protocol {
+	case "", ProtocolPostgres, ProtocolMongoDB, ProtocolRedis:
+	default:
+		return fmt.Errorf("unsupported protocol: %s", c.Protocol)
+	}
+	switch c.ConnectionType {
+	case ConnectionIdle, ConnectionSlowLoris, ConnectionMixed:
+	case ConnectionActive:
+		if c.Protocol == "" {
+			return fmt.Errorf("active connections need a protocol")
+		}
+	default:
+		return fmt.Errorf("unsupported connection type: %s", c.ConnectionType)
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	return nil
+}
+
+// connectionType returns the type of the nth connection. Mixed connections
+// are only active when the protocol is known.
+func (c Config) connectionType(n int) string {
+	if c.ConnectionType != ConnectionMixed {
+		return c.ConnectionType
+	}
+	types := []string{ConnectionIdle, ConnectionSlowLoris}
+	if c.Protocol != "" {
+		types = append(types, ConnectionActive)
+	}
+	return types[n%len(types)]
+}
+
+// Generator holds the configured connections
+type Generator struct {
+	config Config
+	open   atomic.Int64
+}
+
+// NewGenerator creates a load generator for a validated configuration
+func NewGenerator(config Config) (*Generator, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &Generator{config: config}, nil
+}
+
+// Open returns the number of connections currently open
+func (g *Generator) Open() int {
+	return int(g.open.Load())
+}
+
+// Run holds the connections until the context is done
+func (g *Generator) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for n := 0; n < g.config.Connections; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g.hold(ctx, g.config.Targets[n%len(g.config.Targets)], g.config.connectionType(n))
+		}()
+	}
+	wg.Wait()
+}
+
+// hold keeps one connection to the target open, dialing again whenever it
+// is closed
+func (g *Generator) hold(ctx context.Context, target, connectionType string) {
+	var dialer net.Dialer
+	for ctx.Err() == nil {
+		conn, err := dialer.DialContext(ctx, "tcp", target)
+		if err == nil {
+			g.open.Add(1)
+			g.use(ctx, conn, connectionType)
+			g.open.Add(-1)
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// use runs a connection until it fails or the context is done
+func (g *Generator) use(ctx context.Context, conn net.Conn, connectionType string) {
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	defer conn.Close()
+
+	switch connectionType {
+	case ConnectionSlowLoris:
+		_ = slowLoris(ctx, conn, g.config.Protocol, g.config.Interval)
+	case ConnectionActive:
+		_ = active(ctx, conn, g.config.Protocol, g.config.User, g.config.Interval)
+	default:
+		_ = idle(conn)
+	}
+}
@@ -0,0 +1,142 @@
+package loadgen
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	valid := Config{
+		Targets:        []string{"db.default.svc:5432"},
+		Connections:    10,
+		ConnectionType: ConnectionMixed,
+		Interval:       time.Second,
+	}
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(c *Config) {}},
+		{name: "active with a protocol", modify: func(c *Config) { c.ConnectionType, c.Protocol = ConnectionActive, ProtocolPostgres }},
+		{name: "active without a protocol", modify: func(c *Config) { c.ConnectionType = ConnectionActive }, wantErr: true},
+		{name: "target without a port", modify: func(c *Config) { c.Targets = []string{"db"} }, wantErr: true},
+		{name: "no connections", modify: func(c *Config) { c.Connections = 0 }, wantErr: true},
+		{name: "unsupported protocol", modify: func(c *Config) { c.Protocol = "mysql" }, wantErr: true},
+		{name: "unsupported type", modify: func(c *Config) { c.ConnectionType = "burst" }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid
+			tt.modify(&config)
+			if err := config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerator_Run(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	// A Redis server answering PINGs and recording what each connection opened with
+	var mu sync.Mutex
+	var openings []string
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				first := true
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if first {
+						mu.Lock()
+						openings = append(openings, line)
+						mu.Unlock()
+						first = false
+					}
+					if line == "PING\r\n" {
+						_, _ = conn.Write([]byte("+PONG\r\n"))
+					}
+				}
+			}()
+		}
+	}()
+
+	generator, err := NewGenerator(Config{
+		Targets:        []string{listener.Addr().String()},
+		Connections:    6,
+		ConnectionType: ConnectionMixed,
+		Protocol:       ProtocolRedis,
+		Interval:       10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewGenerator() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		generator.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		opened := len(openings)
+		mu.Unlock()
+		if generator.Open() == 6 && opened == 4 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Open connections = %d with %d requests started, want 6 with 4", generator.Open(), opened)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Two connections of each type: idle ones send nothing, slow loris ones
+	// start a SET and active ones PING
+	mu.Lock()
+	var sets, pings int
+	for _, opening := range openings {
+		switch {
+		case strings.HasPrefix(opening, "*3"):
+			sets++
+		case strings.HasPrefix(opening, "*1"):
+			pings++
+		}
+	}
+	mu.Unlock()
+	if sets != 2 || pings != 2 {
+		t.Errorf("Connections opened with %d SETs and %d PINGs, want 2 of each", sets, pings)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not return after the context was done")
+	}
+	if generator.Open() != 0 {
+		t.Errorf("Open connections after Run() = %d, want 0", generator.Open())
+	}
+}
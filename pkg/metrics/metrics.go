@@ -0,0 +1,170 @@
+// Package metrics exposes Prometheus metrics about havock8s experiments on
+// the controller-runtime metrics registry, which the manager serves on its
+// metrics endpoint.
+package metrics
+
+import (
+	"context"
+	"time"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// namespace prefixes the names of all havock8s metrics
+	namespace = "havock8s"
+
+	// Operations of an injector
+	OperationInject  = "inject"
+	OperationCleanup = "cleanup"
+
+	// collectTimeout bounds listing experiments during a scrape
+	collectTimeout = 10 * time.Second
+)
+
+var (
+	// injectorDuration tracks how long injectors take to apply and remove chaos
+	injectorDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "injector_duration_seconds",
+		Help:      "Time taken by an injector to inject or clean up chaos.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+	}, []string{"chaos_type", "operation"})
+
+	// injectorFailures counts injections and cleanups that returned an error
+	injectorFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "injector_failures_total",
+		Help:      "Number of chaos injections or cleanups that failed.",
+	}, []string{"chaos_type", "operation"})
+
+	// safetyTrips counts failed safety checks by the kind of check
+	safetyTrips = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "safety_trips_total",
+		Help:      "Number of times a safety check failed for an experiment.",
+	}, []string{"chaos_type", "reason"})
+
+	// recoveryDuration tracks how long targets take to become ready after chaos ends
+	recoveryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "recovery_duration_seconds",
+		Help:      "Time from the end of an experiment until all target pods were ready again.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 11),
+	}, []string{"chaos_type"})
+
+	// recoveryTimeouts counts experiments whose targets did not recover in time
+	recoveryTimeouts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "recovery_timeouts_total",
+		Help:      "Number of experiments whose target pods did not become ready after the experiment ended.",
+	}, []string{"chaos_type"})
+
+	experimentsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "experiments"),
+		"Number of experiments by phase and chaos type.",
+		[]string{"phase", "chaos_type"}, nil)
+
+	targetsUnderChaosDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "targets_under_chaos"),
+		"Number of target resources of experiments that are injecting or running chaos.",
+		[]string{"chaos_type"}, nil)
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		injectorDuration,
+		injectorFailures,
+		safetyTrips,
+		recoveryDuration,
+		recoveryTimeouts,
+	)
+}
+
+// ObserveInjector records how long an injector operation took and whether it failed
+func ObserveInjector(chaosType, operation string, duration time.Duration, err error) {
+	injectorDuration.WithLabelValues(chaosType, operation).Observe(duration.Seconds())
+	if err != nil {
+		injectorFailures.WithLabelValues(chaosType, operation).Inc()
+	}
+}
+
+// RecordSafetyTrip counts a failed safety check. reason is the kind of check
+// that failed, not the free-form message, to keep the label bounded.
+func RecordSafetyTrip(chaosType, reason string) {
+	safetyTrips.WithLabelValues(chaosType, reason).Inc()
+}
+
+// ObserveRecovery records how long the targets of an experiment took to recover
+func ObserveRecovery(chaosType string, duration time.Duration) {
+	recoveryDuration.WithLabelValues(chaosType).Observe(duration.Seconds())
+}
+
+// RecordRecoveryTimeout counts an experiment whose targets did not recover in time
+func RecordRecoveryTimeout(chaosType string) {
+	recoveryTimeouts.WithLabelValues(chaosType).Inc()
+}
+
+// experimentCollector reports the experiments and their targets as they are
+// in the cluster at scrape time, so the gauges never drift from reality
+type experimentCollector struct {
+	reader client.Reader
+}
+
+// NewExperimentCollector creates a collector of experiment gauges reading
+// experiments through reader
+func NewExperimentCollector(reader client.Reader) prometheus.Collector {
+	return &experimentCollector{reader: reader}
+}
+
+// RegisterExperimentCollector registers the experiment gauges on the
+// controller-runtime metrics registry
+func RegisterExperimentCollector(reader client.Reader) error {
+	return ctrlmetrics.Registry.Register(NewExperimentCollector(reader))
+}
+
+// Describe sends the descriptors of the experiment gauges
+func (c *experimentCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- experimentsDesc
+	ch <- targetsUnderChaosDesc
+}
+
+// Collect lists the experiments and sends the experiment gauges
+func (c *experimentCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	experiments := &chaosv1alpha1.Havock8sExperimentList{}
+	if err := c.reader.List(ctx, experiments); err != nil {
+		// Failing the collector would fail the whole scrape
+		logf.Log.WithName("metrics").Error(err, "Failed to list experiments")
+		return
+	}
+
+	type phaseKey struct{ phase, chaosType string }
+	phases := make(map[phaseKey]int)
+	targets := make(map[string]int)
+	for _, experiment := range experiments.Items {
+		phase := experiment.Status.Phase
+		if phase == "" {
+			phase = "New"
+		}
+		phases[phaseKey{phase, experiment.Spec.ChaosType}]++
+
+		switch experiment.Status.Phase {
+		case "Injecting", "Running":
+			targets[experiment.Spec.ChaosType] += len(experiment.Status.TargetResources)
+		}
+	}
+
+	for key, count := range phases {
+		ch <- prometheus.MustNewConstMetric(experimentsDesc, prometheus.GaugeValue, float64(count), key.phase, key.chaosType)
+	}
+	for chaosType, count := range targets {
+		ch <- prometheus.MustNewConstMetric(targetsUnderChaosDesc, prometheus.GaugeValue, float64(count), chaosType)
+	}
+}
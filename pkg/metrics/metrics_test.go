@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestExperimentCollector(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = chaosv1alpha1.AddToScheme(scheme)
+
+	experiment := func(name, chaosType, phase string, targets int) client.Object {
+		e := &chaosv1alpha1.Havock8sExperiment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       chaosv1alpha1.Havock8sExperimentSpec{ChaosType: chaosType},
+			Status:     chaosv1alpha1.Havock8sExperimentStatus{Phase: phase},
+		}
+		for i := 0; i < targets; i++ {
+			e.Status.TargetResources = append(e.Status.TargetResources, chaosv1alpha1.TargetResourceStatus{Kind: "Pod"})
+		}
+		return e
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			experiment("new", "PodFailure", "", 0),
+			experiment("running-1", "PodFailure", "Running", 2),
+			experiment("running-2", "PodFailure", "Running", 1),
+			experiment("injecting", "NetworkLatency", "Injecting", 3),
+			experiment("completed", "NetworkLatency", "Completed", 4),
+		).
+		Build()
+
+	expected := `
+# HELP havock8s_experiments Number of experiments by phase and chaos type.
+# TYPE havock8s_experiments gauge
+havock8s_experiments{chaos_type="NetworkLatency",phase="Completed"} 1
+havock8s_experiments{chaos_type="NetworkLatency",phase="Injecting"} 1
+havock8s_experiments{chaos_type="PodFailure",phase="New"} 1
+havock8s_experiments{chaos_type="PodFailure",phase="Running"} 2
+# HELP havock8s_targets_under_chaos Number of target resources of experiments that are injecting or running chaos.
+# TYPE havock8s_targets_under_chaos gauge
+havock8s_targets_under_chaos{chaos_type="NetworkLatency"} 3
+havock8s_targets_under_chaos{chaos_type="PodFailure"} 3
+`
+	if err := testutil.CollectAndCompare(NewExperimentCollector(fakeClient), strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestObserveInjector(t *testing.T) {
+	injectorFailures.Reset()
+	injectorDuration.Reset()
+
+	ObserveInjector("PodFailure", OperationInject, time.Second, nil)
+	ObserveInjector("PodFailure", OperationCleanup, time.Second, errors.New("boom"))
+	ObserveInjector("PodFailure", OperationCleanup, time.Second, errors.New("boom"))
+
+	if got := testutil.ToFloat64(injectorFailures.WithLabelValues("PodFailure", OperationInject)); got != 0 {
+		t.Errorf("inject failures = %v, want 0", got)
+	}
+	if got := testutil.ToFloat64(injectorFailures.WithLabelValues("PodFailure", OperationCleanup)); got != 2 {
+		t.Errorf("cleanup failures = %v, want 2", got)
+	}
+	if got := testutil.CollectAndCount(injectorDuration); got != 2 {
+		t.Errorf("duration series = %d, want 2", got)
+	}
+}
+
+func TestRecordSafetyTrip(t *testing.T) {
+	safetyTrips.Reset()
+
+	RecordSafetyTrip("PodFailure", "HealthCheck")
+	RecordSafetyTrip("PodFailure", "HealthCheck")
+	RecordSafetyTrip("PodFailure", "PauseCondition")
+
+	if got := testutil.ToFloat64(safetyTrips.WithLabelValues("PodFailure", "HealthCheck")); got != 2 {
+		t.Errorf("health check trips = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(safetyTrips.WithLabelValues("PodFailure", "PauseCondition")); got != 1 {
+		t.Errorf("pause condition trips = %v, want 1", got)
+	}
+}
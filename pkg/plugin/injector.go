@@ -0,0 +1,157 @@
+// Package plugin lets chaos types be injected by out-of-process plugins. A
+// plugin serves the pluginpb.Injector gRPC service behind a Kubernetes
+// Service and is registered with a ChaosPlugin resource. The controller then
+// builds an Injector forwarding every call to the plugin.
+package plugin
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/chaos"
+	"github.com/havock8s/havock8s/pkg/plugin/pluginpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// DefaultTimeout bounds calls to plugins that do not set spec.timeoutSeconds
+const DefaultTimeout = 30 * time.Second
+
+// Injector implements the chaos injector interfaces by calling a plugin
+type Injector struct {
+	client  pluginpb.InjectorClient
+	timeout time.Duration
+	log     logr.Logger
+}
+
+// NewInjector creates an injector calling the plugin served over conn
+func NewInjector(conn grpc.ClientConnInterface, timeout time.Duration, deps chaos.Dependencies) *Injector {
+	return &Injector{
+		client:  pluginpb.NewInjectorClient(conn),
+		timeout: timeout,
+		log:     deps.Log,
+	}
+}
+
+// Factory returns the injector factory registered for a plugin's chaos types
+func Factory(conn grpc.ClientConnInterface, timeout time.Duration) chaos.InjectorFactory {
+	return func(deps chaos.Dependencies) chaos.Injector {
+		return NewInjector(conn, timeout, deps)
+	}
+}
+
+// Dial sets up the connection to a plugin's Service. Connecting is lazy, so
+// an unreachable plugin only fails the calls made to it.
+func Dial(spec chaosv1alpha1.ChaosPluginSpec) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if spec.CABundle != "" {
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM([]byte(spec.CABundle)) {
+			return nil, errors.New("caBundle contains no PEM encoded certificates")
+		}
+		creds = credentials.NewTLS(&tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12})
+	}
+
+	conn, err := grpc.NewClient(Address(spec.Service), grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to plugin: %w", err)
+	}
+	return conn, nil
+}
+
+// Address returns the in-cluster address of a plugin's Service
+func Address(service chaosv1alpha1.PluginServiceReference) string {
+	return fmt.Sprintf("dns:///%s.%s.svc:%d", service.Name, service.Namespace, service.Port)
+}
+
+// Timeout returns the bound on calls to a plugin
+func Timeout(spec chaosv1alpha1.ChaosPluginSpec) time.Duration {
+	if spec.TimeoutSeconds > 0 {
+		return time.Duration(spec.TimeoutSeconds) * time.Second
+	}
+	return DefaultTimeout
+}
+
+// Inject asks the plugin to apply chaos
+func (i *Injector) Inject(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Injecting chaos through plugin", "chaosType", experiment.Spec.ChaosType)
+
+	ctx, cancel := context.WithTimeout(ctx, i.timeout)
+	defer cancel()
+	if _, err := i.client.Inject(ctx, &pluginpb.InjectRequest{Experiment: Experiment(experiment)}); err != nil {
+		return fmt.Errorf("plugin failed to inject %s chaos: %w", experiment.Spec.ChaosType, err)
+	}
+
+	i.log.Info("Plugin chaos injection completed")
+	return nil
+}
+
+// Cleanup asks the plugin to remove chaos
+func (i *Injector) Cleanup(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) error {
+	i.log.Info("Cleaning up chaos through plugin", "chaosType", experiment.Spec.ChaosType)
+
+	ctx, cancel := context.WithTimeout(ctx, i.timeout)
+	defer cancel()
+	if _, err := i.client.Cleanup(ctx, &pluginpb.CleanupRequest{Experiment: Experiment(experiment)}); err != nil {
+		return fmt.Errorf("plugin failed to clean up %s chaos: %w", experiment.Spec.ChaosType, err)
+	}
+
+	i.log.Info("Plugin chaos cleanup completed")
+	return nil
+}
+
+// Acknowledged reports whether the plugin's chaos is in effect on every target
+func (i *Injector) Acknowledged(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, i.timeout)
+	defer cancel()
+	resp, err := i.client.Status(ctx, &pluginpb.StatusRequest{Experiment: Experiment(experiment)})
+	if err != nil {
+		return false, fmt.Errorf("failed to get %s chaos status from plugin: %w", experiment.Spec.ChaosType, err)
+	}
+	if !resp.GetApplied() {
+		i.log.Info("Plugin has not applied chaos yet", "message", resp.GetMessage())
+	}
+	return resp.GetApplied(), nil
+}
+
+// ValidateParameters asks the plugin to check the experiment's parameters
+func (i *Injector) ValidateParameters(experiment *chaosv1alpha1.Havock8sExperiment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), i.timeout)
+	defer cancel()
+	resp, err := i.client.Validate(ctx, &pluginpb.ValidateRequest{Experiment: Experiment(experiment)})
+	if err != nil {
+		return fmt.Errorf("plugin could not validate parameters: %w", err)
+	}
+	if resp.GetError() != "" {
+		return errors.New(resp.GetError())
+	}
+	return nil
+}
+
+// Experiment converts an experiment to the message sent to plugins
+func Experiment(experiment *chaosv1alpha1.Havock8sExperiment) *pluginpb.Experiment {
+	msg := &pluginpb.Experiment{
+		Uid:        string(experiment.UID),
+		Name:       experiment.Name,
+		Namespace:  experiment.Namespace,
+		ChaosType:  experiment.Spec.ChaosType,
+		Duration:   experiment.Spec.Duration,
+		Intensity:  experiment.Spec.Intensity,
+		Parameters: experiment.Spec.Parameters,
+	}
+	for _, target := range experiment.Status.TargetResources {
+		msg.Targets = append(msg.Targets, &pluginpb.Target{
+			Kind:      target.Kind,
+			Name:      target.Name,
+			Namespace: target.Namespace,
+		})
+	}
+	return msg
+}
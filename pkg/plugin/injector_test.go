@@ -0,0 +1,230 @@
+package plugin
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/chaos"
+	"github.com/havock8s/havock8s/pkg/plugin/pluginpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakePlugin records the experiments it is called with
+type fakePlugin struct {
+	pluginpb.UnimplementedInjectorServer
+
+	validateError string
+	applied       bool
+	err           error
+	calls         []string
+	experiments   []*pluginpb.Experiment
+}
+
+func (p *fakePlugin) record(call string, experiment *pluginpb.Experiment) {
+	p.calls = append(p.calls, call)
+	p.experiments = append(p.experiments, experiment)
+}
+
+func (p *fakePlugin) Validate(ctx context.Context, req *pluginpb.ValidateRequest) (*pluginpb.ValidateResponse, error) {
+	p.record("Validate", req.GetExperiment())
+	return &pluginpb.ValidateResponse{Error: p.validateError}, p.err
+}
+
+func (p *fakePlugin) Inject(ctx context.Context, req *pluginpb.InjectRequest) (*pluginpb.InjectResponse, error) {
+	p.record("Inject", req.GetExperiment())
+	return &pluginpb.InjectResponse{}, p.err
+}
+
+func (p *fakePlugin) Cleanup(ctx context.Context, req *pluginpb.CleanupRequest) (*pluginpb.CleanupResponse, error) {
+	p.record("Cleanup", req.GetExperiment())
+	return &pluginpb.CleanupResponse{}, p.err
+}
+
+func (p *fakePlugin) Status(ctx context.Context, req *pluginpb.StatusRequest) (*pluginpb.StatusResponse, error) {
+	p.record("Status", req.GetExperiment())
+	return &pluginpb.StatusResponse{Applied: p.applied, Message: "restarting storage node"}, p.err
+}
+
+// servePlugin serves a plugin in memory and returns an injector calling it
+func servePlugin(t *testing.T, p *fakePlugin) *Injector {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	pluginpb.RegisterInjectorServer(server, p)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///plugin",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect to plugin: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return NewInjector(conn, 5*time.Second, chaos.Dependencies{})
+}
+
+func TestInjector(t *testing.T) {
+	experiment := &chaosv1alpha1.Havock8sExperiment{
+		ObjectMeta: metav1.ObjectMeta{Name: "storage-chaos", Namespace: "default", UID: "1234"},
+		Spec: chaosv1alpha1.Havock8sExperimentSpec{
+			ChaosType:  "StorageNodeRestart",
+			Duration:   "5m",
+			Intensity:  0.5,
+			Parameters: map[string]string{"node": "2"},
+		},
+		Status: chaosv1alpha1.Havock8sExperimentStatus{
+			TargetResources: []chaosv1alpha1.TargetResourceStatus{
+				{Kind: "Pod", Name: "storage-0", Namespace: "default", Status: "Targeted"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		plugin      *fakePlugin
+		call        func(i *Injector) error
+		wantCall    string
+		wantErr     string
+		wantApplied bool
+	}{
+		{
+			name:     "inject",
+			plugin:   &fakePlugin{},
+			call:     func(i *Injector) error { return i.Inject(context.Background(), experiment) },
+			wantCall: "Inject",
+		},
+		{
+			name:     "inject fails",
+			plugin:   &fakePlugin{err: status.Error(codes.Internal, "node unreachable")},
+			call:     func(i *Injector) error { return i.Inject(context.Background(), experiment) },
+			wantCall: "Inject",
+			wantErr:  "plugin failed to inject StorageNodeRestart chaos: rpc error: code = Internal desc = node unreachable",
+		},
+		{
+			name:     "cleanup",
+			plugin:   &fakePlugin{},
+			call:     func(i *Injector) error { return i.Cleanup(context.Background(), experiment) },
+			wantCall: "Cleanup",
+		},
+		{
+			name:     "cleanup fails",
+			plugin:   &fakePlugin{err: status.Error(codes.Unavailable, "restarting")},
+			call:     func(i *Injector) error { return i.Cleanup(context.Background(), experiment) },
+			wantCall: "Cleanup",
+			wantErr:  "plugin failed to clean up StorageNodeRestart chaos",
+		},
+		{
+			name:     "valid parameters",
+			plugin:   &fakePlugin{},
+			call:     func(i *Injector) error { return i.ValidateParameters(experiment) },
+			wantCall: "Validate",
+		},
+		{
+			name:     "invalid parameters",
+			plugin:   &fakePlugin{validateError: "node must be below 2"},
+			call:     func(i *Injector) error { return i.ValidateParameters(experiment) },
+			wantCall: "Validate",
+			wantErr:  "node must be below 2",
+		},
+		{
+			name:   "applied",
+			plugin: &fakePlugin{applied: true},
+			call: func(i *Injector) error {
+				_, err := i.Acknowledged(context.Background(), experiment)
+				return err
+			},
+			wantCall:    "Status",
+			wantApplied: true,
+		},
+		{
+			name:   "not applied yet",
+			plugin: &fakePlugin{},
+			call: func(i *Injector) error {
+				_, err := i.Acknowledged(context.Background(), experiment)
+				return err
+			},
+			wantCall: "Status",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			injector := servePlugin(t, tt.plugin)
+
+			err := tt.call(injector)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Error = %v, want it to contain %q", err, tt.wantErr)
+			}
+
+			if len(tt.plugin.calls) != 1 || tt.plugin.calls[0] != tt.wantCall {
+				t.Fatalf("Plugin calls = %v, want [%s]", tt.plugin.calls, tt.wantCall)
+			}
+			got := tt.plugin.experiments[0]
+			if got.GetUid() != "1234" || got.GetChaosType() != "StorageNodeRestart" || got.GetParameters()["node"] != "2" {
+				t.Errorf("Plugin got experiment %v", got)
+			}
+			if len(got.GetTargets()) != 1 || got.GetTargets()[0].GetName() != "storage-0" {
+				t.Errorf("Plugin got targets %v, want storage-0", got.GetTargets())
+			}
+
+			if tt.wantCall == "Status" {
+				applied, _ := injector.Acknowledged(context.Background(), experiment)
+				if applied != tt.wantApplied {
+					t.Errorf("Acknowledged() = %v, want %v", applied, tt.wantApplied)
+				}
+			}
+		})
+	}
+}
+
+func TestDial(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    chaosv1alpha1.ChaosPluginSpec
+		wantErr bool
+	}{
+		{
+			name: "plaintext",
+			spec: chaosv1alpha1.ChaosPluginSpec{
+				Service: chaosv1alpha1.PluginServiceReference{Name: "storage-plugin", Namespace: "chaos", Port: 9000},
+			},
+		},
+		{
+			name: "invalid CA bundle",
+			spec: chaosv1alpha1.ChaosPluginSpec{
+				Service:  chaosv1alpha1.PluginServiceReference{Name: "storage-plugin", Namespace: "chaos", Port: 9000},
+				CABundle: "not a certificate",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := Dial(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Dial() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			if want := "dns:///storage-plugin.chaos.svc:9000"; conn.Target() != want {
+				t.Errorf("Target = %q, want %q", conn.Target(), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+// The protocol between havock8s and out-of-process chaos injectors. A plugin
+// serves the Injector service behind a Kubernetes Service and is registered
+// with a ChaosPlugin resource naming the chaos types it injects.
+//
+// Regenerate the Go code with `make proto` after changing this file.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: pkg/plugin/pluginpb/injector.proto
+
+package pluginpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Injector_Validate_FullMethodName = "/havock8s.plugin.v1.Injector/Validate"
+	Injector_Inject_FullMethodName   = "/havock8s.plugin.v1.Injector/Inject"
+	Injector_Cleanup_FullMethodName  = "/havock8s.plugin.v1.Injector/Cleanup"
+	Injector_Status_FullMethodName   = "/havock8s.plugin.v1.Injector/Status"
+)
+
+// InjectorClient is the client API for Injector service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Injector mirrors the injector interface of the havock8s controller
+type InjectorClient interface {
+	// Validate checks an experiment's parameters before it is admitted
+	Validate(ctx context.Context, in *ValidateRequest, opts ...grpc.CallOption) (*ValidateResponse, error)
+	// Inject applies chaos to the experiment's targets
+	Inject(ctx context.Context, in *InjectRequest, opts ...grpc.CallOption) (*InjectResponse, error)
+	// Cleanup removes the experiment's chaos. It is called again if it fails,
+	// and when an experiment is deleted, so it must be idempotent.
+	Cleanup(ctx context.Context, in *CleanupRequest, opts ...grpc.CallOption) (*CleanupResponse, error)
+	// Status reports whether injected chaos is in effect on every target
+	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+}
+
+type injectorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewInjectorClient(cc grpc.ClientConnInterface) InjectorClient {
+	return &injectorClient{cc}
+}
+
+func (c *injectorClient) Validate(ctx context.Context, in *ValidateRequest, opts ...grpc.CallOption) (*ValidateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ValidateResponse)
+	err := c.cc.Invoke(ctx, Injector_Validate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *injectorClient) Inject(ctx context.Context, in *InjectRequest, opts ...grpc.CallOption) (*InjectResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InjectResponse)
+	err := c.cc.Invoke(ctx, Injector_Inject_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *injectorClient) Cleanup(ctx context.Context, in *CleanupRequest, opts ...grpc.CallOption) (*CleanupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CleanupResponse)
+	err := c.cc.Invoke(ctx, Injector_Cleanup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *injectorClient) Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, Injector_Status_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// InjectorServer is the server API for Injector service.
+// All implementations must embed UnimplementedInjectorServer
+// for forward compatibility.
+//
+// Injector mirrors the injector interface of the havock8s controller
+type InjectorServer interface {
+	// Validate checks an experiment's parameters before it is admitted
+	Validate(context.Context, *ValidateRequest) (*ValidateResponse, error)
+	// Inject applies chaos to the experiment's targets
+	Inject(context.Context, *InjectRequest) (*InjectResponse, error)
+	// Cleanup removes the experiment's chaos. It is called again if it fails,
+	// and when an experiment is deleted, so it must be idempotent.
+	Cleanup(context.Context, *CleanupRequest) (*CleanupResponse, error)
+	// Status reports whether injected chaos is in effect on every target
+	Status(context.Context, *StatusRequest) (*StatusResponse, error)
+	mustEmbedUnimplementedInjectorServer()
+}
+
+// UnimplementedInjectorServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedInjectorServer struct{}
+
+func (UnimplementedInjectorServer) Validate(context.Context, *ValidateRequest) (*ValidateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Validate not implemented")
+}
+func (UnimplementedInjectorServer) Inject(context.Context, *InjectRequest) (*InjectResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Inject not implemented")
+}
+func (UnimplementedInjectorServer) Cleanup(context.Context, *CleanupRequest) (*CleanupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Cleanup not implemented")
+}
+func (UnimplementedInjectorServer) Status(context.Context, *StatusRequest) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (UnimplementedInjectorServer) mustEmbedUnimplementedInjectorServer() {}
+func (UnimplementedInjectorServer) testEmbeddedByValue()                  {}
+
+// UnsafeInjectorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to InjectorServer will
+// result in compilation errors.
+type UnsafeInjectorServer interface {
+	mustEmbedUnimplementedInjectorServer()
+}
+
+func RegisterInjectorServer(s grpc.ServiceRegistrar, srv InjectorServer) {
+	// If the following call pancis, it indicates UnimplementedInjectorServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Injector_ServiceDesc, srv)
+}
+
+func _Injector_Validate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InjectorServer).Validate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Injector_Validate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InjectorServer).Validate(ctx, req.(*ValidateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Injector_Inject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InjectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InjectorServer).Inject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Injector_Inject_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InjectorServer).Inject(ctx, req.(*InjectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Injector_Cleanup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CleanupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InjectorServer).Cleanup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Injector_Cleanup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InjectorServer).Cleanup(ctx, req.(*CleanupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Injector_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InjectorServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Injector_Status_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InjectorServer).Status(ctx, req.(*StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Injector_ServiceDesc is the grpc.ServiceDesc for Injector service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Injector_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "havock8s.plugin.v1.Injector",
+	HandlerType: (*InjectorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Validate",
+			Handler:    _Injector_Validate_Handler,
+		},
+		{
+			MethodName: "Inject",
+			Handler:    _Injector_Inject_Handler,
+		},
+		{
+			MethodName: "Cleanup",
+			Handler:    _Injector_Cleanup_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _Injector_Status_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/plugin/pluginpb/injector.proto",
+}
@@ -0,0 +1,165 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// mongoOpMsg is the opcode of OP_MSG, the message carrying commands
+const mongoOpMsg = 2013
+
+// OP_MSG flag bits
+const (
+	mongoChecksumPresent = 1 << 0
+	mongoMoreToCome      = 1 << 1
+)
+
+// mongoErrorCode is the code of injected errors, InternalError
+const mongoErrorCode = 1
+
+// mongoCategories maps MongoDB commands to operation categories
+var mongoCategories = map[string]string{
+	"find":              CategoryRead,
+	"aggregate":         CategoryRead,
+	"count":             CategoryRead,
+	"distinct":          CategoryRead,
+	"getMore":           CategoryRead,
+	"insert":            CategoryWrite,
+	"update":            CategoryWrite,
+	"delete":            CategoryWrite,
+	"findAndModify":     CategoryWrite,
+	"bulkWrite":         CategoryWrite,
+	"create":            CategoryWrite,
+	"drop":              CategoryWrite,
+	"createIndexes":     CategoryWrite,
+	"dropIndexes":       CategoryWrite,
+	"commitTransaction": CategoryCommit,
+}
+
+// mongoCodec frames the MongoDB wire protocol. OP_MSG commands are named by
+// the first key of their body document. Replies carry the id of the request
+// they answer, so failed commands are answered right away.
+type mongoCodec struct{}
+
+func (c *mongoCodec) readRequest(r *bufio.Reader) (*message, error) {
+	raw, err := readMongoMessage(r)
+	if err != nil {
+		return nil, err
+	}
+	msg := &message{raw: raw}
+	if int32(binary.LittleEndian.Uint32(raw[12:16])) == mongoOpMsg {
+		msg.operation = mongoCommand(raw)
+		msg.category = mongoCategories[msg.operation]
+	}
+	return msg, nil
+}
+
+func (c *mongoCodec) readResponse(r *bufio.Reader) ([]byte, error) {
+	return readMongoMessage(r)
+}
+
+func (c *mongoCodec) forward(msg *message) []byte {
+	return msg.raw
+}
+
+func (c *mongoCodec) fail(msg *message) ([]byte, []byte) {
+	if binary.LittleEndian.Uint32(msg.raw[16:20])&mongoMoreToCome != 0 {
+		// The client expects no reply
+		return nil, nil
+	}
+	return nil, mongoError(binary.LittleEndian.Uint32(msg.raw[4:8]))
+}
+
+func (c *mongoCodec) respond(frame []byte) []byte {
+	return frame
+}
+
+// readMongoMessage reads a message with its standard header
+func readMongoMessage(r *bufio.Reader) ([]byte, error) {
+	raw := make([]byte, 16)
+	if _, err := io.ReadFull(r, raw); err != nil {
+		return nil, err
+	}
+	length := binary.LittleEndian.Uint32(raw[0:4])
+	if length < 16 || length > maxMessageSize {
+		return nil, errMessageTooLarge
+	}
+	raw = append(raw, make([]byte, length-16)...)
+	if _, err := io.ReadFull(r, raw[16:]); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// mongoCommand returns the command of an OP_MSG, the first key of its body
+// section, or an empty string when the message is malformed
+func mongoCommand(raw []byte) string {
+	if len(raw) < 20 {
+		return ""
+	}
+	sections := raw[20:]
+	if binary.LittleEndian.Uint32(raw[16:20])&mongoChecksumPresent != 0 {
+		if len(sections) < 4 {
+			return ""
+		}
+		sections = sections[:len(sections)-4]
+	}
+
+	for len(sections) > 0 {
+		kind := sections[0]
+		sections = sections[1:]
+		if len(sections) < 4 {
+			return ""
+		}
+		size := int(binary.LittleEndian.Uint32(sections[0:4]))
+		if size < 5 || size > len(sections) {
+			return ""
+		}
+		if kind == 0 {
+			// The body document: int32 size, then the first element's
+			// type byte and NUL terminated key
+			key, _ := cString(sections[5:size])
+			return key
+		}
+		// A document sequence, skipped
+		sections = sections[size:]
+	}
+	return ""
+}
+
+// mongoError returns an OP_MSG reply failing the request with the given id
+func mongoError(requestID uint32) []byte {
+	var doc bytes.Buffer
+	doc.Write([]byte{0, 0, 0, 0})
+	doc.WriteByte(0x01)
+	doc.WriteString("ok\x00")
+	doc.Write(binary.LittleEndian.AppendUint64(nil, math.Float64bits(0)))
+	bsonString(&doc, "errmsg", "havock8s: injected error")
+	doc.WriteByte(0x10)
+	doc.WriteString("code\x00")
+	doc.Write(binary.LittleEndian.AppendUint32(nil, mongoErrorCode))
+	bsonString(&doc, "codeName", "InternalError")
+	doc.WriteByte(0)
+	body := doc.Bytes()
+	binary.LittleEndian.PutUint32(body[0:4], uint32(len(body)))
+
+	raw := make([]byte, 21, 21+len(body))
+	binary.LittleEndian.PutUint32(raw[0:4], uint32(len(raw)+len(body)))
+	binary.LittleEndian.PutUint32(raw[8:12], requestID)
+	binary.LittleEndian.PutUint32(raw[12:16], mongoOpMsg)
+	// Flags are zero and the only section is the body, of kind 0
+	return append(raw, body...)
+}
+
+// bsonString appends a string element to a BSON document
+func bsonString(doc *bytes.Buffer, key, value string) {
+	doc.WriteByte(0x02)
+	doc.WriteString(key)
+	doc.WriteByte(0)
+	doc.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(value)+1)))
+	doc.WriteString(value)
+	doc.WriteByte(0)
+}
@@ -0,0 +1,268 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+)
+
+// Request codes of the untyped messages a PostgreSQL client may open with
+const (
+	postgresSSLRequest    = 80877103
+	postgresGSSENCRequest = 80877104
+)
+
+// postgresSQLState is the SQLSTATE of injected errors, connection_failure
+const postgresSQLState = "08006"
+
+// postgresCategories maps SQL commands to operation categories
+var postgresCategories = map[string]string{
+	"SELECT":   CategoryRead,
+	"SHOW":     CategoryRead,
+	"EXPLAIN":  CategoryRead,
+	"WITH":     CategoryRead,
+	"TABLE":    CategoryRead,
+	"VALUES":   CategoryRead,
+	"FETCH":    CategoryRead,
+	"INSERT":   CategoryWrite,
+	"UPDATE":   CategoryWrite,
+	"DELETE":   CategoryWrite,
+	"MERGE":    CategoryWrite,
+	"COPY":     CategoryWrite,
+	"TRUNCATE": CategoryWrite,
+	"CREATE":   CategoryWrite,
+	"ALTER":    CategoryWrite,
+	"DROP":     CategoryWrite,
+	"COMMIT":   CategoryCommit,
+	"END":      CategoryCommit,
+}
+
+// postgresCodec frames the PostgreSQL frontend/backend protocol. Simple
+// queries are named by their first SQL keyword, and extended queries at
+// Execute by the statement their portal was bound to.
+//
+// Failed simple queries are replaced by an empty query and failed Executes
+// skip the rest of their batch up to Sync, so the database still answers
+// every Sync and the injected ErrorResponse lands in the right place in the
+// response stream.
+type postgresCodec struct {
+	// Owned by the request side
+	started    bool
+	discarding bool
+	failSync   bool
+	statements map[string]string
+	portals    map[string]string
+
+	// Guarded by the connection's lock. failed has one entry per Query or
+	// Sync sent to the database, in order, telling whether its batch failed.
+	failed []bool
+
+	// ready is set once the database finished the startup phase
+	ready bool
+}
+
+func (c *postgresCodec) readRequest(r *bufio.Reader) (*message, error) {
+	for {
+		if !c.started {
+			// Startup messages carry no type byte
+			raw, err := readPostgresMessage(r, false)
+			if err != nil {
+				return nil, err
+			}
+			if len(raw) >= 8 {
+				switch binary.BigEndian.Uint32(raw[4:8]) {
+				case postgresSSLRequest, postgresGSSENCRequest:
+					// Refuse encryption so the session stays readable.
+					// The client sends its startup message next.
+					return &message{raw: raw, reply: []byte{'N'}}, nil
+				}
+			}
+			c.started = true
+			return &message{raw: raw}, nil
+		}
+
+		raw, err := readPostgresMessage(r, true)
+		if err != nil {
+			return nil, err
+		}
+		body := raw[5:]
+
+		if c.discarding {
+			// The database never sees the rest of a failed batch
+			if raw[0] != 'S' {
+				continue
+			}
+			c.discarding = false
+			c.failSync = true
+			return &message{raw: raw}, nil
+		}
+
+		msg := &message{raw: raw}
+		switch raw[0] {
+		case 'Q':
+			query, _ := cString(body)
+			msg.operation = sqlCommand(query)
+		case 'P':
+			name, rest := cString(body)
+			query, _ := cString(rest)
+			c.statements[name] = sqlCommand(query)
+		case 'B':
+			portal, rest := cString(body)
+			statement, _ := cString(rest)
+			c.portals[portal] = c.statements[statement]
+		case 'E':
+			portal, _ := cString(body)
+			msg.operation = c.portals[portal]
+		case 'C':
+			// Close of a statement or portal
+			if len(body) > 1 {
+				name, _ := cString(body[1:])
+				if body[0] == 'S' {
+					delete(c.statements, name)
+				} else {
+					delete(c.portals, name)
+				}
+			}
+		}
+		msg.category = postgresCategories[msg.operation]
+		return msg, nil
+	}
+}
+
+func (c *postgresCodec) readResponse(r *bufio.Reader) ([]byte, error) {
+	return readPostgresMessage(r, true)
+}
+
+func (c *postgresCodec) forward(msg *message) []byte {
+	// Startup messages open with a zero length byte, never with Q or S
+	if msg.raw[0] == 'Q' || msg.raw[0] == 'S' {
+		c.failed = append(c.failed, c.failSync)
+		c.failSync = false
+	}
+	return msg.raw
+}
+
+func (c *postgresCodec) fail(msg *message) ([]byte, []byte) {
+	if msg.raw[0] == 'Q' {
+		c.failed = append(c.failed, true)
+		return postgresMessage('Q', []byte{0}), nil
+	}
+	// An Execute: skip to the end of the batch
+	c.discarding = true
+	return nil, nil
+}
+
+func (c *postgresCodec) respond(frame []byte) []byte {
+	if frame[0] != 'Z' {
+		if frame[0] == 'I' && len(c.failed) > 0 && c.failed[0] {
+			// The answer to the empty query replacing a failed one
+			return nil
+		}
+		return frame
+	}
+
+	// ReadyForQuery ends the startup phase, then each batch
+	if !c.ready {
+		c.ready = true
+		return frame
+	}
+	if len(c.failed) == 0 {
+		return frame
+	}
+	failed := c.failed[0]
+	c.failed = c.failed[1:]
+	if !failed {
+		return frame
+	}
+	return append(postgresError(), frame...)
+}
+
+// postgresError returns the ErrorResponse of an injected error
+func postgresError() []byte {
+	var fields bytes.Buffer
+	for _, field := range []struct {
+		code  byte
+		value string
+	}{
+		{'S', "ERROR"},
+		{'V', "ERROR"},
+		{'C', postgresSQLState},
+		{'M', "havock8s: injected error"},
+	} {
+		fields.WriteByte(field.code)
+		fields.WriteString(field.value)
+		fields.WriteByte(0)
+	}
+	fields.WriteByte(0)
+	return postgresMessage('E', fields.Bytes())
+}
+
+// postgresMessage frames a typed message
+func postgresMessage(kind byte, body []byte) []byte {
+	raw := make([]byte, 5, 5+len(body))
+	raw[0] = kind
+	binary.BigEndian.PutUint32(raw[1:5], uint32(4+len(body)))
+	return append(raw, body...)
+}
+
+// readPostgresMessage reads a message, with its type byte when typed
+func readPostgresMessage(r *bufio.Reader, typed bool) ([]byte, error) {
+	header := 4
+	if typed {
+		header = 5
+	}
+	raw := make([]byte, header)
+	if _, err := io.ReadFull(r, raw); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(raw[header-4:])
+	if length < 4 || length > maxMessageSize {
+		return nil, errMessageTooLarge
+	}
+	raw = append(raw, make([]byte, length-4)...)
+	if _, err := io.ReadFull(r, raw[header:]); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// cString splits a NUL terminated string off data
+func cString(data []byte) (string, []byte) {
+	end := bytes.IndexByte(data, 0)
+	if end < 0 {
+		return string(data), nil
+	}
+	return string(data[:end]), data[end+1:]
+}
+
+// sqlCommand returns the first keyword of a SQL statement in upper case,
+// skipping white space and comments
+func sqlCommand(query string) string {
+	for {
+		query = strings.TrimLeft(query, " \t\r\n(;")
+		switch {
+		case strings.HasPrefix(query, "--"):
+			end := strings.IndexByte(query, '\n')
+			if end < 0 {
+				return ""
+			}
+			query = query[end+1:]
+		case strings.HasPrefix(query, "/*"):
+			end := strings.Index(query, "*/")
+			if end < 0 {
+				return ""
+			}
+			query = query[end+2:]
+		default:
+			end := strings.IndexFunc(query, func(r rune) bool {
+				return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '_')
+			})
+			if end < 0 {
+				end = len(query)
+			}
+			return strings.ToUpper(query[:end])
+		}
+	}
+}
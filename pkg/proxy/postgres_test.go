@@ -0,0 +1,26 @@
+package proxy
+
+import "testing"
+
+func TestSQLCommand(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT 1", "SELECT"},
+		{"  commit;", "COMMIT"},
+		{"-- audit\ninsert into t values (1)", "INSERT"},
+		{"/* app=api */ UPDATE t SET a = 1", "UPDATE"},
+		{"(SELECT 1) UNION (SELECT 2)", "SELECT"},
+		{"-- only a comment", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := sqlCommand(tt.query); got != tt.want {
+				t.Errorf("sqlCommand(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
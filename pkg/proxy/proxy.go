@@ -0,0 +1,371 @@
+// Package proxy implements the protocol aware TCP proxy behind StateDelay
+// chaos. The node agent runs it inside a database pod's network namespace and
+// redirects the database port to it. The proxy frames the client's requests,
+// names the operation each one performs, and delays, drops or fails the
+// selected operations before they reach the database.
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Supported wire protocols
+const (
+	// ProtocolPostgres is the PostgreSQL frontend/backend protocol
+	ProtocolPostgres = "postgres"
+
+	// ProtocolMongoDB is the MongoDB wire protocol, whose OP_MSG commands are inspected
+	ProtocolMongoDB = "mongodb"
+
+	// ProtocolRedis is the Redis serialization protocol, RESP
+	ProtocolRedis = "redis"
+)
+
+// Actions applied to the selected operations
+const (
+	// ActionDelay holds the operation back before forwarding it
+	ActionDelay = "delay"
+
+	// ActionDrop closes the client's connection instead of forwarding the operation
+	ActionDrop = "drop"
+
+	// ActionError answers the operation with a protocol level error
+	// instead of forwarding it
+	ActionError = "error"
+)
+
+// Operation categories, which select operations across protocols
+const (
+	// CategoryRead selects operations reading data
+	CategoryRead = "read"
+
+	// CategoryWrite selects operations changing data or schema
+	CategoryWrite = "write"
+
+	// CategoryCommit selects transaction commits
+	CategoryCommit = "commit"
+
+	// CategoryAll selects every operation
+	CategoryAll = "all"
+)
+
+// DefaultPorts are the ports the databases listen on by default
+var DefaultPorts = map[string]int{
+	ProtocolPostgres: 5432,
+	ProtocolMongoDB:  27017,
+	ProtocolRedis:    6379,
+}
+
+// tlsHandshake is the first byte of a TLS connection. Encrypted traffic is
+// passed through untouched.
+const tlsHandshake = 0x16
+
+// Config configures the proxy
+type Config struct {
+	// Protocol is the wire protocol spoken by the database
+	Protocol string
+
+	// Upstream is the address of the database
+	Upstream string
+
+	// Operations lists the selected operations, by category or by command
+	// name, in lower case
+	Operations []string
+
+	// Action is applied to the selected operations
+	Action string
+
+	// Delay is how long delayed operations are held back
+	Delay time.Duration
+
+	// Percentage is the share of the selected operations the action applies to
+	Percentage int
+}
+
+// ParseOperations splits a comma separated list of operations
+func ParseOperations(value string) []string {
+	var operations []string
+	for _, operation := range strings.Split(value, ",") {
+		operation = strings.ToLower(strings.TrimSpace(operation))
+		if operation != "" {
+			operations = append(operations, operation)
+		}
+	}
+	return operations
+}
+
+// Validate checks the configuration
+func (c Config) Validate() error {
+	if _, ok := DefaultPorts[c.Protocol]; !ok {
+		return fmt.Errorf("unsupported protocol: %s", c.Protocol)
+	}
+	switch c.Action {
+	case ActionDelay:
+		if c.Delay <= 0 {
+			return fmt.Errorf("delay must be positive")
+		}
+	case ActionDrop, ActionError:
+	default:
+		return fmt.Errorf("unsupported action: %s", c.Action)
+	}
+	if len(c.Operations) == 0 {
+		return fmt.Errorf("at least one operation must be selected")
+	}
+	if c.Percentage < 1 || c.Percentage > 100 {
+		return fmt.Errorf("percentage must be between 1 and 100")
+	}
+	return nil
+}
+
+// message is a framed client request
+type message struct {
+	// raw holds the request as read from the client
+	raw []byte
+
+	// operation names the command the request performs, empty for requests
+	// that are not operations, like handshakes
+	operation string
+
+	// category is the category of the operation
+	category string
+
+	// reply is answered to the client by the proxy itself, the request is
+	// not forwarded when set
+	reply []byte
+}
+
+// codec frames and names the messages of one connection. readRequest is only
+// called by the goroutine reading the client and readResponse by the one
+// reading the database. forward, fail and respond are called with the
+// connection's lock held.
+type codec interface {
+	// readRequest reads the next client request
+	readRequest(r *bufio.Reader) (*message, error)
+
+	// readResponse reads the next database message
+	readResponse(r *bufio.Reader) ([]byte, error)
+
+	// forward records a request about to be sent to the database and
+	// returns the bytes to send
+	forward(msg *message) []byte
+
+	// fail records a request the proxy fails. It returns what to send to
+	// the database in its place and what to answer the client right away.
+	fail(msg *message) (upstream, reply []byte)
+
+	// respond returns what to send the client for a database message
+	respond(frame []byte) []byte
+}
+
+// newCodec returns a codec for a connection speaking the protocol
+func newCodec(protocol string) codec {
+	switch protocol {
+	case ProtocolPostgres:
+		return &postgresCodec{statements: map[string]string{}, portals: map[string]string{}}
+	case ProtocolMongoDB:
+		return &mongoCodec{}
+	default:
+		return &redisCodec{}
+	}
+}
+
+// Server proxies connections to the database, applying the configured
+// action to the selected operations
+type Server struct {
+	config Config
+
+	// random returns a random int in [0, n), rand.IntN when nil
+	random func(n int) int
+}
+
+// NewServer creates a proxy server for a validated configuration
+func NewServer(config Config) (*Server, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &Server{config: config}, nil
+}
+
+// Serve accepts connections until the context is done
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go s.handle(ctx, conn)
+	}
+}
+
+// handle proxies one client connection
+func (s *Server) handle(ctx context.Context, client net.Conn) {
+	defer client.Close()
+
+	var dialer net.Dialer
+	upstream, err := dialer.DialContext(ctx, "tcp", s.config.Upstream)
+	if err != nil {
+		return
+	}
+	defer upstream.Close()
+
+	clientReader := bufio.NewReader(client)
+	first, err := clientReader.Peek(1)
+	if err != nil {
+		return
+	}
+	if first[0] == tlsHandshake {
+		// Encrypted traffic cannot be inspected, pass it through
+		go func() {
+			_, _ = io.Copy(client, upstream)
+			client.Close()
+		}()
+		_, _ = io.Copy(upstream, clientReader)
+		return
+	}
+
+	c := &connection{
+		server:   s,
+		codec:    newCodec(s.config.Protocol),
+		client:   client,
+		upstream: upstream,
+	}
+	go c.relayResponses()
+	c.relayRequests(ctx, clientReader)
+}
+
+// selected reports whether the action applies to a request
+func (s *Server) selected(msg *message) bool {
+	if msg.operation == "" {
+		return false
+	}
+	operation := strings.ToLower(msg.operation)
+	if !slices.ContainsFunc(s.config.Operations, func(o string) bool {
+		return o == CategoryAll || o == operation || o == msg.category
+	}) {
+		return false
+	}
+	return s.randomInt(100) < s.config.Percentage
+}
+
+// randomInt returns a random int in [0, n)
+func (s *Server) randomInt(n int) int {
+	if s.random != nil {
+		return s.random(n)
+	}
+	return rand.IntN(n)
+}
+
+// connection is a proxied client connection
+type connection struct {
+	server   *Server
+	codec    codec
+	client   net.Conn
+	upstream net.Conn
+
+	// mu orders the codec's bookkeeping with the writes to the client
+	mu sync.Mutex
+}
+
+// relayRequests forwards the client's requests until either side closes
+func (c *connection) relayRequests(ctx context.Context, r *bufio.Reader) {
+	for {
+		msg, err := c.codec.readRequest(r)
+		if err != nil {
+			return
+		}
+
+		var forward []byte
+		switch {
+		case msg.reply != nil:
+			if !c.writeClient(msg.reply) {
+				return
+			}
+			continue
+
+		case c.server.selected(msg):
+			switch c.server.config.Action {
+			case ActionDelay:
+				select {
+				case <-time.After(c.server.config.Delay):
+				case <-ctx.Done():
+					return
+				}
+			case ActionDrop:
+				return
+			case ActionError:
+				c.mu.Lock()
+				upstream, reply := c.codec.fail(msg)
+				_, err := c.client.Write(reply)
+				c.mu.Unlock()
+				if err != nil {
+					return
+				}
+				if _, err := c.upstream.Write(upstream); err != nil {
+					return
+				}
+				continue
+			}
+		}
+
+		// The database's answer may arrive as soon as the request is
+		// written, so the codec learns about it first
+		c.mu.Lock()
+		forward = c.codec.forward(msg)
+		c.mu.Unlock()
+		if _, err := c.upstream.Write(forward); err != nil {
+			return
+		}
+	}
+}
+
+// relayResponses forwards the database's messages until either side closes
+func (c *connection) relayResponses() {
+	defer c.client.Close()
+	defer c.upstream.Close()
+
+	r := bufio.NewReader(c.upstream)
+	for {
+		frame, err := c.codec.readResponse(r)
+		if err != nil {
+			return
+		}
+		c.mu.Lock()
+		_, err = c.client.Write(c.codec.respond(frame))
+		c.mu.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+// writeClient writes to the client, reporting whether it succeeded
+func (c *connection) writeClient(data []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err := c.client.Write(data)
+	return err == nil
+}
+
+// errMessageTooLarge is returned for messages beyond maxMessageSize
+var errMessageTooLarge = errors.New("message too large")
+
+// maxMessageSize bounds the messages the proxy buffers
+const maxMessageSize = 64 << 20
@@ -0,0 +1,324 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// startUpstream runs a fake database answering each request read by read
+// with the bytes answer returns
+func startUpstream(t *testing.T, read func(r *bufio.Reader) ([]byte, error), answer func(request []byte) []byte) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					request, err := read(r)
+					if err != nil {
+						return
+					}
+					if _, err := conn.Write(answer(request)); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// startProxy runs a proxy and returns a connection to it
+func startProxy(t *testing.T, config Config) net.Conn {
+	server, err := NewServer(config)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() { _ = server.Serve(ctx, listener) }()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to connect to the proxy: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	return conn
+}
+
+// redisCommand encodes a command as a RESP array
+func redisCommand(args ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		buf.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n")
+	}
+	return buf.Bytes()
+}
+
+func TestConfig_Validate(t *testing.T) {
+	valid := Config{
+		Protocol:   ProtocolPostgres,
+		Upstream:   "127.0.0.1:5432",
+		Operations: []string{CategoryCommit},
+		Action:     ActionDelay,
+		Delay:      time.Second,
+		Percentage: 100,
+	}
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(c *Config) {}},
+		{name: "errors need no delay", modify: func(c *Config) { c.Action, c.Delay = ActionError, 0 }},
+		{name: "unsupported protocol", modify: func(c *Config) { c.Protocol = "mysql" }, wantErr: true},
+		{name: "unsupported action", modify: func(c *Config) { c.Action = "corrupt" }, wantErr: true},
+		{name: "delay without duration", modify: func(c *Config) { c.Delay = 0 }, wantErr: true},
+		{name: "no operations", modify: func(c *Config) { c.Operations = ParseOperations(" , ") }, wantErr: true},
+		{name: "percentage out of range", modify: func(c *Config) { c.Percentage = 0 }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid
+			tt.modify(&config)
+			if err := config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServer_Redis(t *testing.T) {
+	upstream := startUpstream(t,
+		func(r *bufio.Reader) ([]byte, error) { return readRESP(r, nil) },
+		func(request []byte) []byte { return []byte("+OK\r\n") })
+
+	tests := []struct {
+		name      string
+		action    string
+		commands  [][]byte
+		want      string
+		wantDelay time.Duration
+		wantClose bool
+	}{
+		{
+			name:     "errors only the selected commands",
+			action:   ActionError,
+			commands: [][]byte{redisCommand("GET", "a"), redisCommand("SET", "a", "1"), redisCommand("GET", "a")},
+			want:     "+OK\r\n" + redisError + "+OK\r\n",
+		},
+		{
+			name:     "failed EXEC discards the transaction",
+			action:   ActionError,
+			commands: [][]byte{redisCommand("MULTI"), redisCommand("EXEC")},
+			want:     "+OK\r\n" + redisError,
+		},
+		{
+			name:      "delays the selected commands",
+			action:    ActionDelay,
+			commands:  [][]byte{redisCommand("SET", "a", "1")},
+			want:      "+OK\r\n",
+			wantDelay: 100 * time.Millisecond,
+		},
+		{
+			name:      "drops the connection",
+			action:    ActionDrop,
+			commands:  [][]byte{redisCommand("DEL", "a")},
+			wantClose: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := startProxy(t, Config{
+				Protocol:   ProtocolRedis,
+				Upstream:   upstream,
+				Operations: ParseOperations("write,commit"),
+				Action:     tt.action,
+				Delay:      100 * time.Millisecond,
+				Percentage: 100,
+			})
+
+			start := time.Now()
+			for _, command := range tt.commands {
+				if _, err := conn.Write(command); err != nil {
+					t.Fatalf("Failed to write: %v", err)
+				}
+			}
+			got := make([]byte, len(tt.want))
+			if _, err := io.ReadFull(conn, got); err != nil {
+				t.Fatalf("Failed to read replies: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Replies = %q, want %q", got, tt.want)
+			}
+			if elapsed := time.Since(start); elapsed < tt.wantDelay {
+				t.Errorf("Replies took %v, want at least %v", elapsed, tt.wantDelay)
+			}
+			if tt.wantClose {
+				if _, err := conn.Read(make([]byte, 1)); err != io.EOF {
+					t.Errorf("Read after drop error = %v, want EOF", err)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_Postgres(t *testing.T) {
+	started := false
+	upstream := startUpstream(t,
+		func(r *bufio.Reader) ([]byte, error) {
+			if !started {
+				started = true
+				return readPostgresMessage(r, false)
+			}
+			return readPostgresMessage(r, true)
+		},
+		func(request []byte) []byte {
+			ready := postgresMessage('Z', []byte{'I'})
+			switch {
+			case request[0] == 'Q' && len(request) == 6:
+				return append(postgresMessage('I', nil), ready...)
+			case request[0] == 'Q':
+				return append(postgresMessage('C', []byte("OK\x00")), ready...)
+			case request[0] == 'S':
+				return ready
+			case request[0] == 'P':
+				return postgresMessage('1', nil)
+			case request[0] == 'B':
+				return postgresMessage('2', nil)
+			case request[0] == 'E':
+				return postgresMessage('C', []byte("OK\x00"))
+			default:
+				// The startup message
+				return ready
+			}
+		})
+
+	conn := startProxy(t, Config{
+		Protocol:   ProtocolPostgres,
+		Upstream:   upstream,
+		Operations: ParseOperations("commit,insert"),
+		Action:     ActionError,
+		Percentage: 100,
+	})
+	r := bufio.NewReader(conn)
+	expect := func(want ...byte) {
+		t.Helper()
+		for _, kind := range want {
+			msg, err := readPostgresMessage(r, true)
+			if err != nil {
+				t.Fatalf("Failed to read message %c: %v", kind, err)
+			}
+			if msg[0] != kind {
+				t.Fatalf("Message = %c, want %c", msg[0], kind)
+			}
+		}
+	}
+
+	// Encryption is refused so the session can be inspected
+	sslRequest := binary.BigEndian.AppendUint32([]byte{0, 0, 0, 8}, postgresSSLRequest)
+	_, _ = conn.Write(sslRequest)
+	if answer, err := r.ReadByte(); err != nil || answer != 'N' {
+		t.Fatalf("SSLRequest answer = %c, %v, want N", answer, err)
+	}
+	startup := binary.BigEndian.AppendUint32([]byte{0, 0, 0, 9}, 196608)
+	_, _ = conn.Write(append(startup, 0))
+	expect('Z')
+
+	_, _ = conn.Write(postgresMessage('Q', []byte("BEGIN\x00")))
+	expect('C', 'Z')
+	_, _ = conn.Write(postgresMessage('Q', []byte("/* app */ commit\x00")))
+	expect('E', 'Z')
+
+	// An extended query failed at Execute skips the rest of its batch
+	var batch []byte
+	batch = append(batch, postgresMessage('P', []byte("s1\x00INSERT INTO t VALUES ($1)\x00\x00\x00"))...)
+	batch = append(batch, postgresMessage('B', []byte("\x00s1\x00\x00\x00\x00\x00\x00\x00"))...)
+	batch = append(batch, postgresMessage('E', []byte("\x00\x00\x00\x00\x00"))...)
+	batch = append(batch, postgresMessage('E', []byte("\x00\x00\x00\x00\x00"))...)
+	batch = append(batch, postgresMessage('S', nil)...)
+	_, _ = conn.Write(batch)
+	expect('1', '2', 'E', 'Z')
+
+	_, _ = conn.Write(postgresMessage('Q', []byte("SELECT 1\x00")))
+	expect('C', 'Z')
+}
+
+func TestServer_MongoDB(t *testing.T) {
+	upstream := startUpstream(t, readMongoMessage, func(request []byte) []byte {
+		reply := mongoError(binary.LittleEndian.Uint32(request[4:8]))
+		// Flip the reply to a success, ok: 1
+		copy(reply[29:37], binary.LittleEndian.AppendUint64(nil, 0x3ff0000000000000))
+		return reply
+	})
+
+	conn := startProxy(t, Config{
+		Protocol:   ProtocolMongoDB,
+		Upstream:   upstream,
+		Operations: ParseOperations("write"),
+		Action:     ActionError,
+		Percentage: 100,
+	})
+	r := bufio.NewReader(conn)
+
+	for _, tt := range []struct {
+		command string
+		wantOK  bool
+	}{
+		{"find", true},
+		{"insert", false},
+		{"hello", true},
+	} {
+		_, _ = conn.Write(newOpMsg(7, tt.command))
+		reply, err := readMongoMessage(r)
+		if err != nil {
+			t.Fatalf("Failed to read the reply to %s: %v", tt.command, err)
+		}
+		if responseTo := binary.LittleEndian.Uint32(reply[8:12]); responseTo != 7 {
+			t.Errorf("Reply to %s answers request %d, want 7", tt.command, responseTo)
+		}
+		if ok := reply[36] != 0; ok != tt.wantOK {
+			t.Errorf("Reply to %s ok = %v, want %v", tt.command, ok, tt.wantOK)
+		}
+	}
+}
+
+// newOpMsg returns an OP_MSG running a command
+func newOpMsg(requestID uint32, command string) []byte {
+	doc := []byte{0, 0, 0, 0, 0x10}
+	doc = append(doc, command+"\x00"...)
+	doc = binary.LittleEndian.AppendUint32(doc, 1)
+	doc = append(doc, 0)
+	binary.LittleEndian.PutUint32(doc[0:4], uint32(len(doc)))
+
+	raw := make([]byte, 21)
+	binary.LittleEndian.PutUint32(raw[0:4], uint32(len(raw)+len(doc)))
+	binary.LittleEndian.PutUint32(raw[4:8], requestID)
+	binary.LittleEndian.PutUint32(raw[12:16], mongoOpMsg)
+	return append(raw, doc...)
+}
@@ -0,0 +1,218 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// redisError is the reply to failed commands
+const redisError = "-ERR havock8s: injected error\r\n"
+
+// redisMaxElements bounds the elements of an aggregate RESP value
+const redisMaxElements = 1 << 20
+
+// redisCategories maps Redis commands to operation categories
+var redisCategories = map[string]string{}
+
+func init() {
+	for category, commands := range map[string]string{
+		CategoryRead: "GET MGET GETRANGE STRLEN EXISTS TTL PTTL TYPE KEYS SCAN " +
+			"HGET HMGET HGETALL HKEYS HVALS HLEN HEXISTS HSCAN " +
+			"LRANGE LLEN LINDEX SMEMBERS SISMEMBER SCARD SSCAN " +
+			"ZRANGE ZRANGEBYSCORE ZREVRANGE ZSCORE ZCARD ZRANK ZSCAN XRANGE XREAD XLEN",
+		CategoryWrite: "SET SETEX PSETEX SETNX MSET APPEND GETSET GETDEL " +
+			"INCR INCRBY INCRBYFLOAT DECR DECRBY DEL UNLINK EXPIRE PEXPIRE PERSIST RENAME " +
+			"HSET HSETNX HMSET HDEL HINCRBY LPUSH RPUSH LPOP RPOP LSET LREM LTRIM " +
+			"SADD SREM SPOP ZADD ZREM ZINCRBY XADD XDEL FLUSHDB FLUSHALL",
+		CategoryCommit: "EXEC",
+	} {
+		for _, command := range strings.Fields(commands) {
+			redisCategories[command] = category
+		}
+	}
+}
+
+// redisReply tells what answers a request the database was sent
+type redisReply int
+
+const (
+	// redisReplyPass forwards the database's reply
+	redisReplyPass redisReply = iota
+
+	// redisReplyReplace replaces the database's reply with an error
+	redisReplyReplace
+
+	// redisReplyInject answers a request the database never saw with an error
+	redisReplyInject
+)
+
+// redisCodec frames RESP. Commands are named by their first argument.
+// Replies come in request order, so errors are queued behind the replies
+// still due. A failed EXEC is replaced by DISCARD so the transaction does
+// not stay open.
+type redisCodec struct {
+	// pending has one entry per request awaiting its reply, in order. Its
+	// head is never redisReplyInject between calls.
+	pending []redisReply
+}
+
+func (c *redisCodec) readRequest(r *bufio.Reader) (*message, error) {
+	first, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []byte
+	var command string
+	if first[0] == '*' {
+		raw, err = readRESP(r, func(value []byte) {
+			if command == "" && value != nil {
+				command = string(value)
+			}
+		})
+	} else {
+		// An inline command, as typed into telnet
+		var line string
+		line, err = r.ReadString('\n')
+		raw = []byte(line)
+		if fields := strings.Fields(line); len(fields) > 0 {
+			command = fields[0]
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	command = strings.ToUpper(command)
+	return &message{raw: raw, operation: command, category: redisCategories[command]}, nil
+}
+
+func (c *redisCodec) readResponse(r *bufio.Reader) ([]byte, error) {
+	return readRESP(r, nil)
+}
+
+func (c *redisCodec) forward(msg *message) []byte {
+	c.pending = append(c.pending, redisReplyPass)
+	return msg.raw
+}
+
+func (c *redisCodec) fail(msg *message) ([]byte, []byte) {
+	if msg.operation == "EXEC" {
+		c.pending = append(c.pending, redisReplyReplace)
+		return []byte("*1\r\n$7\r\nDISCARD\r\n"), nil
+	}
+	if len(c.pending) == 0 {
+		return nil, []byte(redisError)
+	}
+	c.pending = append(c.pending, redisReplyInject)
+	return nil, nil
+}
+
+func (c *redisCodec) respond(frame []byte) []byte {
+	if frame[0] == '>' || len(c.pending) == 0 {
+		// Pushed messages answer no request
+		return frame
+	}
+
+	reply := frame
+	if c.pending[0] == redisReplyReplace {
+		reply = []byte(redisError)
+	}
+	c.pending = c.pending[1:]
+	for len(c.pending) > 0 && c.pending[0] == redisReplyInject {
+		reply = append(reply, redisError...)
+		c.pending = c.pending[1:]
+	}
+	return reply
+}
+
+// readRESP reads one RESP2 or RESP3 value, calling bulk for every bulk
+// string it contains
+func readRESP(r *bufio.Reader, bulk func(value []byte)) ([]byte, error) {
+	line, err := readRESPLine(r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch line[0] {
+	case '+', '-', ':', '_', ',', '#', '(':
+		return line, nil
+
+	case '$', '!', '=':
+		size, err := respLength(line)
+		if err != nil || size < 0 {
+			return line, err
+		}
+		value := make([]byte, size+2)
+		if _, err := io.ReadFull(r, value); err != nil {
+			return nil, err
+		}
+		if bulk != nil {
+			bulk(value[:size])
+		}
+		return append(line, value...), nil
+
+	case '*', '~', '>', '%', '|':
+		count, err := respLength(line)
+		if err != nil || count < 0 {
+			return line, err
+		}
+		if line[0] == '%' || line[0] == '|' {
+			// Maps and attributes hold key value pairs
+			count *= 2
+		}
+		if count > redisMaxElements {
+			return nil, errMessageTooLarge
+		}
+		raw := line
+		for idx := 0; idx < count; idx++ {
+			element, err := readRESP(r, bulk)
+			if err != nil {
+				return nil, err
+			}
+			raw = append(raw, element...)
+		}
+		if line[0] == '|' {
+			// Attributes decorate the value that follows
+			value, err := readRESP(r, bulk)
+			if err != nil {
+				return nil, err
+			}
+			raw = append(raw, value...)
+		}
+		return raw, nil
+
+	default:
+		return nil, io.ErrUnexpectedEOF
+	}
+}
+
+// readRESPLine reads a CRLF terminated line
+func readRESPLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadSlice('\n')
+	if err != nil {
+		if err == bufio.ErrBufferFull {
+			return nil, errMessageTooLarge
+		}
+		return nil, err
+	}
+	if len(line) < 3 || !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return bytes.Clone(line), nil
+}
+
+// respLength parses the length of a bulk string or aggregate value
+func respLength(line []byte) (int, error) {
+	size, err := strconv.Atoi(string(line[1 : len(line)-2]))
+	if err != nil {
+		return 0, err
+	}
+	if size > maxMessageSize {
+		return 0, errMessageTooLarge
+	}
+	return size, nil
+}
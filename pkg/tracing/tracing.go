@@ -0,0 +1,122 @@
+// Package tracing sets up OpenTelemetry tracing for the havock8s controller.
+// Until Setup is called spans go to the no-op global tracer provider, so
+// instrumented code costs next to nothing when tracing is disabled.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// tracerName identifies the spans created by havock8s
+	tracerName = "github.com/havock8s/havock8s"
+
+	// serviceName is the service the controller's spans are reported under
+	serviceName = "havock8s-controller"
+)
+
+// Attributes recorded on havock8s spans
+const (
+	ExperimentUIDKey       = attribute.Key("havock8s.experiment.uid")
+	ExperimentNameKey      = attribute.Key("havock8s.experiment.name")
+	ExperimentNamespaceKey = attribute.Key("havock8s.experiment.namespace")
+	ChaosTypeKey           = attribute.Key("havock8s.chaos_type")
+	PhaseKey               = attribute.Key("havock8s.phase")
+	SafetyCheckKey         = attribute.Key("havock8s.safety_check")
+	MetricQueryKey         = attribute.Key("havock8s.metric_query")
+)
+
+// Options configures the OTLP exporter
+type Options struct {
+	// Endpoint is the host:port of the OTLP gRPC collector. When empty the
+	// OTEL_EXPORTER_OTLP_ENDPOINT environment variable or localhost:4317 is used.
+	Endpoint string
+
+	// Insecure disables TLS towards the collector
+	Insecure bool
+}
+
+// Setup installs a tracer provider exporting spans over OTLP gRPC as the
+// global tracer provider. The returned function flushes and stops it.
+func Setup(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	var exporterOpts []otlptracegrpc.Option
+	if opts.Endpoint != "" {
+		exporterOpts = append(exporterOpts, otlptracegrpc.WithEndpoint(opts.Endpoint))
+	}
+	if opts.Insecure {
+		exporterOpts = append(exporterOpts, otlptracegrpc.WithInsecure())
+	}
+
+	exporter, err := otlptracegrpc.New(ctx, exporterOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	provider := NewTracerProvider(sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+// NewTracerProvider creates a tracer provider describing the havock8s
+// controller. Tests pass a syncer to an in-memory exporter.
+func NewTracerProvider(opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	res := resource.NewSchemaless(semconv.ServiceName(serviceName))
+	return sdktrace.NewTracerProvider(append([]sdktrace.TracerProviderOption{sdktrace.WithResource(res)}, opts...)...)
+}
+
+// Tracer returns the tracer havock8s creates its spans with
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// ExperimentAttributes returns the attributes identifying an experiment
+func ExperimentAttributes(experiment *chaosv1alpha1.Havock8sExperiment) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		ExperimentUIDKey.String(string(experiment.UID)),
+		ExperimentNameKey.String(experiment.Name),
+		ExperimentNamespaceKey.String(experiment.Namespace),
+		ChaosTypeKey.String(experiment.Spec.ChaosType),
+	}
+}
+
+// StartExperimentSpan starts a span about an experiment
+func StartExperimentSpan(ctx context.Context, name string, experiment *chaosv1alpha1.Havock8sExperiment, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(append(ExperimentAttributes(experiment), attrs...)...))
+}
+
+// InjectHTTPHeaders adds the span context of ctx to outgoing request headers,
+// so traces of the probed application continue the havock8s trace
+func InjectHTTPHeaders(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// EndSpan records err on the span, if any, and ends it
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// EndCheckSpan ends the span of a safety check, marking it failed with message
+func EndCheckSpan(span trace.Span, failed bool, message string) {
+	if failed {
+		span.SetStatus(codes.Error, message)
+	}
+	span.End()
+}
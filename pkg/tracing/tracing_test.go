@@ -0,0 +1,138 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// useInMemoryExporter installs a tracer provider recording spans in memory
+// for the duration of the test
+func useInMemoryExporter(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return exporter
+}
+
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) string {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value.AsString()
+		}
+	}
+	return ""
+}
+
+func TestStartExperimentSpan(t *testing.T) {
+	experiment := &chaosv1alpha1.Havock8sExperiment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "latency-experiment",
+			Namespace: "default",
+			UID:       "1234",
+		},
+		Spec: chaosv1alpha1.Havock8sExperimentSpec{
+			ChaosType: "NetworkLatency",
+		},
+	}
+
+	tests := []struct {
+		name        string
+		end         func(span trace.Span)
+		wantStatus  codes.Code
+		wantMessage string
+	}{
+		{
+			name:       "succeeded",
+			end:        func(span trace.Span) { EndSpan(span, nil) },
+			wantStatus: codes.Unset,
+		},
+		{
+			name:        "failed",
+			end:         func(span trace.Span) { EndSpan(span, errors.New("pod not found")) },
+			wantStatus:  codes.Error,
+			wantMessage: "pod not found",
+		},
+		{
+			name:       "check passed",
+			end:        func(span trace.Span) { EndCheckSpan(span, false, "") },
+			wantStatus: codes.Unset,
+		},
+		{
+			name:        "check failed",
+			end:         func(span trace.Span) { EndCheckSpan(span, true, "health check failed") },
+			wantStatus:  codes.Error,
+			wantMessage: "health check failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := useInMemoryExporter(t)
+
+			_, span := StartExperimentSpan(context.Background(), "Inject", experiment, SafetyCheckKey.String("http"))
+			tt.end(span)
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("Expected 1 span, got %d", len(spans))
+			}
+			got := spans[0]
+			if got.Name != "Inject" {
+				t.Errorf("Span name = %q, want Inject", got.Name)
+			}
+			for key, want := range map[attribute.Key]string{
+				ExperimentUIDKey:       "1234",
+				ExperimentNameKey:      "latency-experiment",
+				ExperimentNamespaceKey: "default",
+				ChaosTypeKey:           "NetworkLatency",
+				SafetyCheckKey:         "http",
+			} {
+				if value := spanAttribute(got, key); value != want {
+					t.Errorf("Attribute %s = %q, want %q", key, value, want)
+				}
+			}
+			if got.Status.Code != tt.wantStatus || got.Status.Description != tt.wantMessage {
+				t.Errorf("Status = %v %q, want %v %q", got.Status.Code, got.Status.Description, tt.wantStatus, tt.wantMessage)
+			}
+			if value, ok := got.Resource.Set().Value("service.name"); !ok || value.AsString() != serviceName {
+				t.Errorf("service.name = %q, want %q", value.AsString(), serviceName)
+			}
+		})
+	}
+}
+
+func TestInjectHTTPHeaders(t *testing.T) {
+	useInMemoryExporter(t)
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(previous)
+
+	ctx, span := Tracer().Start(context.Background(), "HealthCheck")
+	defer span.End()
+
+	header := http.Header{}
+	InjectHTTPHeaders(ctx, header)
+
+	traceparent := header.Get("traceparent")
+	if traceparent == "" {
+		t.Fatal("Expected a traceparent header")
+	}
+	if want := span.SpanContext().TraceID().String(); !strings.Contains(traceparent, want) {
+		t.Errorf("traceparent = %q, want trace ID %s", traceparent, want)
+	}
+}
@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PrometheusClient evaluates PromQL expressions through the Prometheus HTTP API
+type PrometheusClient struct {
+	// Address is the base URL of the Prometheus server, e.g. http://prometheus:9090
+	Address string
+
+	// HTTPClient performs the requests
+	HTTPClient *http.Client
+}
+
+// NewPrometheusClient creates a PrometheusClient for the given server
+func NewPrometheusClient(address string) *PrometheusClient {
+	return &PrometheusClient{
+		Address:    strings.TrimSuffix(address, "/"),
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// prometheusResponse is the envelope of every Prometheus API response
+type prometheusResponse struct {
+	Status    string          `json:"status"`
+	Error     string          `json:"error"`
+	ErrorType string          `json:"errorType"`
+	Data      *prometheusData `json:"data"`
+}
+
+type prometheusData struct {
+	ResultType string          `json:"resultType"`
+	Result     json.RawMessage `json:"result"`
+}
+
+// Query evaluates an instant query and returns one value per resulting series
+func (p *PrometheusClient) Query(ctx context.Context, query string, ts time.Time) ([]float64, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("time", formatPrometheusTime(ts))
+	return p.do(ctx, "/api/v1/query", params)
+}
+
+// QueryRange evaluates a range query and returns every sample of every resulting series
+func (p *PrometheusClient) QueryRange(ctx context.Context, query string, start, end time.Time, step time.Duration) ([]float64, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", formatPrometheusTime(start))
+	params.Set("end", formatPrometheusTime(end))
+	params.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+	return p.do(ctx, "/api/v1/query_range", params)
+}
+
+// do sends a query and extracts the sample values from the response
+func (p *PrometheusClient) do(ctx context.Context, path string, params url.Values) ([]float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.Address+path, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Prometheus: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body prometheusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode Prometheus response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if body.Status != "success" || body.Data == nil {
+		return nil, fmt.Errorf("prometheus query failed: %s: %s", body.ErrorType, body.Error)
+	}
+
+	return parsePrometheusResult(body.Data)
+}
+
+// parsePrometheusResult extracts the sample values of a scalar, vector or matrix result
+func parsePrometheusResult(data *prometheusData) ([]float64, error) {
+	var samples [][2]interface{}
+
+	switch data.ResultType {
+	case "scalar":
+		var sample [2]interface{}
+		if err := json.Unmarshal(data.Result, &sample); err != nil {
+			return nil, fmt.Errorf("invalid scalar result: %w", err)
+		}
+		samples = append(samples, sample)
+	case "vector":
+		var series []struct {
+			Value [2]interface{} `json:"value"`
+		}
+		if err := json.Unmarshal(data.Result, &series); err != nil {
+			return nil, fmt.Errorf("invalid vector result: %w", err)
+		}
+		for _, s := range series {
+			samples = append(samples, s.Value)
+		}
+	case "matrix":
+		var series []struct {
+			Values [][2]interface{} `json:"values"`
+		}
+		if err := json.Unmarshal(data.Result, &series); err != nil {
+			return nil, fmt.Errorf("invalid matrix result: %w", err)
+		}
+		for _, s := range series {
+			samples = append(samples, s.Values...)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported result type %q", data.ResultType)
+	}
+
+	values := make([]float64, 0, len(samples))
+	for _, sample := range samples {
+		// Sample values are encoded as strings to carry NaN and Inf
+		raw, ok := sample[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid sample value %v", sample[1])
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sample value %q: %w", raw, err)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// formatPrometheusTime formats a timestamp as Unix seconds
+func formatPrometheusTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/1e9, 'f', -1, 64)
+}
+
+// thresholdPattern matches thresholds such as "> 10", "<=0.5" or "100"
+var thresholdPattern = regexp.MustCompile(`^\s*(>=|<=|==|!=|>|<)?\s*(\S+)\s*$`)
+
+// Threshold compares metric values against a limit
+type Threshold struct {
+	// Operator is one of >, <, >=, <=, == or !=
+	Operator string
+
+	// Value is the limit
+	Value float64
+}
+
+// ParseThreshold parses a threshold. A bare number means "greater than".
+func ParseThreshold(threshold string) (Threshold, error) {
+	match := thresholdPattern.FindStringSubmatch(threshold)
+	if match == nil {
+		return Threshold{}, fmt.Errorf("invalid threshold %q", threshold)
+	}
+	value, err := strconv.ParseFloat(match[2], 64)
+	if err != nil {
+		return Threshold{}, fmt.Errorf("invalid threshold %q: %w", threshold, err)
+	}
+	operator := match[1]
+	if operator == "" {
+		operator = ">"
+	}
+	return Threshold{Operator: operator, Value: value}, nil
+}
+
+// Exceeded reports whether the value meets the threshold condition
+func (t Threshold) Exceeded(value float64) bool {
+	switch t.Operator {
+	case ">":
+		return value > t.Value
+	case "<":
+		return value < t.Value
+	case ">=":
+		return value >= t.Value
+	case "<=":
+		return value <= t.Value
+	case "==":
+		return value == t.Value
+	case "!=":
+		return value != t.Value
+	}
+	return false
+}
+
+// String formats the threshold
+func (t Threshold) String() string {
+	return fmt.Sprintf("%s %v", t.Operator, t.Value)
+}
@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/utils/prometheustest"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParseThreshold(t *testing.T) {
+	tests := []struct {
+		threshold string
+		want      Threshold
+		wantErr   bool
+	}{
+		{threshold: "100", want: Threshold{Operator: ">", Value: 100}},
+		{threshold: "> 10", want: Threshold{Operator: ">", Value: 10}},
+		{threshold: "<0.5", want: Threshold{Operator: "<", Value: 0.5}},
+		{threshold: ">= 3", want: Threshold{Operator: ">=", Value: 3}},
+		{threshold: "== 0", want: Threshold{Operator: "==", Value: 0}},
+		{threshold: "=> 3", wantErr: true},
+		{threshold: "> ten", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.threshold, func(t *testing.T) {
+			got, err := ParseThreshold(tt.threshold)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseThreshold() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseThreshold() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSafetyChecker_CheckMetricConditions_Prometheus(t *testing.T) {
+	prometheus := prometheustest.NewServer()
+	defer prometheus.Close()
+
+	prometheus.SetVector("errors", 12)
+	prometheus.SetVector("replicas", 1, 3)
+	prometheus.SetVector("sum(rate(errors[1m])) > 10", 12)
+	prometheus.SetRange("latency", 0.6, 0.7, 0.8)
+	prometheus.SetRange("cpu", 0.9, 0.4, 0.95)
+
+	tests := []struct {
+		name          string
+		condition     chaosv1alpha1.PauseConditionSpec
+		prometheusURL string
+		noPrometheus  bool
+		wantRollback  bool
+	}{
+		{
+			name:         "value above threshold",
+			condition:    chaosv1alpha1.PauseConditionSpec{Type: "metric", MetricQuery: "errors", Threshold: "> 10"},
+			wantRollback: true,
+		},
+		{
+			name:         "value below threshold",
+			condition:    chaosv1alpha1.PauseConditionSpec{Type: "metric", MetricQuery: "errors", Threshold: "> 20"},
+			wantRollback: false,
+		},
+		{
+			name:         "any series below threshold",
+			condition:    chaosv1alpha1.PauseConditionSpec{Type: "metric", MetricQuery: "replicas", Threshold: "< 2"},
+			wantRollback: true,
+		},
+		{
+			name:         "equality",
+			condition:    chaosv1alpha1.PauseConditionSpec{Type: "metric", MetricQuery: "errors", Threshold: "== 12"},
+			wantRollback: true,
+		},
+		{
+			name:         "comparison in the query",
+			condition:    chaosv1alpha1.PauseConditionSpec{Type: "metric", MetricQuery: "sum(rate(errors[1m])) > 10"},
+			wantRollback: true,
+		},
+		{
+			name:         "empty result",
+			condition:    chaosv1alpha1.PauseConditionSpec{Type: "metric", MetricQuery: "absent_metric"},
+			wantRollback: false,
+		},
+		{
+			name:         "threshold held for the whole window",
+			condition:    chaosv1alpha1.PauseConditionSpec{Type: "metric", MetricQuery: "latency", Threshold: ">= 0.5", Window: "5m"},
+			wantRollback: true,
+		},
+		{
+			name:         "threshold not held for the whole window",
+			condition:    chaosv1alpha1.PauseConditionSpec{Type: "metric", MetricQuery: "cpu", Threshold: "> 0.8", Window: "5m"},
+			wantRollback: false,
+		},
+		{
+			name:          "unreachable Prometheus",
+			condition:     chaosv1alpha1.PauseConditionSpec{Type: "metric", MetricQuery: "errors", Threshold: "> 10"},
+			prometheusURL: "http://127.0.0.1:1",
+			wantRollback:  true,
+		},
+		{
+			name:         "no Prometheus configured",
+			condition:    chaosv1alpha1.PauseConditionSpec{Type: "metric", MetricQuery: "errors", Threshold: "> 20"},
+			noPrometheus: true,
+			wantRollback: true,
+		},
+		{
+			name:         "invalid threshold",
+			condition:    chaosv1alpha1.PauseConditionSpec{Type: "metric", MetricQuery: "errors", Threshold: "about 10"},
+			wantRollback: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					Safety: &chaosv1alpha1.SafetySpec{
+						PauseConditions: []chaosv1alpha1.PauseConditionSpec{tt.condition},
+					},
+				},
+			}
+
+			s := NewSafetyChecker(fake.NewClientBuilder().Build())
+			address := prometheus.URL
+			if tt.prometheusURL != "" {
+				address = tt.prometheusURL
+			}
+			if tt.noPrometheus {
+				address = ""
+			}
+			s.SetPrometheusURL(address)
+			gotRollback, gotReason := s.CheckMetricConditions(context.Background(), experiment, logr.Discard())
+			if gotRollback != tt.wantRollback {
+				t.Errorf("SafetyChecker.CheckMetricConditions() rollback = %v (%s), want %v", gotRollback, gotReason, tt.wantRollback)
+			}
+		})
+	}
+}
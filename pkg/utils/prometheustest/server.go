@@ -0,0 +1,144 @@
+// Package prometheustest provides a Prometheus HTTP API stand-in for tests.
+package prometheustest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Server answers instant and range queries with canned values
+type Server struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	vectors map[string][]float64
+	ranges  map[string][]float64
+	queries []string
+}
+
+// NewServer starts a Prometheus stand-in. Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		vectors: make(map[string][]float64),
+		ranges:  make(map[string][]float64),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/query", s.handleQuery)
+	mux.HandleFunc("/api/v1/query_range", s.handleQueryRange)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// SetVector makes instant queries for query return one series per value
+func (s *Server) SetVector(query string, values ...float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vectors[query] = values
+}
+
+// SetRange makes range queries for query return a single series with the given samples
+func (s *Server) SetRange(query string, values ...float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ranges[query] = values
+}
+
+// Queries returns the queries received so far
+func (s *Server) Queries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.queries...)
+}
+
+func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
+	query, ok := s.record(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	values := s.vectors[query]
+	s.mu.Unlock()
+
+	now := float64(time.Now().Unix())
+	result := make([]map[string]interface{}, 0, len(values))
+	for i, value := range values {
+		result = append(result, map[string]interface{}{
+			"metric": map[string]string{"series": strconv.Itoa(i)},
+			"value":  []interface{}{now, formatValue(value)},
+		})
+	}
+	writeResult(w, "vector", result)
+}
+
+func (s *Server) handleQueryRange(w http.ResponseWriter, r *http.Request) {
+	query, ok := s.record(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	values := s.ranges[query]
+	s.mu.Unlock()
+
+	result := make([]map[string]interface{}, 0, 1)
+	if len(values) > 0 {
+		start := float64(time.Now().Unix() - int64(len(values)))
+		samples := make([][]interface{}, 0, len(values))
+		for i, value := range values {
+			samples = append(samples, []interface{}{start + float64(i), formatValue(value)})
+		}
+		result = append(result, map[string]interface{}{
+			"metric": map[string]string{},
+			"values": samples,
+		})
+	}
+	writeResult(w, "matrix", result)
+}
+
+// record parses and remembers the query of a request
+func (s *Server) record(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, err.Error())
+		return "", false
+	}
+	query := r.Form.Get("query")
+	if query == "" {
+		writeError(w, "query is required")
+		return "", false
+	}
+
+	s.mu.Lock()
+	s.queries = append(s.queries, query)
+	s.mu.Unlock()
+	return query, true
+}
+
+func writeResult(w http.ResponseWriter, resultType string, result interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"data": map[string]interface{}{
+			"resultType": resultType,
+			"result":     result,
+		},
+	})
+}
+
+func writeError(w http.ResponseWriter, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    "error",
+		"errorType": "bad_data",
+		"error":     msg,
+	})
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -2,22 +2,45 @@ package utils
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/tracing"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// Kinds of safety check that can trip an experiment
+const (
+	SafetyTripProtectedResource = "ProtectedResource"
+	SafetyTripHealthCheck       = "HealthCheck"
+	SafetyTripPauseCondition    = "PauseCondition"
+)
+
 // SafetyChecker provides safety mechanisms for chaos experiments
 type SafetyChecker struct {
 	client client.Client
+
+	// prometheusURL is the Prometheus server used for metric conditions
+	prometheusURL string
+
+	// podExecutor runs the commands of exec health checks
+	podExecutor PodExecutor
+
+	// tripped is the kind of check that failed the last CheckSafety or CheckRunningSafety
+	tripped string
 }
 
 // NewSafetyChecker creates a new SafetyChecker instance
@@ -25,75 +48,187 @@ func NewSafetyChecker(c client.Client) *SafetyChecker {
 	return &SafetyChecker{client: c}
 }
 
+// SetPrometheusURL sets the Prometheus server metric conditions are evaluated against
+func (s *SafetyChecker) SetPrometheusURL(address string) {
+	s.prometheusURL = address
+}
+
+// SetPodExecutor sets how exec health checks run commands in pods
+func (s *SafetyChecker) SetPodExecutor(executor PodExecutor) {
+	s.podExecutor = executor
+}
+
+// Tripped returns the kind of check that failed the last CheckSafety or
+// CheckRunningSafety, or "" if all checks passed
+func (s *SafetyChecker) Tripped() string {
+	return s.tripped
+}
+
 // CheckSafety performs all safety checks for an experiment
 func (s *SafetyChecker) CheckSafety(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, logger logr.Logger) (bool, string) {
+	ctx, span := tracing.StartExperimentSpan(ctx, "CheckSafety", experiment)
+	shouldRollback, reason := s.checkSafety(ctx, experiment, logger)
+	span.SetAttributes(tracing.SafetyCheckKey.String(s.tripped))
+	tracing.EndCheckSpan(span, shouldRollback, reason)
+	return shouldRollback, reason
+}
+
+// checkSafety runs the checks of CheckSafety
+func (s *SafetyChecker) checkSafety(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, logger logr.Logger) (bool, string) {
+	s.tripped = ""
+
 	// Check protected resources
 	if shouldRollback, reason := s.CheckProtectedResources(ctx, experiment, logger); shouldRollback {
+		s.tripped = SafetyTripProtectedResource
 		return true, reason
 	}
 
 	// Check health endpoints
 	if shouldRollback, reason := s.CheckHealthEndpoints(ctx, experiment, logger); shouldRollback {
+		s.tripped = SafetyTripHealthCheck
 		return true, reason
 	}
 
 	// Check metric conditions
 	if shouldRollback, reason := s.CheckMetricConditions(ctx, experiment, logger); shouldRollback {
+		s.tripped = SafetyTripPauseCondition
 		return true, reason
 	}
 
 	return false, ""
 }
 
-// CheckProtectedResources verifies that no protected resources will be affected
+// CheckProtectedResources verifies that no protected resources will be
+// affected. Every resolved target in Status.TargetResources is checked for
+// the protection annotation, so experiments selecting their targets by label
+// are covered as well as those naming a single resource.
 func (s *SafetyChecker) CheckProtectedResources(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, logger logr.Logger) (bool, string) {
 	// Check if target namespace is protected
 	if experiment.Spec.Target.Namespace == "kube-system" {
 		return true, "Target namespace kube-system is protected"
 	}
 
-	// Check if target pod has protection annotation
-	if experiment.Spec.Target.TargetType == "Pod" {
-		pod := &corev1.Pod{}
-		err := s.client.Get(ctx, types.NamespacedName{
-			Name:      experiment.Spec.Target.Name,
-			Namespace: experiment.Spec.Target.Namespace,
-		}, pod)
+	for _, target := range experiment.Status.TargetResources {
+		if target.Namespace == "kube-system" {
+			return true, "Target namespace kube-system is protected"
+		}
+
+		// Check if the target has the protection annotation
+		obj, err := newTargetObject(target.Kind)
 		if err != nil {
-			logger.Error(err, "Failed to get target pod")
-			return true, "Failed to verify pod protection status"
+			// Kinds that cannot be looked up carry no annotation we can read
+			continue
+		}
+		err = s.client.Get(ctx, types.NamespacedName{Name: target.Name, Namespace: target.Namespace}, obj)
+		if err != nil {
+			logger.Error(err, "Failed to get target", "kind", target.Kind, "namespace", target.Namespace, "name", target.Name)
+			return true, fmt.Sprintf("Failed to verify %s protection status", strings.ToLower(target.Kind))
 		}
 
-		if pod.Annotations["havock8s.io/protected"] == "true" {
-			return true, "Pod has protection annotation"
+		if obj.GetAnnotations()["havock8s.io/protected"] == "true" {
+			return true, fmt.Sprintf("%s has protection annotation", target.Kind)
 		}
 	}
 
 	return false, ""
 }
 
-// CheckHealthEndpoints verifies that health check endpoints are responding
+// CheckHealthEndpoints verifies that health check endpoints are responding.
+// The result of every check is recorded in the experiment status.
 func (s *SafetyChecker) CheckHealthEndpoints(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, logger logr.Logger) (bool, string) {
 	if experiment.Spec.Safety == nil || len(experiment.Spec.Safety.HealthChecks) == 0 {
 		return false, ""
 	}
 
-	for _, check := range experiment.Spec.Safety.HealthChecks {
-		switch check.Type {
-		case "httpGet":
-			if !s.checkHTTPEndpoint(check.Path, check.Port) {
-				return true, fmt.Sprintf("Health check failed for endpoint %s:%d", check.Path, check.Port)
-			}
-		case "tcpSocket":
-			if !s.checkTCPEndpoint(check.Port) {
-				return true, fmt.Sprintf("Health check failed for TCP port %d", check.Port)
+	shouldRollback, rollbackReason := false, ""
+	now := time.Now()
+	statuses := make([]chaosv1alpha1.HealthCheckStatus, 0, len(experiment.Spec.Safety.HealthChecks))
+	for i, check := range experiment.Spec.Safety.HealthChecks {
+		result := s.runHealthCheck(ctx, experiment, check)
+		status := newHealthCheckStatus(i, check, result, now)
+		if !result.healthy {
+			logger.Info("Health check failed", "index", i, "message", status.Message)
+			status.ConsecutiveFailures = 1
+			if !shouldRollback {
+				shouldRollback = true
+				rollbackReason = result.reason
 			}
 		}
+		statuses = append(statuses, status)
+	}
+	experiment.Status.HealthChecks = statuses
+
+	return shouldRollback, rollbackReason
+}
+
+// CheckRunningSafety performs the safety checks guarding an experiment whose
+// chaos is active. A health check only trips once it failed FailureThreshold
+// times in a row; the results are recorded in the experiment status.
+func (s *SafetyChecker) CheckRunningSafety(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, now time.Time, logger logr.Logger) (bool, string) {
+	ctx, span := tracing.StartExperimentSpan(ctx, "CheckRunningSafety", experiment)
+	shouldRollback, reason := s.checkRunningSafety(ctx, experiment, now, logger)
+	span.SetAttributes(tracing.SafetyCheckKey.String(s.tripped))
+	tracing.EndCheckSpan(span, shouldRollback, reason)
+	return shouldRollback, reason
+}
+
+// checkRunningSafety runs the checks of CheckRunningSafety
+func (s *SafetyChecker) checkRunningSafety(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, now time.Time, logger logr.Logger) (bool, string) {
+	s.tripped = ""
+
+	if shouldRollback, reason := s.updateHealthCheckStatuses(ctx, experiment, now, logger); shouldRollback {
+		s.tripped = SafetyTripHealthCheck
+		return true, reason
+	}
+
+	// Check metric conditions
+	if shouldRollback, reason := s.CheckMetricConditions(ctx, experiment, logger); shouldRollback {
+		s.tripped = SafetyTripPauseCondition
+		return true, reason
 	}
 
 	return false, ""
 }
 
+// updateHealthCheckStatuses runs every health check and tracks consecutive failures
+func (s *SafetyChecker) updateHealthCheckStatuses(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, now time.Time, logger logr.Logger) (bool, string) {
+	if experiment.Spec.Safety == nil || len(experiment.Spec.Safety.HealthChecks) == 0 {
+		experiment.Status.HealthChecks = nil
+		return false, ""
+	}
+
+	previous := make(map[int32]chaosv1alpha1.HealthCheckStatus, len(experiment.Status.HealthChecks))
+	for _, status := range experiment.Status.HealthChecks {
+		previous[status.Index] = status
+	}
+
+	shouldRollback, rollbackReason := false, ""
+	statuses := make([]chaosv1alpha1.HealthCheckStatus, 0, len(experiment.Spec.Safety.HealthChecks))
+	for i, check := range experiment.Spec.Safety.HealthChecks {
+		result := s.runHealthCheck(ctx, experiment, check)
+		status := newHealthCheckStatus(i, check, result, now)
+
+		if !result.healthy {
+			status.ConsecutiveFailures = previous[int32(i)].ConsecutiveFailures + 1
+
+			threshold := check.FailureThreshold
+			if threshold < 1 {
+				threshold = 1
+			}
+			logger.Info("Health check failed", "index", i, "failures", status.ConsecutiveFailures, "threshold", threshold, "message", status.Message)
+			if status.ConsecutiveFailures >= threshold && !shouldRollback {
+				shouldRollback = true
+				rollbackReason = fmt.Sprintf("%s (%d consecutive failures)", status.Message, status.ConsecutiveFailures)
+			}
+		}
+
+		statuses = append(statuses, status)
+	}
+	experiment.Status.HealthChecks = statuses
+
+	return shouldRollback, rollbackReason
+}
+
 // CheckMetricConditions verifies that metric-based conditions are met
 func (s *SafetyChecker) CheckMetricConditions(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, logger logr.Logger) (bool, string) {
 	if experiment.Spec.Safety == nil || len(experiment.Spec.Safety.PauseConditions) == 0 {
@@ -101,54 +236,373 @@ func (s *SafetyChecker) CheckMetricConditions(ctx context.Context, experiment *c
 	}
 
 	for _, condition := range experiment.Spec.Safety.PauseConditions {
-		if condition.Type == "metric" {
-			// Example: Check MongoDB connections
-			if condition.MetricQuery == "mongodb_connections" {
-				threshold, err := strconv.ParseFloat(condition.Threshold, 64)
-				if err != nil {
-					logger.Error(err, "Failed to parse threshold")
-					continue
-				}
-
-				currentValue := s.getMetricValue(condition.MetricQuery)
-				if currentValue > threshold {
-					return true, fmt.Sprintf("Metric %s exceeds threshold: %v > %v",
-						condition.MetricQuery, currentValue, threshold)
-				}
-			}
+		if condition.Type != "metric" {
+			continue
+		}
+
+		// Only the operator configures the server, experiments must not be
+		// able to make the controller send requests anywhere else. Without
+		// one the condition cannot be verified, so chaos must not continue.
+		if s.prometheusURL == "" {
+			logger.Info("No Prometheus server configured to evaluate metric condition", "query", condition.MetricQuery)
+			return true, fmt.Sprintf("Cannot evaluate metric condition %s: no Prometheus server is configured", condition.MetricQuery)
+		}
+
+		conditionCtx, span := tracing.StartExperimentSpan(ctx, "PauseCondition", experiment,
+			tracing.SafetyCheckKey.String(condition.Type), tracing.MetricQueryKey.String(condition.MetricQuery))
+		met, reason, err := s.evaluateMetricCondition(conditionCtx, NewPrometheusClient(s.prometheusURL), condition, time.Now())
+		if err != nil {
+			tracing.EndSpan(span, err)
+		} else {
+			tracing.EndCheckSpan(span, met, reason)
+		}
+		if err != nil {
+			// Chaos must not continue when its safety cannot be verified
+			logger.Error(err, "Failed to evaluate metric condition", "query", condition.MetricQuery)
+			return true, fmt.Sprintf("Failed to evaluate metric condition %s: %v", condition.MetricQuery, err)
+		}
+		if met {
+			return true, reason
 		}
 	}
 
 	return false, ""
 }
 
+// evaluateMetricCondition runs the condition's query and compares the result
+// against its threshold. With a window, every sample within the window must
+// meet the threshold.
+func (s *SafetyChecker) evaluateMetricCondition(ctx context.Context, prometheus *PrometheusClient, condition chaosv1alpha1.PauseConditionSpec, now time.Time) (bool, string, error) {
+	if condition.MetricQuery == "" {
+		return false, "", fmt.Errorf("metricQuery is required")
+	}
+
+	var threshold *Threshold
+	if condition.Threshold != "" {
+		t, err := ParseThreshold(condition.Threshold)
+		if err != nil {
+			return false, "", err
+		}
+		threshold = &t
+	}
+
+	var values []float64
+	var err error
+	if condition.Window != "" {
+		window, parseErr := time.ParseDuration(condition.Window)
+		if parseErr != nil || window <= 0 {
+			return false, "", fmt.Errorf("invalid window %q", condition.Window)
+		}
+		values, err = prometheus.QueryRange(ctx, condition.MetricQuery, now.Add(-window), now, windowStep(window))
+	} else {
+		values, err = prometheus.Query(ctx, condition.MetricQuery, now)
+	}
+	if err != nil {
+		return false, "", err
+	}
+
+	if len(values) == 0 {
+		return false, "", nil
+	}
+
+	// Without a threshold the query itself filters, e.g. "errors > 10"
+	if threshold == nil {
+		return true, fmt.Sprintf("Metric %s returned %v", condition.MetricQuery, values[len(values)-1]), nil
+	}
+
+	if condition.Window != "" {
+		for _, value := range values {
+			if !threshold.Exceeded(value) {
+				return false, "", nil
+			}
+		}
+		return true, fmt.Sprintf("Metric %s stayed %s for %s", condition.MetricQuery, threshold, condition.Window), nil
+	}
+
+	for _, value := range values {
+		if threshold.Exceeded(value) {
+			return true, fmt.Sprintf("Metric %s exceeds threshold: %v %s", condition.MetricQuery, value, threshold), nil
+		}
+	}
+	return false, "", nil
+}
+
+// windowStep picks the resolution of the range query for an evaluation window
+func windowStep(window time.Duration) time.Duration {
+	step := window / 10
+	if step < time.Second {
+		step = time.Second
+	}
+	if step > 15*time.Second {
+		step = 15 * time.Second
+	}
+	return step
+}
+
 // Helper functions
 
-func (s *SafetyChecker) checkHTTPEndpoint(path string, port int32) bool {
-	url := fmt.Sprintf("http://localhost:%d%s", port, path)
-	resp, err := http.Get(url)
+const (
+	// defaultHealthCheckTimeout is how long a probe may take unless the check sets TimeoutSeconds
+	defaultHealthCheckTimeout = 5 * time.Second
+
+	// maxHealthCheckBody limits how much of a response body is matched against ExpectedBody
+	maxHealthCheckBody = 1 << 20
+)
+
+// healthCheckResult is the outcome of running a single health check
+type healthCheckResult struct {
+	healthy bool
+
+	// reason summarizes which check failed
+	reason string
+
+	// detail names the endpoint or pod that failed and why
+	detail string
+}
+
+// message combines the reason and detail of a failed check
+func (r healthCheckResult) message() string {
+	if r.detail == "" {
+		return r.reason
+	}
+	return r.reason + ": " + r.detail
+}
+
+// newHealthCheckStatus records the result of a health check
+func newHealthCheckStatus(index int, check chaosv1alpha1.HealthCheckSpec, result healthCheckResult, now time.Time) chaosv1alpha1.HealthCheckStatus {
+	status := chaosv1alpha1.HealthCheckStatus{
+		Index:         int32(index),
+		Type:          check.Type,
+		Healthy:       result.healthy,
+		LastProbeTime: &metav1.Time{Time: now},
+	}
+	if !result.healthy {
+		status.Message = result.message()
+	}
+	return status
+}
+
+// runHealthCheck probes a single health check. httpGet and tcpSocket checks
+// probe the Host or Service they name, or else every running pod of the
+// target; exec checks run their command in every running pod of the target.
+// The check fails if any probe fails.
+func (s *SafetyChecker) runHealthCheck(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, check chaosv1alpha1.HealthCheckSpec) healthCheckResult {
+	ctx, span := tracing.StartExperimentSpan(ctx, "HealthCheck", experiment, tracing.SafetyCheckKey.String(check.Type))
+	result := s.probeHealthCheck(ctx, experiment, check)
+	tracing.EndCheckSpan(span, !result.healthy, result.message())
+	return result
+}
+
+// probeHealthCheck runs the probes of runHealthCheck
+func (s *SafetyChecker) probeHealthCheck(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, check chaosv1alpha1.HealthCheckSpec) healthCheckResult {
+	timeout := defaultHealthCheckTimeout
+	if check.TimeoutSeconds > 0 {
+		timeout = time.Duration(check.TimeoutSeconds) * time.Second
+	}
+
+	var reason string
+	var err error
+	switch check.Type {
+	case "httpGet":
+		reason = fmt.Sprintf("Health check failed for endpoint %s:%d", check.Path, check.Port)
+		err = s.probeHosts(ctx, experiment, check, func(host string) error {
+			return checkHTTPEndpoint(ctx, check, host, timeout)
+		})
+	case "tcpSocket":
+		reason = fmt.Sprintf("Health check failed for TCP port %d", check.Port)
+		err = s.probeHosts(ctx, experiment, check, func(host string) error {
+			return checkTCPEndpoint(ctx, host, check.Port, timeout)
+		})
+	case "exec":
+		reason = fmt.Sprintf("Health check failed for command %q", strings.Join(check.Command, " "))
+		err = s.checkExec(ctx, experiment, check, timeout)
+	default:
+		return healthCheckResult{healthy: true}
+	}
+
+	if err != nil {
+		return healthCheckResult{reason: reason, detail: err.Error()}
+	}
+	return healthCheckResult{healthy: true}
+}
+
+// probeHosts runs probe against every host a network health check targets
+func (s *SafetyChecker) probeHosts(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, check chaosv1alpha1.HealthCheckSpec, probe func(host string) error) error {
+	hosts, err := s.healthCheckHosts(ctx, experiment, check)
 	if err != nil {
-		return false
+		return err
+	}
+	for _, host := range hosts {
+		if err := probe(host); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// healthCheckHosts returns the hosts a network health check probes
+func (s *SafetyChecker) healthCheckHosts(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, check chaosv1alpha1.HealthCheckSpec) ([]string, error) {
+	if check.Host != "" {
+		return []string{check.Host}, nil
+	}
+
+	if check.Service != "" {
+		_, namespace := targetScope(experiment)
+		service := &corev1.Service{}
+		if err := s.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: check.Service}, service); err != nil {
+			return nil, fmt.Errorf("failed to get service %s/%s: %w", namespace, check.Service, err)
+		}
+		if service.Spec.ClusterIP != "" && service.Spec.ClusterIP != corev1.ClusterIPNone {
+			return []string{service.Spec.ClusterIP}, nil
+		}
+		// Headless services only resolve through DNS
+		return []string{fmt.Sprintf("%s.%s.svc", service.Name, service.Namespace)}, nil
+	}
+
+	pods, err := s.runningTargetPods(ctx, experiment)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		hosts = append(hosts, pod.Status.PodIP)
+	}
+	return hosts, nil
+}
+
+// runningTargetPods returns the running pods of the experiment's target.
+// Pods that are starting or terminating, e.g. because chaos killed them, are
+// skipped.
+func (s *SafetyChecker) runningTargetPods(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment) ([]corev1.Pod, error) {
+	pods, err := WorkloadPods(ctx, s.client, experiment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find target pods: %w", err)
+	}
+
+	var running []corev1.Pod
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodRunning && pod.Status.PodIP != "" && pod.DeletionTimestamp.IsZero() {
+			running = append(running, pod)
+		}
+	}
+	if len(running) == 0 {
+		return nil, fmt.Errorf("no running target pods to probe")
+	}
+	return running, nil
+}
+
+// checkHTTPEndpoint sends a GET request to host and verifies the response
+func checkHTTPEndpoint(ctx context.Context, check chaosv1alpha1.HealthCheckSpec, host string, timeout time.Duration) error {
+	scheme := "http"
+	transport := &http.Transport{}
+	if check.Scheme == "HTTPS" {
+		scheme = "https"
+		tlsConfig := &tls.Config{
+			ServerName:         check.ServerName,
+			InsecureSkipVerify: check.InsecureSkipVerify,
+		}
+		if check.CABundle != "" {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM([]byte(check.CABundle)) {
+				return fmt.Errorf("caBundle contains no valid certificates")
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	defer transport.CloseIdleConnections()
+
+	url := fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(host, strconv.Itoa(int(check.Port))), check.Path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	tracing.InjectHTTPHeaders(ctx, req.Header)
+
+	httpClient := &http.Client{Transport: transport, Timeout: timeout}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("GET %s: %w", url, err)
 	}
 	defer resp.Body.Close()
-	return resp.StatusCode == http.StatusOK
+
+	if !expectedStatusCode(check.ExpectedStatusCodes, resp.StatusCode) {
+		return fmt.Errorf("GET %s returned status %d", url, resp.StatusCode)
+	}
+
+	if check.ExpectedBody != "" {
+		pattern, err := regexp.Compile(check.ExpectedBody)
+		if err != nil {
+			return fmt.Errorf("invalid expectedBody: %w", err)
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxHealthCheckBody))
+		if err != nil {
+			return fmt.Errorf("GET %s: failed to read body: %w", url, err)
+		}
+		if !pattern.Match(body) {
+			return fmt.Errorf("GET %s returned a body not matching %q", url, check.ExpectedBody)
+		}
+	}
+
+	return nil
+}
+
+// expectedStatusCode reports whether an HTTP status code counts as healthy.
+// Without expected codes, any code from 200 to 399 does, like kubelet probes.
+func expectedStatusCode(expected []int32, code int) bool {
+	if len(expected) == 0 {
+		return code >= http.StatusOK && code < http.StatusBadRequest
+	}
+	for _, c := range expected {
+		if int(c) == code {
+			return true
+		}
+	}
+	return false
 }
 
-func (s *SafetyChecker) checkTCPEndpoint(port int32) bool {
-	addr := fmt.Sprintf("localhost:%d", port)
-	conn, err := net.DialTimeout("tcp", addr, time.Second*5)
+// checkTCPEndpoint opens a TCP connection to host
+func checkTCPEndpoint(ctx context.Context, host string, port int32, timeout time.Duration) error {
+	addr := net.JoinHostPort(host, strconv.Itoa(int(port)))
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
 	if err != nil {
-		return false
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
 	}
 	defer conn.Close()
-	return true
+	return nil
 }
 
-func (s *SafetyChecker) getMetricValue(query string) float64 {
-	// This is a placeholder. In a real implementation, this would query
-	// a metrics system like Prometheus
-	return 0.0
-}
+// checkExec runs the check's command in every running target pod
+func (s *SafetyChecker) checkExec(ctx context.Context, experiment *chaosv1alpha1.Havock8sExperiment, check chaosv1alpha1.HealthCheckSpec, timeout time.Duration) error {
+	if len(check.Command) == 0 {
+		return fmt.Errorf("exec health checks require a command")
+	}
+	if s.podExecutor == nil {
+		return fmt.Errorf("exec health checks are not supported without a pod executor")
+	}
 
+	pods, err := s.runningTargetPods(ctx, experiment)
+	if err != nil {
+		return err
+	}
 
+	for i := range pods {
+		pod := &pods[i]
+		container := check.Container
+		if container == "" && len(pod.Spec.Containers) > 0 {
+			container = pod.Spec.Containers[0].Name
+		}
 
+		execCtx, cancel := context.WithTimeout(ctx, timeout)
+		output, err := s.podExecutor.Exec(execCtx, pod, container, check.Command)
+		cancel()
+		if err != nil {
+			if output != "" {
+				return fmt.Errorf("pod %s/%s: %w: %s", pod.Namespace, pod.Name, err, output)
+			}
+			return fmt.Errorf("pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+	}
+	return nil
+}
@@ -2,10 +2,25 @@ package utils
 
 import (
 	"context"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
 	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -17,7 +32,8 @@ func TestSafetyChecker_CheckProtectedResources(t *testing.T) {
 	tests := []struct {
 		name       string
 		experiment *chaosv1alpha1.Havock8sExperiment
-		pod        *corev1.Pod
+		pod          *corev1.Pod
+		statefulSet  *appsv1.StatefulSet
 		wantRollback bool
 		wantReason   string
 	}{
@@ -45,6 +61,11 @@ func TestSafetyChecker_CheckProtectedResources(t *testing.T) {
 						Namespace: "default",
 					},
 				},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{
+						{Kind: "Pod", Name: "test-pod", Namespace: "default"},
+					},
+				},
 			},
 			pod: &corev1.Pod{
 				ObjectMeta: metav1.ObjectMeta{
@@ -68,6 +89,35 @@ func TestSafetyChecker_CheckProtectedResources(t *testing.T) {
 						Namespace: "default",
 					},
 				},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{
+						{Kind: "Pod", Name: "test-pod", Namespace: "default"},
+					},
+				},
+			},
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-pod",
+					Namespace: "default",
+				},
+			},
+			wantRollback: false,
+			wantReason:   "",
+		},
+		{
+			name: "selector without a protected target",
+			experiment: &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					Target: chaosv1alpha1.TargetSpec{
+						TargetType: "Pod",
+						Selector:   &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+					},
+				},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{
+						{Kind: "Pod", Name: "test-pod", Namespace: "default"},
+					},
+				},
 			},
 			pod: &corev1.Pod{
 				ObjectMeta: metav1.ObjectMeta{
@@ -78,18 +128,87 @@ func TestSafetyChecker_CheckProtectedResources(t *testing.T) {
 			wantRollback: false,
 			wantReason:   "",
 		},
+		{
+			name: "selector resolving to a protected pod",
+			experiment: &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					Target: chaosv1alpha1.TargetSpec{
+						TargetType: "Pod",
+						Selector:   &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+					},
+				},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{
+						{Kind: "Pod", Name: "test-pod", Namespace: "default"},
+					},
+				},
+			},
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-pod",
+					Namespace:   "default",
+					Annotations: map[string]string{"havock8s.io/protected": "true"},
+				},
+			},
+			wantRollback: true,
+			wantReason:   "Pod has protection annotation",
+		},
+		{
+			name: "protected statefulset",
+			experiment: &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					Target: chaosv1alpha1.TargetSpec{TargetType: "StatefulSet", Name: "db"},
+				},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{
+						{Kind: "StatefulSet", Name: "db", Namespace: "default"},
+					},
+				},
+			},
+			statefulSet: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "db",
+					Namespace:   "default",
+					Annotations: map[string]string{"havock8s.io/protected": "true"},
+				},
+			},
+			wantRollback: true,
+			wantReason:   "StatefulSet has protection annotation",
+		},
+		{
+			name: "resolved target disappeared",
+			experiment: &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					Target: chaosv1alpha1.TargetSpec{
+						TargetType: "Pod",
+						Selector:   &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+					},
+				},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					TargetResources: []chaosv1alpha1.TargetResourceStatus{
+						{Kind: "Pod", Name: "test-pod", Namespace: "default"},
+					},
+				},
+			},
+			wantRollback: true,
+			wantReason:   "Failed to verify pod protection status",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			scheme := runtime.NewScheme()
 			_ = corev1.AddToScheme(scheme)
+			_ = appsv1.AddToScheme(scheme)
 			_ = chaosv1alpha1.AddToScheme(scheme)
 
 			objs := []client.Object{tt.experiment}
 			if tt.pod != nil {
 				objs = append(objs, tt.pod)
 			}
+			if tt.statefulSet != nil {
+				objs = append(objs, tt.statefulSet)
+			}
 
 			fakeClient := fake.NewClientBuilder().
 				WithScheme(scheme).
@@ -184,7 +303,7 @@ func TestSafetyChecker_CheckMetricConditions(t *testing.T) {
 			wantReason:   "",
 		},
 		{
-			name: "with metric conditions",
+			name: "with metric conditions but no Prometheus",
 			experiment: &chaosv1alpha1.Havock8sExperiment{
 				Spec: chaosv1alpha1.Havock8sExperimentSpec{
 					Safety: &chaosv1alpha1.SafetySpec{
@@ -198,8 +317,8 @@ func TestSafetyChecker_CheckMetricConditions(t *testing.T) {
 					},
 				},
 			},
-			wantRollback: false, // Since our mock always returns 0
-			wantReason:   "",
+			wantRollback: true,
+			wantReason:   "Cannot evaluate metric condition mongodb_connections: no Prometheus server is configured",
 		},
 	}
 
@@ -225,4 +344,385 @@ func TestSafetyChecker_CheckMetricConditions(t *testing.T) {
 			}
 		})
 	}
-} 
\ No newline at end of file
+} 
+func TestSafetyChecker_CheckRunningSafety(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+	healthyPort := serverPort(t, healthy)
+
+	// A closed server leaves a port nothing listens on
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	downPort := serverPort(t, down)
+	down.Close()
+
+	tests := []struct {
+		name         string
+		port         int32
+		threshold    int32
+		previous     []chaosv1alpha1.HealthCheckStatus
+		wantRollback bool
+		wantFailures int32
+	}{
+		{
+			name:         "healthy endpoint",
+			port:         healthyPort,
+			threshold:    2,
+			previous:     []chaosv1alpha1.HealthCheckStatus{{Index: 0, ConsecutiveFailures: 1}},
+			wantRollback: false,
+			wantFailures: 0,
+		},
+		{
+			name:         "failure below threshold",
+			port:         downPort,
+			threshold:    2,
+			wantRollback: false,
+			wantFailures: 1,
+		},
+		{
+			name:         "failure reaching threshold",
+			port:         downPort,
+			threshold:    2,
+			previous:     []chaosv1alpha1.HealthCheckStatus{{Index: 0, ConsecutiveFailures: 1}},
+			wantRollback: true,
+			wantFailures: 2,
+		},
+		{
+			name:         "default threshold trips on first failure",
+			port:         downPort,
+			wantRollback: true,
+			wantFailures: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					Safety: &chaosv1alpha1.SafetySpec{
+						HealthChecks: []chaosv1alpha1.HealthCheckSpec{
+							{
+								Type:             "httpGet",
+								Host:             "127.0.0.1",
+								Path:             "/health",
+								Port:             tt.port,
+								FailureThreshold: tt.threshold,
+							},
+						},
+					},
+				},
+				Status: chaosv1alpha1.Havock8sExperimentStatus{
+					HealthChecks: tt.previous,
+				},
+			}
+
+			s := NewSafetyChecker(fake.NewClientBuilder().Build())
+			gotRollback, gotReason := s.CheckRunningSafety(context.Background(), experiment, time.Now(), logr.Discard())
+
+			if gotRollback != tt.wantRollback {
+				t.Errorf("SafetyChecker.CheckRunningSafety() rollback = %v (%s), want %v", gotRollback, gotReason, tt.wantRollback)
+			}
+			wantTripped := ""
+			if tt.wantRollback {
+				wantTripped = SafetyTripHealthCheck
+			}
+			if s.Tripped() != wantTripped {
+				t.Errorf("SafetyChecker.Tripped() = %q, want %q", s.Tripped(), wantTripped)
+			}
+			if len(experiment.Status.HealthChecks) != 1 {
+				t.Fatalf("Expected one health check status, got %d", len(experiment.Status.HealthChecks))
+			}
+			status := experiment.Status.HealthChecks[0]
+			if status.ConsecutiveFailures != tt.wantFailures {
+				t.Errorf("ConsecutiveFailures = %d, want %d", status.ConsecutiveFailures, tt.wantFailures)
+			}
+			if status.Healthy != (tt.wantFailures == 0) {
+				t.Errorf("Healthy = %v, want %v", status.Healthy, tt.wantFailures == 0)
+			}
+		})
+	}
+}
+
+func serverPort(t *testing.T, server *httptest.Server) int32 {
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse server URL: %v", err)
+	}
+	port, err := strconv.ParseInt(u.Port(), 10, 32)
+	if err != nil {
+		t.Fatalf("Failed to parse port: %v", err)
+	}
+	return int32(port)
+}
+
+// fakePodExecutor records exec calls and fails for pods listed in failures
+type fakePodExecutor struct {
+	failures map[string]string
+	calls    []string
+}
+
+func (f *fakePodExecutor) Exec(ctx context.Context, pod *corev1.Pod, container string, command []string) (string, error) {
+	f.calls = append(f.calls, pod.Name+"/"+container+": "+strings.Join(command, " "))
+	if output, ok := f.failures[pod.Name]; ok {
+		return output, fmt.Errorf("command terminated with exit code 1")
+	}
+	return "", nil
+}
+
+func TestSafetyChecker_RunHealthCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			fmt.Fprint(w, `{"status":"ok"}`)
+		case "/slow":
+			time.Sleep(2 * time.Second)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	port := serverPort(t, server)
+
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer tlsServer.Close()
+	tlsPort := serverPort(t, tlsServer)
+	caBundle := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw}))
+
+	runningPod := func(name, ip string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{"app": "web"},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "web"}, {Name: "sidecar"}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning, PodIP: ip},
+		}
+	}
+	pendingPod := runningPod("web-2", "")
+	pendingPod.Status.Phase = corev1.PodPending
+
+	tests := []struct {
+		name        string
+		check       chaosv1alpha1.HealthCheckSpec
+		objects     []client.Object
+		failures    map[string]string
+		wantHealthy bool
+		wantMessage string
+		wantCalls   []string
+	}{
+		{
+			name:        "http probes target pod IPs",
+			check:       chaosv1alpha1.HealthCheckSpec{Type: "httpGet", Path: "/health", Port: port},
+			objects:     []client.Object{runningPod("web-0", "127.0.0.1"), pendingPod},
+			wantHealthy: true,
+		},
+		{
+			name:        "http fails on a pod that does not answer",
+			check:       chaosv1alpha1.HealthCheckSpec{Type: "httpGet", Path: "/health", Port: port},
+			objects:     []client.Object{runningPod("web-0", "127.0.0.1"), runningPod("web-1", "127.0.0.2")},
+			wantHealthy: false,
+			wantMessage: "127.0.0.2",
+		},
+		{
+			name:        "http without running target pods",
+			check:       chaosv1alpha1.HealthCheckSpec{Type: "httpGet", Path: "/health", Port: port},
+			objects:     []client.Object{pendingPod},
+			wantHealthy: false,
+			wantMessage: "no running target pods to probe",
+		},
+		{
+			name:  "http probes the service cluster IP",
+			check: chaosv1alpha1.HealthCheckSpec{Type: "httpGet", Service: "web", Path: "/health", Port: port},
+			objects: []client.Object{&corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec:       corev1.ServiceSpec{ClusterIP: "127.0.0.1"},
+			}},
+			wantHealthy: true,
+		},
+		{
+			name:        "http with missing service",
+			check:       chaosv1alpha1.HealthCheckSpec{Type: "httpGet", Service: "web", Path: "/health", Port: port},
+			wantHealthy: false,
+			wantMessage: "failed to get service default/web",
+		},
+		{
+			name:        "unexpected status code",
+			check:       chaosv1alpha1.HealthCheckSpec{Type: "httpGet", Host: "127.0.0.1", Path: "/broken", Port: port},
+			wantHealthy: false,
+			wantMessage: "returned status 503",
+		},
+		{
+			name: "expected status code",
+			check: chaosv1alpha1.HealthCheckSpec{
+				Type: "httpGet", Host: "127.0.0.1", Path: "/broken", Port: port,
+				ExpectedStatusCodes: []int32{503},
+			},
+			wantHealthy: true,
+		},
+		{
+			name: "body matches",
+			check: chaosv1alpha1.HealthCheckSpec{
+				Type: "httpGet", Host: "127.0.0.1", Path: "/health", Port: port,
+				ExpectedBody: `"status":\s*"ok"`,
+			},
+			wantHealthy: true,
+		},
+		{
+			name: "body does not match",
+			check: chaosv1alpha1.HealthCheckSpec{
+				Type: "httpGet", Host: "127.0.0.1", Path: "/health", Port: port,
+				ExpectedBody: "degraded",
+			},
+			wantHealthy: false,
+			wantMessage: "body not matching",
+		},
+		{
+			name: "timeout",
+			check: chaosv1alpha1.HealthCheckSpec{
+				Type: "httpGet", Host: "127.0.0.1", Path: "/slow", Port: port,
+				TimeoutSeconds: 1,
+			},
+			wantHealthy: false,
+			wantMessage: "Client.Timeout",
+		},
+		{
+			name: "https with CA bundle",
+			check: chaosv1alpha1.HealthCheckSpec{
+				Type: "httpGet", Host: "127.0.0.1", Port: tlsPort,
+				Scheme: "HTTPS", CABundle: caBundle,
+			},
+			wantHealthy: true,
+		},
+		{
+			name: "https with unknown CA",
+			check: chaosv1alpha1.HealthCheckSpec{
+				Type: "httpGet", Host: "127.0.0.1", Port: tlsPort,
+				Scheme: "HTTPS",
+			},
+			wantHealthy: false,
+			wantMessage: "certificate",
+		},
+		{
+			name: "https without verification",
+			check: chaosv1alpha1.HealthCheckSpec{
+				Type: "httpGet", Host: "127.0.0.1", Port: tlsPort,
+				Scheme: "HTTPS", InsecureSkipVerify: true,
+			},
+			wantHealthy: true,
+		},
+		{
+			name:        "tcp probes target pod IPs",
+			check:       chaosv1alpha1.HealthCheckSpec{Type: "tcpSocket", Port: port},
+			objects:     []client.Object{runningPod("web-0", "127.0.0.1")},
+			wantHealthy: true,
+		},
+		{
+			name:        "exec runs in every running pod",
+			check:       chaosv1alpha1.HealthCheckSpec{Type: "exec", Command: []string{"pg_isready"}},
+			objects:     []client.Object{runningPod("web-0", "10.0.0.1"), runningPod("web-1", "10.0.0.2"), pendingPod},
+			wantHealthy: true,
+			wantCalls:   []string{"web-0/web: pg_isready", "web-1/web: pg_isready"},
+		},
+		{
+			name:        "exec in a named container fails",
+			check:       chaosv1alpha1.HealthCheckSpec{Type: "exec", Command: []string{"pg_isready"}, Container: "sidecar"},
+			objects:     []client.Object{runningPod("web-0", "10.0.0.1")},
+			failures:    map[string]string{"web-0": "no response"},
+			wantHealthy: false,
+			wantMessage: "pod default/web-0: command terminated with exit code 1: no response",
+			wantCalls:   []string{"web-0/sidecar: pg_isready"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			_ = chaosv1alpha1.AddToScheme(scheme)
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(tt.objects...).
+				Build()
+
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web-chaos", Namespace: "default"},
+				Spec: chaosv1alpha1.Havock8sExperimentSpec{
+					Target: chaosv1alpha1.TargetSpec{
+						Selector:   &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+						TargetType: "Pod",
+					},
+				},
+			}
+
+			executor := &fakePodExecutor{failures: tt.failures}
+			s := NewSafetyChecker(fakeClient)
+			s.SetPodExecutor(executor)
+
+			result := s.runHealthCheck(context.Background(), experiment, tt.check)
+			if result.healthy != tt.wantHealthy {
+				t.Fatalf("runHealthCheck() healthy = %v (%s), want %v", result.healthy, result.message(), tt.wantHealthy)
+			}
+			if !strings.Contains(result.message(), tt.wantMessage) {
+				t.Errorf("runHealthCheck() message = %q, want it to contain %q", result.message(), tt.wantMessage)
+			}
+			if tt.wantCalls != nil && fmt.Sprint(executor.calls) != fmt.Sprint(tt.wantCalls) {
+				t.Errorf("Exec calls = %v, want %v", executor.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestSafetyChecker_RunHealthCheckTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previousProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(tracing.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(previousProvider)
+	previousPropagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(previousPropagator)
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	experiment := &chaosv1alpha1.Havock8sExperiment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-chaos", Namespace: "default", UID: "experiment-uid"},
+	}
+	check := chaosv1alpha1.HealthCheckSpec{Type: "httpGet", Host: "127.0.0.1", Path: "/health", Port: serverPort(t, server)}
+
+	s := NewSafetyChecker(fake.NewClientBuilder().Build())
+	if result := s.runHealthCheck(context.Background(), experiment, check); result.healthy {
+		t.Fatal("runHealthCheck() healthy = true, want false")
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "HealthCheck" {
+		t.Fatalf("Expected a single HealthCheck span, got %v", spans)
+	}
+	span := spans[0]
+	if span.Status.Code != codes.Error || !strings.Contains(span.Status.Description, "returned status 503") {
+		t.Errorf("Span status = %v %q, want an error about status 503", span.Status.Code, span.Status.Description)
+	}
+	if !strings.Contains(traceparent, span.SpanContext.TraceID().String()) {
+		t.Errorf("traceparent = %q, want trace ID %s", traceparent, span.SpanContext.TraceID())
+	}
+	var uid string
+	for _, attr := range span.Attributes {
+		if attr.Key == tracing.ExperimentUIDKey {
+			uid = attr.Value.AsString()
+		}
+	}
+	if uid != "experiment-uid" {
+		t.Errorf("Experiment UID = %q, want experiment-uid", uid)
+	}
+}
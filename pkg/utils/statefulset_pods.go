@@ -0,0 +1,257 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// roleProbeTimeout bounds each role probe run in a pod
+const roleProbeTimeout = 10 * time.Second
+
+// roleProbe is a built-in command reporting the role of a database pod
+type roleProbe struct {
+	command []string
+	// roles maps the last line of the command's output to a role
+	roles map[string]string
+}
+
+// roleProbes are the built-in role probes by PodRoleSpec.Probe
+var roleProbes = map[string]roleProbe{
+	chaosv1alpha1.RoleProbePostgres: {
+		command: []string{"sh", "-c", `psql -U "${POSTGRES_USER:-postgres}" -tAc "SELECT pg_is_in_recovery()"`},
+		roles: map[string]string{
+			"f": chaosv1alpha1.RolePrimary,
+			"t": chaosv1alpha1.RoleReplica,
+		},
+	},
+	chaosv1alpha1.RoleProbeMongoDB: {
+		// mongosh replaced the legacy mongo shell, images ship one or the other
+		command: []string{"sh", "-c", `if command -v mongosh >/dev/null; then mongosh --quiet --eval "rs.isMaster().ismaster"; else mongo --quiet --eval "rs.isMaster().ismaster"; fi`},
+		roles: map[string]string{
+			"true":  chaosv1alpha1.RolePrimary,
+			"false": chaosv1alpha1.RoleReplica,
+		},
+	},
+}
+
+// ValidateStatefulSetPods checks that a StatefulSet pod selection can be resolved
+func ValidateStatefulSetPods(spec chaosv1alpha1.StatefulSetPodsSpec) error {
+	for _, ordinal := range spec.Ordinals {
+		if ordinal < 0 {
+			return fmt.Errorf("ordinals must not be negative")
+		}
+	}
+	if spec.OrdinalRange != "" {
+		if _, _, err := parseOrdinalRange(spec.OrdinalRange); err != nil {
+			return err
+		}
+	}
+	switch spec.Ordinal {
+	case "", chaosv1alpha1.OrdinalHighest, chaosv1alpha1.OrdinalLowest:
+	default:
+		return fmt.Errorf("unsupported ordinal %q: must be highest or lowest", spec.Ordinal)
+	}
+
+	role := spec.Role
+	if role == nil {
+		return nil
+	}
+	if role.Role == "" {
+		return fmt.Errorf("role is required")
+	}
+
+	sources := 0
+	for _, source := range []string{role.Label, role.Annotation, role.Probe} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return fmt.Errorf("exactly one of label, annotation or probe must discover the role")
+	}
+
+	switch role.Probe {
+	case chaosv1alpha1.RoleProbePostgres, chaosv1alpha1.RoleProbeMongoDB:
+		if role.Role != chaosv1alpha1.RolePrimary && role.Role != chaosv1alpha1.RoleReplica {
+			return fmt.Errorf("the %s probe reports roles primary or replica, not %q", role.Probe, role.Role)
+		}
+	case "":
+	default:
+		return fmt.Errorf("unsupported role probe: %s", role.Probe)
+	}
+
+	return nil
+}
+
+// selectStatefulSetPods replaces StatefulSet candidates with the pods of each
+// StatefulSet matching the selection
+func selectStatefulSetPods(ctx context.Context, c client.Client, executor PodExecutor, candidates []chaosv1alpha1.TargetResourceStatus, spec chaosv1alpha1.StatefulSetPodsSpec) ([]chaosv1alpha1.TargetResourceStatus, error) {
+	if err := ValidateStatefulSetPods(spec); err != nil {
+		return nil, fmt.Errorf("invalid statefulSetPods: %w", err)
+	}
+	if spec.Role != nil && spec.Role.Probe != "" && executor == nil {
+		return nil, fmt.Errorf("role probes are not supported without a pod executor")
+	}
+
+	var selected []chaosv1alpha1.TargetResourceStatus
+	for _, candidate := range candidates {
+		pods, err := OwnedPods(ctx, c, "StatefulSet", candidate.Namespace, candidate.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		var matching []*corev1.Pod
+		ordinals := make(map[string]int)
+		for i := range pods {
+			pod := &pods[i]
+			if pod.DeletionTimestamp != nil {
+				continue
+			}
+			ordinal, ok := podOrdinal(candidate.Name, pod.Name)
+			if !ok || !ordinalSelected(ordinal, spec) {
+				continue
+			}
+			if spec.Role != nil {
+				role, err := podRole(ctx, executor, pod, *spec.Role)
+				if err != nil {
+					return nil, err
+				}
+				if role != spec.Role.Role {
+					continue
+				}
+			}
+			ordinals[pod.Name] = ordinal
+			matching = append(matching, pod)
+		}
+
+		sort.Slice(matching, func(i, j int) bool {
+			return ordinals[matching[i].Name] < ordinals[matching[j].Name]
+		})
+		switch {
+		case len(matching) == 0:
+		case spec.Ordinal == chaosv1alpha1.OrdinalLowest:
+			matching = matching[:1]
+		case spec.Ordinal == chaosv1alpha1.OrdinalHighest:
+			matching = matching[len(matching)-1:]
+		}
+
+		for _, pod := range matching {
+			selected = append(selected, chaosv1alpha1.TargetResourceStatus{
+				Kind:      "Pod",
+				Name:      pod.Name,
+				Namespace: pod.Namespace,
+				UID:       string(pod.UID),
+				Status:    "Targeted",
+			})
+		}
+	}
+
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].Name < selected[j].Name
+	})
+	return selected, nil
+}
+
+// podOrdinal returns the ordinal of a pod of the named StatefulSet, which
+// names its pods <statefulset>-<ordinal>
+func podOrdinal(statefulSet, pod string) (int, bool) {
+	suffix, found := strings.CutPrefix(pod, statefulSet+"-")
+	if !found {
+		return 0, false
+	}
+	ordinal, err := strconv.Atoi(suffix)
+	if err != nil || ordinal < 0 || strconv.Itoa(ordinal) != suffix {
+		return 0, false
+	}
+	return ordinal, true
+}
+
+// ordinalSelected reports whether an ordinal matches the selection's ordinal
+// list and range
+func ordinalSelected(ordinal int, spec chaosv1alpha1.StatefulSetPodsSpec) bool {
+	if len(spec.Ordinals) > 0 && !slices.Contains(spec.Ordinals, int32(ordinal)) {
+		return false
+	}
+	if spec.OrdinalRange != "" {
+		// Validated before
+		start, end, _ := parseOrdinalRange(spec.OrdinalRange)
+		if ordinal < start || ordinal > end {
+			return false
+		}
+	}
+	return true
+}
+
+// parseOrdinalRange parses an inclusive ordinal range such as "1-3"
+func parseOrdinalRange(value string) (start, end int, err error) {
+	first, last, found := strings.Cut(value, "-")
+	if !found {
+		return 0, 0, fmt.Errorf("invalid ordinal range %q: must look like 1-3", value)
+	}
+	start, err = strconv.Atoi(first)
+	if err != nil || start < 0 {
+		return 0, 0, fmt.Errorf("invalid ordinal range %q: must look like 1-3", value)
+	}
+	end, err = strconv.Atoi(last)
+	if err != nil || end < 0 {
+		return 0, 0, fmt.Errorf("invalid ordinal range %q: must look like 1-3", value)
+	}
+	if start > end {
+		return 0, 0, fmt.Errorf("invalid ordinal range %q: start is after end", value)
+	}
+	return start, end, nil
+}
+
+// podRole returns the role a pod plays according to spec. Pods that are not
+// running cannot be probed and play no role.
+func podRole(ctx context.Context, executor PodExecutor, pod *corev1.Pod, spec chaosv1alpha1.PodRoleSpec) (string, error) {
+	switch {
+	case spec.Label != "":
+		return pod.Labels[spec.Label], nil
+	case spec.Annotation != "":
+		return pod.Annotations[spec.Annotation], nil
+	}
+
+	if pod.Status.Phase != corev1.PodRunning {
+		return "", nil
+	}
+
+	probe := roleProbes[spec.Probe]
+	container := spec.Container
+	if container == "" && len(pod.Spec.Containers) > 0 {
+		container = pod.Spec.Containers[0].Name
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, roleProbeTimeout)
+	output, err := executor.Exec(execCtx, pod, container, probe.command)
+	cancel()
+	if err != nil {
+		if output != "" {
+			return "", fmt.Errorf("failed to probe the role of pod %s/%s: %w: %s", pod.Namespace, pod.Name, err, output)
+		}
+		return "", fmt.Errorf("failed to probe the role of pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+
+	result := lastLine(output)
+	role, ok := probe.roles[result]
+	if !ok {
+		return "", fmt.Errorf("unexpected %s probe output from pod %s/%s: %q", spec.Probe, pod.Namespace, pod.Name, result)
+	}
+	return role, nil
+}
+
+// lastLine returns the last non-empty line of a command's output, skipping
+// any banner or warning printed before the result
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,270 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// rolePodExecutor answers role probes with a fixed output per pod
+type rolePodExecutor struct {
+	outputs map[string]string
+}
+
+func (e *rolePodExecutor) Exec(ctx context.Context, pod *corev1.Pod, container string, command []string) (string, error) {
+	output, ok := e.outputs[pod.Name]
+	if !ok {
+		return "", fmt.Errorf("command terminated with exit code 2")
+	}
+	return output, nil
+}
+
+// statefulSetPod returns a running pod owned by the named StatefulSet
+func statefulSetPod(statefulSet string, ordinal int, role string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            fmt.Sprintf("%s-%d", statefulSet, ordinal),
+			Namespace:       "default",
+			Labels:          map[string]string{"app": "postgres", "spilo-role": role},
+			Annotations:     map[string]string{"example.com/role": role},
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "StatefulSet", Name: statefulSet}},
+		},
+		Spec:   corev1.PodSpec{Containers: []corev1.Container{{Name: "postgres"}}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func TestResolveTargets_StatefulSetPods(t *testing.T) {
+	objects := []client.Object{
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "default", Labels: map[string]string{"app": "postgres"}}},
+		statefulSetPod("postgres", 0, "replica"),
+		statefulSetPod("postgres", 1, "master"),
+		statefulSetPod("postgres", 2, "replica"),
+		statefulSetPod("postgres", 10, "replica"),
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "postgres-backup", Namespace: "default", Labels: map[string]string{"app": "postgres"}}},
+		statefulSetPod("postgres-backup", 0, "master"),
+	}
+	executor := &rolePodExecutor{outputs: map[string]string{
+		"postgres-0":  "t",
+		"postgres-1":  "f",
+		"postgres-2":  "t",
+		"postgres-10": "WARNING: no password\nt\n",
+	}}
+
+	tests := []struct {
+		name     string
+		pods     chaosv1alpha1.StatefulSetPodsSpec
+		selector bool
+		executor PodExecutor
+		mode     string
+		wantPods []string
+		wantErr  bool
+	}{
+		{
+			name:     "all pods",
+			wantPods: []string{"postgres-0", "postgres-1", "postgres-10", "postgres-2"},
+		},
+		{
+			name:     "ordinal list",
+			pods:     chaosv1alpha1.StatefulSetPodsSpec{Ordinals: []int32{0, 2, 5}},
+			wantPods: []string{"postgres-0", "postgres-2"},
+		},
+		{
+			name:     "ordinal range",
+			pods:     chaosv1alpha1.StatefulSetPodsSpec{OrdinalRange: "1-2"},
+			wantPods: []string{"postgres-1", "postgres-2"},
+		},
+		{
+			name:     "highest ordinal is compared numerically",
+			pods:     chaosv1alpha1.StatefulSetPodsSpec{Ordinal: chaosv1alpha1.OrdinalHighest},
+			wantPods: []string{"postgres-10"},
+		},
+		{
+			name:     "lowest ordinal within a range",
+			pods:     chaosv1alpha1.StatefulSetPodsSpec{OrdinalRange: "2-10", Ordinal: chaosv1alpha1.OrdinalLowest},
+			wantPods: []string{"postgres-2"},
+		},
+		{
+			name: "role from a label",
+			pods: chaosv1alpha1.StatefulSetPodsSpec{
+				Role: &chaosv1alpha1.PodRoleSpec{Role: "master", Label: "spilo-role"},
+			},
+			wantPods: []string{"postgres-1"},
+		},
+		{
+			name: "highest replica from an annotation",
+			pods: chaosv1alpha1.StatefulSetPodsSpec{
+				Ordinals: []int32{0, 1, 2},
+				Ordinal:  chaosv1alpha1.OrdinalHighest,
+				Role:     &chaosv1alpha1.PodRoleSpec{Role: "replica", Annotation: "example.com/role"},
+			},
+			wantPods: []string{"postgres-2"},
+		},
+		{
+			name: "primary from the postgres probe",
+			pods: chaosv1alpha1.StatefulSetPodsSpec{
+				Role: &chaosv1alpha1.PodRoleSpec{Role: chaosv1alpha1.RolePrimary, Probe: chaosv1alpha1.RoleProbePostgres},
+			},
+			executor: executor,
+			wantPods: []string{"postgres-1"},
+		},
+		{
+			name: "replicas from the postgres probe",
+			pods: chaosv1alpha1.StatefulSetPodsSpec{
+				Role: &chaosv1alpha1.PodRoleSpec{Role: chaosv1alpha1.RoleReplica, Probe: chaosv1alpha1.RoleProbePostgres},
+			},
+			executor: executor,
+			wantPods: []string{"postgres-0", "postgres-10", "postgres-2"},
+		},
+		{
+			name: "mode picks among the selected pods",
+			pods: chaosv1alpha1.StatefulSetPodsSpec{OrdinalRange: "0-2"},
+			mode: chaosv1alpha1.TargetModeOne,
+		},
+		{
+			name: "selected StatefulSets contribute their own pods",
+			pods: chaosv1alpha1.StatefulSetPodsSpec{
+				Ordinal: chaosv1alpha1.OrdinalLowest,
+			},
+			selector: true,
+			wantPods: []string{"postgres-0", "postgres-backup-0"},
+		},
+		{
+			name: "probe without an executor",
+			pods: chaosv1alpha1.StatefulSetPodsSpec{
+				Role: &chaosv1alpha1.PodRoleSpec{Role: chaosv1alpha1.RolePrimary, Probe: chaosv1alpha1.RoleProbePostgres},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unexpected probe output",
+			pods: chaosv1alpha1.StatefulSetPodsSpec{
+				Role: &chaosv1alpha1.PodRoleSpec{Role: chaosv1alpha1.RolePrimary, Probe: chaosv1alpha1.RoleProbeMongoDB},
+			},
+			executor: executor,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			_ = appsv1.AddToScheme(scheme)
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(objects...).
+				Build()
+
+			pods := tt.pods
+			target := chaosv1alpha1.TargetSpec{
+				TargetType:      "StatefulSet",
+				Name:            "postgres",
+				Mode:            tt.mode,
+				StatefulSetPods: &pods,
+			}
+			if tt.selector {
+				target.Name = ""
+				target.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "postgres"}}
+			}
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-experiment", Namespace: "default"},
+				Spec:       chaosv1alpha1.Havock8sExperimentSpec{Target: target},
+			}
+
+			got, err := ResolveTargets(context.Background(), fakeClient, tt.executor, experiment)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveTargets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			for _, target := range got {
+				if target.Kind != "Pod" {
+					t.Errorf("Target kind = %v, want Pod", target.Kind)
+				}
+			}
+			if tt.mode == chaosv1alpha1.TargetModeOne {
+				if len(got) != 1 || (got[0].Name != "postgres-0" && got[0].Name != "postgres-1" && got[0].Name != "postgres-2") {
+					t.Errorf("ResolveTargets() = %v, want one of postgres-0 to postgres-2", got)
+				}
+				return
+			}
+			var names []string
+			for _, target := range got {
+				names = append(names, target.Name)
+			}
+			if fmt.Sprint(names) != fmt.Sprint(tt.wantPods) {
+				t.Errorf("ResolveTargets() = %v, want %v", names, tt.wantPods)
+			}
+		})
+	}
+}
+
+func TestValidateStatefulSetPods(t *testing.T) {
+	tests := []struct {
+		name    string
+		pods    chaosv1alpha1.StatefulSetPodsSpec
+		wantErr bool
+	}{
+		{
+			name: "ordinals and range",
+			pods: chaosv1alpha1.StatefulSetPodsSpec{Ordinals: []int32{0, 1}, OrdinalRange: "0-3"},
+		},
+		{
+			name:    "reversed range",
+			pods:    chaosv1alpha1.StatefulSetPodsSpec{OrdinalRange: "3-1"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown ordinal",
+			pods:    chaosv1alpha1.StatefulSetPodsSpec{Ordinal: "middle"},
+			wantErr: true,
+		},
+		{
+			name:    "role without a source",
+			pods:    chaosv1alpha1.StatefulSetPodsSpec{Role: &chaosv1alpha1.PodRoleSpec{Role: "primary"}},
+			wantErr: true,
+		},
+		{
+			name: "role with two sources",
+			pods: chaosv1alpha1.StatefulSetPodsSpec{
+				Role: &chaosv1alpha1.PodRoleSpec{Role: "primary", Label: "role", Probe: "postgres"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "built-in probe with a custom role",
+			pods: chaosv1alpha1.StatefulSetPodsSpec{
+				Role: &chaosv1alpha1.PodRoleSpec{Role: "leader", Probe: "mongodb"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "custom exec probe",
+			pods: chaosv1alpha1.StatefulSetPodsSpec{
+				Role: &chaosv1alpha1.PodRoleSpec{Role: "leader", Probe: "exec"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateStatefulSetPods(tt.pods)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateStatefulSetPods() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
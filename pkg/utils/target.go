@@ -2,30 +2,472 @@ package utils
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 
 	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// CheckTargetExists verifies if the target resource exists
-func CheckTargetExists(ctx context.Context, c client.Client, target chaosv1alpha1.TargetSpec) (bool, error) {
-	switch target.TargetType {
-	case "Pod":
-		pod := &corev1.Pod{}
-		err := c.Get(ctx, types.NamespacedName{
-			Name:      target.Name,
-			Namespace: target.Namespace,
-		}, pod)
+// TargetsExist reports whether any of the resolved targets still exists.
+// Targets are matched by UID, so a pod recreated under the same name by its
+// controller does not count. Kinds that cannot be looked up are assumed to
+// exist.
+func TargetsExist(ctx context.Context, c client.Client, targets []chaosv1alpha1.TargetResourceStatus) (bool, error) {
+	for _, target := range targets {
+		obj, err := newTargetObject(target.Kind)
+		if err != nil {
+			return true, nil
+		}
+		err = c.Get(ctx, types.NamespacedName{Namespace: target.Namespace, Name: target.Name}, obj)
+		if err != nil {
+			if client.IgnoreNotFound(err) == nil {
+				continue
+			}
+			return false, fmt.Errorf("failed to get %s %s/%s: %w", target.Kind, target.Namespace, target.Name, err)
+		}
+		if target.UID == "" || string(obj.GetUID()) == target.UID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ResolveTargets finds the resources matching the experiment's TargetSpec and
+// picks victims among them according to Mode and Value. The returned list is
+// meant to be stored in Status.TargetResources so every injector works on the
+// same set of resources. StatefulSet targets narrowed down by StatefulSetPods
+// resolve to the selected pods; executor runs their role probes and may be
+// nil when none is configured.
+func ResolveTargets(ctx context.Context, c client.Client, executor PodExecutor, experiment *chaosv1alpha1.Havock8sExperiment) ([]chaosv1alpha1.TargetResourceStatus, error) {
+	target := experiment.Spec.Target
+	kind, namespace := targetScope(experiment)
+
+	candidates, err := listTargetCandidates(ctx, c, kind, namespace, target)
+	if err != nil {
+		return nil, err
+	}
+
+	if target.StatefulSetPods != nil {
+		if kind != "StatefulSet" {
+			return nil, fmt.Errorf("statefulSetPods requires targetType StatefulSet, not %s", kind)
+		}
+		candidates, err = selectStatefulSetPods(ctx, c, executor, candidates, *target.StatefulSetPods)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return SelectTargets(candidates, target.Mode, target.Value)
+}
+
+// WorkloadPods returns the pods of every resource matching the experiment's
+// TargetSpec, regardless of Mode. Workloads and PersistentVolumeClaims are
+// expanded into their pods.
+func WorkloadPods(ctx context.Context, c client.Client, experiment *chaosv1alpha1.Havock8sExperiment) ([]corev1.Pod, error) {
+	kind, namespace := targetScope(experiment)
+
+	candidates, err := listTargetCandidates(ctx, c, kind, namespace, experiment.Spec.Target)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	if kind == "Pod" {
+		var pods []corev1.Pod
+		for _, candidate := range candidates {
+			pod := &corev1.Pod{}
+			err := c.Get(ctx, types.NamespacedName{Namespace: candidate.Namespace, Name: candidate.Name}, pod)
+			if err != nil {
+				if client.IgnoreNotFound(err) == nil {
+					continue
+				}
+				return nil, fmt.Errorf("failed to get pod %s/%s: %w", candidate.Namespace, candidate.Name, err)
+			}
+			pods = append(pods, *pod)
+		}
+		return pods, nil
+	}
+
+	names := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		names = append(names, candidate.Name)
+	}
+
+	if kind == "PersistentVolumeClaim" {
+		return claimPods(ctx, c, namespace, names)
+	}
+
+	return ownedPods(ctx, c, kind, namespace, names)
+}
+
+// IsWorkloadKind reports whether targets of the kind are workloads whose pods
+// are found through owner references
+func IsWorkloadKind(kind string) bool {
+	switch kind {
+	case "StatefulSet", "Deployment", "ReplicaSet", "DaemonSet", "Job":
+		return true
+	}
+	return false
+}
+
+// OwnedPods returns the pods of a workload. Deployments own their pods
+// through ReplicaSets, the other workload kinds own them directly. Pods are
+// matched by owner reference, never by name, so StatefulSet "db" does not
+// claim the pods of StatefulSet "db-backup".
+func OwnedPods(ctx context.Context, c client.Client, kind, namespace, name string) ([]corev1.Pod, error) {
+	return ownedPods(ctx, c, kind, namespace, []string{name})
+}
+
+// ownedPods returns the pods owned by any of the named workloads of a kind
+func ownedPods(ctx context.Context, c client.Client, kind, namespace string, names []string) ([]corev1.Pod, error) {
+	if !IsWorkloadKind(kind) {
+		return nil, fmt.Errorf("unsupported workload kind: %s", kind)
+	}
+
+	// The owners of the pods, as "Kind/name"
+	owners := make(map[string]bool)
+	for _, name := range names {
+		owners[kind+"/"+name] = true
+	}
+
+	if kind == "Deployment" {
+		replicaSets := &appsv1.ReplicaSetList{}
+		if err := c.List(ctx, replicaSets, client.InNamespace(namespace)); err != nil {
+			return nil, fmt.Errorf("failed to list replicasets in namespace %s: %w", namespace, err)
+		}
+		owners = make(map[string]bool)
+		for _, replicaSet := range replicaSets.Items {
+			if ownedBy(replicaSet.OwnerReferences, kind, names) {
+				owners["ReplicaSet/"+replicaSet.Name] = true
+			}
+		}
+		if len(owners) == 0 {
+			return nil, nil
+		}
+	}
+
+	podList := &corev1.PodList{}
+	if err := c.List(ctx, podList, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
+	}
+
+	var pods []corev1.Pod
+	for _, pod := range podList.Items {
+		for _, owner := range pod.OwnerReferences {
+			if owners[owner.Kind+"/"+owner.Name] {
+				pods = append(pods, pod)
+				break
+			}
+		}
+	}
+	return pods, nil
+}
+
+// ownedBy reports whether any of the owner references points to one of the
+// named resources of a kind
+func ownedBy(references []metav1.OwnerReference, kind string, names []string) bool {
+	for _, owner := range references {
+		if owner.Kind == kind && slices.Contains(names, owner.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClaimPods returns the pods mounting a PersistentVolumeClaim
+func ClaimPods(ctx context.Context, c client.Client, namespace, name string) ([]corev1.Pod, error) {
+	return claimPods(ctx, c, namespace, []string{name})
+}
+
+// claimPods returns the pods mounting any of the named claims
+func claimPods(ctx context.Context, c client.Client, namespace string, claims []string) ([]corev1.Pod, error) {
+	podList := &corev1.PodList{}
+	if err := c.List(ctx, podList, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
+	}
+	var pods []corev1.Pod
+	for _, pod := range podList.Items {
+		if mountsClaim(pod, claims) {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+// mountsClaim reports whether a pod mounts any of the named claims
+func mountsClaim(pod corev1.Pod, claims []string) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil && slices.Contains(claims, volume.PersistentVolumeClaim.ClaimName) {
+			return true
+		}
+	}
+	return false
+}
+
+// targetScope returns the kind and namespace of the resources an experiment targets
+func targetScope(experiment *chaosv1alpha1.Havock8sExperiment) (kind, namespace string) {
+	kind = experiment.Spec.Target.TargetType
+	if kind == "" {
+		kind = "Pod"
+	}
+
+	namespace = experiment.Spec.Target.Namespace
+	if namespace == "" {
+		namespace = experiment.Namespace
+	}
+	return kind, namespace
+}
+
+// SelectTargets picks targets from candidates according to the selection mode.
+// An empty mode selects all candidates.
+func SelectTargets(candidates []chaosv1alpha1.TargetResourceStatus, mode, value string) ([]chaosv1alpha1.TargetResourceStatus, error) {
+	if len(candidates) == 0 {
+		return candidates, nil
+	}
+
+	var count int
+	switch normalizeTargetMode(mode) {
+	case "", chaosv1alpha1.TargetModeAll:
+		count = len(candidates)
+	case chaosv1alpha1.TargetModeOne:
+		count = 1
+	case chaosv1alpha1.TargetModeFixed:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid value %q for mode %s: must be a non-negative integer", value, mode)
+		}
+		count = n
+	case chaosv1alpha1.TargetModeFixedPercent:
+		percent, err := parsePercent(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for mode %s: %w", value, mode, err)
+		}
+		count = percentOf(len(candidates), percent)
+	case chaosv1alpha1.TargetModeRandomMaxPercent:
+		percent, err := parsePercent(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for mode %s: %w", value, mode, err)
+		}
+		maxCount := percentOf(len(candidates), percent)
+		if maxCount > 0 {
+			count = rand.Intn(maxCount) + 1
+		}
+	default:
+		return nil, fmt.Errorf("unsupported target mode: %s", mode)
+	}
+
+	if count > len(candidates) {
+		count = len(candidates)
+	}
+
+	selected := make([]chaosv1alpha1.TargetResourceStatus, len(candidates))
+	copy(selected, candidates)
+	if count < len(selected) {
+		rand.Shuffle(len(selected), func(i, j int) {
+			selected[i], selected[j] = selected[j], selected[i]
+		})
+		selected = selected[:count]
+		sort.Slice(selected, func(i, j int) bool {
+			return selected[i].Name < selected[j].Name
+		})
+	}
+
+	return selected, nil
+}
+
+// normalizeTargetMode returns the current name of a target mode. Modes are
+// compared in lower case, and the Random and Percentage modes of the original
+// API map to one and fixed-percent.
+func normalizeTargetMode(mode string) string {
+	switch {
+	case strings.EqualFold(mode, chaosv1alpha1.TargetModeRandom):
+		return chaosv1alpha1.TargetModeOne
+	case strings.EqualFold(mode, chaosv1alpha1.TargetModePercentage):
+		return chaosv1alpha1.TargetModeFixedPercent
+	}
+	return strings.ToLower(mode)
+}
+
+// listTargetCandidates lists all resources of the given kind matching the
+// target's name or label selector
+func listTargetCandidates(ctx context.Context, c client.Client, kind, namespace string, target chaosv1alpha1.TargetSpec) ([]chaosv1alpha1.TargetResourceStatus, error) {
+	var objects []client.Object
+
+	if target.Name != "" {
+		obj, err := newTargetObject(kind)
+		if err != nil {
+			return nil, err
+		}
+		err = c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: target.Name}, obj)
 		if err != nil {
 			if client.IgnoreNotFound(err) == nil {
-				return false, nil
+				return nil, nil
 			}
-			return false, err
+			return nil, fmt.Errorf("failed to get %s %s/%s: %w", kind, namespace, target.Name, err)
+		}
+		objects = append(objects, obj)
+	} else {
+		if target.Selector == nil {
+			return nil, fmt.Errorf("target must specify either a name or a selector")
+		}
+		selector, err := metav1.LabelSelectorAsSelector(target.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid target selector: %w", err)
+		}
+		objects, err = listTargetObjects(ctx, c, kind, namespace, selector)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	candidates := make([]chaosv1alpha1.TargetResourceStatus, 0, len(objects))
+	for _, obj := range objects {
+		// Pods that are already terminating make poor victims
+		if obj.GetDeletionTimestamp() != nil {
+			continue
+		}
+		candidates = append(candidates, chaosv1alpha1.TargetResourceStatus{
+			Kind:      kind,
+			Name:      obj.GetName(),
+			Namespace: obj.GetNamespace(),
+			UID:       string(obj.GetUID()),
+			Status:    "Targeted",
+		})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Name < candidates[j].Name
+	})
+
+	return candidates, nil
+}
+
+// listTargetObjects lists resources of the given kind matching a label selector
+func listTargetObjects(ctx context.Context, c client.Client, kind, namespace string, selector labels.Selector) ([]client.Object, error) {
+	opts := []client.ListOption{
+		client.InNamespace(namespace),
+		client.MatchingLabelsSelector{Selector: selector},
+	}
+
+	var objects []client.Object
+	switch kind {
+	case "Pod":
+		list := &corev1.PodList{}
+		if err := c.List(ctx, list, opts...); err != nil {
+			return nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "StatefulSet":
+		list := &appsv1.StatefulSetList{}
+		if err := c.List(ctx, list, opts...); err != nil {
+			return nil, fmt.Errorf("failed to list statefulsets in namespace %s: %w", namespace, err)
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "Deployment":
+		list := &appsv1.DeploymentList{}
+		if err := c.List(ctx, list, opts...); err != nil {
+			return nil, fmt.Errorf("failed to list deployments in namespace %s: %w", namespace, err)
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "ReplicaSet":
+		list := &appsv1.ReplicaSetList{}
+		if err := c.List(ctx, list, opts...); err != nil {
+			return nil, fmt.Errorf("failed to list replicasets in namespace %s: %w", namespace, err)
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "DaemonSet":
+		list := &appsv1.DaemonSetList{}
+		if err := c.List(ctx, list, opts...); err != nil {
+			return nil, fmt.Errorf("failed to list daemonsets in namespace %s: %w", namespace, err)
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "Job":
+		list := &batchv1.JobList{}
+		if err := c.List(ctx, list, opts...); err != nil {
+			return nil, fmt.Errorf("failed to list jobs in namespace %s: %w", namespace, err)
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "PersistentVolumeClaim":
+		list := &corev1.PersistentVolumeClaimList{}
+		if err := c.List(ctx, list, opts...); err != nil {
+			return nil, fmt.Errorf("failed to list persistentvolumeclaims in namespace %s: %w", namespace, err)
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
 		}
-		return true, nil
 	default:
-		return false, nil
+		return nil, fmt.Errorf("unsupported target type: %s", kind)
+	}
+
+	return objects, nil
+}
+
+// newTargetObject returns an empty object for the given target kind
+func newTargetObject(kind string) (client.Object, error) {
+	switch kind {
+	case "Pod":
+		return &corev1.Pod{}, nil
+	case "StatefulSet":
+		return &appsv1.StatefulSet{}, nil
+	case "Deployment":
+		return &appsv1.Deployment{}, nil
+	case "ReplicaSet":
+		return &appsv1.ReplicaSet{}, nil
+	case "DaemonSet":
+		return &appsv1.DaemonSet{}, nil
+	case "Job":
+		return &batchv1.Job{}, nil
+	case "PersistentVolumeClaim":
+		return &corev1.PersistentVolumeClaim{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported target type: %s", kind)
+	}
+}
+
+// parsePercent parses a percentage value such as "30" or "30%"
+func parsePercent(value string) (float64, error) {
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("must be a percentage")
+	}
+	if percent < 0 || percent > 100 {
+		return 0, fmt.Errorf("percentage must be between 0 and 100")
+	}
+	return percent, nil
+}
+
+// percentOf returns the number of items representing percent of total. Any
+// non-zero percentage selects at least one item.
+func percentOf(total int, percent float64) int {
+	count := int(math.Floor(float64(total) * percent / 100))
+	if count == 0 && percent > 0 {
+		count = 1
 	}
-} 
\ No newline at end of file
+	return count
+}
@@ -0,0 +1,384 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestResolveTargets(t *testing.T) {
+	pods := []client.Object{
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "mongodb-0", Namespace: "default", Labels: map[string]string{"app": "mongodb"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "mongodb-1", Namespace: "default", Labels: map[string]string{"app": "mongodb"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "mongodb-2", Namespace: "default", Labels: map[string]string{"app": "mongodb"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "mongodb-3", Namespace: "other", Labels: map[string]string{"app": "mongodb"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "redis-0", Namespace: "default", Labels: map[string]string{"app": "redis"}}},
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "mongodb", Namespace: "default", Labels: map[string]string{"app": "mongodb"}}},
+	}
+
+	tests := []struct {
+		name      string
+		target    chaosv1alpha1.TargetSpec
+		wantCount int
+		wantKind  string
+		wantErr   bool
+	}{
+		{
+			name:      "named pod",
+			target:    chaosv1alpha1.TargetSpec{TargetType: "Pod", Name: "redis-0"},
+			wantCount: 1,
+			wantKind:  "Pod",
+		},
+		{
+			name:      "missing named pod",
+			target:    chaosv1alpha1.TargetSpec{TargetType: "Pod", Name: "missing"},
+			wantCount: 0,
+		},
+		{
+			name: "selector defaults to all pods in experiment namespace",
+			target: chaosv1alpha1.TargetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "mongodb"}},
+			},
+			wantCount: 3,
+			wantKind:  "Pod",
+		},
+		{
+			name: "selector in explicit namespace",
+			target: chaosv1alpha1.TargetSpec{
+				Namespace: "other",
+				Selector:  &metav1.LabelSelector{MatchLabels: map[string]string{"app": "mongodb"}},
+			},
+			wantCount: 1,
+			wantKind:  "Pod",
+		},
+		{
+			name: "mode one",
+			target: chaosv1alpha1.TargetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "mongodb"}},
+				Mode:     chaosv1alpha1.TargetModeOne,
+			},
+			wantCount: 1,
+			wantKind:  "Pod",
+		},
+		{
+			name: "mode fixed",
+			target: chaosv1alpha1.TargetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "mongodb"}},
+				Mode:     chaosv1alpha1.TargetModeFixed,
+				Value:    "2",
+			},
+			wantCount: 2,
+			wantKind:  "Pod",
+		},
+		{
+			name: "mode fixed-percent",
+			target: chaosv1alpha1.TargetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "mongodb"}},
+				Mode:     chaosv1alpha1.TargetModeFixedPercent,
+				Value:    "70",
+			},
+			wantCount: 2,
+			wantKind:  "Pod",
+		},
+		{
+			name: "statefulset selector",
+			target: chaosv1alpha1.TargetSpec{
+				TargetType: "StatefulSet",
+				Selector:   &metav1.LabelSelector{MatchLabels: map[string]string{"app": "mongodb"}},
+			},
+			wantCount: 1,
+			wantKind:  "StatefulSet",
+		},
+		{
+			name:    "no name or selector",
+			target:  chaosv1alpha1.TargetSpec{TargetType: "Pod"},
+			wantErr: true,
+		},
+		{
+			name: "invalid fixed value",
+			target: chaosv1alpha1.TargetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "mongodb"}},
+				Mode:     chaosv1alpha1.TargetModeFixed,
+				Value:    "many",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			_ = appsv1.AddToScheme(scheme)
+			_ = chaosv1alpha1.AddToScheme(scheme)
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(pods...).
+				Build()
+
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-experiment", Namespace: "default"},
+				Spec:       chaosv1alpha1.Havock8sExperimentSpec{Target: tt.target},
+			}
+
+			got, err := ResolveTargets(context.Background(), fakeClient, nil, experiment)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ResolveTargets() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(got) != tt.wantCount {
+				t.Errorf("ResolveTargets() returned %d targets, want %d", len(got), tt.wantCount)
+			}
+			for _, target := range got {
+				if target.Kind != tt.wantKind {
+					t.Errorf("Target kind = %v, want %v", target.Kind, tt.wantKind)
+				}
+				if target.Status != "Targeted" {
+					t.Errorf("Target status = %v, want Targeted", target.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestSelectTargets(t *testing.T) {
+	candidates := make([]chaosv1alpha1.TargetResourceStatus, 10)
+	for i := range candidates {
+		candidates[i] = chaosv1alpha1.TargetResourceStatus{Kind: "Pod", Name: fmt.Sprintf("pod-%d", i)}
+	}
+
+	tests := []struct {
+		name     string
+		mode     string
+		value    string
+		minCount int
+		maxCount int
+		wantErr  bool
+	}{
+		{name: "empty mode selects all", mode: "", minCount: 10, maxCount: 10},
+		{name: "all", mode: "all", minCount: 10, maxCount: 10},
+		{name: "one", mode: "one", minCount: 1, maxCount: 1},
+		{name: "fixed larger than candidates", mode: "fixed", value: "20", minCount: 10, maxCount: 10},
+		{name: "fixed-percent", mode: "fixed-percent", value: "30", minCount: 3, maxCount: 3},
+		{name: "fixed-percent with suffix", mode: "fixed-percent", value: "50%", minCount: 5, maxCount: 5},
+		{name: "fixed-percent rounds up to one", mode: "fixed-percent", value: "1", minCount: 1, maxCount: 1},
+		{name: "random-max-percent", mode: "random-max-percent", value: "40", minCount: 1, maxCount: 4},
+		{name: "legacy One", mode: "One", minCount: 1, maxCount: 1},
+		{name: "legacy All", mode: "All", minCount: 10, maxCount: 10},
+		{name: "legacy Fixed", mode: "Fixed", value: "2", minCount: 2, maxCount: 2},
+		{name: "legacy Random selects one", mode: "Random", minCount: 1, maxCount: 1},
+		{name: "legacy Percentage is a fixed percentage", mode: "Percentage", value: "30", minCount: 3, maxCount: 3},
+		{name: "percent out of range", mode: "fixed-percent", value: "150", wantErr: true},
+		{name: "unknown mode", mode: "most", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SelectTargets(candidates, tt.mode, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SelectTargets() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(got) < tt.minCount || len(got) > tt.maxCount {
+				t.Errorf("SelectTargets() returned %d targets, want between %d and %d", len(got), tt.minCount, tt.maxCount)
+			}
+
+			seen := make(map[string]bool)
+			for _, target := range got {
+				if seen[target.Name] {
+					t.Errorf("Target %s selected more than once", target.Name)
+				}
+				seen[target.Name] = true
+			}
+		})
+	}
+}
+
+func TestWorkloadPods(t *testing.T) {
+	owner := []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "mongodb"}}
+	objects := []client.Object{
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "mongodb", Namespace: "default"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "mongodb-0", Namespace: "default", OwnerReferences: owner}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "mongodb-1", Namespace: "default", OwnerReferences: owner}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "mongodb-backup", Namespace: "default"}},
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "mongodb-arbiter", Namespace: "default"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "mongodb-arbiter-0", Namespace: "default", OwnerReferences: []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "mongodb-arbiter"},
+		}}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api-gateway", Namespace: "default"}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "api-gateway-7c9f", Namespace: "default", OwnerReferences: []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: "Deployment", Name: "api-gateway"},
+		}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api-gateway-7c9f-abcde", Namespace: "default", OwnerReferences: []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "api-gateway-7c9f"},
+		}}},
+		&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "node-exporter", Namespace: "default"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "node-exporter-x2k4p", Namespace: "default", OwnerReferences: []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "node-exporter"},
+		}}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default"}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres-0", Namespace: "default"},
+			Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+				Name:         "data",
+				VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}},
+			}}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		target   chaosv1alpha1.TargetSpec
+		wantPods []string
+	}{
+		{
+			name:     "named pod",
+			target:   chaosv1alpha1.TargetSpec{TargetType: "Pod", Name: "mongodb-backup"},
+			wantPods: []string{"mongodb-backup"},
+		},
+		{
+			name:     "statefulset pods ignore mode",
+			target:   chaosv1alpha1.TargetSpec{TargetType: "StatefulSet", Name: "mongodb", Mode: chaosv1alpha1.TargetModeOne},
+			wantPods: []string{"mongodb-0", "mongodb-1"},
+		},
+		{
+			name:     "statefulset sharing a name prefix",
+			target:   chaosv1alpha1.TargetSpec{TargetType: "StatefulSet", Name: "mongodb-arbiter"},
+			wantPods: []string{"mongodb-arbiter-0"},
+		},
+		{
+			name:     "deployment pods through their replicaset",
+			target:   chaosv1alpha1.TargetSpec{TargetType: "Deployment", Name: "api-gateway"},
+			wantPods: []string{"api-gateway-7c9f-abcde"},
+		},
+		{
+			name:     "daemonset pods",
+			target:   chaosv1alpha1.TargetSpec{TargetType: "DaemonSet", Name: "node-exporter"},
+			wantPods: []string{"node-exporter-x2k4p"},
+		},
+		{
+			name:     "pods mounting a claim",
+			target:   chaosv1alpha1.TargetSpec{TargetType: "PersistentVolumeClaim", Name: "data"},
+			wantPods: []string{"postgres-0"},
+		},
+		{
+			name:   "missing target",
+			target: chaosv1alpha1.TargetSpec{TargetType: "StatefulSet", Name: "missing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			_ = appsv1.AddToScheme(scheme)
+			_ = chaosv1alpha1.AddToScheme(scheme)
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(objects...).
+				Build()
+
+			experiment := &chaosv1alpha1.Havock8sExperiment{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-experiment", Namespace: "default"},
+				Spec:       chaosv1alpha1.Havock8sExperimentSpec{Target: tt.target},
+			}
+
+			pods, err := WorkloadPods(context.Background(), fakeClient, experiment)
+			if err != nil {
+				t.Fatalf("WorkloadPods() error = %v", err)
+			}
+			var names []string
+			for _, pod := range pods {
+				names = append(names, pod.Name)
+			}
+			if fmt.Sprint(names) != fmt.Sprint(tt.wantPods) {
+				t.Errorf("WorkloadPods() = %v, want %v", names, tt.wantPods)
+			}
+		})
+	}
+}
+
+func TestTargetsExist(t *testing.T) {
+	objects := []client.Object{
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "default", UID: "uid-db-0"}},
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", UID: "uid-db"}},
+	}
+
+	tests := []struct {
+		name    string
+		targets []chaosv1alpha1.TargetResourceStatus
+		want    bool
+	}{
+		{
+			name:    "pod with the resolved UID",
+			targets: []chaosv1alpha1.TargetResourceStatus{{Kind: "Pod", Name: "db-0", Namespace: "default", UID: "uid-db-0"}},
+			want:    true,
+		},
+		{
+			name:    "pod recreated under the same name",
+			targets: []chaosv1alpha1.TargetResourceStatus{{Kind: "Pod", Name: "db-0", Namespace: "default", UID: "uid-old"}},
+			want:    false,
+		},
+		{
+			name:    "deleted pod",
+			targets: []chaosv1alpha1.TargetResourceStatus{{Kind: "Pod", Name: "db-1", Namespace: "default", UID: "uid-db-1"}},
+			want:    false,
+		},
+		{
+			name: "one of several targets remains",
+			targets: []chaosv1alpha1.TargetResourceStatus{
+				{Kind: "Pod", Name: "db-1", Namespace: "default", UID: "uid-db-1"},
+				{Kind: "StatefulSet", Name: "db", Namespace: "default", UID: "uid-db"},
+			},
+			want: true,
+		},
+		{
+			name:    "kind that cannot be looked up",
+			targets: []chaosv1alpha1.TargetResourceStatus{{Kind: "Service", Name: "db", Namespace: "default"}},
+			want:    true,
+		},
+		{
+			name: "no targets",
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			_ = appsv1.AddToScheme(scheme)
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(objects...).
+				Build()
+
+			got, err := TargetsExist(context.Background(), fakeClient, tt.targets)
+			if err != nil {
+				t.Fatalf("TargetsExist() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("TargetsExist() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	"github.com/havock8s/havock8s/pkg/chaos"
+	"github.com/havock8s/havock8s/pkg/utils"
+	"github.com/robfig/cron/v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// protectedNamespace can never be targeted by an experiment
+const protectedNamespace = "kube-system"
+
+var webhookLog = logf.Log.WithName("havock8sexperiment-webhook")
+
+// SetupHavock8sExperimentWebhookWithManager registers the defaulting and
+// validating webhooks for Havock8sExperiment with the manager
+func SetupHavock8sExperimentWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&chaosv1alpha1.Havock8sExperiment{}).
+		WithDefaulter(&Havock8sExperimentDefaulter{}).
+		WithValidator(&Havock8sExperimentValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-chaos-havock8s-io-v1alpha1-havock8sexperiment,mutating=true,failurePolicy=fail,sideEffects=None,groups=chaos.havock8s.io,resources=havock8sexperiments,verbs=create;update,versions=v1alpha1,name=mhavock8sexperiment.kb.io,admissionReviewVersions=v1
+
+// Havock8sExperimentDefaulter sets defaults on experiments when they are created or updated
+type Havock8sExperimentDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &Havock8sExperimentDefaulter{}
+
+// Default defaults Target.Namespace to the experiment's namespace
+func (d *Havock8sExperimentDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	experiment, ok := obj.(*chaosv1alpha1.Havock8sExperiment)
+	if !ok {
+		return fmt.Errorf("expected a Havock8sExperiment but got a %T", obj)
+	}
+
+	if experiment.Spec.Target.Namespace == "" {
+		namespace := experiment.Namespace
+		// Objects created without metadata.namespace only carry it on the request
+		if namespace == "" {
+			if req, err := admission.RequestFromContext(ctx); err == nil {
+				namespace = req.Namespace
+			}
+		}
+		experiment.Spec.Target.Namespace = namespace
+	}
+
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-chaos-havock8s-io-v1alpha1-havock8sexperiment,mutating=false,failurePolicy=fail,sideEffects=None,groups=chaos.havock8s.io,resources=havock8sexperiments,verbs=create;update,versions=v1alpha1,name=vhavock8sexperiment.kb.io,admissionReviewVersions=v1
+
+// Havock8sExperimentValidator rejects experiments that would only fail once reconciled
+type Havock8sExperimentValidator struct{}
+
+var _ webhook.CustomValidator = &Havock8sExperimentValidator{}
+
+// ValidateCreate validates a new experiment
+func (v *Havock8sExperimentValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	experiment, ok := obj.(*chaosv1alpha1.Havock8sExperiment)
+	if !ok {
+		return nil, fmt.Errorf("expected a Havock8sExperiment but got a %T", obj)
+	}
+	return nil, validateExperiment(experiment)
+}
+
+// ValidateUpdate validates an updated experiment
+func (v *Havock8sExperimentValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	experiment, ok := newObj.(*chaosv1alpha1.Havock8sExperiment)
+	if !ok {
+		return nil, fmt.Errorf("expected a Havock8sExperiment but got a %T", newObj)
+	}
+
+	// Let experiments that are being deleted drop their finalizer
+	if !experiment.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+	return nil, validateExperiment(experiment)
+}
+
+// ValidateDelete allows every deletion
+func (v *Havock8sExperimentValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateExperiment checks an experiment's spec and returns all problems found
+func validateExperiment(experiment *chaosv1alpha1.Havock8sExperiment) error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	allErrs = append(allErrs, validateTarget(experiment, specPath.Child("target"))...)
+
+	// Chaos type and its parameters
+	chaosTypePath := specPath.Child("chaosType")
+	injector, err := chaos.NewInjector(experiment.Spec.ChaosType, chaos.Dependencies{})
+	if experiment.Spec.ChaosType == "" {
+		allErrs = append(allErrs, field.Required(chaosTypePath, "chaosType is required"))
+	} else if err != nil {
+		allErrs = append(allErrs, field.NotSupported(chaosTypePath, experiment.Spec.ChaosType, chaos.RegisteredChaosTypes()))
+	} else if validator, ok := injector.(chaos.ParameterValidator); ok {
+		if err := validator.ValidateParameters(experiment); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("parameters"), experiment.Spec.Parameters, err.Error()))
+		}
+	}
+
+	// Duration
+	durationPath := specPath.Child("duration")
+	if experiment.Spec.Duration == "" {
+		allErrs = append(allErrs, field.Required(durationPath, "duration is required"))
+	} else if d, err := time.ParseDuration(experiment.Spec.Duration); err != nil {
+		allErrs = append(allErrs, field.Invalid(durationPath, experiment.Spec.Duration, err.Error()))
+	} else if d <= 0 {
+		allErrs = append(allErrs, field.Invalid(durationPath, experiment.Spec.Duration, "must be greater than zero"))
+	}
+
+	// Intensity
+	if experiment.Spec.Intensity < 0 || experiment.Spec.Intensity > 1 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("intensity"), experiment.Spec.Intensity, "must be between 0 and 1"))
+	}
+
+	// Schedule
+	if schedule := experiment.Spec.Schedule; schedule != nil && schedule.Cron != "" {
+		if _, err := cron.ParseStandard(schedule.Cron); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("schedule", "cron"), schedule.Cron, err.Error()))
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	webhookLog.Info("Rejecting invalid experiment", "namespace", experiment.Namespace, "name", experiment.Name, "errors", allErrs.ToAggregate().Error())
+	return apierrors.NewInvalid(
+		chaosv1alpha1.GroupVersion.WithKind("Havock8sExperiment").GroupKind(),
+		experiment.Name,
+		allErrs,
+	)
+}
+
+// validateTarget checks that the target names its resources, stays out of
+// protected namespaces and selects StatefulSet pods consistently
+func validateTarget(experiment *chaosv1alpha1.Havock8sExperiment, targetPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	target := experiment.Spec.Target
+
+	if target.Name == "" && target.Selector == nil {
+		allErrs = append(allErrs, field.Required(targetPath, "either name or selector must be set"))
+	}
+
+	namespace := target.Namespace
+	if namespace == "" {
+		namespace = experiment.Namespace
+	}
+	if namespace == protectedNamespace {
+		allErrs = append(allErrs, field.Forbidden(targetPath.Child("namespace"), fmt.Sprintf("namespace %s is protected", protectedNamespace)))
+	}
+
+	if pods := target.StatefulSetPods; pods != nil {
+		podsPath := targetPath.Child("statefulSetPods")
+		if target.TargetType != "StatefulSet" {
+			allErrs = append(allErrs, field.Invalid(targetPath.Child("targetType"), target.TargetType, "statefulSetPods requires targetType StatefulSet"))
+		}
+		if err := utils.ValidateStatefulSetPods(*pods); err != nil {
+			allErrs = append(allErrs, field.Invalid(podsPath, pods, err.Error()))
+		}
+	}
+
+	return allErrs
+}
@@ -0,0 +1,260 @@
+package webhooks
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	chaosv1alpha1 "github.com/havock8s/havock8s/api/v1alpha1"
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func newExperiment(mutate func(*chaosv1alpha1.Havock8sExperiment)) *chaosv1alpha1.Havock8sExperiment {
+	experiment := &chaosv1alpha1.Havock8sExperiment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-experiment",
+			Namespace: "default",
+		},
+		Spec: chaosv1alpha1.Havock8sExperimentSpec{
+			Target: chaosv1alpha1.TargetSpec{
+				Name:       "test-pod",
+				TargetType: "Pod",
+			},
+			ChaosType: "PodFailure",
+			Duration:  "5m",
+			Intensity: 0.5,
+			Parameters: map[string]string{
+				"failureMode": "terminate",
+			},
+		},
+	}
+	if mutate != nil {
+		mutate(experiment)
+	}
+	return experiment
+}
+
+func TestHavock8sExperimentValidator_ValidateCreate(t *testing.T) {
+	tests := []struct {
+		name       string
+		experiment *chaosv1alpha1.Havock8sExperiment
+		wantFields []string
+	}{
+		{
+			name:       "valid experiment",
+			experiment: newExperiment(nil),
+		},
+		{
+			name: "unknown chaos type",
+			experiment: newExperiment(func(e *chaosv1alpha1.Havock8sExperiment) {
+				e.Spec.ChaosType = "PodFailur"
+			}),
+			wantFields: []string{"spec.chaosType"},
+		},
+		{
+			name: "unparseable duration",
+			experiment: newExperiment(func(e *chaosv1alpha1.Havock8sExperiment) {
+				e.Spec.Duration = "5 minutes"
+			}),
+			wantFields: []string{"spec.duration"},
+		},
+		{
+			name: "missing duration",
+			experiment: newExperiment(func(e *chaosv1alpha1.Havock8sExperiment) {
+				e.Spec.Duration = ""
+			}),
+			wantFields: []string{"spec.duration"},
+		},
+		{
+			name: "intensity out of range",
+			experiment: newExperiment(func(e *chaosv1alpha1.Havock8sExperiment) {
+				e.Spec.Intensity = 1.5
+			}),
+			wantFields: []string{"spec.intensity"},
+		},
+		{
+			name: "missing failure mode",
+			experiment: newExperiment(func(e *chaosv1alpha1.Havock8sExperiment) {
+				e.Spec.Parameters = nil
+			}),
+			wantFields: []string{"spec.parameters"},
+		},
+		{
+			name: "invalid latency",
+			experiment: newExperiment(func(e *chaosv1alpha1.Havock8sExperiment) {
+				e.Spec.ChaosType = "NetworkLatency"
+				e.Spec.Parameters = map[string]string{"latency": "100"}
+			}),
+			wantFields: []string{"spec.parameters"},
+		},
+		{
+			name: "invalid scale mode",
+			experiment: newExperiment(func(e *chaosv1alpha1.Havock8sExperiment) {
+				e.Spec.ChaosType = "StatefulSetScaling"
+				e.Spec.Target.TargetType = "StatefulSet"
+				e.Spec.Parameters = map[string]string{"scaleMode": "sideways"}
+			}),
+			wantFields: []string{"spec.parameters"},
+		},
+		{
+			name: "kube-system target",
+			experiment: newExperiment(func(e *chaosv1alpha1.Havock8sExperiment) {
+				e.Spec.Target.Namespace = "kube-system"
+			}),
+			wantFields: []string{"spec.target.namespace"},
+		},
+		{
+			name: "experiment in kube-system targets its own namespace",
+			experiment: newExperiment(func(e *chaosv1alpha1.Havock8sExperiment) {
+				e.Namespace = "kube-system"
+			}),
+			wantFields: []string{"spec.target.namespace"},
+		},
+		{
+			name: "target without name or selector",
+			experiment: newExperiment(func(e *chaosv1alpha1.Havock8sExperiment) {
+				e.Spec.Target.Name = ""
+			}),
+			wantFields: []string{"spec.target"},
+		},
+		{
+			name: "primary of a StatefulSet",
+			experiment: newExperiment(func(e *chaosv1alpha1.Havock8sExperiment) {
+				e.Spec.Target.TargetType = "StatefulSet"
+				e.Spec.Target.StatefulSetPods = &chaosv1alpha1.StatefulSetPodsSpec{
+					Role: &chaosv1alpha1.PodRoleSpec{Role: "primary", Probe: "postgres"},
+				}
+			}),
+		},
+		{
+			name: "statefulSetPods on a Pod target",
+			experiment: newExperiment(func(e *chaosv1alpha1.Havock8sExperiment) {
+				e.Spec.Target.StatefulSetPods = &chaosv1alpha1.StatefulSetPodsSpec{Ordinal: "highest"}
+			}),
+			wantFields: []string{"spec.target.targetType"},
+		},
+		{
+			name: "reversed ordinal range",
+			experiment: newExperiment(func(e *chaosv1alpha1.Havock8sExperiment) {
+				e.Spec.Target.TargetType = "StatefulSet"
+				e.Spec.Target.StatefulSetPods = &chaosv1alpha1.StatefulSetPodsSpec{OrdinalRange: "3-1"}
+			}),
+			wantFields: []string{"spec.target.statefulSetPods"},
+		},
+		{
+			name: "invalid cron schedule",
+			experiment: newExperiment(func(e *chaosv1alpha1.Havock8sExperiment) {
+				e.Spec.Schedule = &chaosv1alpha1.ScheduleSpec{Cron: "every night"}
+			}),
+			wantFields: []string{"spec.schedule.cron"},
+		},
+		{
+			name: "all problems are reported",
+			experiment: newExperiment(func(e *chaosv1alpha1.Havock8sExperiment) {
+				e.Spec.Duration = "soon"
+				e.Spec.Intensity = -1
+			}),
+			wantFields: []string{"spec.duration", "spec.intensity"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&Havock8sExperimentValidator{}).ValidateCreate(context.Background(), tt.experiment)
+			if len(tt.wantFields) == 0 {
+				if err != nil {
+					t.Fatalf("ValidateCreate() error = %v", err)
+				}
+				return
+			}
+
+			if !apierrors.IsInvalid(err) {
+				t.Fatalf("ValidateCreate() error = %v, want an Invalid error", err)
+			}
+			causes := err.(*apierrors.StatusError).ErrStatus.Details.Causes
+			if len(causes) != len(tt.wantFields) {
+				t.Fatalf("ValidateCreate() causes = %v, want fields %v", causes, tt.wantFields)
+			}
+			for i, cause := range causes {
+				if cause.Field != tt.wantFields[i] {
+					t.Errorf("Cause %d field = %s, want %s", i, cause.Field, tt.wantFields[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHavock8sExperimentValidator_ValidateUpdate(t *testing.T) {
+	validator := &Havock8sExperimentValidator{}
+	invalid := newExperiment(func(e *chaosv1alpha1.Havock8sExperiment) {
+		e.Spec.ChaosType = "Unknown"
+	})
+
+	if _, err := validator.ValidateUpdate(context.Background(), newExperiment(nil), invalid); err == nil {
+		t.Error("ValidateUpdate() accepted an invalid chaos type")
+	}
+
+	// Experiments being deleted must be able to release their finalizer
+	now := metav1.Now()
+	invalid.DeletionTimestamp = &now
+	if _, err := validator.ValidateUpdate(context.Background(), newExperiment(nil), invalid); err != nil {
+		t.Errorf("ValidateUpdate() error = %v for an experiment being deleted", err)
+	}
+}
+
+func TestHavock8sExperimentDefaulter_Default(t *testing.T) {
+	tests := []struct {
+		name          string
+		experiment    *chaosv1alpha1.Havock8sExperiment
+		ctx           context.Context
+		wantNamespace string
+	}{
+		{
+			name:          "defaults to the experiment namespace",
+			experiment:    newExperiment(nil),
+			ctx:           context.Background(),
+			wantNamespace: "default",
+		},
+		{
+			name: "keeps an explicit namespace",
+			experiment: newExperiment(func(e *chaosv1alpha1.Havock8sExperiment) {
+				e.Spec.Target.Namespace = "databases"
+			}),
+			ctx:           context.Background(),
+			wantNamespace: "databases",
+		},
+		{
+			name: "falls back to the request namespace",
+			experiment: newExperiment(func(e *chaosv1alpha1.Havock8sExperiment) {
+				e.Namespace = ""
+			}),
+			ctx: admission.NewContextWithRequest(context.Background(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{Namespace: "staging"},
+			}),
+			wantNamespace: "staging",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := (&Havock8sExperimentDefaulter{}).Default(tt.ctx, tt.experiment); err != nil {
+				t.Fatalf("Default() error = %v", err)
+			}
+			if tt.experiment.Spec.Target.Namespace != tt.wantNamespace {
+				t.Errorf("Target.Namespace = %q, want %q", tt.experiment.Spec.Target.Namespace, tt.wantNamespace)
+			}
+		})
+	}
+}
+
+func TestValidateExperiment_ListsRegisteredChaosTypes(t *testing.T) {
+	err := validateExperiment(newExperiment(func(e *chaosv1alpha1.Havock8sExperiment) {
+		e.Spec.ChaosType = "Unknown"
+	}))
+	if err == nil || !strings.Contains(err.Error(), `"PodFailure"`) {
+		t.Errorf("validateExperiment() error = %v, want the supported chaos types", err)
+	}
+}